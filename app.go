@@ -0,0 +1,15 @@
+package main
+
+import (
+	"xiaoweishu/internal/job"
+	"xiaoweishu/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type App struct {
+	server    *gin.Engine
+	scheduler *job.Scheduler
+	// pushSvc 需要在后台分发实时推送事件
+	pushSvc service.PushService
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// 管理员操作，记录在审计日志里面
+const (
+	AdminActionSearchUsers  = "search_users"
+	AdminActionBanUser      = "ban_user"
+	AdminActionRestrictUser = "restrict_user"
+	AdminActionUnbanUser    = "unban_user"
+	AdminActionViewArticle  = "view_article"
+	AdminActionHideArticle  = "hide_article"
+	AdminActionShowArticle  = "show_article"
+	AdminActionAcceptReport = "accept_report"
+	AdminActionRejectReport = "reject_report"
+)
+
+// AdminOperator 执行操作的管理员
+type AdminOperator struct {
+	Uid int64
+	IP  string
+}
+
+// AdminAuditLog 管理员的操作记录，包括查询，只增不改
+type AdminAuditLog struct {
+	Id       int64
+	Operator AdminOperator
+	// Action 取值见 AdminActionXXX
+	Action string
+	// TargetId 操作的用户、文章或者举报，搜索用户的时候为 0
+	TargetId int64
+	// Detail 封禁的原因、搜索的关键字、审核举报的备注等
+	Detail string
+	Ctime  time.Time
+}
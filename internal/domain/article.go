@@ -1,13 +1,60 @@
 package domain
 
+import "time"
+
 type Article struct {
 	Id      int64
 	Title   string
 	Content string
 	Author  Author
+	Tags    []string
+	Status  ArticleStatus
+	// Hidden 审核隐藏，被隐藏的文章只有作者本人可见
+	Hidden  bool
+	ReadCnt int64
+	// DeletedAt 放入回收站的时间，零值表示没有删除
+	DeletedAt time.Time
+	// Version 乐观锁版本号，保存的时候带上读到的版本号，不一致说明文章已经在别处被修改
+	Version int64
+	Ctime   time.Time
+	Utime   time.Time
 }
 
 type Author struct {
+	Id     int64
+	Name   string
+	Avatar Avatar
+}
+
+type ArticleStatus uint8
+
+const (
+	// ArticleStatusUnknown 未知状态，作为零值
+	ArticleStatusUnknown ArticleStatus = iota
+	// ArticleStatusUnpublished 未发表，即草稿
+	ArticleStatusUnpublished
+	// ArticleStatusPublished 已发表
+	ArticleStatusPublished
+)
+
+func (s ArticleStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s ArticleStatus) String() string {
+	switch s {
+	case ArticleStatusUnpublished:
+		return "unpublished"
+	case ArticleStatusPublished:
+		return "published"
+	default:
+		return "unknown"
+	}
+}
+
+// ArticleImportResult 导入文章时单个文件的结果，Err 不为 nil 说明这个文件没有导入
+type ArticleImportResult struct {
+	File string
 	Id   int64
-	Name string
+	Err  error
 }
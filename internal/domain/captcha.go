@@ -0,0 +1,8 @@
+package domain
+
+// Captcha 一张图片验证码，答案只保存在服务端
+type Captcha struct {
+	Id string
+	// Image PNG 格式的图片
+	Image []byte
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// LoginGuard 一个账号和一个 IP 最近的密码登录失败情况
+// 账号不存在的时候也一样统计，这样没有办法通过锁定和延迟判断账号是否存在
+type LoginGuard struct {
+	// Failures 账号连续失败的次数，登录成功之后清零
+	Failures    int64
+	LastFailure time.Time
+	// IPFailures 同一个 IP 在统计窗口里面失败的次数，不管是哪个账号
+	IPFailures int64
+	// LockedUntil 零值表示没有锁定
+	LockedUntil time.Time
+}
+
+// 解锁验证码的发送方式
+const (
+	LoginUnlockChannelEmail = "email"
+	LoginUnlockChannelSMS   = "sms"
+)
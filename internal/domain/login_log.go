@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"net"
+	"time"
+)
+
+// LoginLog 一次登录尝试
+type LoginLog struct {
+	Id int64
+	// Uid 登录失败的时候可能不知道是哪个用户，这时为 0
+	Uid int64
+	// Account 登录时输入的手机号或者邮箱，微信登录为空
+	Account string
+	// Method 登录方式，取值见 LoginMethodXXX
+	Method string
+	Status LoginStatus
+	// Reason 失败或者需要二次验证的原因
+	Reason    string
+	IP        string
+	UserAgent string
+	Ctime     time.Time
+}
+
+type LoginStatus uint8
+
+const (
+	LoginStatusUnknown LoginStatus = iota
+	// LoginStatusSuccess 登录成功，签发了 token
+	LoginStatusSuccess
+	// LoginStatusFailed 密码、验证码不对等
+	LoginStatusFailed
+	// LoginStatusChallenged 验证通过了但是登录可疑，要求短信二次验证
+	LoginStatusChallenged
+	// LoginStatusMFAPending 第一步验证通过了，等待两步验证，两步验证通过之后另外记录一条成功
+	LoginStatusMFAPending
+)
+
+func (s LoginStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s LoginStatus) String() string {
+	switch s {
+	case LoginStatusSuccess:
+		return "success"
+	case LoginStatusFailed:
+		return "failed"
+	case LoginStatusChallenged:
+		return "challenged"
+	case LoginStatusMFAPending:
+		return "mfa_pending"
+	default:
+		return "unknown"
+	}
+}
+
+// IPRange IPv4 取 /24 网段，IPv6 取 /48 网段，同一个网段认为是同一个地方
+// 解析不了的 IP 原样返回
+func (l LoginLog) IPRange() string {
+	ip := net.ParseIP(l.IP)
+	if ip == nil {
+		return l.IP
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
+
+// LoginFamiliarity 以前成功的登录里面，有多少次是同一个设备、同一个网段
+type LoginFamiliarity struct {
+	Total      int64
+	SameDevice int64
+	SameRange  int64
+}
+
+// LoginChallenge 可疑登录的二次验证，验证码正确之后才签发 token
+// 优先发短信，没有绑定手机号的时候发到验证过的邮箱
+type LoginChallenge struct {
+	Token string
+	Uid   int64
+	// Method 原本的登录方式，二次验证通过之后会话还是记录这个登录方式
+	Method string
+	// Phone 接收验证码的手机号，验证码发到邮箱的时候为空
+	Phone string
+	// Email 接收验证码的邮箱，只有 Phone 为空的时候才有
+	Email     string
+	IP        string
+	UserAgent string
+	Reason    string
+}
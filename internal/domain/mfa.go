@@ -0,0 +1,21 @@
+package domain
+
+// UserMFA 用户的两步验证
+type UserMFA struct {
+	Uid int64
+	// Secret base32 编码的 TOTP 密钥
+	Secret string
+	// Enabled 绑定之后用第一个验证码确认了才算开启
+	Enabled bool
+	// LastStep 最后一次用过的 TOTP 时间步
+	LastStep int64
+}
+
+// MFAPending 密码等第一步验证已经通过，等待两步验证的登录
+type MFAPending struct {
+	Token     string
+	Uid       int64
+	Method    string
+	IP        string
+	UserAgent string
+}
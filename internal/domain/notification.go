@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// NotificationBizLogin 账号登录提醒
+	NotificationBizLogin = "login"
+	// NotificationBizWechatBind 绑定微信提醒
+	NotificationBizWechatBind = "wechat_bind"
+	// NotificationBizSuspiciousLogin 可疑登录提醒，没有绑定手机号不能二次验证的时候发送
+	NotificationBizSuspiciousLogin = "suspicious_login"
+)
+
+// Notification 站内通知
+// 同一个用户 Biz 和 AggKey 都相同的未读通知会被聚合成一条，Count 是聚合的次数，ActorCnt 是其中不同的人数
+type Notification struct {
+	Id  int64
+	Uid int64
+	Biz string
+	// AggKey 聚合用的 key，为空表示不聚合
+	AggKey string
+	// Actor 触发通知的人，例如点赞的用户，系统通知可以为空
+	Actor    string
+	Content  string
+	Count    int64
+	ActorCnt int64
+	Read     bool
+	Ctime    time.Time
+	Utime    time.Time
+}
+
+// Text 展示给用户的文案
+// 多个人触发的显示为最近的一个人和其他多少人，同一个人或者系统重复触发的带上次数
+func (n Notification) Text() string {
+	switch {
+	case n.ActorCnt > 1:
+		return fmt.Sprintf("%s 和其他 %d 人%s", n.Actor, n.ActorCnt-1, n.Content)
+	case n.Count <= 1:
+		return n.Actor + n.Content
+	default:
+		return fmt.Sprintf("%s%s（共 %d 次）", n.Actor, n.Content, n.Count)
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// PrivacyLevel 其他用户能看到多少，零值是最严格的
+type PrivacyLevel uint8
+
+const (
+	// PrivacyHidden 完全不展示
+	PrivacyHidden PrivacyLevel = iota
+	// PrivacyMasked 展示打码之后的，例如 138****0000
+	PrivacyMasked
+)
+
+func (p PrivacyLevel) ToUint8() uint8 {
+	return uint8(p)
+}
+
+func (p PrivacyLevel) String() string {
+	switch p {
+	case PrivacyMasked:
+		return "masked"
+	default:
+		return "hidden"
+	}
+}
+
+// UserPrivacy 个人主页上手机号和邮箱的展示方式，任何设置都不会展示完整的
+type UserPrivacy struct {
+	Email PrivacyLevel
+	Phone PrivacyLevel
+}
+
+// PublicProfile 其他用户能看到的个人主页，手机号和邮箱已经按照隐私设置处理过了
+type PublicProfile struct {
+	Id       int64
+	Handle   string
+	NickName string
+	AboutMe  string
+	Avatar   Avatar
+	// Email Phone 隐私设置不展示的时候为空
+	Email string
+	Phone string
+	// ArticleCnt 发表并且没有被隐藏的文章数
+	ArticleCnt int64
+	ReadCnt    int64
+	SeriesCnt  int64
+	// MergedInto 账号被合并到了哪个用户，调用方需要跳转
+	MergedInto int64
+	Ctime      time.Time
+}
+
+// AuthorStats 作者发表的文章的统计
+type AuthorStats struct {
+	ArticleCnt int64
+	ReadCnt    int64
+}
+
+// MaskPhone 只显示前 3 位和后 4 位
+func MaskPhone(phone string) string {
+	if len(phone) < 7 {
+		return phone
+	}
+	return phone[:3] + "****" + phone[len(phone)-4:]
+}
+
+// MaskEmail 用户名只显示第一个字符，域名完整显示
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+const (
+	// PushEventSessionLogout 某个会话退出登录，Data 中的 ssid 是退出的会话
+	PushEventSessionLogout = "session_logout"
+)
+
+// PushEvent 实时推送给用户所有在线连接的事件
+type PushEvent struct {
+	Uid  int64             `json:"uid"`
+	Type string            `json:"type"`
+	Data map[string]string `json:"data,omitempty"`
+	// Ctime 事件产生的时间
+	Ctime time.Time `json:"ctime"`
+}
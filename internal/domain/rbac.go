@@ -0,0 +1,50 @@
+package domain
+
+// 角色，一个用户可以有多个角色，没有角色的就是普通用户
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+)
+
+// 权限，接口上校验的是权限而不是角色，调整角色能做什么只需要修改 rolePermissions
+const (
+	PermUserRead     = "user:read"
+	PermUserBan      = "user:ban"
+	PermArticleRead  = "article:read"
+	PermArticleHide  = "article:hide"
+	PermAuditLogRead = "audit_log:read"
+	PermReportReview = "report:review"
+)
+
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermUserRead, PermUserBan,
+		PermArticleRead, PermArticleHide,
+		PermAuditLogRead,
+		PermReportReview,
+	},
+	// RoleModerator 内容审核，只处理文章和举报，不能封禁账号
+	RoleModerator: {
+		PermUserRead,
+		PermArticleRead, PermArticleHide,
+		PermReportReview,
+	},
+}
+
+// ValidRole 是不是已知的角色，数据库里面不认识的角色直接忽略
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission 任意一个角色有这个权限就可以
+func HasPermission(roles []string, perm string) bool {
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// Report 举报，可以针对文章，也可以针对用户的个人资料
+type Report struct {
+	Id       int64
+	Reporter int64
+	// Biz 被举报对象的类型，和 BizId 一起确定被举报对象
+	Biz   string
+	BizId int64
+	// Field 举报个人资料时，具体是哪个字段违规
+	Field  string
+	Reason string
+	Status ReportStatus
+	Ctime  time.Time
+	Utime  time.Time
+}
+
+const (
+	ReportBizArticle = "article"
+	ReportBizUser    = "user"
+)
+
+const (
+	ProfileFieldNickName = "nickname"
+	ProfileFieldAboutMe  = "about_me"
+)
+
+type ReportStatus uint8
+
+const (
+	ReportStatusUnknown ReportStatus = iota
+	// ReportStatusPending 待审核
+	ReportStatusPending
+	// ReportStatusAccepted 举报成立
+	ReportStatusAccepted
+	// ReportStatusRejected 举报驳回
+	ReportStatusRejected
+)
+
+func (s ReportStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+// ReportDecision 审核记录，每一次审核都会留下一条，用于审计
+type ReportDecision struct {
+	Id       int64
+	ReportId int64
+	Reviewer int64
+	From     ReportStatus
+	To       ReportStatus
+	Note     string
+	Ctime    time.Time
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Series 专栏，作者把多篇文章按顺序组织在一起，比如分成多个部分的教程
+type Series struct {
+	Id     int64
+	Title  string
+	Author Author
+	// ArticleIds 专栏中的文章，按照阅读顺序排列
+	ArticleIds []int64
+	// Version 修改文章列表的时候用来判断期间有没有被别人修改
+	Version int64
+	Ctime   time.Time
+	Utime   time.Time
+}
+
+// SeriesNavigation 文章在专栏中的位置，Prev 和 Next 的 Id 为 0 表示没有
+type SeriesNavigation struct {
+	Series Series
+	Prev   Article
+	Next   Article
+}
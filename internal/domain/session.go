@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// Session 一次登录产生的会话，access token 和 refresh token 通过 Ssid 关联到会话
+type Session struct {
+	Ssid      string
+	Uid       int64
+	UserAgent string
+	IP        string
+	// LoginMethod 登录方式，取值见 LoginMethodXXX
+	LoginMethod string
+	// Ctime 登录时间，refresh token 从这个时间开始计算有效期
+	Ctime      time.Time
+	LastActive time.Time
+}
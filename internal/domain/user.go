@@ -4,14 +4,101 @@ import "time"
 
 // User 领域对象，是 DDD 中的 entity
 type User struct {
-	Id       int64
-	Email    string
-	Phone    string
-	Password string
-	NickName string
-	Birthday string
-	AboutMe  string
+	Id int64
+	// Handle 用户自己设置的唯一用户名，不区分大小写，还没有设置的时候为空
+	Handle string
+	// HandleUtime 上一次修改用户名的时间，用来限制修改频率
+	HandleUtime time.Time
+	Email       string
+	// EmailVerified 邮箱注册的用户需要验证邮箱之后才能发表文章、修改资料
+	EmailVerified bool
+	Phone         string
+	Password      string
+	NickName      string
+	Birthday      string
+	AboutMe       string
+	Avatar        Avatar
 	// 防止以后有dingding 等第三方登录
 	WechatInfo WechatInfo
-	Ctime      time.Time
+	// MergedInto 账号被合并到了哪个用户，0 表示没有被合并
+	MergedInto int64
+	// DeleteAt 申请注销之后真正注销的时间，零值表示没有申请注销
+	DeleteAt time.Time
+	// Ban 管理员的封禁或者限制
+	Ban UserBan
+	// Privacy 个人主页上手机号和邮箱的展示方式
+	Privacy UserPrivacy
+	Ctime   time.Time
+}
+
+// UserBan 封禁状态，过了到期时间自动解除
+type UserBan struct {
+	Status UserBanStatus
+	Reason string
+	// Until 到期时间，零值表示永久
+	Until time.Time
+}
+
+// Effective 当前生效的状态，已经到期的当作没有封禁
+func (b UserBan) Effective(now time.Time) UserBanStatus {
+	if !b.Until.IsZero() && !now.Before(b.Until) {
+		return UserBanStatusNone
+	}
+	return b.Status
+}
+
+type UserBanStatus uint8
+
+const (
+	UserBanStatusNone UserBanStatus = iota
+	// UserBanStatusRestricted 限制，只能浏览，不能发表文章和修改资料
+	UserBanStatusRestricted
+	// UserBanStatusBanned 封禁，不能登录，已经登录的设备全部退出
+	UserBanStatusBanned
+)
+
+func (s UserBanStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s UserBanStatus) String() string {
+	switch s {
+	case UserBanStatusRestricted:
+		return "restricted"
+	case UserBanStatusBanned:
+		return "banned"
+	default:
+		return "none"
+	}
+}
+
+// UserMergeLog 账号合并记录
+type UserMergeLog struct {
+	Id         int64
+	SurvivorId int64
+	MergedId   int64
+	// MovedFields 转移给保留账号的登录方式
+	MovedFields []string
+	// DroppedFields 保留账号已经有了，被丢弃的登录方式
+	DroppedFields []string
+	ArticleCnt    int
+	SeriesCnt     int
+	Ctime         time.Time
+}
+
+// 登录方式，绑定和解绑的时候使用
+const (
+	LoginMethodPhone  = "phone"
+	LoginMethodEmail  = "email"
+	LoginMethodWechat = "wechat"
+)
+
+// Avatar 头像，Key 为空表示还没有上传过头像
+type Avatar struct {
+	// Key 头像在文件存储中的前缀，不同尺寸的文件都以它开头
+	Key string
+	// Small Medium Large 分别是 48、96、256 像素的头像地址
+	Small  string
+	Medium string
+	Large  string
 }
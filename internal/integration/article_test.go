@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/integration/startup"
 	"xiaoweishu/internal/repository/dao"
+	"xiaoweishu/internal/web"
 	ijwt "xiaoweishu/internal/web/jwt"
 
 	"github.com/gin-gonic/gin"
@@ -22,6 +24,7 @@ type Article struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	Version int64  `json:"version"`
 }
 
 // 预期输出
@@ -97,6 +100,8 @@ func (s *ArticleTestSuite) TestArticleEdit() {
 					Title:    "标题",
 					Content:  "内容",
 					AuthorId: 123,
+					Status:   domain.ArticleStatusUnpublished.ToUint8(),
+					Version:  1,
 					Ctime:    0,
 					Utime:    0,
 				}, art)
@@ -120,6 +125,7 @@ func (s *ArticleTestSuite) TestArticleEdit() {
 					Title:    "标题",
 					Content:  "内容",
 					AuthorId: 123,
+					Version:  1,
 					Ctime:    123,
 					Utime:    234,
 				}).Error
@@ -137,6 +143,8 @@ func (s *ArticleTestSuite) TestArticleEdit() {
 					Title:    "新的标题",
 					Content:  "新的内容",
 					AuthorId: 123,
+					Status:   domain.ArticleStatusUnpublished.ToUint8(),
+					Version:  2,
 					Ctime:    123,
 					Utime:    0,
 				}, art)
@@ -145,6 +153,7 @@ func (s *ArticleTestSuite) TestArticleEdit() {
 				Id:      2,
 				Title:   "新的标题",
 				Content: "新的内容",
+				Version: 1,
 			},
 			wantCode: http.StatusOK,
 			wantRes: Result[int64]{
@@ -194,6 +203,47 @@ func (s *ArticleTestSuite) TestArticleEdit() {
 				Data: 0,
 			},
 		},
+		{
+			name: "版本号不一致-修改失败",
+			before: func(t *testing.T) {
+				// 另外一个标签页已经保存过了
+				err := s.db.Create(&dao.Article{
+					Id:       4,
+					Title:    "标题",
+					Content:  "内容",
+					AuthorId: 123,
+					Version:  3,
+					Ctime:    123,
+					Utime:    234,
+				}).Error
+				assert.NoError(t, err)
+			},
+			after: func(t *testing.T) {
+				var art dao.Article
+				err := s.db.Where("id=?", 4).First(&art).Error
+				assert.NoError(t, err)
+				assert.Equal(t, dao.Article{
+					Id:       4,
+					Title:    "标题",
+					Content:  "内容",
+					AuthorId: 123,
+					Version:  3,
+					Ctime:    123,
+					Utime:    234,
+				}, art)
+			},
+			article: Article{
+				Id:      4,
+				Title:   "新的标题",
+				Content: "新的内容",
+				Version: 2,
+			},
+			wantCode: http.StatusOK,
+			wantRes: Result[int64]{
+				Code: web.CodeArticleVersionConflict,
+				Msg:  "文章已经在别处被修改，请刷新后再编辑",
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
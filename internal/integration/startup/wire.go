@@ -13,44 +13,152 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 )
 
 var thirdPartySet = wire.NewSet(
 	ioc.InitDB, ioc.InitRedis, ioc.InitLogger,
+	wire.Bind(new(redis.Cmdable), new(redis.UniversalClient)),
 )
 
 var userSvcProvider = wire.NewSet(
+	ioc.NewStorageConfig,
+	ioc.InitFileStorage,
 	dao.NewUserDao,
 	cache.NewUserCache,
 	repository.NewUserRepository,
 	service.NewUserService,
 )
 
+var notificationSvcProvider = wire.NewSet(
+	dao.NewGormNotificationDao,
+	cache.NewNotificationCache,
+	repository.NewNotificationRepository,
+	service.NewNotificationService,
+)
+
+var pushSvcProvider = wire.NewSet(
+	cache.NewPushCache,
+	repository.NewPushRepository,
+	service.NewPushService,
+)
+
+var sessionSvcProvider = wire.NewSet(
+	cache.NewSessionCache,
+	repository.NewSessionRepository,
+	service.NewSessionService,
+)
+
+var loginAuditSvcProvider = wire.NewSet(
+	dao.NewGormLoginLogDao,
+	cache.NewLoginChallengeCache,
+	repository.NewLoginLogRepository,
+	service.NewLoginAuditService,
+	cache.NewLoginGuardCache,
+	repository.NewLoginGuardRepository,
+	service.NewLoginGuardService,
+	cache.NewCaptchaCache,
+	repository.NewCaptchaRepository,
+	ioc.InitCaptchaService,
+	dao.NewGormUserMFADao,
+	cache.NewMFAPendingCache,
+	repository.NewUserMFARepository,
+	ioc.InitMFAService,
+)
+
 var articleSvcProvider = wire.NewSet(
 	repository.NewArticleRepository,
 	dao.NewGormArticleDao,
 	service.NewArticleService,
+	service.NewArticleArchiveService,
+	dao.NewGormSeriesDao,
+	repository.NewSeriesRepository,
+	service.NewSeriesService,
+	cache.NewArticleDraftCache,
+	repository.NewArticleDraftRepository,
+	service.NewArticleDraftService,
+)
+
+var reportSvcProvider = wire.NewSet(
+	dao.NewGormReportDao,
+	repository.NewReportRepository,
+	service.NewReportService,
+)
+
+var adminSvcProvider = wire.NewSet(
+	dao.NewGormUserRoleDao,
+	repository.NewUserRoleRepository,
+	service.NewUserRoleService,
+	dao.NewGormAdminAuditLogDao,
+	repository.NewAdminAuditLogRepository,
+	service.NewAdminService,
+)
+
+var feedSvcProvider = wire.NewSet(
+	cache.NewFeedCache,
+	repository.NewFeedRepository,
+	ioc.NewFeedConfig,
+	service.NewFeedService,
 )
 
 func InitWebServer() *gin.Engine {
 	wire.Build(
 		thirdPartySet,
 		userSvcProvider,
+		notificationSvcProvider,
+		pushSvcProvider,
+		sessionSvcProvider,
+		loginAuditSvcProvider,
 		articleSvcProvider,
+		reportSvcProvider,
+		feedSvcProvider,
+		adminSvcProvider,
 		// DAO
 		cache.NewCodeCache,
+		dao.NewGormUserMergeDao,
+		dao.NewGormUserDeletionDao,
 		// Repository
 		repository.NewCodeRepository,
+		repository.NewUserMergeRepository,
+		repository.NewUserDeletionRepository,
 		// Service
 		service.NewCodeService,
+		ioc.InitPasswordResetService,
+		service.NewEmailVerifyService,
+		service.NewAccountBindService,
+		ioc.InitAccountMergeService,
+		ioc.NewAccountDeletionConfig,
+		service.NewAccountDataService,
+		service.NewProfileService,
 		ioc.InitSmsService,
+		ioc.InitEmailService,
 		ioc.InitOauth2WechatService,
 		// Handler
 		ijwt.NewRedisJwtHandler,
+		wire.Bind(new(ijwt.Notifier), new(service.NotificationService)),
+		wire.Bind(new(ijwt.Pusher), new(service.PushService)),
+		wire.Bind(new(ijwt.SessionStore), new(service.SessionService)),
+		wire.Bind(new(ijwt.RoleProvider), new(service.UserRoleService)),
 		web.NewUserHandler,
+		web.NewProfileHandler,
+		web.NewAccountBindHandler,
+		web.NewAccountMergeHandler,
+		web.NewAccountDataHandler,
+		web.NewSessionHandler,
+		web.NewLoginAuditHandler,
+		web.NewCaptchaHandler,
+		web.NewMFAHandler,
 		web.NewArticleHandler,
+		web.NewArticleArchiveHandler,
+		web.NewArticleDraftHandler,
 		ioc.NewWechatHandlerConfig,
 		web.NewOauth2WechatHandler,
+		web.NewReportHandler,
+		web.NewFeedHandler,
+		web.NewSeriesHandler,
+		web.NewNotificationHandler,
+		web.NewPushHandler,
+		web.NewAdminHandler,
 
 		// middlewares
 		ioc.InitMiddlewares,
@@ -61,6 +169,6 @@ func InitWebServer() *gin.Engine {
 }
 
 func InitArticleHandler() *web.ArticleHandler {
-	wire.Build(thirdPartySet, dao.NewGormArticleDao, repository.NewArticleRepository, service.NewArticleService, web.NewArticleHandler)
+	wire.Build(thirdPartySet, userSvcProvider, notificationSvcProvider, articleSvcProvider, web.NewArticleHandler)
 	return &web.ArticleHandler{}
 }
@@ -0,0 +1,59 @@
+package job
+
+import (
+	"context"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+)
+
+const accountDeletionBatch = 100
+
+// AccountDeletionJob 定时注销已经过了冷静期的账号
+type AccountDeletionJob struct {
+	svc    service.AccountDataService
+	jwtHdl ijwt.Handler
+	l      logger.LoggerV1
+}
+
+func NewAccountDeletionJob(svc service.AccountDataService,
+	jwtHdl ijwt.Handler,
+	l logger.LoggerV1) *AccountDeletionJob {
+	return &AccountDeletionJob{
+		svc:    svc,
+		jwtHdl: jwtHdl,
+		l:      l,
+	}
+}
+
+func (j *AccountDeletionJob) Name() string {
+	return "account_deletion"
+}
+
+func (j *AccountDeletionJob) Run(ctx context.Context) error {
+	for {
+		uids, err := j.svc.ListDueDeletion(ctx, accountDeletionBatch)
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, uid := range uids {
+			if err = j.delete(ctx, uid); err != nil {
+				failed++
+				j.l.Error("注销账号失败", logger.Int64("uid", uid), logger.Error(err))
+			}
+		}
+		// 有失败的留到下一次，避免反复查到同一批用户
+		if failed > 0 || len(uids) < accountDeletionBatch {
+			return nil
+		}
+	}
+}
+
+// delete 先让登录失效再清空数据，登录失效失败的话这一次不注销，下一次重试
+func (j *AccountDeletionJob) delete(ctx context.Context, uid int64) error {
+	if err := j.jwtHdl.RevokeSessions(ctx, uid); err != nil {
+		return err
+	}
+	return j.svc.Delete(ctx, uid)
+}
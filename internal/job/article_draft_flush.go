@@ -0,0 +1,32 @@
+package job
+
+import (
+	"context"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+)
+
+// ArticleDraftFlushJob 定时把自动保存的草稿持久化到 MySQL
+type ArticleDraftFlushJob struct {
+	svc service.ArticleDraftService
+	l   logger.LoggerV1
+}
+
+func NewArticleDraftFlushJob(svc service.ArticleDraftService, l logger.LoggerV1) *ArticleDraftFlushJob {
+	return &ArticleDraftFlushJob{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (j *ArticleDraftFlushJob) Name() string {
+	return "article_draft_flush"
+}
+
+func (j *ArticleDraftFlushJob) Run(ctx context.Context) error {
+	cnt, err := j.svc.FlushDirty(ctx)
+	if cnt > 0 {
+		j.l.Debug("持久化自动保存的草稿", logger.Int64("cnt", cnt))
+	}
+	return err
+}
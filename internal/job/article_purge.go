@@ -0,0 +1,32 @@
+package job
+
+import (
+	"context"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+)
+
+// ArticlePurgeJob 定时清理回收站中过期的文章
+type ArticlePurgeJob struct {
+	svc service.ArticleService
+	l   logger.LoggerV1
+}
+
+func NewArticlePurgeJob(svc service.ArticleService, l logger.LoggerV1) *ArticlePurgeJob {
+	return &ArticlePurgeJob{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (j *ArticlePurgeJob) Name() string {
+	return "article_purge"
+}
+
+func (j *ArticlePurgeJob) Run(ctx context.Context) error {
+	cnt, err := j.svc.PurgeExpired(ctx)
+	if cnt > 0 {
+		j.l.Info("清理回收站过期文章", logger.Int64("cnt", cnt))
+	}
+	return err
+}
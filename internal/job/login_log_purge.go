@@ -0,0 +1,38 @@
+package job
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+)
+
+// LoginLogPurgeJob 定时删除过了保留期的登录记录，登录记录里面有 IP 和设备信息
+type LoginLogPurgeJob struct {
+	svc service.LoginAuditService
+	// retention 登录记录保留多久
+	retention time.Duration
+	l         logger.LoggerV1
+}
+
+func NewLoginLogPurgeJob(svc service.LoginAuditService,
+	retention time.Duration,
+	l logger.LoggerV1) *LoginLogPurgeJob {
+	return &LoginLogPurgeJob{
+		svc:       svc,
+		retention: retention,
+		l:         l,
+	}
+}
+
+func (j *LoginLogPurgeJob) Name() string {
+	return "login_log_purge"
+}
+
+func (j *LoginLogPurgeJob) Run(ctx context.Context) error {
+	cnt, err := j.svc.PurgeExpired(ctx, time.Now().Add(-j.retention))
+	if cnt > 0 {
+		j.l.Info("删除过期的登录记录", logger.Int64("cnt", cnt))
+	}
+	return err
+}
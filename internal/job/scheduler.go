@@ -0,0 +1,87 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+	"xiaoweishu/internal/pkg/logger"
+)
+
+// Scheduler 按照固定间隔调度 Job
+// 每个 Job 一个 goroutine，同一个 Job 不会并发执行
+type Scheduler struct {
+	jobs   []scheduledJob
+	l      logger.LoggerV1
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type scheduledJob struct {
+	job      Job
+	interval time.Duration
+}
+
+func NewScheduler(l logger.LoggerV1) *Scheduler {
+	return &Scheduler{
+		l: l,
+	}
+}
+
+// Register 注册任务，必须在 Start 之前调用
+func (s *Scheduler) Register(j Job, interval time.Duration) *Scheduler {
+	s.jobs = append(s.jobs, scheduledJob{
+		job:      j,
+		interval: interval,
+	})
+	return s
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	for _, sj := range s.jobs {
+		s.wg.Add(1)
+		go func(sj scheduledJob) {
+			defer s.wg.Done()
+			s.loop(ctx, sj)
+		}(sj)
+	}
+}
+
+// Stop 停止调度，并且等待正在执行的任务结束
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, sj scheduledJob) {
+	ticker := time.NewTicker(sj.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, sj)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, sj scheduledJob) {
+	// 单次执行不能超过调度间隔
+	ctx, cancel := context.WithTimeout(ctx, sj.interval)
+	defer cancel()
+	start := time.Now()
+	err := sj.job.Run(ctx)
+	if err != nil {
+		s.l.Error("执行定时任务失败",
+			logger.String("job", sj.job.Name()),
+			logger.Error(err))
+		return
+	}
+	s.l.Debug("执行定时任务成功",
+		logger.String("job", sj.job.Name()),
+		logger.Int64("cost_ms", time.Since(start).Milliseconds()))
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+)
+
+// UnverifiedUserPurgeJob 定时删除注册之后一直没有验证邮箱的用户
+type UnverifiedUserPurgeJob struct {
+	svc    service.EmailVerifyService
+	jwtHdl ijwt.Handler
+	// retention 注册之后保留多久
+	retention time.Duration
+	l         logger.LoggerV1
+}
+
+func NewUnverifiedUserPurgeJob(svc service.EmailVerifyService,
+	jwtHdl ijwt.Handler,
+	retention time.Duration,
+	l logger.LoggerV1) *UnverifiedUserPurgeJob {
+	return &UnverifiedUserPurgeJob{
+		svc:       svc,
+		jwtHdl:    jwtHdl,
+		retention: retention,
+		l:         l,
+	}
+}
+
+func (j *UnverifiedUserPurgeJob) Name() string {
+	return "unverified_user_purge"
+}
+
+func (j *UnverifiedUserPurgeJob) Run(ctx context.Context) error {
+	uids, err := j.svc.PurgeUnverified(ctx, time.Now().Add(-j.retention))
+	if len(uids) > 0 {
+		j.l.Info("删除没有验证邮箱的用户", logger.Int64("cnt", int64(len(uids))))
+	}
+	// 用户已经删掉了，撤销失败只是已经登录的设备还能用到 token 过期
+	for _, uid := range uids {
+		if er := j.jwtHdl.RevokeSessions(ctx, uid); er != nil {
+			j.l.Error("撤销被删除用户的会话失败", logger.Int64("uid", uid), logger.Error(er))
+		}
+	}
+	return err
+}
@@ -0,0 +1,179 @@
+// Package captcha 生成图片验证码，只依赖标准库
+package captcha
+
+import (
+	"bytes"
+	"crypto/rand"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"math/big"
+	mrand "math/rand/v2"
+)
+
+// glyphs 0 到 9 的 5x7 点阵
+var glyphs = [10][7]string{
+	{".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	{"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	{".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	{"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	{"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	{"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	{"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	{"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	{".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	{".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+}
+
+const (
+	glyphCols = 5
+	glyphRows = 7
+)
+
+// Generator 生成扭曲过的数字图片
+type Generator struct {
+	Width  int
+	Height int
+	// Length 数字的个数
+	Length int
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		Width:  160,
+		Height: 60,
+		Length: 4,
+	}
+}
+
+// Digits 随机生成答案，答案要保密，使用 crypto/rand
+func (g *Generator) Digits() string {
+	buf := make([]byte, g.Length)
+	for i := range buf {
+		n, _ := rand.Int(rand.Reader, big.NewInt(10))
+		buf[i] = '0' + byte(n.Int64())
+	}
+	return string(buf)
+}
+
+// Draw 把 digits 画成 PNG 图片
+// 每个数字随机倾斜、缩放和上下偏移，整张图再做一次正弦扭曲，最后加上干扰线和噪点
+func (g *Generator) Draw(digits string) ([]byte, error) {
+	bg := color.RGBA{R: uint8(230 + mrand.IntN(26)), G: uint8(230 + mrand.IntN(26)), B: uint8(230 + mrand.IntN(26)), A: 255}
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Width, g.Height))
+	fill(canvas, bg)
+
+	cellW := g.Width / max(len(digits), 1)
+	for i, d := range digits {
+		if d < '0' || d > '9' {
+			continue
+		}
+		// 每个点阵格子画成 scale x scale 的方块
+		scale := float64(g.Height) * (0.5 + mrand.Float64()*0.15) / glyphRows
+		shear := mrand.Float64()*0.6 - 0.3
+		x0 := float64(i*cellW) + (float64(cellW)-glyphCols*scale)/2 + mrand.Float64()*4 - 2
+		y0 := (float64(g.Height)-glyphRows*scale)/2 + mrand.Float64()*10 - 5
+		c := randDark()
+		drawGlyph(canvas, glyphs[d-'0'], x0, y0, scale, shear, c)
+	}
+
+	out := image.NewRGBA(canvas.Bounds())
+	fill(out, bg)
+	warp(out, canvas)
+
+	for i := 0; i < 3; i++ {
+		line(out, mrand.IntN(g.Width/4), mrand.IntN(g.Height),
+			g.Width-1-mrand.IntN(g.Width/4), mrand.IntN(g.Height), randDark())
+	}
+	for i := 0; i < g.Width*g.Height/30; i++ {
+		out.Set(mrand.IntN(g.Width), mrand.IntN(g.Height), randDark())
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawGlyph(img *image.RGBA, glyph [7]string, x0, y0, scale, shear float64, c color.Color) {
+	for row, line := range glyph {
+		for col, ch := range line {
+			if ch != '#' {
+				continue
+			}
+			// 越往下越往左右偏，形成倾斜
+			x := x0 + float64(col)*scale + shear*(float64(glyphRows-row)*scale)
+			y := y0 + float64(row)*scale
+			for dy := 0; dy < int(math.Ceil(scale)); dy++ {
+				for dx := 0; dx < int(math.Ceil(scale)); dx++ {
+					img.Set(int(x)+dx, int(y)+dy, c)
+				}
+			}
+		}
+	}
+}
+
+// warp 正弦扭曲，dst 上的每个点从 src 上偏移过的位置取颜色
+func warp(dst, src *image.RGBA) {
+	b := src.Bounds()
+	ampX, ampY := 2+mrand.Float64()*2, 3+mrand.Float64()*3
+	periodX, periodY := 15+mrand.Float64()*10, 30+mrand.Float64()*20
+	phase := mrand.Float64() * 2 * math.Pi
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			sx := x + int(ampX*math.Sin(float64(y)/periodX+phase))
+			sy := y + int(ampY*math.Sin(float64(x)/periodY+phase))
+			if image.Pt(sx, sy).In(b) {
+				dst.Set(x, y, src.At(sx, sy))
+			}
+		}
+	}
+}
+
+// line Bresenham 画线
+func line(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		img.Set(x0, y0+1, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func fill(img *image.RGBA, c color.RGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+}
+
+func randDark() color.RGBA {
+	return color.RGBA{R: uint8(mrand.IntN(120)), G: uint8(mrand.IntN(120)), B: uint8(mrand.IntN(120)), A: 255}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
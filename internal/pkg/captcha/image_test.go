@@ -0,0 +1,25 @@
+package captcha
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator(t *testing.T) {
+	g := NewGenerator()
+	digits := g.Digits()
+	require.Len(t, digits, g.Length)
+	for _, d := range digits {
+		assert.True(t, d >= '0' && d <= '9')
+	}
+	data, err := g.Draw(digits)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, g.Width, img.Bounds().Dx())
+	assert.Equal(t, g.Height, img.Bounds().Dy())
+}
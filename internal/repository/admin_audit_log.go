@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/dao"
+)
+
+type AdminAuditLogRepository interface {
+	Create(ctx context.Context, l domain.AdminAuditLog) error
+	// List operatorId 为 0 的时候查询所有管理员的记录
+	List(ctx context.Context, operatorId int64, offset, limit int) ([]domain.AdminAuditLog, error)
+}
+
+type adminAuditLogRepository struct {
+	dao dao.AdminAuditLogDao
+}
+
+func NewAdminAuditLogRepository(d dao.AdminAuditLogDao) AdminAuditLogRepository {
+	return &adminAuditLogRepository{
+		dao: d,
+	}
+}
+
+func (r *adminAuditLogRepository) Create(ctx context.Context, l domain.AdminAuditLog) error {
+	return r.dao.Insert(ctx, dao.AdminAuditLog{
+		OperatorId: l.Operator.Uid,
+		IP:         l.Operator.IP,
+		Action:     l.Action,
+		TargetId:   l.TargetId,
+		Detail:     l.Detail,
+		Ctime:      l.Ctime.UnixMilli(),
+	})
+}
+
+func (r *adminAuditLogRepository) List(ctx context.Context, operatorId int64, offset, limit int) ([]domain.AdminAuditLog, error) {
+	logs, err := r.dao.List(ctx, operatorId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.AdminAuditLog, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, domain.AdminAuditLog{
+			Id: l.Id,
+			Operator: domain.AdminOperator{
+				Uid: l.OperatorId,
+				IP:  l.IP,
+			},
+			Action:   l.Action,
+			TargetId: l.TargetId,
+			Detail:   l.Detail,
+			Ctime:    time.UnixMilli(l.Ctime),
+		})
+	}
+	return res, nil
+}
@@ -2,13 +2,34 @@ package repository
 
 import (
 	"context"
+	"time"
 	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ekit/sqlx"
 	"xiaoweishu/internal/repository/dao"
 )
 
+var (
+	ErrArticleNotFound        = dao.ErrArticleNotFound
+	ErrArticleVersionConflict = dao.ErrArticleVersionConflict
+)
+
 type ArticleRepository interface {
 	Create(ctx context.Context, article domain.Article) (int64, error)
 	Update(ctx context.Context, article domain.Article) error
+	FindById(ctx context.Context, id int64) (domain.Article, error)
+	// SaveDraft 持久化自动保存的草稿，保留草稿的修改时间
+	SaveDraft(ctx context.Context, article domain.Article) error
+	// SetHidden 审核隐藏或者取消隐藏
+	SetHidden(ctx context.Context, id int64, hidden bool) error
+	Delete(ctx context.Context, id, authorId int64) error
+	ListDeleted(ctx context.Context, authorId int64, since time.Time, offset, limit int) ([]domain.Article, error)
+	Restore(ctx context.Context, id, authorId, version int64, since time.Time) error
+	Purge(ctx context.Context, before time.Time, limit int) (int64, error)
+	ListByAuthor(ctx context.Context, authorId int64, offset, limit int) ([]domain.Article, error)
+	ListPublishedByAuthor(ctx context.Context, authorId int64, limit int) ([]domain.Article, error)
+	ListHot(ctx context.Context, limit int) ([]domain.Article, error)
+	AuthorStats(ctx context.Context, authorId int64) (domain.AuthorStats, error)
+	IncrReadCnt(ctx context.Context, id, reader int64) error
 }
 
 type CachedArticleRepository struct {
@@ -26,6 +47,8 @@ func (c *CachedArticleRepository) Create(ctx context.Context, article domain.Art
 		Title:    article.Title,
 		Content:  article.Content,
 		AuthorId: article.Author.Id,
+		Tags:     c.tagsToEntity(article.Tags),
+		Status:   article.Status.ToUint8(),
 	})
 }
 func (c *CachedArticleRepository) Update(ctx context.Context, article domain.Article) error {
@@ -34,5 +57,129 @@ func (c *CachedArticleRepository) Update(ctx context.Context, article domain.Art
 		Title:    article.Title,
 		Content:  article.Content,
 		AuthorId: article.Author.Id,
+		Tags:     c.tagsToEntity(article.Tags),
+		Status:   article.Status.ToUint8(),
+		Version:  article.Version,
+	})
+}
+
+func (c *CachedArticleRepository) SaveDraft(ctx context.Context, article domain.Article) error {
+	return c.dao.UpdateDraft(ctx, dao.Article{
+		Id:       article.Id,
+		Title:    article.Title,
+		Content:  article.Content,
+		AuthorId: article.Author.Id,
+		Tags:     c.tagsToEntity(article.Tags),
+		Version:  article.Version,
+		Utime:    article.Utime.UnixMilli(),
 	})
 }
+
+func (c *CachedArticleRepository) FindById(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := c.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return c.toDomain(art), nil
+}
+
+func (c *CachedArticleRepository) SetHidden(ctx context.Context, id int64, hidden bool) error {
+	return c.dao.UpdateHidden(ctx, id, hidden)
+}
+
+func (c *CachedArticleRepository) Delete(ctx context.Context, id, authorId int64) error {
+	return c.dao.SoftDelete(ctx, id, authorId)
+}
+
+func (c *CachedArticleRepository) ListDeleted(ctx context.Context, authorId int64, since time.Time, offset, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.ListDeleted(ctx, authorId, since.UnixMilli(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.toDomains(arts), nil
+}
+
+func (c *CachedArticleRepository) Restore(ctx context.Context, id, authorId, version int64, since time.Time) error {
+	return c.dao.Restore(ctx, id, authorId, version, since.UnixMilli())
+}
+
+func (c *CachedArticleRepository) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return c.dao.Purge(ctx, before.UnixMilli(), limit)
+}
+
+func (c *CachedArticleRepository) ListByAuthor(ctx context.Context, authorId int64, offset, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.ListByAuthor(ctx, authorId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.toDomains(arts), nil
+}
+
+func (c *CachedArticleRepository) ListPublishedByAuthor(ctx context.Context, authorId int64, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.ListPublishedByAuthor(ctx, authorId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.toDomains(arts), nil
+}
+
+func (c *CachedArticleRepository) AuthorStats(ctx context.Context, authorId int64) (domain.AuthorStats, error) {
+	st, err := c.dao.AuthorStats(ctx, authorId)
+	if err != nil {
+		return domain.AuthorStats{}, err
+	}
+	return domain.AuthorStats{
+		ArticleCnt: st.ArticleCnt,
+		ReadCnt:    st.ReadCnt,
+	}, nil
+}
+
+func (c *CachedArticleRepository) ListHot(ctx context.Context, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.ListHot(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.toDomains(arts), nil
+}
+
+func (c *CachedArticleRepository) IncrReadCnt(ctx context.Context, id, reader int64) error {
+	return c.dao.IncrReadCnt(ctx, id, reader)
+}
+
+func (c *CachedArticleRepository) toDomains(arts []dao.Article) []domain.Article {
+	res := make([]domain.Article, 0, len(arts))
+	for _, art := range arts {
+		res = append(res, c.toDomain(art))
+	}
+	return res
+}
+
+func (c *CachedArticleRepository) tagsToEntity(tags []string) sqlx.JsonColumn[[]string] {
+	return sqlx.JsonColumn[[]string]{
+		Val:   tags,
+		Valid: len(tags) > 0,
+	}
+}
+
+func (c *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
+	var deletedAt time.Time
+	if art.DeletedAt > 0 {
+		deletedAt = time.UnixMilli(art.DeletedAt)
+	}
+	return domain.Article{
+		Id:      art.Id,
+		Title:   art.Title,
+		Content: art.Content,
+		Author: domain.Author{
+			Id: art.AuthorId,
+		},
+		Tags:      art.Tags.Val,
+		Status:    domain.ArticleStatus(art.Status),
+		Hidden:    art.Hidden,
+		ReadCnt:   art.ReadCnt,
+		DeletedAt: deletedAt,
+		Version:   art.Version,
+		Ctime:     time.UnixMilli(art.Ctime),
+		Utime:     time.UnixMilli(art.Utime),
+	}
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+)
+
+// ErrArticleDraftNotFound 没有自动保存的草稿，或者草稿已经过期
+var ErrArticleDraftNotFound = cache.ErrKeyNotFound
+
+type ArticleDraftRepository interface {
+	Save(ctx context.Context, art domain.Article) error
+	Find(ctx context.Context, uid, id int64) (domain.Article, error)
+	Delete(ctx context.Context, uid, id int64) error
+	ListDirty(ctx context.Context, limit int) ([]domain.Article, error)
+	ClearDirty(ctx context.Context, art domain.Article) error
+	// IncrFlushFailure 记录一次持久化失败，返回连续失败的次数
+	IncrFlushFailure(ctx context.Context, uid, id int64) (int64, error)
+	// DropDirty 放弃持久化，草稿还留在 redis 里面，作者打开编辑器的时候还能看到
+	DropDirty(ctx context.Context, uid, id int64) error
+}
+
+type articleDraftRepository struct {
+	cache cache.ArticleDraftCache
+}
+
+func NewArticleDraftRepository(c cache.ArticleDraftCache) ArticleDraftRepository {
+	return &articleDraftRepository{
+		cache: c,
+	}
+}
+
+func (r *articleDraftRepository) Save(ctx context.Context, art domain.Article) error {
+	if art.Utime.IsZero() {
+		art.Utime = time.Now()
+	}
+	return r.cache.Set(ctx, art)
+}
+
+func (r *articleDraftRepository) Find(ctx context.Context, uid, id int64) (domain.Article, error) {
+	return r.cache.Get(ctx, uid, id)
+}
+
+func (r *articleDraftRepository) Delete(ctx context.Context, uid, id int64) error {
+	return r.cache.Delete(ctx, uid, id)
+}
+
+func (r *articleDraftRepository) ListDirty(ctx context.Context, limit int) ([]domain.Article, error) {
+	return r.cache.ListDirty(ctx, limit)
+}
+
+func (r *articleDraftRepository) ClearDirty(ctx context.Context, art domain.Article) error {
+	return r.cache.ClearDirty(ctx, art.Author.Id, art.Id, art.Utime)
+}
+
+func (r *articleDraftRepository) IncrFlushFailure(ctx context.Context, uid, id int64) (int64, error) {
+	return r.cache.IncrFlushFailure(ctx, uid, id)
+}
+
+func (r *articleDraftRepository) DropDirty(ctx context.Context, uid, id int64) error {
+	return r.cache.DropDirty(ctx, uid, id)
+}
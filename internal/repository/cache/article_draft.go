@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"xiaoweishu/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/clear_draft_dirty.lua
+var luaClearDraftDirty string
+
+const (
+	// draftDirtyKey 还没有持久化的草稿，member 是 uid:id，score 是自动保存的时间
+	draftDirtyKey = "article:draft:dirty"
+	// draftFailureKey 草稿连续持久化失败的次数，field 和 draftDirtyKey 的 member 一样
+	draftFailureKey = "article:draft:flush_failures"
+)
+
+// ArticleDraftCache 自动保存的草稿
+type ArticleDraftCache interface {
+	// Set 保存草稿，并且标记为需要持久化
+	Set(ctx context.Context, art domain.Article) error
+	Get(ctx context.Context, uid, id int64) (domain.Article, error)
+	// Delete 删除草稿和持久化标记
+	Delete(ctx context.Context, uid, id int64) error
+	// ListDirty 最早修改的 limit 篇需要持久化的草稿，只有 Id 和 Author 有值
+	ListDirty(ctx context.Context, limit int) ([]domain.Article, error)
+	// ClearDirty 持久化之后移除标记，utime 是持久化的那份草稿的修改时间
+	ClearDirty(ctx context.Context, uid, id int64, utime time.Time) error
+	// IncrFlushFailure 持久化失败的次数加一，返回连续失败的次数
+	IncrFlushFailure(ctx context.Context, uid, id int64) (int64, error)
+	// DropDirty 不再尝试持久化，只移除标记，草稿本身保留到过期
+	DropDirty(ctx context.Context, uid, id int64) error
+}
+
+type RedisArticleDraftCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewArticleDraftCache(client redis.Cmdable) ArticleDraftCache {
+	return &RedisArticleDraftCache{
+		client:     client,
+		expiration: time.Hour * 24 * 7,
+	}
+}
+
+func (c *RedisArticleDraftCache) Set(ctx context.Context, art domain.Article) error {
+	val, err := json.Marshal(art)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.key(art.Author.Id, art.Id), val, c.expiration)
+		pipe.ZAdd(ctx, draftDirtyKey, redis.Z{
+			Score:  float64(art.Utime.UnixMilli()),
+			Member: c.member(art.Author.Id, art.Id),
+		})
+		return nil
+	})
+	return err
+}
+
+func (c *RedisArticleDraftCache) Get(ctx context.Context, uid, id int64) (domain.Article, error) {
+	val, err := c.client.Get(ctx, c.key(uid, id)).Bytes()
+	if err != nil {
+		return domain.Article{}, err
+	}
+	var art domain.Article
+	err = json.Unmarshal(val, &art)
+	return art, err
+}
+
+func (c *RedisArticleDraftCache) Delete(ctx context.Context, uid, id int64) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, c.key(uid, id))
+		pipe.ZRem(ctx, draftDirtyKey, c.member(uid, id))
+		pipe.HDel(ctx, draftFailureKey, c.member(uid, id))
+		return nil
+	})
+	return err
+}
+
+func (c *RedisArticleDraftCache) ListDirty(ctx context.Context, limit int) ([]domain.Article, error) {
+	members, err := c.client.ZRange(ctx, draftDirtyKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Article, 0, len(members))
+	var invalid []any
+	for _, m := range members {
+		uidStr, idStr, ok := strings.Cut(m, ":")
+		uid, err1 := strconv.ParseInt(uidStr, 10, 64)
+		id, err2 := strconv.ParseInt(idStr, 10, 64)
+		if !ok || err1 != nil || err2 != nil {
+			invalid = append(invalid, m)
+			continue
+		}
+		res = append(res, domain.Article{
+			Id:     id,
+			Author: domain.Author{Id: uid},
+		})
+	}
+	// 不合法的 member 永远也持久化不了，留着会一直占住最前面的位置
+	if len(invalid) > 0 {
+		err = c.client.ZRem(ctx, draftDirtyKey, invalid...).Err()
+	}
+	return res, err
+}
+
+func (c *RedisArticleDraftCache) ClearDirty(ctx context.Context, uid, id int64, utime time.Time) error {
+	return c.client.Eval(ctx, luaClearDraftDirty, []string{draftDirtyKey, draftFailureKey},
+		c.member(uid, id), utime.UnixMilli()).Err()
+}
+
+func (c *RedisArticleDraftCache) IncrFlushFailure(ctx context.Context, uid, id int64) (int64, error) {
+	return c.client.HIncrBy(ctx, draftFailureKey, c.member(uid, id), 1).Result()
+}
+
+func (c *RedisArticleDraftCache) DropDirty(ctx context.Context, uid, id int64) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, draftDirtyKey, c.member(uid, id))
+		pipe.HDel(ctx, draftFailureKey, c.member(uid, id))
+		return nil
+	})
+	return err
+}
+
+func (c *RedisArticleDraftCache) key(uid, id int64) string {
+	return fmt.Sprintf("article:draft:%d:%d", uid, id)
+}
+
+func (c *RedisArticleDraftCache) member(uid, id int64) string {
+	return fmt.Sprintf("%d:%d", uid, id)
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CaptchaCache 图片验证码的答案
+type CaptchaCache interface {
+	Set(ctx context.Context, id, answer string) error
+	// Verify 不管答案对不对，验证一次之后就作废，不能反复猜
+	Verify(ctx context.Context, id, answer string) (bool, error)
+}
+
+type RedisCaptchaCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewCaptchaCache(client redis.Cmdable) CaptchaCache {
+	return &RedisCaptchaCache{
+		client:     client,
+		expiration: time.Minute * 5,
+	}
+}
+
+func (c *RedisCaptchaCache) Set(ctx context.Context, id, answer string) error {
+	return c.client.Set(ctx, c.key(id), answer, c.expiration).Err()
+}
+
+func (c *RedisCaptchaCache) Verify(ctx context.Context, id, answer string) (bool, error) {
+	val, err := c.client.GetDel(ctx, c.key(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		// 已经过期或者已经用过了
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return val == answer, nil
+}
+
+func (c *RedisCaptchaCache) key(id string) string {
+	return fmt.Sprintf("captcha:%s", id)
+}
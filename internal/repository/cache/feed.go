@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// FeedCache 缓存渲染好的 RSS/Atom，订阅器轮询很频繁，没必要每次都查库渲染
+type FeedCache interface {
+	GetAuthor(ctx context.Context, format string, authorId int64) ([]byte, error)
+	SetAuthor(ctx context.Context, format string, authorId int64, data []byte) error
+	GetHot(ctx context.Context, format string) ([]byte, error)
+	SetHot(ctx context.Context, format string, data []byte) error
+}
+
+type RedisFeedCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewFeedCache(client redis.Cmdable) FeedCache {
+	return &RedisFeedCache{
+		client:     client,
+		expiration: time.Minute * 5,
+	}
+}
+
+func (c *RedisFeedCache) GetAuthor(ctx context.Context, format string, authorId int64) ([]byte, error) {
+	return c.client.Get(ctx, c.authorKey(format, authorId)).Bytes()
+}
+
+func (c *RedisFeedCache) SetAuthor(ctx context.Context, format string, authorId int64, data []byte) error {
+	return c.client.Set(ctx, c.authorKey(format, authorId), data, c.expiration).Err()
+}
+
+func (c *RedisFeedCache) GetHot(ctx context.Context, format string) ([]byte, error) {
+	return c.client.Get(ctx, c.hotKey(format)).Bytes()
+}
+
+func (c *RedisFeedCache) SetHot(ctx context.Context, format string, data []byte) error {
+	return c.client.Set(ctx, c.hotKey(format), data, c.expiration).Err()
+}
+
+func (c *RedisFeedCache) authorKey(format string, authorId int64) string {
+	return fmt.Sprintf("feed:%s:author:%d", format, authorId)
+}
+
+func (c *RedisFeedCache) hotKey(format string) string {
+	return fmt.Sprintf("feed:%s:hot", format)
+}
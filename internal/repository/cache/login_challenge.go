@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"xiaoweishu/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginChallengeCache 等待二次验证的登录，验证码输错了不删除，过期之后自动失效
+type LoginChallengeCache interface {
+	Set(ctx context.Context, c domain.LoginChallenge) error
+	// Get 不存在或者已经过期返回 ErrKeyNotFound
+	Get(ctx context.Context, token string) (domain.LoginChallenge, error)
+	Delete(ctx context.Context, token string) error
+}
+
+type RedisLoginChallengeCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewLoginChallengeCache(client redis.Cmdable) LoginChallengeCache {
+	return &RedisLoginChallengeCache{
+		client: client,
+		// 和验证码的有效期一致
+		expiration: time.Minute * 10,
+	}
+}
+
+func (c *RedisLoginChallengeCache) Set(ctx context.Context, ch domain.LoginChallenge) error {
+	val, err := json.Marshal(ch)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key(ch.Token), val, c.expiration).Err()
+}
+
+func (c *RedisLoginChallengeCache) Get(ctx context.Context, token string) (domain.LoginChallenge, error) {
+	val, err := c.client.Get(ctx, c.key(token)).Bytes()
+	if err != nil {
+		return domain.LoginChallenge{}, err
+	}
+	var ch domain.LoginChallenge
+	err = json.Unmarshal(val, &ch)
+	return ch, err
+}
+
+func (c *RedisLoginChallengeCache) Delete(ctx context.Context, token string) error {
+	return c.client.Del(ctx, c.key(token)).Err()
+}
+
+func (c *RedisLoginChallengeCache) key(token string) string {
+	return fmt.Sprintf("login:challenge:%s", token)
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"xiaoweishu/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/incr_login_failure.lua
+var luaIncrLoginFailure string
+
+// LoginGuardCache 密码登录的失败次数和账号锁定
+// account 由调用方统一转成小写
+type LoginGuardCache interface {
+	Get(ctx context.Context, account, ip string) (domain.LoginGuard, error)
+	// IncrFailure 账号和 IP 的失败次数都加一，返回账号的失败次数
+	IncrFailure(ctx context.Context, account, ip string, now time.Time) (int64, error)
+	Lock(ctx context.Context, account string, d time.Duration) error
+	// Reset 清空账号的失败次数并且解除锁定，IP 的失败次数不清空
+	Reset(ctx context.Context, account string) error
+}
+
+type RedisLoginGuardCache struct {
+	client redis.Cmdable
+	// window 账号在这段时间里面没有再失败，失败次数清零
+	window time.Duration
+}
+
+func NewLoginGuardCache(client redis.Cmdable) LoginGuardCache {
+	return &RedisLoginGuardCache{
+		client: client,
+		window: time.Minute * 15,
+	}
+}
+
+func (c *RedisLoginGuardCache) Get(ctx context.Context, account, ip string) (domain.LoginGuard, error) {
+	var (
+		failures *redis.MapStringStringCmd
+		ipCnt    *redis.StringCmd
+		lockTTL  *redis.DurationCmd
+	)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		failures = pipe.HGetAll(ctx, c.key(account))
+		ipCnt = pipe.Get(ctx, c.ipKey(ip))
+		lockTTL = pipe.PTTL(ctx, c.lockKey(account))
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return domain.LoginGuard{}, err
+	}
+	var res domain.LoginGuard
+	vals := failures.Val()
+	res.Failures, _ = strconv.ParseInt(vals["cnt"], 10, 64)
+	if last, _ := strconv.ParseInt(vals["last"], 10, 64); last > 0 {
+		res.LastFailure = time.UnixMilli(last)
+	}
+	res.IPFailures, _ = strconv.ParseInt(ipCnt.Val(), 10, 64)
+	// key 不存在的时候 PTTL 返回负数
+	if ttl := lockTTL.Val(); ttl > 0 {
+		res.LockedUntil = time.Now().Add(ttl)
+	}
+	return res, nil
+}
+
+func (c *RedisLoginGuardCache) IncrFailure(ctx context.Context, account, ip string, now time.Time) (int64, error) {
+	return c.client.Eval(ctx, luaIncrLoginFailure,
+		[]string{c.key(account), c.ipKey(ip)},
+		now.UnixMilli(), c.window.Milliseconds()).Int64()
+}
+
+func (c *RedisLoginGuardCache) Lock(ctx context.Context, account string, d time.Duration) error {
+	return c.client.Set(ctx, c.lockKey(account), "1", d).Err()
+}
+
+func (c *RedisLoginGuardCache) Reset(ctx context.Context, account string) error {
+	return c.client.Del(ctx, c.key(account), c.lockKey(account)).Err()
+}
+
+func (c *RedisLoginGuardCache) key(account string) string {
+	return fmt.Sprintf("login:guard:account:%s", account)
+}
+
+func (c *RedisLoginGuardCache) ipKey(ip string) string {
+	return fmt.Sprintf("login:guard:ip:%s", ip)
+}
+
+func (c *RedisLoginGuardCache) lockKey(account string) string {
+	return fmt.Sprintf("login:guard:lock:%s", account)
+}
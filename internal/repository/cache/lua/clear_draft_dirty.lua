@@ -0,0 +1,9 @@
+-- 草稿持久化之后移除脏标记，并且清空失败次数
+-- 如果分数变了，说明持久化期间又有新的自动保存，需要保留
+redis.call("hdel", KEYS[2], ARGV[1])
+local score = redis.call("zscore", KEYS[1], ARGV[1])
+if score == tostring(ARGV[2]) then
+    redis.call("zrem", KEYS[1], ARGV[1])
+    return 1
+end
+return 0
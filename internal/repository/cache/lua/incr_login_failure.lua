@@ -0,0 +1,19 @@
+-- 账号的失败次数和最后一次失败的时间
+local key = KEYS[1]
+-- 同一个 IP 的失败次数
+local ipKey = KEYS[2]
+local now = ARGV[1]
+-- 统计窗口，毫秒
+local window = tonumber(ARGV[2])
+
+local cnt = redis.call("hincrby", key, "cnt", 1)
+redis.call("hset", key, "last", now)
+-- 每次失败都往后顺延，一直在猜就一直不会清零
+redis.call("pexpire", key, window)
+
+local ipCnt = redis.call("incr", ipKey)
+if ipCnt == 1 then
+    -- IP 按照固定窗口统计
+    redis.call("pexpire", ipKey, window)
+end
+return cnt
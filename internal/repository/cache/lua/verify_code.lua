@@ -1,10 +1,10 @@
-local key = KYES[1]
+local key = KEYS[1]
 -- 用户输入的 code
 local expectedCode = ARGV[1]
 local code = redis.call("get", key)
 local cntKey = key..":cnt"
 local cnt = tonumber(redis.call("get", cntKey))
-if cnt == nil or c nt <= 0 then
+if cnt == nil or cnt <= 0 then
     -- 说明用户一直输入错误
     -- 说明验证码已经使用过了
     return -1
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"xiaoweishu/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MFAPendingCache 等待两步验证的登录
+type MFAPendingCache interface {
+	Set(ctx context.Context, p domain.MFAPending) error
+	// Get 不存在或者已经过期返回 ErrKeyNotFound
+	Get(ctx context.Context, token string) (domain.MFAPending, error)
+	// IncrAttempts 验证次数加一，返回加一之后的次数
+	IncrAttempts(ctx context.Context, token string) (int64, error)
+	Delete(ctx context.Context, token string) error
+}
+
+type RedisMFAPendingCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewMFAPendingCache(client redis.Cmdable) MFAPendingCache {
+	return &RedisMFAPendingCache{
+		client:     client,
+		expiration: time.Minute * 5,
+	}
+}
+
+func (c *RedisMFAPendingCache) Set(ctx context.Context, p domain.MFAPending) error {
+	val, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key(p.Token), val, c.expiration).Err()
+}
+
+func (c *RedisMFAPendingCache) Get(ctx context.Context, token string) (domain.MFAPending, error) {
+	val, err := c.client.Get(ctx, c.key(token)).Bytes()
+	if err != nil {
+		return domain.MFAPending{}, err
+	}
+	var p domain.MFAPending
+	err = json.Unmarshal(val, &p)
+	return p, err
+}
+
+func (c *RedisMFAPendingCache) IncrAttempts(ctx context.Context, token string) (int64, error) {
+	var cnt *redis.IntCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		cnt = pipe.Incr(ctx, c.attemptsKey(token))
+		pipe.Expire(ctx, c.attemptsKey(token), c.expiration)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return cnt.Val(), nil
+}
+
+func (c *RedisMFAPendingCache) Delete(ctx context.Context, token string) error {
+	return c.client.Del(ctx, c.key(token), c.attemptsKey(token)).Err()
+}
+
+func (c *RedisMFAPendingCache) key(token string) string {
+	return fmt.Sprintf("login:mfa:%s", token)
+}
+
+func (c *RedisMFAPendingCache) attemptsKey(token string) string {
+	return fmt.Sprintf("login:mfa:%s:attempts", token)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/cache/notification.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/cache/notification.go -package=cachemocks -destination=./internal/repository/cache/mocks/notification.mock.go
+//
+
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationCache is a mock of NotificationCache interface.
+type MockNotificationCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationCacheMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationCacheMockRecorder is the mock recorder for MockNotificationCache.
+type MockNotificationCacheMockRecorder struct {
+	mock *MockNotificationCache
+}
+
+// NewMockNotificationCache creates a new mock instance.
+func NewMockNotificationCache(ctrl *gomock.Controller) *MockNotificationCache {
+	mock := &MockNotificationCache{ctrl: ctrl}
+	mock.recorder = &MockNotificationCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationCache) EXPECT() *MockNotificationCacheMockRecorder {
+	return m.recorder
+}
+
+// DeleteUnread mocks base method.
+func (m *MockNotificationCache) DeleteUnread(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUnread", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUnread indicates an expected call of DeleteUnread.
+func (mr *MockNotificationCacheMockRecorder) DeleteUnread(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUnread", reflect.TypeOf((*MockNotificationCache)(nil).DeleteUnread), ctx, uid)
+}
+
+// GetUnread mocks base method.
+func (m *MockNotificationCache) GetUnread(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnread", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnread indicates an expected call of GetUnread.
+func (mr *MockNotificationCacheMockRecorder) GetUnread(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnread", reflect.TypeOf((*MockNotificationCache)(nil).GetUnread), ctx, uid)
+}
+
+// SetUnread mocks base method.
+func (m *MockNotificationCache) SetUnread(ctx context.Context, uid, cnt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUnread", ctx, uid, cnt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUnread indicates an expected call of SetUnread.
+func (mr *MockNotificationCacheMockRecorder) SetUnread(ctx, uid, cnt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUnread", reflect.TypeOf((*MockNotificationCache)(nil).SetUnread), ctx, uid, cnt)
+}
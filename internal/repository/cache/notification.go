@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NotificationCache 缓存未读通知数，前端每个页面都会查询
+type NotificationCache interface {
+	GetUnread(ctx context.Context, uid int64) (int64, error)
+	SetUnread(ctx context.Context, uid int64, cnt int64) error
+	DeleteUnread(ctx context.Context, uid int64) error
+}
+
+type RedisNotificationCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewNotificationCache(client redis.Cmdable) NotificationCache {
+	return &RedisNotificationCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (c *RedisNotificationCache) GetUnread(ctx context.Context, uid int64) (int64, error) {
+	return c.client.Get(ctx, c.key(uid)).Int64()
+}
+
+func (c *RedisNotificationCache) SetUnread(ctx context.Context, uid int64, cnt int64) error {
+	return c.client.Set(ctx, c.key(uid), cnt, c.expiration).Err()
+}
+
+func (c *RedisNotificationCache) DeleteUnread(ctx context.Context, uid int64) error {
+	return c.client.Del(ctx, c.key(uid)).Err()
+}
+
+func (c *RedisNotificationCache) key(uid int64) string {
+	return fmt.Sprintf("notification:unread:%d", uid)
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"xiaoweishu/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// pushChannel 所有实例都订阅同一个频道，收到之后再分发给本实例上的连接
+const pushChannel = "push:events"
+
+// PushCache 通过 redis pub/sub 在多个实例之间广播推送事件
+type PushCache interface {
+	Publish(ctx context.Context, evt domain.PushEvent) error
+	// Subscribe 订阅推送事件，ctx 结束的时候取消订阅并关闭返回的 channel
+	Subscribe(ctx context.Context) (<-chan domain.PushEvent, error)
+}
+
+type RedisPushCache struct {
+	client redis.UniversalClient
+}
+
+func NewPushCache(client redis.UniversalClient) PushCache {
+	return &RedisPushCache{
+		client: client,
+	}
+}
+
+func (c *RedisPushCache) Publish(ctx context.Context, evt domain.PushEvent) error {
+	val, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	return c.client.Publish(ctx, pushChannel, val).Err()
+}
+
+func (c *RedisPushCache) Subscribe(ctx context.Context) (<-chan domain.PushEvent, error) {
+	sub := c.client.Subscribe(ctx, pushChannel)
+	// 等待订阅成功，不然可能会漏掉最开始的事件
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+	ch := make(chan domain.PushEvent)
+	go func() {
+		defer close(ch)
+		defer sub.Close()
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				var evt domain.PushEvent
+				// 格式不对的消息直接丢弃
+				if err := json.Unmarshal([]byte(msg.Payload), &evt); err != nil {
+					continue
+				}
+				select {
+				case ch <- evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
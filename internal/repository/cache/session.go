@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"xiaoweishu/internal/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionCache 每个用户一个 hash 保存所有会话，field 是 ssid
+// 最后活跃时间更新得很频繁，单独放在另外一个 hash 里面，不用每次都重写整个会话
+type SessionCache interface {
+	Set(ctx context.Context, s domain.Session) error
+	// Get 会话不存在返回 ErrKeyNotFound
+	Get(ctx context.Context, uid int64, ssid string) (domain.Session, error)
+	List(ctx context.Context, uid int64) ([]domain.Session, error)
+	Touch(ctx context.Context, uid int64, ssid string, t time.Time) error
+	Delete(ctx context.Context, uid int64, ssids ...string) error
+	DeleteAll(ctx context.Context, uid int64) error
+}
+
+type RedisSessionCache struct {
+	client redis.Cmdable
+	// expiration 和 refresh token 的有效期一致，用户一直没有登录就整个过期
+	expiration time.Duration
+}
+
+func NewSessionCache(client redis.Cmdable) SessionCache {
+	return &RedisSessionCache{
+		client:     client,
+		expiration: time.Hour * 24 * 7,
+	}
+}
+
+func (c *RedisSessionCache) Set(ctx context.Context, s domain.Session) error {
+	val, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, c.key(s.Uid), s.Ssid, val)
+		pipe.HSet(ctx, c.activeKey(s.Uid), s.Ssid, s.LastActive.UnixMilli())
+		pipe.Expire(ctx, c.key(s.Uid), c.expiration)
+		pipe.Expire(ctx, c.activeKey(s.Uid), c.expiration)
+		return nil
+	})
+	return err
+}
+
+func (c *RedisSessionCache) Get(ctx context.Context, uid int64, ssid string) (domain.Session, error) {
+	var (
+		val    *redis.StringCmd
+		active *redis.StringCmd
+	)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		val = pipe.HGet(ctx, c.key(uid), ssid)
+		active = pipe.HGet(ctx, c.activeKey(uid), ssid)
+		return nil
+	})
+	// 最后活跃时间不存在不算错误
+	if err != nil && val.Err() != nil {
+		return domain.Session{}, val.Err()
+	}
+	return c.decode(val.Val(), active.Val())
+}
+
+func (c *RedisSessionCache) List(ctx context.Context, uid int64) ([]domain.Session, error) {
+	var (
+		vals    *redis.MapStringStringCmd
+		actives *redis.MapStringStringCmd
+	)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		vals = pipe.HGetAll(ctx, c.key(uid))
+		actives = pipe.HGetAll(ctx, c.activeKey(uid))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Session, 0, len(vals.Val()))
+	for ssid, val := range vals.Val() {
+		s, err := c.decode(val, actives.Val()[ssid])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+func (c *RedisSessionCache) Touch(ctx context.Context, uid int64, ssid string, t time.Time) error {
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, c.activeKey(uid), ssid, t.UnixMilli())
+		pipe.Expire(ctx, c.activeKey(uid), c.expiration)
+		return nil
+	})
+	return err
+}
+
+func (c *RedisSessionCache) Delete(ctx context.Context, uid int64, ssids ...string) error {
+	if len(ssids) == 0 {
+		return nil
+	}
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, c.key(uid), ssids...)
+		pipe.HDel(ctx, c.activeKey(uid), ssids...)
+		return nil
+	})
+	return err
+}
+
+func (c *RedisSessionCache) DeleteAll(ctx context.Context, uid int64) error {
+	return c.client.Del(ctx, c.key(uid), c.activeKey(uid)).Err()
+}
+
+func (c *RedisSessionCache) decode(val, active string) (domain.Session, error) {
+	var s domain.Session
+	if err := json.Unmarshal([]byte(val), &s); err != nil {
+		return domain.Session{}, err
+	}
+	if ms, err := strconv.ParseInt(active, 10, 64); err == nil && ms > s.LastActive.UnixMilli() {
+		s.LastActive = time.UnixMilli(ms)
+	}
+	return s, nil
+}
+
+func (c *RedisSessionCache) key(uid int64) string {
+	return fmt.Sprintf("users:sessions:%d", uid)
+}
+
+func (c *RedisSessionCache) activeKey(uid int64) string {
+	return fmt.Sprintf("users:sessions:active:%d", uid)
+}
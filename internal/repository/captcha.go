@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"xiaoweishu/internal/repository/cache"
+)
+
+type CaptchaRepository interface {
+	Store(ctx context.Context, id, answer string) error
+	Verify(ctx context.Context, id, answer string) (bool, error)
+}
+
+type captchaRepository struct {
+	cache cache.CaptchaCache
+}
+
+func NewCaptchaRepository(c cache.CaptchaCache) CaptchaRepository {
+	return &captchaRepository{
+		cache: c,
+	}
+}
+
+func (r *captchaRepository) Store(ctx context.Context, id, answer string) error {
+	return r.cache.Set(ctx, id, answer)
+}
+
+func (r *captchaRepository) Verify(ctx context.Context, id, answer string) (bool, error) {
+	return r.cache.Verify(ctx, id, answer)
+}
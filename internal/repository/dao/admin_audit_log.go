@@ -0,0 +1,51 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type AdminAuditLogDao interface {
+	Insert(ctx context.Context, l AdminAuditLog) error
+	// List 按照时间倒序，operatorId 为 0 的时候查询所有管理员的记录
+	List(ctx context.Context, operatorId int64, offset, limit int) ([]AdminAuditLog, error)
+}
+
+type GormAdminAuditLogDao struct {
+	db *gorm.DB
+}
+
+func NewGormAdminAuditLogDao(db *gorm.DB) AdminAuditLogDao {
+	return &GormAdminAuditLogDao{
+		db: db,
+	}
+}
+
+// AdminAuditLog 管理员的操作记录，只增不改
+type AdminAuditLog struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	OperatorId int64  `gorm:"index:operator_ctime"`
+	IP         string `gorm:"type:varchar(64)"`
+	Action     string `gorm:"type:varchar(32)"`
+	TargetId   int64
+	Detail     string `gorm:"type:varchar(512)"`
+	Ctime      int64  `gorm:"index:operator_ctime;index"`
+}
+
+func (dao *GormAdminAuditLogDao) Insert(ctx context.Context, l AdminAuditLog) error {
+	return dao.db.WithContext(ctx).Create(&l).Error
+}
+
+func (dao *GormAdminAuditLogDao) List(ctx context.Context, operatorId int64, offset, limit int) ([]AdminAuditLog, error) {
+	var res []AdminAuditLog
+	db := dao.db.WithContext(ctx)
+	if operatorId > 0 {
+		db = db.Where("operator_id=?", operatorId)
+	}
+	err := db.Order("ctime DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
@@ -2,12 +2,22 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
+	"xiaoweishu/internal/pkg/ekit/sqlx"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrArticleNotFound        = gorm.ErrRecordNotFound
+	ErrArticleVersionConflict = errors.New("文章已经被修改")
+)
+
+// articleStatusPublished 和 domain.ArticleStatusPublished 保持一致
+const articleStatusPublished uint8 = 2
+
 // Article 制作库的
 // 如何设计索引，和 WHERE 相关
 // 对于帖子来说，是什么查询场景
@@ -21,16 +31,56 @@ import (
 type Article struct {
 	Id int64 `gorm:"primaryKey,autoIncrement"`
 	// 长度1024
-	Title    string `gorm:"type=varchar(1024)"`
-	Content  string `gorm:"type=BLOB"`
-	AuthorId int64  `gorm:"index=aid_ctime"`
-	Ctime    int64  `gorm:"index=aid_ctime"`
-	Utime    int64
+	Title    string                    `gorm:"type=varchar(1024)"`
+	Content  string                    `gorm:"type=BLOB"`
+	AuthorId int64                     `gorm:"index=aid_ctime"`
+	Tags     sqlx.JsonColumn[[]string] `gorm:"type:json"`
+	Status   uint8
+	// Hidden 审核隐藏
+	Hidden bool
+	// ReadCnt 阅读数，热榜按照阅读数排序
+	ReadCnt int64
+	// DeletedAt 软删除时间，毫秒数，0 表示没有删除
+	// 回收站按照作者查询，定时清理按照删除时间查询
+	DeletedAt int64 `gorm:"index"`
+	// Version 乐观锁版本号，作者每次修改都会加一
+	Version int64 `gorm:"not null;default:0"`
+	Ctime   int64 `gorm:"index=aid_ctime"`
+	Utime   int64
 }
 
 type ArticleDao interface {
 	Insert(ctx context.Context, article Article) (int64, error)
+	// UpdateById 只有 article.Version 和数据库中的一致才会更新，否则返回 ErrArticleVersionConflict
 	UpdateById(ctx context.Context, article Article) error
+	FindById(ctx context.Context, id int64) (Article, error)
+	// UpdateDraft 持久化自动保存的草稿，只更新还没有发表的文章，并且不修改版本号
+	// 版本号不一致、文章已经发表或者删除都返回 ErrArticleVersionConflict
+	UpdateDraft(ctx context.Context, article Article) error
+	UpdateHidden(ctx context.Context, id int64, hidden bool) error
+	// SoftDelete 作者删除文章，放入回收站
+	SoftDelete(ctx context.Context, id, authorId int64) error
+	// ListDeleted 回收站，只返回在 since 之后删除的文章
+	ListDeleted(ctx context.Context, authorId int64, since int64, offset, limit int) ([]Article, error)
+	// Restore 从回收站恢复，只能恢复在 since 之后删除的文章，同样需要校验版本号
+	Restore(ctx context.Context, id, authorId, version int64, since int64) error
+	// Purge 彻底删除在 before 之前删除的文章，返回删除的行数
+	Purge(ctx context.Context, before int64, limit int) (int64, error)
+	// ListByAuthor 作者的所有文章，按照 id 排序
+	ListByAuthor(ctx context.Context, authorId int64, offset, limit int) ([]Article, error)
+	// ListPublishedByAuthor 作者最近发表的文章，按照创建时间倒序，不包括被审核隐藏的
+	ListPublishedByAuthor(ctx context.Context, authorId int64, limit int) ([]Article, error)
+	// ListHot 阅读数最多的已发表文章
+	ListHot(ctx context.Context, limit int) ([]Article, error)
+	// AuthorStats 作者发表的文章数和总阅读数，和 ListPublishedByAuthor 一样不包括被审核隐藏的
+	AuthorStats(ctx context.Context, authorId int64) (AuthorStats, error)
+	// IncrReadCnt 阅读数加一，reader 是读者，作者自己阅读和回收站中的文章不计数
+	IncrReadCnt(ctx context.Context, id, reader int64) error
+}
+
+type AuthorStats struct {
+	ArticleCnt int64
+	ReadCnt    int64
 }
 
 type GormArticleDao struct {
@@ -47,6 +97,7 @@ func (dao *GormArticleDao) Insert(ctx context.Context, article Article) (int64,
 	now := time.Now().UnixMilli()
 	article.Ctime = now
 	article.Utime = now
+	article.Version = 1
 	err := dao.db.WithContext(ctx).Create(&article).Error
 	return article.Id, err
 }
@@ -56,10 +107,14 @@ func (dao *GormArticleDao) UpdateById(ctx context.Context, article Article) erro
 	article.Utime = now
 	// gorm 忽略零值特性，使用主键进行更新
 	res := dao.db.WithContext(ctx).Model(&article).
-		Where("id=? AND author_id=?", article.Id, article.AuthorId).
+		Scopes(notDeleted).
+		Where("id=? AND author_id=? AND version=?", article.Id, article.AuthorId, article.Version).
 		Updates(map[string]any{
 			"title":   article.Title,
 			"content": article.Content,
+			"tags":    article.Tags,
+			"status":  article.Status,
+			"version": gorm.Expr("version + 1"),
 			"utime":   article.Utime,
 		})
 	// 需不需要检查是否真的更新
@@ -67,7 +122,190 @@ func (dao *GormArticleDao) UpdateById(ctx context.Context, article Article) erro
 		return res.Error
 	}
 	if res.RowsAffected == 0 { // 更新行数
+		exist, err := dao.exist(ctx, dao.db.Scopes(notDeleted).Where("id=? AND author_id=?", article.Id, article.AuthorId))
+		if err != nil {
+			return err
+		}
+		if exist {
+			return ErrArticleVersionConflict
+		}
 		return fmt.Errorf("更新失败，文章不存在或非作者本人, id: %d, author_id: %d", article.Id, article.AuthorId)
 	}
 	return res.Error
 }
+
+// exist 更新失败的时候用来区分是版本号不对还是文章不存在
+func (dao *GormArticleDao) exist(ctx context.Context, query *gorm.DB) (bool, error) {
+	var cnt int64
+	err := query.WithContext(ctx).Model(&Article{}).Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (dao *GormArticleDao) FindById(ctx context.Context, id int64) (Article, error) {
+	var art Article
+	err := dao.db.WithContext(ctx).Scopes(notDeleted).Where("id=?", id).First(&art).Error
+	return art, err
+}
+
+func (dao *GormArticleDao) UpdateDraft(ctx context.Context, article Article) error {
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Scopes(notDeleted).
+		Where("id=? AND author_id=? AND version=? AND status<>?",
+			article.Id, article.AuthorId, article.Version, articleStatusPublished).
+		Updates(map[string]any{
+			"title":   article.Title,
+			"content": article.Content,
+			"tags":    article.Tags,
+			"utime":   article.Utime,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrArticleVersionConflict
+	}
+	return nil
+}
+
+func (dao *GormArticleDao) UpdateHidden(ctx context.Context, id int64, hidden bool) error {
+	return dao.db.WithContext(ctx).Model(&Article{}).
+		Scopes(notDeleted).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"hidden": hidden,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GormArticleDao) SoftDelete(ctx context.Context, id, authorId int64) error {
+	now := time.Now().UnixMilli()
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Scopes(notDeleted).
+		Where("id=? AND author_id=?", id, authorId).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"utime":      now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrArticleNotFound
+	}
+	return nil
+}
+
+func (dao *GormArticleDao) ListDeleted(ctx context.Context, authorId int64, since int64, offset, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Where("author_id=? AND deleted_at>?", authorId, since).
+		Order("deleted_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormArticleDao) Restore(ctx context.Context, id, authorId, version int64, since int64) error {
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id=? AND author_id=? AND deleted_at>? AND version=?", id, authorId, since, version).
+		Updates(map[string]any{
+			"deleted_at": 0,
+			"version":    gorm.Expr("version + 1"),
+			"utime":      time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		exist, err := dao.exist(ctx, dao.db.Where("id=? AND author_id=? AND deleted_at>?", id, authorId, since))
+		if err != nil {
+			return err
+		}
+		if exist {
+			return ErrArticleVersionConflict
+		}
+		return ErrArticleNotFound
+	}
+	return nil
+}
+
+func (dao *GormArticleDao) Purge(ctx context.Context, before int64, limit int) (int64, error) {
+	res := dao.db.WithContext(ctx).
+		Where("deleted_at>0 AND deleted_at<?", before).
+		Limit(limit).
+		Delete(&Article{})
+	return res.RowsAffected, res.Error
+}
+
+func (dao *GormArticleDao) ListByAuthor(ctx context.Context, authorId int64, offset, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Scopes(notDeleted).
+		Where("author_id=?", authorId).
+		Order("id ASC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormArticleDao) ListPublishedByAuthor(ctx context.Context, authorId int64, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Scopes(notDeleted, visible).
+		Where("author_id=?", authorId).
+		Order("ctime DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormArticleDao) AuthorStats(ctx context.Context, authorId int64) (AuthorStats, error) {
+	var res AuthorStats
+	err := dao.db.WithContext(ctx).Model(&Article{}).
+		Scopes(notDeleted, visible).
+		Select("COUNT(*) AS article_cnt, COALESCE(SUM(read_cnt), 0) AS read_cnt").
+		Where("author_id=?", authorId).
+		Scan(&res).Error
+	return res, err
+}
+
+func (dao *GormArticleDao) ListHot(ctx context.Context, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Scopes(notDeleted, visible).
+		Order("read_cnt DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormArticleDao) IncrReadCnt(ctx context.Context, id, reader int64) error {
+	return dao.db.WithContext(ctx).Model(&Article{}).
+		Scopes(notDeleted).
+		Where("id=? AND author_id<>?", id, reader).
+		UpdateColumn("read_cnt", gorm.Expr("read_cnt + 1")).Error
+}
+
+// visible 读者能看到的文章，即已经发表并且没有被审核隐藏
+func visible(db *gorm.DB) *gorm.DB {
+	return db.Where("status=? AND hidden=?", articleStatusPublished, false)
+}
+
+// notDeleted 过滤掉已经软删除的文章，除了回收站以外的查询都要带上
+func notDeleted(db *gorm.DB) *gorm.DB {
+	return db.Where("deleted_at=?", 0)
+}
+
+// purgeArticles 彻底删除作者的文章和专栏，包括回收站里面的
+func purgeArticles(tx *gorm.DB, authorIds []int64) error {
+	err := tx.Where("series_id IN (?)", tx.Model(&Series{}).Select("id").Where("author_id IN ?", authorIds)).
+		Delete(&SeriesArticle{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("author_id IN ?", authorIds).Delete(&Series{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("author_id IN ?", authorIds).Delete(&Article{}).Error
+}
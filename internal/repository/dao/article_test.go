@@ -0,0 +1,144 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGormArticleDao_UpdateById(t *testing.T) {
+	const (
+		updateSQL = "UPDATE `articles` SET .*`version`=version \\+ 1 WHERE \\(id=\\? AND author_id=\\? AND version=\\?\\) AND deleted_at=\\?"
+		countSQL  = "SELECT count\\(\\*\\) FROM `articles` WHERE \\(id=\\? AND author_id=\\?\\) AND deleted_at=\\?"
+	)
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		art     Article
+		wantErr error
+	}{
+		{
+			name: "更新成功",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				// 必须带上 deleted_at 条件，回收站中的文章不能被修改
+				// 版本号一致才能更新，同时版本号加一
+				mock.ExpectExec(updateSQL).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			art: Article{
+				Id:       1,
+				Title:    "标题",
+				Content:  "内容",
+				AuthorId: 123,
+				Version:  2,
+			},
+		},
+		{
+			name: "文章在回收站中",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec(updateSQL).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(countSQL).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				return mockDB
+			},
+			art: Article{
+				Id:       1,
+				Title:    "标题",
+				Content:  "内容",
+				AuthorId: 123,
+				Version:  2,
+			},
+			wantErr: errors.New("更新失败，文章不存在或非作者本人, id: 1, author_id: 123"),
+		},
+		{
+			name: "版本号不一致",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec(updateSQL).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(countSQL).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				return mockDB
+			},
+			art: Article{
+				Id:       1,
+				Title:    "标题",
+				Content:  "内容",
+				AuthorId: 123,
+				Version:  1,
+			},
+			wantErr: ErrArticleVersionConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      tc.mock(t),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			require.NoError(t, err)
+			d := NewGormArticleDao(db)
+			err = d.UpdateById(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGormArticleDao_Purge(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectExec("DELETE FROM `articles` WHERE deleted_at>0 AND deleted_at<\\? LIMIT \\?").
+		WithArgs(int64(1000), 100).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	d := NewGormArticleDao(db)
+	cnt, err := d.Purge(context.Background(), 1000, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cnt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGormArticleDao_IncrReadCnt(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	// 回收站中的文章和作者自己阅读都不计数
+	mock.ExpectExec("UPDATE `articles` SET `read_cnt`=read_cnt \\+ 1 WHERE \\(id=\\? AND author_id<>\\?\\) AND deleted_at=\\?").
+		WithArgs(int64(1), int64(123), 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	err = NewGormArticleDao(db).IncrReadCnt(context.Background(), 1, 123)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,5 +3,5 @@ package dao
 import "gorm.io/gorm"
 
 func InitTable(db *gorm.DB) error {
-	return db.AutoMigrate(&User{}, &Article{})
+	return db.AutoMigrate(&User{}, &Article{}, &Report{}, &ReportDecision{}, &Series{}, &SeriesArticle{}, &Notification{}, &NotificationActor{}, &UserMergeLog{}, &LoginLog{}, &UserMFA{}, &UserMFARecoveryCode{}, &UserRole{}, &AdminAuditLog{})
 }
@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// 和 domain.LoginStatus 保持一致
+const (
+	loginStatusSuccess uint8 = 1
+	loginStatusFailed  uint8 = 2
+)
+
+type LoginLogDao interface {
+	Insert(ctx context.Context, l LoginLog) error
+	// ListByUid 最近的登录记录，按照时间倒序
+	ListByUid(ctx context.Context, uid int64, offset, limit int) ([]LoginLog, error)
+	// CountFailedSinceSuccess 最近一次成功登录之后，并且在 since 之后失败的次数
+	CountFailedSinceSuccess(ctx context.Context, uid int64, since int64) (int64, error)
+	// Familiarity 统计以前成功的登录里面，有多少次是同一个设备、同一个网段
+	Familiarity(ctx context.Context, uid int64, userAgent, ipRange string) (LoginFamiliarity, error)
+	// Purge 删除 before 之前的登录记录，返回删除的行数
+	Purge(ctx context.Context, before int64, limit int) (int64, error)
+}
+
+type GormLoginLogDao struct {
+	db *gorm.DB
+}
+
+func NewGormLoginLogDao(db *gorm.DB) LoginLogDao {
+	return &GormLoginLogDao{
+		db: db,
+	}
+}
+
+// LoginLog 登录记录，只增不改
+type LoginLog struct {
+	Id      int64  `gorm:"primaryKey,autoIncrement"`
+	Uid     int64  `gorm:"index:uid_ctime"`
+	Account string `gorm:"type:varchar(128)"`
+	Method  string `gorm:"type:varchar(16)"`
+	Status  uint8
+	Reason  string `gorm:"type:varchar(128)"`
+	IP      string `gorm:"type:varchar(64)"`
+	// IPRange IP 所在的网段，判断是不是在常用的地方登录
+	IPRange   string `gorm:"type:varchar(64)"`
+	UserAgent string `gorm:"type:varchar(512)"`
+	Ctime     int64  `gorm:"index:uid_ctime"`
+}
+
+type LoginFamiliarity struct {
+	// Total 以前成功登录的次数
+	Total      int64
+	SameDevice int64
+	SameRange  int64
+}
+
+func (dao *GormLoginLogDao) Insert(ctx context.Context, l LoginLog) error {
+	return dao.db.WithContext(ctx).Create(&l).Error
+}
+
+func (dao *GormLoginLogDao) ListByUid(ctx context.Context, uid int64, offset, limit int) ([]LoginLog, error) {
+	var res []LoginLog
+	err := dao.db.WithContext(ctx).
+		Where("uid=?", uid).
+		Order("ctime DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormLoginLogDao) CountFailedSinceSuccess(ctx context.Context, uid int64, since int64) (int64, error) {
+	var cnt int64
+	lastSuccess := dao.db.Model(&LoginLog{}).
+		Select("COALESCE(MAX(ctime), 0)").
+		Where("uid=? AND status=?", uid, loginStatusSuccess)
+	err := dao.db.WithContext(ctx).Model(&LoginLog{}).
+		Where("uid=? AND status=? AND ctime>?", uid, loginStatusFailed, since).
+		Where("ctime>(?)", lastSuccess).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+func (dao *GormLoginLogDao) Familiarity(ctx context.Context, uid int64, userAgent, ipRange string) (LoginFamiliarity, error) {
+	var res LoginFamiliarity
+	err := dao.db.WithContext(ctx).Model(&LoginLog{}).
+		Select("COUNT(*) AS total, "+
+			"COALESCE(SUM(user_agent=?), 0) AS same_device, "+
+			"COALESCE(SUM(ip_range=?), 0) AS same_range", userAgent, ipRange).
+		Where("uid=? AND status=?", uid, loginStatusSuccess).
+		Scan(&res).Error
+	return res, err
+}
+
+func (dao *GormLoginLogDao) Purge(ctx context.Context, before int64, limit int) (int64, error) {
+	res := dao.db.WithContext(ctx).
+		Where("ctime<?", before).
+		Limit(limit).
+		Delete(&LoginLog{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/dao/notification.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/dao/notification.go -package=daomocks -destination=./internal/repository/dao/mocks/notification.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "xiaoweishu/internal/repository/dao"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationDao is a mock of NotificationDao interface.
+type MockNotificationDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationDaoMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationDaoMockRecorder is the mock recorder for MockNotificationDao.
+type MockNotificationDaoMockRecorder struct {
+	mock *MockNotificationDao
+}
+
+// NewMockNotificationDao creates a new mock instance.
+func NewMockNotificationDao(ctrl *gomock.Controller) *MockNotificationDao {
+	mock := &MockNotificationDao{ctrl: ctrl}
+	mock.recorder = &MockNotificationDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationDao) EXPECT() *MockNotificationDaoMockRecorder {
+	return m.recorder
+}
+
+// CountUnread mocks base method.
+func (m *MockNotificationDao) CountUnread(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockNotificationDaoMockRecorder) CountUnread(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockNotificationDao)(nil).CountUnread), ctx, uid)
+}
+
+// List mocks base method.
+func (m *MockNotificationDao) List(ctx context.Context, uid int64, offset, limit int) ([]dao.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]dao.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockNotificationDaoMockRecorder) List(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockNotificationDao)(nil).List), ctx, uid, offset, limit)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotificationDao) MarkAllRead(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationDaoMockRecorder) MarkAllRead(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotificationDao)(nil).MarkAllRead), ctx, uid)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotificationDao) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, uid, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationDaoMockRecorder) MarkRead(ctx, uid, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotificationDao)(nil).MarkRead), ctx, uid, ids)
+}
+
+// Upsert mocks base method.
+func (m *MockNotificationDao) Upsert(ctx context.Context, n dao.Notification) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, n)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockNotificationDaoMockRecorder) Upsert(ctx, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockNotificationDao)(nil).Upsert), ctx, n)
+}
@@ -41,6 +41,35 @@ func (m *MockUserDao) EXPECT() *MockUserDaoMockRecorder {
 	return m.recorder
 }
 
+// ClearProfileField mocks base method.
+func (m *MockUserDao) ClearProfileField(ctx context.Context, id int64, column string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearProfileField", ctx, id, column)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearProfileField indicates an expected call of ClearProfileField.
+func (mr *MockUserDaoMockRecorder) ClearProfileField(ctx, id, column any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearProfileField", reflect.TypeOf((*MockUserDao)(nil).ClearProfileField), ctx, id, column)
+}
+
+// DeleteUnverified mocks base method.
+func (m *MockUserDao) DeleteUnverified(ctx context.Context, before int64, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUnverified", ctx, before, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUnverified indicates an expected call of DeleteUnverified.
+func (mr *MockUserDaoMockRecorder) DeleteUnverified(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUnverified", reflect.TypeOf((*MockUserDao)(nil).DeleteUnverified), ctx, before, limit)
+}
+
 // FindByEmail mocks base method.
 func (m *MockUserDao) FindByEmail(ctx context.Context, email string) (dao.User, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +85,21 @@ func (mr *MockUserDaoMockRecorder) FindByEmail(ctx, email any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockUserDao)(nil).FindByEmail), ctx, email)
 }
 
+// FindByHandle mocks base method.
+func (m *MockUserDao) FindByHandle(ctx context.Context, key string) (dao.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHandle", ctx, key)
+	ret0, _ := ret[0].(dao.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHandle indicates an expected call of FindByHandle.
+func (mr *MockUserDaoMockRecorder) FindByHandle(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHandle", reflect.TypeOf((*MockUserDao)(nil).FindByHandle), ctx, key)
+}
+
 // FindById mocks base method.
 func (m *MockUserDao) FindById(ctx context.Context, id int64) (dao.User, error) {
 	m.ctrl.T.Helper()
@@ -114,3 +158,172 @@ func (mr *MockUserDaoMockRecorder) Insert(ctx, u any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockUserDao)(nil).Insert), ctx, u)
 }
+
+// MarkEmailVerified mocks base method.
+func (m *MockUserDao) MarkEmailVerified(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockUserDaoMockRecorder) MarkEmailVerified(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockUserDao)(nil).MarkEmailVerified), ctx, id)
+}
+
+// Search mocks base method.
+func (m *MockUserDao) Search(ctx context.Context, keyword string, offset, limit int) ([]dao.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, keyword, offset, limit)
+	ret0, _ := ret[0].([]dao.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockUserDaoMockRecorder) Search(ctx, keyword, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserDao)(nil).Search), ctx, keyword, offset, limit)
+}
+
+// Unbind mocks base method.
+func (m *MockUserDao) Unbind(ctx context.Context, id int64, method string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unbind", ctx, id, method)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unbind indicates an expected call of Unbind.
+func (mr *MockUserDaoMockRecorder) Unbind(ctx, id, method any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unbind", reflect.TypeOf((*MockUserDao)(nil).Unbind), ctx, id, method)
+}
+
+// UpdateAvatar mocks base method.
+func (m *MockUserDao) UpdateAvatar(ctx context.Context, id int64, avatar string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAvatar", ctx, id, avatar)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAvatar indicates an expected call of UpdateAvatar.
+func (mr *MockUserDaoMockRecorder) UpdateAvatar(ctx, id, avatar any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAvatar", reflect.TypeOf((*MockUserDao)(nil).UpdateAvatar), ctx, id, avatar)
+}
+
+// UpdateBan mocks base method.
+func (m *MockUserDao) UpdateBan(ctx context.Context, id int64, status uint8, reason string, until int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBan", ctx, id, status, reason, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBan indicates an expected call of UpdateBan.
+func (mr *MockUserDaoMockRecorder) UpdateBan(ctx, id, status, reason, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBan", reflect.TypeOf((*MockUserDao)(nil).UpdateBan), ctx, id, status, reason, until)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockUserDao) UpdateEmail(ctx context.Context, id int64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, id, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockUserDaoMockRecorder) UpdateEmail(ctx, id, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUserDao)(nil).UpdateEmail), ctx, id, email)
+}
+
+// UpdateHandle mocks base method.
+func (m *MockUserDao) UpdateHandle(ctx context.Context, id int64, handle, key string, before int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHandle", ctx, id, handle, key, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHandle indicates an expected call of UpdateHandle.
+func (mr *MockUserDaoMockRecorder) UpdateHandle(ctx, id, handle, key, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHandle", reflect.TypeOf((*MockUserDao)(nil).UpdateHandle), ctx, id, handle, key, before)
+}
+
+// UpdateNonSensitiveInfo mocks base method.
+func (m *MockUserDao) UpdateNonSensitiveInfo(ctx context.Context, u dao.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNonSensitiveInfo", ctx, u)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNonSensitiveInfo indicates an expected call of UpdateNonSensitiveInfo.
+func (mr *MockUserDaoMockRecorder) UpdateNonSensitiveInfo(ctx, u any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNonSensitiveInfo", reflect.TypeOf((*MockUserDao)(nil).UpdateNonSensitiveInfo), ctx, u)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserDao) UpdatePassword(ctx context.Context, id int64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserDaoMockRecorder) UpdatePassword(ctx, id, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserDao)(nil).UpdatePassword), ctx, id, password)
+}
+
+// UpdatePhone mocks base method.
+func (m *MockUserDao) UpdatePhone(ctx context.Context, id int64, phone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePhone", ctx, id, phone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePhone indicates an expected call of UpdatePhone.
+func (mr *MockUserDaoMockRecorder) UpdatePhone(ctx, id, phone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePhone", reflect.TypeOf((*MockUserDao)(nil).UpdatePhone), ctx, id, phone)
+}
+
+// UpdatePrivacy mocks base method.
+func (m *MockUserDao) UpdatePrivacy(ctx context.Context, id int64, email, phone uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrivacy", ctx, id, email, phone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePrivacy indicates an expected call of UpdatePrivacy.
+func (mr *MockUserDaoMockRecorder) UpdatePrivacy(ctx, id, email, phone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrivacy", reflect.TypeOf((*MockUserDao)(nil).UpdatePrivacy), ctx, id, email, phone)
+}
+
+// UpdateWechat mocks base method.
+func (m *MockUserDao) UpdateWechat(ctx context.Context, id int64, openID, unionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWechat", ctx, id, openID, unionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWechat indicates an expected call of UpdateWechat.
+func (mr *MockUserDaoMockRecorder) UpdateWechat(ctx, id, openID, unionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWechat", reflect.TypeOf((*MockUserDao)(nil).UpdateWechat), ctx, id, openID, unionID)
+}
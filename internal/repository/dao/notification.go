@@ -0,0 +1,157 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationDao interface {
+	// Upsert 存在可以聚合的未读通知就累加次数，否则插入一条新的
+	// created 表示是否插入了新的通知
+	Upsert(ctx context.Context, n Notification) (created bool, err error)
+	List(ctx context.Context, uid int64, offset, limit int) ([]Notification, error)
+	CountUnread(ctx context.Context, uid int64) (int64, error)
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	MarkAllRead(ctx context.Context, uid int64) error
+}
+
+type GormNotificationDao struct {
+	db *gorm.DB
+}
+
+func NewGormNotificationDao(db *gorm.DB) NotificationDao {
+	return &GormNotificationDao{
+		db: db,
+	}
+}
+
+// Notification 站内通知表
+// 列表按照 uid 和 utime 查询，聚合按照 uid, biz, agg_key 查询未读的
+type Notification struct {
+	Id      int64  `gorm:"primaryKey,autoIncrement"`
+	Uid     int64  `gorm:"index:uid_utime;index:uid_biz_key"`
+	Biz     string `gorm:"type:varchar(64);index:uid_biz_key"`
+	AggKey  string `gorm:"type:varchar(128);index:uid_biz_key"`
+	Actor   string `gorm:"type:varchar(128)"`
+	Content string `gorm:"type:varchar(1024)"`
+	Cnt     int64
+	// ActorCnt 聚合进来的不同的 Actor 的数量
+	ActorCnt int64
+	Read     bool
+	Ctime    int64
+	Utime    int64 `gorm:"index:uid_utime"`
+}
+
+// NotificationActor 聚合通知里面出现过的 Actor，同一个人重复触发只算一次
+type NotificationActor struct {
+	Id             int64  `gorm:"primaryKey,autoIncrement"`
+	NotificationId int64  `gorm:"uniqueIndex:notification_actor"`
+	Actor          string `gorm:"type:varchar(128);uniqueIndex:notification_actor"`
+	Ctime          int64
+}
+
+func (dao *GormNotificationDao) Upsert(ctx context.Context, n Notification) (bool, error) {
+	now := time.Now().UnixMilli()
+	n.Ctime = now
+	n.Utime = now
+	n.Cnt = 1
+	if n.Actor != "" {
+		n.ActorCnt = 1
+	}
+	if n.AggKey == "" {
+		return true, dao.db.WithContext(ctx).Create(&n).Error
+	}
+	created := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old Notification
+		// 锁住这条未读通知，防止并发的时候重复插入或者少算次数
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("uid=? AND biz=? AND agg_key=? AND `read`=?", n.Uid, n.Biz, n.AggKey, false).
+			First(&old).Error
+		switch {
+		case err == nil:
+			updates := map[string]any{
+				"cnt":     gorm.Expr("cnt + 1"),
+				"actor":   n.Actor,
+				"content": n.Content,
+				"utime":   now,
+			}
+			added, err := dao.addActor(tx, old.Id, n.Actor, now)
+			if err != nil {
+				return err
+			}
+			if added {
+				updates["actor_cnt"] = gorm.Expr("actor_cnt + 1")
+			}
+			return tx.Model(&old).Updates(updates).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			created = true
+			if err = tx.Create(&n).Error; err != nil {
+				return err
+			}
+			_, err = dao.addActor(tx, n.Id, n.Actor, now)
+			return err
+		default:
+			return err
+		}
+	})
+	return created, err
+}
+
+// addActor 记录聚合进来的 Actor，added 表示这个 Actor 是第一次出现
+func (dao *GormNotificationDao) addActor(tx *gorm.DB, notificationId int64, actor string, now int64) (bool, error) {
+	if actor == "" {
+		return false, nil
+	}
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&NotificationActor{
+		NotificationId: notificationId,
+		Actor:          actor,
+		Ctime:          now,
+	})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (dao *GormNotificationDao) List(ctx context.Context, uid int64, offset, limit int) ([]Notification, error) {
+	var res []Notification
+	err := dao.db.WithContext(ctx).
+		Where("uid=?", uid).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormNotificationDao) CountUnread(ctx context.Context, uid int64) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&Notification{}).
+		Where("uid=? AND `read`=?", uid, false).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+// MarkRead 已读不修改 utime，否则列表的顺序会变
+func (dao *GormNotificationDao) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	return dao.db.WithContext(ctx).Model(&Notification{}).
+		Where("uid=? AND id IN ? AND `read`=?", uid, ids, false).
+		Update("read", true).Error
+}
+
+func (dao *GormNotificationDao) MarkAllRead(ctx context.Context, uid int64) error {
+	return dao.db.WithContext(ctx).Model(&Notification{}).
+		Where("uid=? AND `read`=?", uid, false).
+		Update("read", true).Error
+}
+
+// deleteNotifications 删除用户的全部通知，以及聚合记录的 Actor
+func deleteNotifications(tx *gorm.DB, uids []int64) error {
+	err := tx.Where("notification_id IN (?)", tx.Model(&Notification{}).Select("id").Where("uid IN ?", uids)).
+		Delete(&NotificationActor{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("uid IN ?", uids).Delete(&Notification{}).Error
+}
@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGormNotificationDao_Upsert(t *testing.T) {
+	const (
+		findSQL  = "SELECT \\* FROM `notifications` WHERE uid=.* AND biz=.* AND agg_key=.* FOR UPDATE"
+		actorSQL = "INSERT INTO `notification_actors` .* ON DUPLICATE KEY UPDATE `id`=`id`"
+	)
+	testCases := []struct {
+		name        string
+		mock        func(mock sqlmock.Sqlmock)
+		wantCreated bool
+	}{
+		{
+			name: "第一条通知",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(findSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectExec("INSERT INTO `notifications`").
+					WillReturnResult(sqlmock.NewResult(10, 1))
+				mock.ExpectExec(actorSQL).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantCreated: true,
+		},
+		{
+			name: "另外一个人，人数加一",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(findSQL).WillReturnRows(sqlmock.NewRows([]string{"id", "cnt", "actor_cnt"}).AddRow(10, 1, 1))
+				mock.ExpectExec(actorSQL).WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec("UPDATE `notifications` SET .*`actor_cnt`=actor_cnt \\+ 1.*`cnt`=cnt \\+ 1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "同一个人重复触发，人数不变",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(findSQL).WillReturnRows(sqlmock.NewRows([]string{"id", "cnt", "actor_cnt"}).AddRow(10, 2, 2))
+				// 已经有这个人了，插入被忽略
+				mock.ExpectExec(actorSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("UPDATE `notifications` SET `actor`=\\?,`cnt`=cnt \\+ 1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.mock(mock)
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      mockDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing: true,
+			})
+			require.NoError(t, err)
+			created, err := NewGormNotificationDao(db).Upsert(context.Background(), Notification{
+				Uid:     1,
+				Biz:     "like",
+				AggKey:  "article:1",
+				Actor:   "Alice",
+				Content: "赞了你的文章",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCreated, created)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportDuplicate      = errors.New("重复举报")
+	ErrReportNotFound       = gorm.ErrRecordNotFound
+	ErrReportStatusConflict = errors.New("举报状态已变更")
+)
+
+type ReportDao interface {
+	Insert(ctx context.Context, r Report) (int64, error)
+	FindById(ctx context.Context, id int64) (Report, error)
+	ListByStatus(ctx context.Context, status uint8, offset, limit int) ([]Report, error)
+	// ListByReporter 用户提交过的举报，按照 id 排序
+	ListByReporter(ctx context.Context, reporterId int64, offset, limit int) ([]Report, error)
+	// Transit 把举报从 from 状态流转到 to 状态，同时写入一条审核记录
+	Transit(ctx context.Context, id int64, from, to uint8, d ReportDecision) error
+}
+
+type GormReportDao struct {
+	db *gorm.DB
+}
+
+func NewGormReportDao(db *gorm.DB) ReportDao {
+	return &GormReportDao{
+		db: db,
+	}
+}
+
+// Report 举报表
+// 同一个人对同一个对象只能举报一次，所以在 reporter_id, biz, biz_id 上建唯一索引
+// 审核人员按照状态和创建时间查看待审核队列
+type Report struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	ReporterId int64  `gorm:"uniqueIndex:reporter_biz_bizid"`
+	Biz        string `gorm:"type:varchar(64);uniqueIndex:reporter_biz_bizid"`
+	BizId      int64  `gorm:"uniqueIndex:reporter_biz_bizid"`
+	Field      string `gorm:"type:varchar(64)"`
+	Reason     string `gorm:"type:varchar(1024)"`
+	Status     uint8  `gorm:"index:status_ctime"`
+	Ctime      int64  `gorm:"index:status_ctime"`
+	Utime      int64
+}
+
+// ReportDecision 审核记录，只增不改
+type ReportDecision struct {
+	Id         int64 `gorm:"primaryKey,autoIncrement"`
+	ReportId   int64 `gorm:"index"`
+	ReviewerId int64
+	FromStatus uint8
+	ToStatus   uint8
+	Note       string `gorm:"type:varchar(1024)"`
+	Ctime      int64
+}
+
+func (dao *GormReportDao) Insert(ctx context.Context, r Report) (int64, error) {
+	now := time.Now().UnixMilli()
+	r.Ctime = now
+	r.Utime = now
+	err := dao.db.WithContext(ctx).Create(&r).Error
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		const uniqueConflictsErrNo uint16 = 1062
+		if mysqlErr.Number == uniqueConflictsErrNo {
+			return 0, ErrReportDuplicate
+		}
+	}
+	return r.Id, err
+}
+
+func (dao *GormReportDao) FindById(ctx context.Context, id int64) (Report, error) {
+	var r Report
+	err := dao.db.WithContext(ctx).Where("id=?", id).First(&r).Error
+	return r, err
+}
+
+func (dao *GormReportDao) ListByStatus(ctx context.Context, status uint8, offset, limit int) ([]Report, error) {
+	var res []Report
+	err := dao.db.WithContext(ctx).
+		Where("status=?", status).
+		Order("ctime ASC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormReportDao) ListByReporter(ctx context.Context, reporterId int64, offset, limit int) ([]Report, error) {
+	var res []Report
+	err := dao.db.WithContext(ctx).
+		Where("reporter_id=?", reporterId).
+		Order("id ASC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormReportDao) Transit(ctx context.Context, id int64, from, to uint8, d ReportDecision) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 带上 from 作为条件，保证只有一个审核人员能处理成功
+		res := tx.Model(&Report{}).
+			Where("id=? AND status=?", id, from).
+			Updates(map[string]any{
+				"status": to,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrReportStatusConflict
+		}
+		d.ReportId = id
+		d.FromStatus = from
+		d.ToStatus = to
+		d.Ctime = now
+		return tx.Create(&d).Error
+	})
+}
+
+// deleteReports 删除用户提交的举报，以及这些举报的审核记录
+func deleteReports(tx *gorm.DB, reporterIds []int64) error {
+	err := tx.Where("report_id IN (?)", tx.Model(&Report{}).Select("id").Where("reporter_id IN ?", reporterIds)).
+		Delete(&ReportDecision{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("reporter_id IN ?", reporterIds).Delete(&Report{}).Error
+}
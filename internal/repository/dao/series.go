@@ -0,0 +1,157 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSeriesNotFound         = gorm.ErrRecordNotFound
+	ErrSeriesArticleDuplicate = errors.New("文章已经在其它专栏中")
+	ErrSeriesVersionConflict  = errors.New("专栏已经被修改")
+)
+
+type SeriesDao interface {
+	Insert(ctx context.Context, s Series) (int64, error)
+	UpdateTitle(ctx context.Context, id, authorId int64, title string) error
+	FindById(ctx context.Context, id int64) (Series, error)
+	ListByAuthor(ctx context.Context, authorId int64) ([]Series, error)
+	// ListArticleIds 按照顺序返回专栏中的文章
+	ListArticleIds(ctx context.Context, seriesId int64) ([]int64, error)
+	// SetArticleIds 整体替换专栏中的文章和顺序
+	// 只有 version 和数据库中的一致才会替换，否则返回 ErrSeriesVersionConflict
+	SetArticleIds(ctx context.Context, seriesId, version int64, articleIds []int64) error
+	// FindByArticle 查找文章所在的专栏
+	FindByArticle(ctx context.Context, articleId int64) (SeriesArticle, error)
+}
+
+type GormSeriesDao struct {
+	db *gorm.DB
+}
+
+func NewGormSeriesDao(db *gorm.DB) SeriesDao {
+	return &GormSeriesDao{
+		db: db,
+	}
+}
+
+// Series 专栏表
+type Series struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	AuthorId int64  `gorm:"index"`
+	Title    string `gorm:"type:varchar(256)"`
+	// Version 乐观锁版本号，专栏中的文章或者顺序每次变化都会加一
+	Version int64 `gorm:"not null;default:0"`
+	Ctime   int64
+	Utime   int64
+}
+
+// SeriesArticle 专栏和文章的关系
+// 一篇文章只能属于一个专栏，所以在 article_id 上建唯一索引
+type SeriesArticle struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	SeriesId  int64 `gorm:"index:series_position"`
+	ArticleId int64 `gorm:"uniqueIndex"`
+	Position  int   `gorm:"index:series_position"`
+	Ctime     int64
+}
+
+func (dao *GormSeriesDao) Insert(ctx context.Context, s Series) (int64, error) {
+	now := time.Now().UnixMilli()
+	s.Ctime = now
+	s.Utime = now
+	err := dao.db.WithContext(ctx).Create(&s).Error
+	return s.Id, err
+}
+
+func (dao *GormSeriesDao) UpdateTitle(ctx context.Context, id, authorId int64, title string) error {
+	res := dao.db.WithContext(ctx).Model(&Series{}).
+		Where("id=? AND author_id=?", id, authorId).
+		Updates(map[string]any{
+			"title": title,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrSeriesNotFound
+	}
+	return nil
+}
+
+func (dao *GormSeriesDao) FindById(ctx context.Context, id int64) (Series, error) {
+	var s Series
+	err := dao.db.WithContext(ctx).Where("id=?", id).First(&s).Error
+	return s, err
+}
+
+func (dao *GormSeriesDao) ListByAuthor(ctx context.Context, authorId int64) ([]Series, error) {
+	var res []Series
+	err := dao.db.WithContext(ctx).
+		Where("author_id=?", authorId).
+		Order("utime DESC").
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GormSeriesDao) ListArticleIds(ctx context.Context, seriesId int64) ([]int64, error) {
+	var ids []int64
+	err := dao.db.WithContext(ctx).Model(&SeriesArticle{}).
+		Where("series_id=?", seriesId).
+		Order("position ASC").
+		Pluck("article_id", &ids).Error
+	return ids, err
+}
+
+func (dao *GormSeriesDao) SetArticleIds(ctx context.Context, seriesId, version int64, articleIds []int64) error {
+	now := time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先更新版本号，同时锁住专栏，并发的修改只有一个能成功
+		res := tx.Model(&Series{}).Where("id=? AND version=?", seriesId, version).
+			Updates(map[string]any{
+				"version": gorm.Expr("version + 1"),
+				"utime":   now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSeriesVersionConflict
+		}
+		err := tx.Where("series_id=?", seriesId).Delete(&SeriesArticle{}).Error
+		if err != nil {
+			return err
+		}
+		if len(articleIds) > 0 {
+			rows := make([]SeriesArticle, 0, len(articleIds))
+			for i, aid := range articleIds {
+				rows = append(rows, SeriesArticle{
+					SeriesId:  seriesId,
+					ArticleId: aid,
+					Position:  i,
+					Ctime:     now,
+				})
+			}
+			return tx.Create(&rows).Error
+		}
+		return nil
+	})
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		const uniqueConflictsErrNo uint16 = 1062
+		if mysqlErr.Number == uniqueConflictsErrNo {
+			return ErrSeriesArticleDuplicate
+		}
+	}
+	return err
+}
+
+func (dao *GormSeriesDao) FindByArticle(ctx context.Context, articleId int64) (SeriesArticle, error) {
+	var sa SeriesArticle
+	err := dao.db.WithContext(ctx).Where("article_id=?", articleId).First(&sa).Error
+	return sa, err
+}
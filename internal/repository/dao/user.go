@@ -4,22 +4,83 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+	"strings"
 	"time"
 )
 
 var (
 	ErrUserDuplicate = errors.New("邮箱冲突 or 手机号冲突")
 	ErrUserNotFound  = gorm.ErrRecordNotFound
+	// 绑定的时候能确定是哪个唯一索引冲突了
+	ErrUserPhoneDuplicate  = errors.New("手机号已经被其它账号绑定")
+	ErrUserEmailDuplicate  = errors.New("邮箱已经被其它账号绑定")
+	ErrUserWechatDuplicate = errors.New("微信已经被其它账号绑定")
+	// ErrUserLastLoginMethod 解绑之后账号就没有办法登录了
+	ErrUserLastLoginMethod = errors.New("至少要保留一种登录方式")
+	ErrUserHandleDuplicate = errors.New("用户名已经被使用")
+	// ErrUserHandleCooldown 距离上一次修改用户名的时间太短
+	ErrUserHandleCooldown = errors.New("修改用户名太频繁")
 )
 
+// 登录方式，解绑的时候使用
+const (
+	LoginMethodPhone  = "phone"
+	LoginMethodEmail  = "email"
+	LoginMethodWechat = "wechat"
+)
+
+// loginMethodConds 每种登录方式可用的条件，邮箱登录还需要设置过密码
+var loginMethodConds = []struct {
+	method string
+	cond   string
+}{
+	{method: LoginMethodPhone, cond: "phone IS NOT NULL"},
+	{method: LoginMethodEmail, cond: "(email IS NOT NULL AND password <> '')"},
+	{method: LoginMethodWechat, cond: "wechat_open_id IS NOT NULL"},
+}
+
 type UserDao interface {
 	Insert(ctx context.Context, u User) error
 	FindByEmail(ctx context.Context, email string) (User, error)
 	FindByPhone(ctx context.Context, phone string) (User, error)
 	FindById(ctx context.Context, id int64) (User, error)
 	FindByWechat(ctx context.Context, openID string) (User, error)
+	// ClearProfileField 把个人资料的某一列置空，column 只能是 nic_name 或 about_me
+	ClearProfileField(ctx context.Context, id int64, column string) error
+	// UpdateNonSensitiveInfo 只更新昵称、生日和个人简介，BirthDay 是零值的时候置为 NULL
+	UpdateNonSensitiveInfo(ctx context.Context, u User) error
+	UpdateAvatar(ctx context.Context, id int64, avatar string) error
+	// UpdatePassword password 是加密之后的密码
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	MarkEmailVerified(ctx context.Context, id int64) error
+	// DeleteUnverified 删除 before 之前注册并且一直没有验证邮箱的用户，连同文章、专栏、通知、举报和登录记录
+	// 返回删除的用户 id，调用方需要让这些用户的登录失效
+	DeleteUnverified(ctx context.Context, before int64, limit int) ([]int64, error)
+	// UpdatePhone 绑定手机号，已经被其它账号绑定返回 ErrUserPhoneDuplicate
+	UpdatePhone(ctx context.Context, id int64, phone string) error
+	// UpdateEmail 绑定邮箱，绑定之前已经验证过了，已经被其它账号绑定返回 ErrUserEmailDuplicate
+	UpdateEmail(ctx context.Context, id int64, email string) error
+	// UpdateWechat 绑定微信，已经被其它账号绑定返回 ErrUserWechatDuplicate
+	UpdateWechat(ctx context.Context, id int64, openID, unionID string) error
+	// Unbind 解绑登录方式，method 取值见 LoginMethodXXX
+	// 解绑之后没有其它登录方式返回 ErrUserLastLoginMethod
+	Unbind(ctx context.Context, id int64, method string) error
+	// Search 管理后台搜索用户，keyword 是数字的时候同时按照 id 查找，其它按照邮箱、手机号、昵称的前缀查找
+	Search(ctx context.Context, keyword string, offset, limit int) ([]User, error)
+	// UpdateBan 封禁、限制或者解除，status 取值和 domain.UserBanStatus 一致，until 为 0 表示永久
+	UpdateBan(ctx context.Context, id int64, status uint8, reason string, until int64) error
+	// FindByHandle key 是小写之后的用户名
+	FindByHandle(ctx context.Context, key string) (User, error)
+	// UpdateHandle 只有上一次修改在 before 之前才能修改，否则返回 ErrUserHandleCooldown
+	// 已经被其它账号使用返回 ErrUserHandleDuplicate
+	UpdateHandle(ctx context.Context, id int64, handle, key string, before int64) error
+	// UpdatePrivacy 取值和 domain.PrivacyLevel 一致
+	UpdatePrivacy(ctx context.Context, id int64, email, phone uint8) error
 }
 
 type GORMUserDao struct {
@@ -38,34 +99,66 @@ type User struct {
 	Email    sql.NullString `gorm:"unique"`
 	Phone    sql.NullString `gorm:"unique"`
 	Password string
-	NicName  string    `gorm:"type=varchar(128)"`
+	NicName  string    `gorm:"type:varchar(128)"`
 	BirthDay time.Time `gorm:"default:null"`
-	AboutMe  string    `gorm:"type=varchar(4096)"`
+	AboutMe  string    `gorm:"type:varchar(4096)"`
+	// Avatar 头像在文件存储中的前缀
+	Avatar string `gorm:"type:varchar(256)"`
+	// EmailUnverified 邮箱注册之后还没有验证，默认值 false 让已有的用户都当作验证过的
+	EmailUnverified bool `gorm:"not null;default:false;index:idx_unverified_ctime,priority:1"`
+	// MergedInto 账号被合并到了哪个用户，0 表示没有被合并
+	MergedInto int64 `gorm:"not null;default:0"`
+	// DeleteAt 申请注销之后真正注销的时间，毫秒数，0 表示没有申请注销
+	DeleteAt int64 `gorm:"not null;default:0;index"`
+	// Deleted 已经注销，个人信息都已经清空
+	Deleted bool `gorm:"not null;default:false"`
+	// BanStatus 封禁状态，和 domain.UserBanStatus 保持一致，0 表示正常
+	BanStatus uint8  `gorm:"not null;default:0"`
+	BanReason string `gorm:"type:varchar(256)"`
+	// BanUntil 封禁的到期时间，毫秒数，0 表示永久
+	BanUntil int64 `gorm:"not null;default:0"`
+	// Handle 用户名，保留用户输入的大小写
+	// HandleKey 是小写的用户名，用唯一索引保证不区分大小写的唯一，没有设置的时候为 NULL
+	Handle    string         `gorm:"type:varchar(32)"`
+	HandleKey sql.NullString `gorm:"type:varchar(32);unique"`
+	// HandleUtime 上一次修改用户名的时间，毫秒数
+	HandleUtime int64 `gorm:"not null;default:0"`
+	// EmailPrivacy PhonePrivacy 和 domain.PrivacyLevel 保持一致，0 表示不展示
+	EmailPrivacy uint8 `gorm:"not null;default:0"`
+	PhonePrivacy uint8 `gorm:"not null;default:0"`
 
 	// wechat 字段
 	WechatOpenID  sql.NullString `gorm:"type=varchar(128);unique"`
 	WechatUnionID sql.NullString
 
-	Ctime int64 // 创新时间, 毫秒数
+	Ctime int64 `gorm:"index:idx_unverified_ctime,priority:2"` // 创新时间, 毫秒数
 	Utime int64 // 更新时间, 毫秒数
 }
 
+// banned 封禁或者限制还没有到期，和 domain.UserBan 的 Effective 一致
+func (u User) banned(now int64) bool {
+	return u.BanStatus != 0 && (u.BanUntil == 0 || now < u.BanUntil)
+}
+
 func (dao *GORMUserDao) Insert(ctx context.Context, u User) error {
 	// 存毫秒数
 	now := time.Now().UnixMilli()
 	u.Utime = now
 	u.Ctime = now
 	err := dao.db.WithContext(ctx).Create(&u).Error
-	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
-		const uniqueConflictsErrNo uint16 = 1062
-		if mysqlErr.Number == uniqueConflictsErrNo {
-			// 邮箱冲突
-			return ErrUserDuplicate
-		}
+	if isUniqueConflict(err) {
+		// 邮箱冲突
+		return ErrUserDuplicate
 	}
 	return err
 }
 
+func isUniqueConflict(err error) bool {
+	const uniqueConflictsErrNo uint16 = 1062
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == uniqueConflictsErrNo
+}
+
 func (dao *GORMUserDao) FindByEmail(ctx context.Context, email string) (User, error) {
 	var u User
 	err := dao.db.WithContext(ctx).Where("email=?", email).First(&u).Error
@@ -89,3 +182,250 @@ func (dao *GORMUserDao) FindByWechat(ctx context.Context, openID string) (User,
 	err := dao.db.WithContext(ctx).Where("wechat_open_id=?", openID).First(&u).Error
 	return u, err
 }
+
+func (dao *GORMUserDao) ClearProfileField(ctx context.Context, id int64, column string) error {
+	return dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			column:  "",
+			"utime": time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMUserDao) UpdateNonSensitiveInfo(ctx context.Context, u User) error {
+	var birthday any
+	if !u.BirthDay.IsZero() {
+		birthday = u.BirthDay
+	}
+	return dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", u.Id).
+		Updates(map[string]any{
+			"nic_name":  u.NicName,
+			"birth_day": birthday,
+			"about_me":  u.AboutMe,
+			"utime":     time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMUserDao) UpdateAvatar(ctx context.Context, id int64, avatar string) error {
+	return dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"avatar": avatar,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMUserDao) UpdatePassword(ctx context.Context, id int64, password string) error {
+	return dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"password": password,
+			"utime":    time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMUserDao) MarkEmailVerified(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"email_unverified": false,
+			"utime":            time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMUserDao) DeleteUnverified(ctx context.Context, before int64, limit int) ([]int64, error) {
+	var ids []int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 加锁之后用户就不能在删除的过程中验证邮箱了
+		err := tx.Model(&User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("email_unverified = ? AND ctime < ?", true, before).
+			Order("id ASC").
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		// 没有验证的用户不能修改资料，不会有头像文件要删
+		err = tx.Where("survivor_id IN ? OR merged_id IN ?", ids, ids).Delete(&UserMergeLog{}).Error
+		if err != nil {
+			return err
+		}
+		err = deleteNotifications(tx, ids)
+		if err != nil {
+			return err
+		}
+		err = deleteReports(tx, ids)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid IN ?", ids).Delete(&LoginLog{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid IN ?", ids).Delete(&UserMFARecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid IN ?", ids).Delete(&UserMFA{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid IN ?", ids).Delete(&UserRole{}).Error
+		if err != nil {
+			return err
+		}
+		if err = purgeArticles(tx, ids); err != nil {
+			return err
+		}
+		// 被合并进来的墓碑账号也一起删掉
+		return tx.Where("id IN ? OR merged_into IN ?", ids, ids).Delete(&User{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (dao *GORMUserDao) UpdatePhone(ctx context.Context, id int64, phone string) error {
+	err := dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"phone": phone,
+			"utime": time.Now().UnixMilli(),
+		}).Error
+	if isUniqueConflict(err) {
+		return ErrUserPhoneDuplicate
+	}
+	return err
+}
+
+func (dao *GORMUserDao) UpdateEmail(ctx context.Context, id int64, email string) error {
+	err := dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"email":            email,
+			"email_unverified": false,
+			"utime":            time.Now().UnixMilli(),
+		}).Error
+	if isUniqueConflict(err) {
+		return ErrUserEmailDuplicate
+	}
+	return err
+}
+
+func (dao *GORMUserDao) UpdateWechat(ctx context.Context, id int64, openID, unionID string) error {
+	err := dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"wechat_open_id":  openID,
+			"wechat_union_id": sql.NullString{String: unionID, Valid: unionID != ""},
+			"utime":           time.Now().UnixMilli(),
+		}).Error
+	if isUniqueConflict(err) {
+		return ErrUserWechatDuplicate
+	}
+	return err
+}
+
+func (dao *GORMUserDao) Unbind(ctx context.Context, id int64, method string) error {
+	updates := map[string]any{
+		"utime": time.Now().UnixMilli(),
+	}
+	switch method {
+	case LoginMethodPhone:
+		updates["phone"] = nil
+	case LoginMethodEmail:
+		updates["email"] = nil
+		updates["email_unverified"] = false
+	case LoginMethodWechat:
+		updates["wechat_open_id"] = nil
+		updates["wechat_union_id"] = nil
+	default:
+		return fmt.Errorf("不支持解绑的登录方式 %s", method)
+	}
+	// 检查剩下的登录方式和更新放在同一条语句里，避免并发解绑之后一种登录方式都没有了
+	others := make([]string, 0, len(loginMethodConds))
+	for _, c := range loginMethodConds {
+		if c.method != method {
+			others = append(others, c.cond)
+		}
+	}
+	res := dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Where(strings.Join(others, " OR ")).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserLastLoginMethod
+	}
+	return nil
+}
+
+func (dao *GORMUserDao) Search(ctx context.Context, keyword string, offset, limit int) ([]User, error) {
+	var res []User
+	// LIKE 的通配符需要转义，只做前缀匹配才能用上索引
+	prefix := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword) + "%"
+	db := dao.db.WithContext(ctx).
+		Where("email LIKE ? OR phone LIKE ? OR nic_name LIKE ?", prefix, prefix, prefix)
+	if id, err := strconv.ParseInt(keyword, 10, 64); err == nil {
+		db = db.Or("id=?", id)
+	}
+	err := db.Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMUserDao) UpdateBan(ctx context.Context, id int64, status uint8, reason string, until int64) error {
+	return dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"ban_status": status,
+			"ban_reason": reason,
+			"ban_until":  until,
+			"utime":      time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMUserDao) FindByHandle(ctx context.Context, key string) (User, error) {
+	var u User
+	err := dao.db.WithContext(ctx).Where("handle_key=?", key).First(&u).Error
+	return u, err
+}
+
+func (dao *GORMUserDao) UpdateHandle(ctx context.Context, id int64, handle, key string, before int64) error {
+	now := time.Now().UnixMilli()
+	// 检查冷却时间和更新放在同一条语句里面，并发修改只有一个能成功
+	res := dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=? AND handle_utime<?", id, before).
+		Updates(map[string]any{
+			"handle":       handle,
+			"handle_key":   key,
+			"handle_utime": now,
+			"utime":        now,
+		})
+	if isUniqueConflict(res.Error) {
+		return ErrUserHandleDuplicate
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserHandleCooldown
+	}
+	return nil
+}
+
+func (dao *GORMUserDao) UpdatePrivacy(ctx context.Context, id int64, email, phone uint8) error {
+	return dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=?", id).
+		Updates(map[string]any{
+			"email_privacy": email,
+			"phone_privacy": phone,
+			"utime":         time.Now().UnixMilli(),
+		}).Error
+}
@@ -0,0 +1,173 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrUserDeleted 账号已经注销
+	ErrUserDeleted = errors.New("账号已经注销")
+	// ErrUserDeleteNotDue 用户已经取消注销，或者还没有到注销的时间
+	ErrUserDeleteNotDue = errors.New("还没有到注销的时间")
+)
+
+type UserDeletionDao interface {
+	// Schedule 申请注销，at 是真正注销的时间，已经注销的账号返回 ErrUserDeleted
+	Schedule(ctx context.Context, id, at int64) error
+	// Cancel 取消注销，没有申请过注销也不报错
+	Cancel(ctx context.Context, id int64) error
+	// ListDue 注销时间在 before 之前并且还没有注销的用户 id
+	ListDue(ctx context.Context, before int64, limit int) ([]int64, error)
+	// Anonymize 注销账号：清空个人信息和登录方式，删除通知、合并记录、登录记录、举报和角色
+	// deleteArticles 为 true 的时候连同文章和专栏一起删除，否则文章保留，作者显示为已注销用户
+	// 注销时间不在 before 之前返回 ErrUserDeleteNotDue，返回被清空的头像，需要调用方删除文件
+	Anonymize(ctx context.Context, id, before int64, nickname string, deleteArticles bool) ([]string, error)
+}
+
+type GormUserDeletionDao struct {
+	db *gorm.DB
+}
+
+func NewGormUserDeletionDao(db *gorm.DB) UserDeletionDao {
+	return &GormUserDeletionDao{
+		db: db,
+	}
+}
+
+func (dao *GormUserDeletionDao) Schedule(ctx context.Context, id, at int64) error {
+	res := dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=? AND deleted=?", id, false).
+		Updates(map[string]any{
+			"delete_at": at,
+			"utime":     time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserDeleted
+	}
+	return nil
+}
+
+func (dao *GormUserDeletionDao) Cancel(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Model(&User{}).
+		Where("id=? AND deleted=?", id, false).
+		Updates(map[string]any{
+			"delete_at": 0,
+			"utime":     time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GormUserDeletionDao) ListDue(ctx context.Context, before int64, limit int) ([]int64, error) {
+	var ids []int64
+	err := dao.db.WithContext(ctx).Model(&User{}).
+		Where("delete_at>0 AND delete_at<?", before).
+		Order("delete_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (dao *GormUserDeletionDao) Anonymize(ctx context.Context, id, before int64, nickname string, deleteArticles bool) ([]string, error) {
+	now := time.Now().UnixMilli()
+	var avatars []string
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 加锁之后再检查一次，避免和用户取消注销并发
+		var u User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id=?", id).
+			First(&u).Error
+		if err != nil {
+			return err
+		}
+		if u.DeleteAt == 0 || u.DeleteAt >= before {
+			return ErrUserDeleteNotDue
+		}
+
+		// 被合并进来的墓碑账号还留着以前的资料，一起清空
+		err = tx.Model(&User{}).
+			Where("merged_into=? AND avatar<>''", id).
+			Pluck("avatar", &avatars).Error
+		if err != nil {
+			return err
+		}
+		uids := []int64{id}
+		var mergedIds []int64
+		err = tx.Model(&User{}).Where("merged_into=?", id).Pluck("id", &mergedIds).Error
+		if err != nil {
+			return err
+		}
+		uids = append(uids, mergedIds...)
+		if u.Avatar != "" {
+			avatars = append(avatars, u.Avatar)
+		}
+		// 用户名也释放出来，其他人可以使用
+		profile := map[string]any{
+			"nic_name":   nickname,
+			"birth_day":  nil,
+			"about_me":   "",
+			"avatar":     "",
+			"handle":     "",
+			"handle_key": sql.NullString{},
+			"utime":      now,
+		}
+		err = tx.Model(&User{}).Where("merged_into=?", id).Updates(profile).Error
+		if err != nil {
+			return err
+		}
+		// 登录方式全部置为 NULL，之后可以用同样的手机号、邮箱和微信重新注册
+		profile["email"] = sql.NullString{}
+		profile["phone"] = sql.NullString{}
+		profile["wechat_open_id"] = sql.NullString{}
+		profile["wechat_union_id"] = sql.NullString{}
+		profile["password"] = ""
+		profile["email_unverified"] = false
+		profile["delete_at"] = 0
+		profile["deleted"] = true
+		err = tx.Model(&User{}).Where("id=?", id).Updates(profile).Error
+		if err != nil {
+			return err
+		}
+
+		// 合并记录里面有被合并账号的完整资料
+		err = tx.Where("survivor_id=? OR merged_id=?", id, id).Delete(&UserMergeLog{}).Error
+		if err != nil {
+			return err
+		}
+		err = deleteNotifications(tx, []int64{id})
+		if err != nil {
+			return err
+		}
+		// 登录记录里面有 IP 和设备，被合并的账号以前的登录记录也一起删掉
+		err = tx.Where("uid IN ?", uids).Delete(&LoginLog{}).Error
+		if err != nil {
+			return err
+		}
+		// 合并的时候重复的举报和角色留在墓碑账号下面，也一起删掉
+		err = deleteReports(tx, uids)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid IN ?", uids).Delete(&UserRole{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid=?", id).Delete(&UserMFARecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid=?", id).Delete(&UserMFA{}).Error
+		if err != nil || !deleteArticles {
+			return err
+		}
+		return purgeArticles(tx, []int64{id})
+	})
+	return avatars, err
+}
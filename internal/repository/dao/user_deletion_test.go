@@ -0,0 +1,107 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGormUserDeletionDao_Anonymize(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mock        func(mock sqlmock.Sqlmock)
+		wantAvatars []string
+		wantErr     error
+	}{
+		{
+			name: "已经取消注销",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id=.* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "delete_at"}).AddRow(1, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserDeleteNotDue,
+		},
+		{
+			name: "还在冷静期",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id=.* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "delete_at"}).AddRow(1, 2000))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserDeleteNotDue,
+		},
+		{
+			name: "注销并删除文章",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id=.* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "delete_at", "avatar"}).AddRow(1, 500, "avatar/1"))
+				mock.ExpectQuery("SELECT `avatar` FROM `users` WHERE merged_into=").
+					WillReturnRows(sqlmock.NewRows([]string{"avatar"}).AddRow("avatar/2"))
+				mock.ExpectQuery("SELECT `id` FROM `users` WHERE merged_into=").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectExec("UPDATE `users` SET .* WHERE merged_into=").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `users` SET .*`deleted`=.* WHERE id=").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `user_merge_logs`").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `notification_actors` WHERE notification_id IN \\(SELECT `id` FROM `notifications`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM `notifications`").
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("DELETE FROM `login_logs` WHERE uid IN \\(\\?,\\?\\)").
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 5))
+				mock.ExpectExec("DELETE FROM `report_decisions` WHERE report_id IN \\(SELECT `id` FROM `reports` WHERE reporter_id IN").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `reports` WHERE reporter_id IN \\(\\?,\\?\\)").
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM `user_roles` WHERE uid IN \\(\\?,\\?\\)").
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `user_mfa_recovery_codes`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM `user_mfas`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM `series_articles` WHERE series_id IN \\(SELECT `id` FROM `series`").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM `series`").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `articles`").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			wantAvatars: []string{"avatar/2", "avatar/1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.mock(mock)
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      mockDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing: true,
+			})
+			require.NoError(t, err)
+			avatars, err := NewGormUserDeletionDao(db).Anonymize(context.Background(), 1, 1000, "已注销用户", true)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr == nil {
+				assert.Equal(t, tc.wantAvatars, avatars)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+	"xiaoweishu/internal/pkg/ekit/sqlx"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrUserMerged 其中一个账号已经被合并过了
+	ErrUserMerged = errors.New("账号已经被合并")
+	// ErrUserMergeBanned 其中一个账号被封禁或者限制，合并之后封禁就丢了
+	ErrUserMergeBanned = errors.New("封禁中的账号不能合并")
+	// ErrUserMergeUnverified 保留账号的邮箱还没有验证，会被清理任务连同合并进来的数据一起删掉
+	ErrUserMergeUnverified = errors.New("保留账号的邮箱还没有验证")
+	// ErrUserMergeDeleting 其中一个账号申请了注销
+	ErrUserMergeDeleting = errors.New("申请注销的账号不能合并")
+	// ErrUserMergeMFA 只有被合并的账号开启了两步验证，合并之后它的登录方式就不需要第二步验证了
+	ErrUserMergeMFA = errors.New("被合并的账号开启了两步验证")
+)
+
+type UserMergeDao interface {
+	// Merge 把 mergedId 合并到 survivorId，返回合并记录
+	// 登录方式、用户名、文章、专栏、通知、举报、登录记录和角色都转移给 survivorId，mergedId 只留下一条墓碑记录
+	Merge(ctx context.Context, survivorId, mergedId int64) (UserMergeLog, error)
+	ListLogs(ctx context.Context, uid int64) ([]UserMergeLog, error)
+}
+
+type GormUserMergeDao struct {
+	db *gorm.DB
+}
+
+func NewGormUserMergeDao(db *gorm.DB) UserMergeDao {
+	return &GormUserMergeDao{
+		db: db,
+	}
+}
+
+// UserMergeLog 账号合并记录，只增不改
+// Detail 里面有恢复合并需要的全部信息
+type UserMergeLog struct {
+	Id         int64                            `gorm:"primaryKey,autoIncrement"`
+	SurvivorId int64                            `gorm:"index"`
+	MergedId   int64                            `gorm:"index"`
+	Detail     sqlx.JsonColumn[UserMergeDetail] `gorm:"type:json"`
+	Ctime      int64
+}
+
+// UserMergeDetail 合并的时候转移了什么
+type UserMergeDetail struct {
+	// Merged 合并之前被合并账号的完整数据
+	Merged User `json:"merged"`
+	// MovedFields 转移给保留账号的字段，保留账号已经有的不会覆盖
+	MovedFields []string `json:"moved_fields"`
+	// DroppedFields 保留账号已经有了，被合并账号的这些字段被丢弃
+	DroppedFields   []string `json:"dropped_fields"`
+	ArticleIds      []int64  `json:"article_ids"`
+	SeriesIds       []int64  `json:"series_ids"`
+	NotificationIds []int64  `json:"notification_ids"`
+	ReportIds       []int64  `json:"report_ids"`
+	LoginLogIds     []int64  `json:"login_log_ids"`
+	RoleIds         []int64  `json:"role_ids"`
+}
+
+func (dao *GormUserMergeDao) Merge(ctx context.Context, survivorId, mergedId int64) (UserMergeLog, error) {
+	now := time.Now().UnixMilli()
+	var log UserMergeLog
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var users []User
+		// 按照 id 的顺序加锁，避免两个方向同时合并的时候死锁
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []int64{survivorId, mergedId}).
+			Order("id ASC").
+			Find(&users).Error
+		if err != nil {
+			return err
+		}
+		if len(users) != 2 {
+			return ErrUserNotFound
+		}
+		survivor, merged := users[0], users[1]
+		if survivor.Id != survivorId {
+			survivor, merged = merged, survivor
+		}
+		if survivor.MergedInto != 0 || merged.MergedInto != 0 {
+			return ErrUserMerged
+		}
+		if survivor.banned(now) || merged.banned(now) {
+			return ErrUserMergeBanned
+		}
+		if survivor.DeleteAt != 0 || survivor.Deleted || merged.DeleteAt != 0 || merged.Deleted {
+			return ErrUserMergeDeleting
+		}
+		if survivor.EmailUnverified {
+			return ErrUserMergeUnverified
+		}
+		var mfaUids []int64
+		err = tx.Model(&UserMFA{}).
+			Where("uid IN ? AND enabled=?", []int64{survivorId, mergedId}, true).
+			Pluck("uid", &mfaUids).Error
+		if err != nil {
+			return err
+		}
+		if slices.Contains(mfaUids, mergedId) && !slices.Contains(mfaUids, survivorId) {
+			return ErrUserMergeMFA
+		}
+
+		detail := UserMergeDetail{Merged: merged}
+		survivorUpdates := map[string]any{"utime": now}
+		// 保留账号已经有的登录方式不覆盖，被合并账号的直接丢弃
+		if merged.Email.Valid {
+			// 没有验证过的邮箱也不转移，避免保留账号被清理没有验证邮箱的任务删除
+			if survivor.Email.Valid || merged.EmailUnverified {
+				detail.DroppedFields = append(detail.DroppedFields, "email")
+			} else {
+				detail.MovedFields = append(detail.MovedFields, "email")
+				survivorUpdates["email"] = merged.Email
+				// 密码跟着邮箱走，只有邮箱转移了密码才有用
+				if survivor.Password == "" {
+					survivorUpdates["password"] = merged.Password
+				}
+			}
+		}
+		if merged.Phone.Valid {
+			if survivor.Phone.Valid {
+				detail.DroppedFields = append(detail.DroppedFields, "phone")
+			} else {
+				detail.MovedFields = append(detail.MovedFields, "phone")
+				survivorUpdates["phone"] = merged.Phone
+			}
+		}
+		if merged.WechatOpenID.Valid {
+			if survivor.WechatOpenID.Valid {
+				detail.DroppedFields = append(detail.DroppedFields, "wechat")
+			} else {
+				detail.MovedFields = append(detail.MovedFields, "wechat")
+				survivorUpdates["wechat_open_id"] = merged.WechatOpenID
+				survivorUpdates["wechat_union_id"] = merged.WechatUnionID
+			}
+		}
+		// 用户名不能留在墓碑上，不然这个用户名再也没有人能用
+		if merged.HandleKey.Valid {
+			if survivor.HandleKey.Valid {
+				detail.DroppedFields = append(detail.DroppedFields, "handle")
+			} else {
+				detail.MovedFields = append(detail.MovedFields, "handle")
+				survivorUpdates["handle"] = merged.Handle
+				survivorUpdates["handle_key"] = merged.HandleKey
+				survivorUpdates["handle_utime"] = merged.HandleUtime
+			}
+		}
+
+		// 先清空被合并账号的登录方式和用户名，否则转移的时候唯一索引冲突
+		err = tx.Model(&User{}).Where("id=?", mergedId).Updates(map[string]any{
+			"email":            sql.NullString{},
+			"phone":            sql.NullString{},
+			"wechat_open_id":   sql.NullString{},
+			"wechat_union_id":  sql.NullString{},
+			"password":         "",
+			"handle":           "",
+			"handle_key":       sql.NullString{},
+			"email_unverified": false,
+			"merged_into":      survivorId,
+			"utime":            now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&User{}).Where("id=?", survivorId).Updates(survivorUpdates).Error
+		if err != nil {
+			return err
+		}
+
+		if detail.ArticleIds, err = dao.repoint(tx, &Article{}, "author_id", mergedId, survivorId, now); err != nil {
+			return err
+		}
+		if detail.SeriesIds, err = dao.repoint(tx, &Series{}, "author_id", mergedId, survivorId, now); err != nil {
+			return err
+		}
+		if detail.NotificationIds, err = dao.repoint(tx, &Notification{}, "uid", mergedId, survivorId, now); err != nil {
+			return err
+		}
+		if detail.ReportIds, err = dao.repointReports(tx, mergedId, survivorId, now); err != nil {
+			return err
+		}
+		if detail.LoginLogIds, err = dao.repointLoginLogs(tx, mergedId, survivorId); err != nil {
+			return err
+		}
+		if detail.RoleIds, err = dao.repointRoles(tx, mergedId, survivorId); err != nil {
+			return err
+		}
+
+		log = UserMergeLog{
+			SurvivorId: survivorId,
+			MergedId:   mergedId,
+			Detail:     sqlx.JsonColumn[UserMergeDetail]{Val: detail, Valid: true},
+			Ctime:      now,
+		}
+		return tx.Create(&log).Error
+	})
+	return log, err
+}
+
+// repoint 把 column 等于 from 的行改成 to，返回改过的 id，恢复合并的时候用
+func (dao *GormUserMergeDao) repoint(tx *gorm.DB, model any, column string, from, to, now int64) ([]int64, error) {
+	var ids []int64
+	err := tx.Model(model).Where(column+"=?", from).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+	err = tx.Model(model).Where("id IN ?", ids).Updates(map[string]any{
+		column:  to,
+		"utime": now,
+	}).Error
+	return ids, err
+}
+
+// repointReports 两个账号举报过同一个对象的时候违反唯一索引，这些举报留在被合并的账号下面
+func (dao *GormUserMergeDao) repointReports(tx *gorm.DB, from, to, now int64) ([]int64, error) {
+	var ids []int64
+	err := tx.Model(&Report{}).
+		Where("reporter_id=?", from).
+		Where("NOT EXISTS (SELECT 1 FROM reports r WHERE r.reporter_id=? AND r.biz=reports.biz AND r.biz_id=reports.biz_id)", to).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+	err = tx.Model(&Report{}).Where("id IN ?", ids).Updates(map[string]any{
+		"reporter_id": to,
+		"utime":       now,
+	}).Error
+	return ids, err
+}
+
+// repointLoginLogs 登录记录没有 utime，只改 uid
+func (dao *GormUserMergeDao) repointLoginLogs(tx *gorm.DB, from, to int64) ([]int64, error) {
+	var ids []int64
+	err := tx.Model(&LoginLog{}).Where("uid=?", from).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+	err = tx.Model(&LoginLog{}).Where("id IN ?", ids).Update("uid", to).Error
+	return ids, err
+}
+
+// repointRoles 保留账号已经有的角色违反唯一索引，这些角色留在被合并的账号下面，保留账号不会少任何角色
+func (dao *GormUserMergeDao) repointRoles(tx *gorm.DB, from, to int64) ([]int64, error) {
+	var ids []int64
+	err := tx.Model(&UserRole{}).
+		Where("uid=?", from).
+		Where("NOT EXISTS (SELECT 1 FROM user_roles r WHERE r.uid=? AND r.role=user_roles.role)", to).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+	err = tx.Model(&UserRole{}).Where("id IN ?", ids).Update("uid", to).Error
+	return ids, err
+}
+
+func (dao *GormUserMergeDao) ListLogs(ctx context.Context, uid int64) ([]UserMergeLog, error) {
+	var logs []UserMergeLog
+	err := dao.db.WithContext(ctx).
+		Where("survivor_id=? OR merged_id=?", uid, uid).
+		Order("id DESC").
+		Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestGormUserMergeDao_Merge(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "已经被合并过",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id IN .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "merged_into"}).
+						AddRow(1, 0).AddRow(2, 3))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserMerged,
+		},
+		{
+			name: "保留账号被封禁",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id IN .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "merged_into", "ban_status", "ban_until"}).
+						AddRow(1, 0, 2, 0).AddRow(2, 0, 0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserMergeBanned,
+		},
+		{
+			name: "被合并账号被限制",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id IN .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "merged_into", "ban_status", "ban_until"}).
+						AddRow(1, 0, 0, 0).AddRow(2, 0, 1, time.Now().Add(time.Hour).UnixMilli()))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserMergeBanned,
+		},
+		{
+			name: "保留账号的邮箱没有验证",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id IN .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "merged_into", "email_unverified"}).
+						AddRow(1, 0, true).AddRow(2, 0, false))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserMergeUnverified,
+		},
+		{
+			name: "被合并账号申请了注销",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id IN .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "merged_into", "delete_at"}).
+						AddRow(1, 0, 0).AddRow(2, 0, time.Now().Add(time.Hour).UnixMilli()))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserMergeDeleting,
+		},
+		{
+			name: "只有被合并账号开启了两步验证",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id IN .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "merged_into"}).
+						AddRow(1, 0).AddRow(2, 0))
+				mock.ExpectQuery("SELECT `uid` FROM `user_mfas` WHERE uid IN").
+					WillReturnRows(sqlmock.NewRows([]string{"uid"}).AddRow(2))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserMergeMFA,
+		},
+		{
+			name: "转移用户名、登录记录和角色",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id IN .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "merged_into", "handle", "handle_key", "handle_utime"}).
+						AddRow(1, 0, "", nil, 0).AddRow(2, 0, "Tom", "tom", 123))
+				mock.ExpectQuery("SELECT `uid` FROM `user_mfas` WHERE uid IN").
+					WillReturnRows(sqlmock.NewRows([]string{"uid"}))
+				// 先清空墓碑上的用户名
+				mock.ExpectExec("UPDATE `users` SET .*`handle`=\\?,`handle_key`=\\?.* WHERE id=\\?").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `users` SET `handle`=\\?,`handle_key`=\\?,`handle_utime`=\\?,`utime`=\\? WHERE id=\\?").
+					WithArgs("Tom", "tom", 123, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				for _, table := range []string{"articles", "series", "notifications", "reports"} {
+					mock.ExpectQuery("SELECT `id` FROM `" + table + "`").
+						WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				}
+				mock.ExpectQuery("SELECT `id` FROM `login_logs` WHERE uid=\\?").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5).AddRow(6))
+				mock.ExpectExec("UPDATE `login_logs` SET `uid`=\\? WHERE id IN \\(\\?,\\?\\)").
+					WithArgs(1, 5, 6).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				// 保留账号已经有的角色不转移
+				mock.ExpectQuery("SELECT `id` FROM `user_roles` WHERE uid=\\? AND \\(NOT EXISTS").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+				mock.ExpectExec("UPDATE `user_roles` SET `uid`=\\? WHERE id IN \\(\\?\\)").
+					WithArgs(1, 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `user_merge_logs`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "账号不存在",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT \\* FROM `users` WHERE id IN .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "merged_into"}).AddRow(1, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.mock(mock)
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      mockDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing: true,
+			})
+			require.NoError(t, err)
+			_, err = NewGormUserMergeDao(db).Merge(context.Background(), 1, 2)
+			assert.Equal(t, tc.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
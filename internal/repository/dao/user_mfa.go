@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUserMFANotFound = gorm.ErrRecordNotFound
+	// ErrUserMFAEnabled 已经开启了两步验证，不能重新绑定
+	ErrUserMFAEnabled = errors.New("已经开启了两步验证")
+)
+
+type UserMFADao interface {
+	FindByUid(ctx context.Context, uid int64) (UserMFA, error)
+	// SaveSecret 开始绑定，没有开启之前可以反复绑定，每次覆盖密钥
+	SaveSecret(ctx context.Context, uid int64, secret string) error
+	// Enable 开启两步验证，同时替换掉所有恢复码，step 是确认时用的时间步
+	Enable(ctx context.Context, uid int64, step int64, codeHashes []string) error
+	// UseStep 时间步比上一次用过的大才会成功，防止同一个验证码被重复使用
+	UseStep(ctx context.Context, uid int64, step int64) (bool, error)
+	// UseRecoveryCode 恢复码只能用一次
+	UseRecoveryCode(ctx context.Context, uid int64, codeHash string) (bool, error)
+	// Delete 关闭两步验证，删除密钥和恢复码
+	Delete(ctx context.Context, uid int64) error
+}
+
+type GormUserMFADao struct {
+	db *gorm.DB
+}
+
+func NewGormUserMFADao(db *gorm.DB) UserMFADao {
+	return &GormUserMFADao{
+		db: db,
+	}
+}
+
+// UserMFA 每个用户最多一条
+type UserMFA struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Uid      int64  `gorm:"uniqueIndex"`
+	Secret   string `gorm:"type:varchar(64)"`
+	Enabled  bool
+	LastStep int64
+	Ctime    int64
+	Utime    int64
+}
+
+// UserMFARecoveryCode 恢复码只保存 SHA256，恢复码本身是足够长的随机字符串，不需要加盐
+type UserMFARecoveryCode struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"index"`
+	Hash  string `gorm:"type:char(64)"`
+	Used  bool
+	Ctime int64
+	Utime int64
+}
+
+func (dao *GormUserMFADao) FindByUid(ctx context.Context, uid int64) (UserMFA, error) {
+	var res UserMFA
+	err := dao.db.WithContext(ctx).Where("uid=?", uid).First(&res).Error
+	return res, err
+}
+
+func (dao *GormUserMFADao) SaveSecret(ctx context.Context, uid int64, secret string) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var m UserMFA
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uid=?", uid).First(&m).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(&UserMFA{
+				Uid:    uid,
+				Secret: secret,
+				Ctime:  now,
+				Utime:  now,
+			}).Error
+		case err != nil:
+			return err
+		case m.Enabled:
+			return ErrUserMFAEnabled
+		}
+		return tx.Model(&UserMFA{}).Where("id=?", m.Id).Updates(map[string]any{
+			"secret":    secret,
+			"last_step": 0,
+			"utime":     now,
+		}).Error
+	})
+}
+
+func (dao *GormUserMFADao) Enable(ctx context.Context, uid int64, step int64, codeHashes []string) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserMFA{}).Where("uid=? AND enabled=?", uid, false).Updates(map[string]any{
+			"enabled":   true,
+			"last_step": step,
+			"utime":     now,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrUserMFAEnabled
+		}
+		if err := tx.Where("uid=?", uid).Delete(&UserMFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]UserMFARecoveryCode, 0, len(codeHashes))
+		for _, h := range codeHashes {
+			codes = append(codes, UserMFARecoveryCode{
+				Uid:   uid,
+				Hash:  h,
+				Ctime: now,
+				Utime: now,
+			})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (dao *GormUserMFADao) UseStep(ctx context.Context, uid int64, step int64) (bool, error) {
+	res := dao.db.WithContext(ctx).Model(&UserMFA{}).
+		Where("uid=? AND enabled=? AND last_step<?", uid, true, step).
+		Updates(map[string]any{
+			"last_step": step,
+			"utime":     time.Now().UnixMilli(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (dao *GormUserMFADao) UseRecoveryCode(ctx context.Context, uid int64, codeHash string) (bool, error) {
+	res := dao.db.WithContext(ctx).Model(&UserMFARecoveryCode{}).
+		Where("uid=? AND hash=? AND used=?", uid, codeHash, false).
+		Updates(map[string]any{
+			"used":  true,
+			"utime": time.Now().UnixMilli(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (dao *GormUserMFADao) Delete(ctx context.Context, uid int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("uid=?", uid).Delete(&UserMFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("uid=?", uid).Delete(&UserMFA{}).Error
+	})
+}
@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type UserRoleDao interface {
+	// FindRoles 用户的所有角色，普通用户返回空切片
+	FindRoles(ctx context.Context, uid int64) ([]string, error)
+}
+
+type GormUserRoleDao struct {
+	db *gorm.DB
+}
+
+func NewGormUserRoleDao(db *gorm.DB) UserRoleDao {
+	return &GormUserRoleDao{
+		db: db,
+	}
+}
+
+// UserRole 用户的角色，一个角色一行
+// 目前没有分配角色的接口，第一个管理员直接在数据库里面插入
+type UserRole struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:uid_role"`
+	Role  string `gorm:"type:varchar(32);uniqueIndex:uid_role"`
+	Ctime int64
+}
+
+func (dao *GormUserRoleDao) FindRoles(ctx context.Context, uid int64) ([]string, error) {
+	var res []string
+	err := dao.db.WithContext(ctx).Model(&UserRole{}).
+		Where("uid=?", uid).
+		Pluck("role", &res).Error
+	return res, err
+}
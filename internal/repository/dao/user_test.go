@@ -11,6 +11,7 @@ import (
 	"go.uber.org/mock/gomock"
 	gormMysql "gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"regexp"
 	"testing"
 )
 
@@ -101,3 +102,177 @@ func TestGORMUserDao_Insert(t *testing.T) {
 		})
 	}
 }
+
+func TestGORMUserDao_Unbind(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		method  string
+		wantErr error
+	}{
+		{
+			name: "解绑成功",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `phone`=?,`utime`=? WHERE id=? AND " +
+					"((email IS NOT NULL AND password <> '') OR wechat_open_id IS NOT NULL)")).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			method: LoginMethodPhone,
+		},
+		{
+			name: "没有其它登录方式",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec("UPDATE `users` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return mockDB
+			},
+			method:  LoginMethodWechat,
+			wantErr: ErrUserLastLoginMethod,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      tc.mock(t),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			require.NoError(t, err)
+			d := NewUserDao(db)
+			err = d.Unbind(context.Background(), 1, tc.method)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMUserDao_UpdatePhone(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectExec("UPDATE `users` SET .*").WillReturnError(&mysql.MySQLError{
+		Number: 1062,
+	})
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      mockDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	err = NewUserDao(db).UpdatePhone(context.Background(), 1, "15212345678")
+	assert.Equal(t, ErrUserPhoneDuplicate, err)
+}
+
+func TestGORMUserDao_UpdateHandle(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(mock sqlmock.Sqlmock)
+
+		wantErr error
+	}{
+		{
+			name: "修改成功",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE `users` SET .* WHERE id=\\? AND handle_utime<\\?").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "用户名冲突",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE `users` SET .*").WillReturnError(&mysql.MySQLError{
+					Number: 1062,
+				})
+			},
+			wantErr: ErrUserHandleDuplicate,
+		},
+		{
+			name: "冷却时间内没有更新",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE `users` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrUserHandleCooldown,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.mock(mock)
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      mockDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			require.NoError(t, err)
+			err = NewUserDao(db).UpdateHandle(context.Background(), 1, "Alice", "alice", 100)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMUserDao_DeleteUnverified(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(mock sqlmock.Sqlmock)
+
+		wantIds []int64
+		wantErr error
+	}{
+		{
+			name: "没有要删除的用户",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `users` WHERE .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectCommit()
+			},
+			wantIds: []int64{},
+		},
+		{
+			name: "删除关联数据失败整个回滚",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `users` WHERE .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectExec("DELETE FROM `user_merge_logs`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM `notification_actors` WHERE notification_id IN \\(SELECT `id` FROM `notifications`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM `notifications`").
+					WillReturnError(errors.New("mock db 错误"))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("mock db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.mock(mock)
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      mockDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing: true,
+			})
+			require.NoError(t, err)
+			ids, err := NewUserDao(db).DeleteUnverified(context.Background(), 100, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIds, ids)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
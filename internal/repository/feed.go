@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"xiaoweishu/internal/repository/cache"
+)
+
+type FeedRepository interface {
+	GetAuthorFeed(ctx context.Context, format string, authorId int64) ([]byte, error)
+	SetAuthorFeed(ctx context.Context, format string, authorId int64, data []byte) error
+	GetHotFeed(ctx context.Context, format string) ([]byte, error)
+	SetHotFeed(ctx context.Context, format string, data []byte) error
+}
+
+type CachedFeedRepository struct {
+	cache cache.FeedCache
+}
+
+func NewFeedRepository(c cache.FeedCache) FeedRepository {
+	return &CachedFeedRepository{
+		cache: c,
+	}
+}
+
+func (r *CachedFeedRepository) GetAuthorFeed(ctx context.Context, format string, authorId int64) ([]byte, error) {
+	return r.cache.GetAuthor(ctx, format, authorId)
+}
+
+func (r *CachedFeedRepository) SetAuthorFeed(ctx context.Context, format string, authorId int64, data []byte) error {
+	return r.cache.SetAuthor(ctx, format, authorId, data)
+}
+
+func (r *CachedFeedRepository) GetHotFeed(ctx context.Context, format string) ([]byte, error) {
+	return r.cache.GetHot(ctx, format)
+}
+
+func (r *CachedFeedRepository) SetHotFeed(ctx context.Context, format string, data []byte) error {
+	return r.cache.SetHot(ctx, format, data)
+}
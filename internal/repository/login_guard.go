@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+)
+
+type LoginGuardRepository interface {
+	Get(ctx context.Context, account, ip string) (domain.LoginGuard, error)
+	IncrFailure(ctx context.Context, account, ip string, now time.Time) (int64, error)
+	Lock(ctx context.Context, account string, d time.Duration) error
+	Reset(ctx context.Context, account string) error
+}
+
+type loginGuardRepository struct {
+	cache cache.LoginGuardCache
+}
+
+func NewLoginGuardRepository(c cache.LoginGuardCache) LoginGuardRepository {
+	return &loginGuardRepository{
+		cache: c,
+	}
+}
+
+func (r *loginGuardRepository) Get(ctx context.Context, account, ip string) (domain.LoginGuard, error) {
+	return r.cache.Get(ctx, account, ip)
+}
+
+func (r *loginGuardRepository) IncrFailure(ctx context.Context, account, ip string, now time.Time) (int64, error) {
+	return r.cache.IncrFailure(ctx, account, ip, now)
+}
+
+func (r *loginGuardRepository) Lock(ctx context.Context, account string, d time.Duration) error {
+	return r.cache.Lock(ctx, account, d)
+}
+
+func (r *loginGuardRepository) Reset(ctx context.Context, account string) error {
+	return r.cache.Reset(ctx, account)
+}
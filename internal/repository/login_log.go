@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+	"xiaoweishu/internal/repository/dao"
+)
+
+// ErrLoginChallengeNotFound 二次验证不存在或者已经过期
+var ErrLoginChallengeNotFound = cache.ErrKeyNotFound
+
+type LoginLogRepository interface {
+	Create(ctx context.Context, l domain.LoginLog) error
+	ListByUid(ctx context.Context, uid int64, offset, limit int) ([]domain.LoginLog, error)
+	CountFailedSinceSuccess(ctx context.Context, uid int64, since time.Time) (int64, error)
+	// Familiarity 以前成功的登录里面，同一个设备、同一个网段的次数
+	Familiarity(ctx context.Context, l domain.LoginLog) (domain.LoginFamiliarity, error)
+	Purge(ctx context.Context, before time.Time, limit int) (int64, error)
+	// 可疑登录的二次验证只放在缓存里面
+	SaveChallenge(ctx context.Context, c domain.LoginChallenge) error
+	FindChallenge(ctx context.Context, token string) (domain.LoginChallenge, error)
+	DeleteChallenge(ctx context.Context, token string) error
+}
+
+type loginLogRepository struct {
+	dao   dao.LoginLogDao
+	cache cache.LoginChallengeCache
+}
+
+func NewLoginLogRepository(d dao.LoginLogDao, c cache.LoginChallengeCache) LoginLogRepository {
+	return &loginLogRepository{
+		dao:   d,
+		cache: c,
+	}
+}
+
+func (r *loginLogRepository) Create(ctx context.Context, l domain.LoginLog) error {
+	return r.dao.Insert(ctx, dao.LoginLog{
+		Uid:       l.Uid,
+		Account:   l.Account,
+		Method:    l.Method,
+		Status:    l.Status.ToUint8(),
+		Reason:    l.Reason,
+		IP:        l.IP,
+		IPRange:   l.IPRange(),
+		UserAgent: l.UserAgent,
+		Ctime:     l.Ctime.UnixMilli(),
+	})
+}
+
+func (r *loginLogRepository) ListByUid(ctx context.Context, uid int64, offset, limit int) ([]domain.LoginLog, error) {
+	logs, err := r.dao.ListByUid(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.LoginLog, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, domain.LoginLog{
+			Id:        l.Id,
+			Uid:       l.Uid,
+			Account:   l.Account,
+			Method:    l.Method,
+			Status:    domain.LoginStatus(l.Status),
+			Reason:    l.Reason,
+			IP:        l.IP,
+			UserAgent: l.UserAgent,
+			Ctime:     time.UnixMilli(l.Ctime),
+		})
+	}
+	return res, nil
+}
+
+func (r *loginLogRepository) CountFailedSinceSuccess(ctx context.Context, uid int64, since time.Time) (int64, error) {
+	return r.dao.CountFailedSinceSuccess(ctx, uid, since.UnixMilli())
+}
+
+func (r *loginLogRepository) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return r.dao.Purge(ctx, before.UnixMilli(), limit)
+}
+
+func (r *loginLogRepository) Familiarity(ctx context.Context, l domain.LoginLog) (domain.LoginFamiliarity, error) {
+	f, err := r.dao.Familiarity(ctx, l.Uid, l.UserAgent, l.IPRange())
+	return domain.LoginFamiliarity{
+		Total:      f.Total,
+		SameDevice: f.SameDevice,
+		SameRange:  f.SameRange,
+	}, err
+}
+
+func (r *loginLogRepository) SaveChallenge(ctx context.Context, c domain.LoginChallenge) error {
+	return r.cache.Set(ctx, c)
+}
+
+func (r *loginLogRepository) FindChallenge(ctx context.Context, token string) (domain.LoginChallenge, error) {
+	return r.cache.Get(ctx, token)
+}
+
+func (r *loginLogRepository) DeleteChallenge(ctx context.Context, token string) error {
+	return r.cache.Delete(ctx, token)
+}
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/admin_audit_log.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/admin_audit_log.go -package=repomocks -destination=./internal/repository/mocks/admin_audit_log.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAdminAuditLogRepository is a mock of AdminAuditLogRepository interface.
+type MockAdminAuditLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminAuditLogRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAdminAuditLogRepositoryMockRecorder is the mock recorder for MockAdminAuditLogRepository.
+type MockAdminAuditLogRepositoryMockRecorder struct {
+	mock *MockAdminAuditLogRepository
+}
+
+// NewMockAdminAuditLogRepository creates a new mock instance.
+func NewMockAdminAuditLogRepository(ctrl *gomock.Controller) *MockAdminAuditLogRepository {
+	mock := &MockAdminAuditLogRepository{ctrl: ctrl}
+	mock.recorder = &MockAdminAuditLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminAuditLogRepository) EXPECT() *MockAdminAuditLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAdminAuditLogRepository) Create(ctx context.Context, l domain.AdminAuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAdminAuditLogRepositoryMockRecorder) Create(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAdminAuditLogRepository)(nil).Create), ctx, l)
+}
+
+// List mocks base method.
+func (m *MockAdminAuditLogRepository) List(ctx context.Context, operatorId int64, offset, limit int) ([]domain.AdminAuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, operatorId, offset, limit)
+	ret0, _ := ret[0].([]domain.AdminAuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAdminAuditLogRepositoryMockRecorder) List(ctx, operatorId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAdminAuditLogRepository)(nil).List), ctx, operatorId, offset, limit)
+}
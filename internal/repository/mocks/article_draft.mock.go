@@ -0,0 +1,143 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/article_draft.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/article_draft.go -package=repomocks -destination=./internal/repository/mocks/article_draft.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleDraftRepository is a mock of ArticleDraftRepository interface.
+type MockArticleDraftRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleDraftRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockArticleDraftRepositoryMockRecorder is the mock recorder for MockArticleDraftRepository.
+type MockArticleDraftRepositoryMockRecorder struct {
+	mock *MockArticleDraftRepository
+}
+
+// NewMockArticleDraftRepository creates a new mock instance.
+func NewMockArticleDraftRepository(ctrl *gomock.Controller) *MockArticleDraftRepository {
+	mock := &MockArticleDraftRepository{ctrl: ctrl}
+	mock.recorder = &MockArticleDraftRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleDraftRepository) EXPECT() *MockArticleDraftRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClearDirty mocks base method.
+func (m *MockArticleDraftRepository) ClearDirty(ctx context.Context, art domain.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearDirty", ctx, art)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearDirty indicates an expected call of ClearDirty.
+func (mr *MockArticleDraftRepositoryMockRecorder) ClearDirty(ctx, art any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearDirty", reflect.TypeOf((*MockArticleDraftRepository)(nil).ClearDirty), ctx, art)
+}
+
+// Delete mocks base method.
+func (m *MockArticleDraftRepository) Delete(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockArticleDraftRepositoryMockRecorder) Delete(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockArticleDraftRepository)(nil).Delete), ctx, uid, id)
+}
+
+// DropDirty mocks base method.
+func (m *MockArticleDraftRepository) DropDirty(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropDirty", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropDirty indicates an expected call of DropDirty.
+func (mr *MockArticleDraftRepositoryMockRecorder) DropDirty(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropDirty", reflect.TypeOf((*MockArticleDraftRepository)(nil).DropDirty), ctx, uid, id)
+}
+
+// Find mocks base method.
+func (m *MockArticleDraftRepository) Find(ctx context.Context, uid, id int64) (domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, uid, id)
+	ret0, _ := ret[0].(domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockArticleDraftRepositoryMockRecorder) Find(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockArticleDraftRepository)(nil).Find), ctx, uid, id)
+}
+
+// IncrFlushFailure mocks base method.
+func (m *MockArticleDraftRepository) IncrFlushFailure(ctx context.Context, uid, id int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrFlushFailure", ctx, uid, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrFlushFailure indicates an expected call of IncrFlushFailure.
+func (mr *MockArticleDraftRepositoryMockRecorder) IncrFlushFailure(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrFlushFailure", reflect.TypeOf((*MockArticleDraftRepository)(nil).IncrFlushFailure), ctx, uid, id)
+}
+
+// ListDirty mocks base method.
+func (m *MockArticleDraftRepository) ListDirty(ctx context.Context, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDirty", ctx, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDirty indicates an expected call of ListDirty.
+func (mr *MockArticleDraftRepositoryMockRecorder) ListDirty(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDirty", reflect.TypeOf((*MockArticleDraftRepository)(nil).ListDirty), ctx, limit)
+}
+
+// Save mocks base method.
+func (m *MockArticleDraftRepository) Save(ctx context.Context, art domain.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, art)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockArticleDraftRepositoryMockRecorder) Save(ctx, art any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockArticleDraftRepository)(nil).Save), ctx, art)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/captcha.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/captcha.go -package=repomocks -destination=./internal/repository/mocks/captcha.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCaptchaRepository is a mock of CaptchaRepository interface.
+type MockCaptchaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCaptchaRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCaptchaRepositoryMockRecorder is the mock recorder for MockCaptchaRepository.
+type MockCaptchaRepositoryMockRecorder struct {
+	mock *MockCaptchaRepository
+}
+
+// NewMockCaptchaRepository creates a new mock instance.
+func NewMockCaptchaRepository(ctrl *gomock.Controller) *MockCaptchaRepository {
+	mock := &MockCaptchaRepository{ctrl: ctrl}
+	mock.recorder = &MockCaptchaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCaptchaRepository) EXPECT() *MockCaptchaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method.
+func (m *MockCaptchaRepository) Store(ctx context.Context, id, answer string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, id, answer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockCaptchaRepositoryMockRecorder) Store(ctx, id, answer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockCaptchaRepository)(nil).Store), ctx, id, answer)
+}
+
+// Verify mocks base method.
+func (m *MockCaptchaRepository) Verify(ctx context.Context, id, answer string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, id, answer)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockCaptchaRepositoryMockRecorder) Verify(ctx, id, answer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCaptchaRepository)(nil).Verify), ctx, id, answer)
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/feed.go -package=repomocks -destination=./internal/repository/mocks/feed.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedRepository is a mock of FeedRepository interface.
+type MockFeedRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockFeedRepositoryMockRecorder is the mock recorder for MockFeedRepository.
+type MockFeedRepositoryMockRecorder struct {
+	mock *MockFeedRepository
+}
+
+// NewMockFeedRepository creates a new mock instance.
+func NewMockFeedRepository(ctrl *gomock.Controller) *MockFeedRepository {
+	mock := &MockFeedRepository{ctrl: ctrl}
+	mock.recorder = &MockFeedRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedRepository) EXPECT() *MockFeedRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAuthorFeed mocks base method.
+func (m *MockFeedRepository) GetAuthorFeed(ctx context.Context, format string, authorId int64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthorFeed", ctx, format, authorId)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthorFeed indicates an expected call of GetAuthorFeed.
+func (mr *MockFeedRepositoryMockRecorder) GetAuthorFeed(ctx, format, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorFeed", reflect.TypeOf((*MockFeedRepository)(nil).GetAuthorFeed), ctx, format, authorId)
+}
+
+// GetHotFeed mocks base method.
+func (m *MockFeedRepository) GetHotFeed(ctx context.Context, format string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotFeed", ctx, format)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotFeed indicates an expected call of GetHotFeed.
+func (mr *MockFeedRepositoryMockRecorder) GetHotFeed(ctx, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotFeed", reflect.TypeOf((*MockFeedRepository)(nil).GetHotFeed), ctx, format)
+}
+
+// SetAuthorFeed mocks base method.
+func (m *MockFeedRepository) SetAuthorFeed(ctx context.Context, format string, authorId int64, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAuthorFeed", ctx, format, authorId, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAuthorFeed indicates an expected call of SetAuthorFeed.
+func (mr *MockFeedRepositoryMockRecorder) SetAuthorFeed(ctx, format, authorId, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAuthorFeed", reflect.TypeOf((*MockFeedRepository)(nil).SetAuthorFeed), ctx, format, authorId, data)
+}
+
+// SetHotFeed mocks base method.
+func (m *MockFeedRepository) SetHotFeed(ctx context.Context, format string, data []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHotFeed", ctx, format, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHotFeed indicates an expected call of SetHotFeed.
+func (mr *MockFeedRepositoryMockRecorder) SetHotFeed(ctx, format, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHotFeed", reflect.TypeOf((*MockFeedRepository)(nil).SetHotFeed), ctx, format, data)
+}
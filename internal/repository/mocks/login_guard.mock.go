@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/login_guard.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/login_guard.go -package=repomocks -destination=./internal/repository/mocks/login_guard.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginGuardRepository is a mock of LoginGuardRepository interface.
+type MockLoginGuardRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginGuardRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLoginGuardRepositoryMockRecorder is the mock recorder for MockLoginGuardRepository.
+type MockLoginGuardRepositoryMockRecorder struct {
+	mock *MockLoginGuardRepository
+}
+
+// NewMockLoginGuardRepository creates a new mock instance.
+func NewMockLoginGuardRepository(ctrl *gomock.Controller) *MockLoginGuardRepository {
+	mock := &MockLoginGuardRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginGuardRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginGuardRepository) EXPECT() *MockLoginGuardRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockLoginGuardRepository) Get(ctx context.Context, account, ip string) (domain.LoginGuard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, account, ip)
+	ret0, _ := ret[0].(domain.LoginGuard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockLoginGuardRepositoryMockRecorder) Get(ctx, account, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLoginGuardRepository)(nil).Get), ctx, account, ip)
+}
+
+// IncrFailure mocks base method.
+func (m *MockLoginGuardRepository) IncrFailure(ctx context.Context, account, ip string, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrFailure", ctx, account, ip, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrFailure indicates an expected call of IncrFailure.
+func (mr *MockLoginGuardRepositoryMockRecorder) IncrFailure(ctx, account, ip, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrFailure", reflect.TypeOf((*MockLoginGuardRepository)(nil).IncrFailure), ctx, account, ip, now)
+}
+
+// Lock mocks base method.
+func (m *MockLoginGuardRepository) Lock(ctx context.Context, account string, d time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, account, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLoginGuardRepositoryMockRecorder) Lock(ctx, account, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLoginGuardRepository)(nil).Lock), ctx, account, d)
+}
+
+// Reset mocks base method.
+func (m *MockLoginGuardRepository) Reset(ctx context.Context, account string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginGuardRepositoryMockRecorder) Reset(ctx, account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginGuardRepository)(nil).Reset), ctx, account)
+}
@@ -0,0 +1,160 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/login_log.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/login_log.go -package=repomocks -destination=./internal/repository/mocks/login_log.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginLogRepository is a mock of LoginLogRepository interface.
+type MockLoginLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginLogRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLoginLogRepositoryMockRecorder is the mock recorder for MockLoginLogRepository.
+type MockLoginLogRepositoryMockRecorder struct {
+	mock *MockLoginLogRepository
+}
+
+// NewMockLoginLogRepository creates a new mock instance.
+func NewMockLoginLogRepository(ctrl *gomock.Controller) *MockLoginLogRepository {
+	mock := &MockLoginLogRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginLogRepository) EXPECT() *MockLoginLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountFailedSinceSuccess mocks base method.
+func (m *MockLoginLogRepository) CountFailedSinceSuccess(ctx context.Context, uid int64, since time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFailedSinceSuccess", ctx, uid, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFailedSinceSuccess indicates an expected call of CountFailedSinceSuccess.
+func (mr *MockLoginLogRepositoryMockRecorder) CountFailedSinceSuccess(ctx, uid, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFailedSinceSuccess", reflect.TypeOf((*MockLoginLogRepository)(nil).CountFailedSinceSuccess), ctx, uid, since)
+}
+
+// Create mocks base method.
+func (m *MockLoginLogRepository) Create(ctx context.Context, l domain.LoginLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockLoginLogRepositoryMockRecorder) Create(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLoginLogRepository)(nil).Create), ctx, l)
+}
+
+// DeleteChallenge mocks base method.
+func (m *MockLoginLogRepository) DeleteChallenge(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChallenge", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChallenge indicates an expected call of DeleteChallenge.
+func (mr *MockLoginLogRepositoryMockRecorder) DeleteChallenge(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChallenge", reflect.TypeOf((*MockLoginLogRepository)(nil).DeleteChallenge), ctx, token)
+}
+
+// Familiarity mocks base method.
+func (m *MockLoginLogRepository) Familiarity(ctx context.Context, l domain.LoginLog) (domain.LoginFamiliarity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Familiarity", ctx, l)
+	ret0, _ := ret[0].(domain.LoginFamiliarity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Familiarity indicates an expected call of Familiarity.
+func (mr *MockLoginLogRepositoryMockRecorder) Familiarity(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Familiarity", reflect.TypeOf((*MockLoginLogRepository)(nil).Familiarity), ctx, l)
+}
+
+// FindChallenge mocks base method.
+func (m *MockLoginLogRepository) FindChallenge(ctx context.Context, token string) (domain.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChallenge", ctx, token)
+	ret0, _ := ret[0].(domain.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChallenge indicates an expected call of FindChallenge.
+func (mr *MockLoginLogRepositoryMockRecorder) FindChallenge(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChallenge", reflect.TypeOf((*MockLoginLogRepository)(nil).FindChallenge), ctx, token)
+}
+
+// ListByUid mocks base method.
+func (m *MockLoginLogRepository) ListByUid(ctx context.Context, uid int64, offset, limit int) ([]domain.LoginLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUid", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]domain.LoginLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUid indicates an expected call of ListByUid.
+func (mr *MockLoginLogRepositoryMockRecorder) ListByUid(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUid", reflect.TypeOf((*MockLoginLogRepository)(nil).ListByUid), ctx, uid, offset, limit)
+}
+
+// Purge mocks base method.
+func (m *MockLoginLogRepository) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockLoginLogRepositoryMockRecorder) Purge(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockLoginLogRepository)(nil).Purge), ctx, before, limit)
+}
+
+// SaveChallenge mocks base method.
+func (m *MockLoginLogRepository) SaveChallenge(ctx context.Context, c domain.LoginChallenge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveChallenge", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveChallenge indicates an expected call of SaveChallenge.
+func (mr *MockLoginLogRepositoryMockRecorder) SaveChallenge(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveChallenge", reflect.TypeOf((*MockLoginLogRepository)(nil).SaveChallenge), ctx, c)
+}
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/push.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/push.go -package=repomocks -destination=./internal/repository/mocks/push.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPushRepository is a mock of PushRepository interface.
+type MockPushRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPushRepositoryMockRecorder is the mock recorder for MockPushRepository.
+type MockPushRepositoryMockRecorder struct {
+	mock *MockPushRepository
+}
+
+// NewMockPushRepository creates a new mock instance.
+func NewMockPushRepository(ctrl *gomock.Controller) *MockPushRepository {
+	mock := &MockPushRepository{ctrl: ctrl}
+	mock.recorder = &MockPushRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushRepository) EXPECT() *MockPushRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockPushRepository) Publish(ctx context.Context, evt domain.PushEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPushRepositoryMockRecorder) Publish(ctx, evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPushRepository)(nil).Publish), ctx, evt)
+}
+
+// Subscribe mocks base method.
+func (m *MockPushRepository) Subscribe(ctx context.Context) (<-chan domain.PushEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx)
+	ret0, _ := ret[0].(<-chan domain.PushEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockPushRepositoryMockRecorder) Subscribe(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockPushRepository)(nil).Subscribe), ctx)
+}
@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/report.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/report.go -package=repomocks -destination=./internal/repository/mocks/report.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReportRepository is a mock of ReportRepository interface.
+type MockReportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockReportRepositoryMockRecorder is the mock recorder for MockReportRepository.
+type MockReportRepositoryMockRecorder struct {
+	mock *MockReportRepository
+}
+
+// NewMockReportRepository creates a new mock instance.
+func NewMockReportRepository(ctrl *gomock.Controller) *MockReportRepository {
+	mock := &MockReportRepository{ctrl: ctrl}
+	mock.recorder = &MockReportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportRepository) EXPECT() *MockReportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReportRepository) Create(ctx context.Context, r domain.Report) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, r)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReportRepositoryMockRecorder) Create(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReportRepository)(nil).Create), ctx, r)
+}
+
+// FindById mocks base method.
+func (m *MockReportRepository) FindById(ctx context.Context, id int64) (domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockReportRepositoryMockRecorder) FindById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockReportRepository)(nil).FindById), ctx, id)
+}
+
+// ListByReporter mocks base method.
+func (m *MockReportRepository) ListByReporter(ctx context.Context, reporterId int64, offset, limit int) ([]domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByReporter", ctx, reporterId, offset, limit)
+	ret0, _ := ret[0].([]domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByReporter indicates an expected call of ListByReporter.
+func (mr *MockReportRepositoryMockRecorder) ListByReporter(ctx, reporterId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByReporter", reflect.TypeOf((*MockReportRepository)(nil).ListByReporter), ctx, reporterId, offset, limit)
+}
+
+// ListByStatus mocks base method.
+func (m *MockReportRepository) ListByStatus(ctx context.Context, status domain.ReportStatus, offset, limit int) ([]domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByStatus", ctx, status, offset, limit)
+	ret0, _ := ret[0].([]domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatus indicates an expected call of ListByStatus.
+func (mr *MockReportRepositoryMockRecorder) ListByStatus(ctx, status, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatus", reflect.TypeOf((*MockReportRepository)(nil).ListByStatus), ctx, status, offset, limit)
+}
+
+// Transit mocks base method.
+func (m *MockReportRepository) Transit(ctx context.Context, d domain.ReportDecision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transit", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transit indicates an expected call of Transit.
+func (mr *MockReportRepositoryMockRecorder) Transit(ctx, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transit", reflect.TypeOf((*MockReportRepository)(nil).Transit), ctx, d)
+}
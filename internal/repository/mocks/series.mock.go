@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/series.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/series.go -package=repomocks -destination=./internal/repository/mocks/series.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSeriesRepository is a mock of SeriesRepository interface.
+type MockSeriesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSeriesRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSeriesRepositoryMockRecorder is the mock recorder for MockSeriesRepository.
+type MockSeriesRepositoryMockRecorder struct {
+	mock *MockSeriesRepository
+}
+
+// NewMockSeriesRepository creates a new mock instance.
+func NewMockSeriesRepository(ctrl *gomock.Controller) *MockSeriesRepository {
+	mock := &MockSeriesRepository{ctrl: ctrl}
+	mock.recorder = &MockSeriesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSeriesRepository) EXPECT() *MockSeriesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSeriesRepository) Create(ctx context.Context, s domain.Series) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, s)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSeriesRepositoryMockRecorder) Create(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSeriesRepository)(nil).Create), ctx, s)
+}
+
+// FindByArticle mocks base method.
+func (m *MockSeriesRepository) FindByArticle(ctx context.Context, articleId int64) (domain.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByArticle", ctx, articleId)
+	ret0, _ := ret[0].(domain.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByArticle indicates an expected call of FindByArticle.
+func (mr *MockSeriesRepositoryMockRecorder) FindByArticle(ctx, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByArticle", reflect.TypeOf((*MockSeriesRepository)(nil).FindByArticle), ctx, articleId)
+}
+
+// FindById mocks base method.
+func (m *MockSeriesRepository) FindById(ctx context.Context, id int64) (domain.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(domain.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockSeriesRepositoryMockRecorder) FindById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockSeriesRepository)(nil).FindById), ctx, id)
+}
+
+// ListByAuthor mocks base method.
+func (m *MockSeriesRepository) ListByAuthor(ctx context.Context, authorId int64) ([]domain.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByAuthor", ctx, authorId)
+	ret0, _ := ret[0].([]domain.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByAuthor indicates an expected call of ListByAuthor.
+func (mr *MockSeriesRepositoryMockRecorder) ListByAuthor(ctx, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByAuthor", reflect.TypeOf((*MockSeriesRepository)(nil).ListByAuthor), ctx, authorId)
+}
+
+// SetArticleIds mocks base method.
+func (m *MockSeriesRepository) SetArticleIds(ctx context.Context, id, version int64, articleIds []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArticleIds", ctx, id, version, articleIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetArticleIds indicates an expected call of SetArticleIds.
+func (mr *MockSeriesRepositoryMockRecorder) SetArticleIds(ctx, id, version, articleIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArticleIds", reflect.TypeOf((*MockSeriesRepository)(nil).SetArticleIds), ctx, id, version, articleIds)
+}
+
+// UpdateTitle mocks base method.
+func (m *MockSeriesRepository) UpdateTitle(ctx context.Context, s domain.Series) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTitle", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTitle indicates an expected call of UpdateTitle.
+func (mr *MockSeriesRepositoryMockRecorder) UpdateTitle(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTitle", reflect.TypeOf((*MockSeriesRepository)(nil).UpdateTitle), ctx, s)
+}
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/user_deletion.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/user_deletion.go -package=repomocks -destination=./internal/repository/mocks/user_deletion.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserDeletionRepository is a mock of UserDeletionRepository interface.
+type MockUserDeletionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserDeletionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserDeletionRepositoryMockRecorder is the mock recorder for MockUserDeletionRepository.
+type MockUserDeletionRepositoryMockRecorder struct {
+	mock *MockUserDeletionRepository
+}
+
+// NewMockUserDeletionRepository creates a new mock instance.
+func NewMockUserDeletionRepository(ctrl *gomock.Controller) *MockUserDeletionRepository {
+	mock := &MockUserDeletionRepository{ctrl: ctrl}
+	mock.recorder = &MockUserDeletionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserDeletionRepository) EXPECT() *MockUserDeletionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Anonymize mocks base method.
+func (m *MockUserDeletionRepository) Anonymize(ctx context.Context, id int64, before time.Time, nickname string, deleteArticles bool) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", ctx, id, before, nickname, deleteArticles)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Anonymize indicates an expected call of Anonymize.
+func (mr *MockUserDeletionRepositoryMockRecorder) Anonymize(ctx, id, before, nickname, deleteArticles any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockUserDeletionRepository)(nil).Anonymize), ctx, id, before, nickname, deleteArticles)
+}
+
+// Cancel mocks base method.
+func (m *MockUserDeletionRepository) Cancel(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockUserDeletionRepositoryMockRecorder) Cancel(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockUserDeletionRepository)(nil).Cancel), ctx, id)
+}
+
+// ListDue mocks base method.
+func (m *MockUserDeletionRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDue", ctx, before, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDue indicates an expected call of ListDue.
+func (mr *MockUserDeletionRepositoryMockRecorder) ListDue(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDue", reflect.TypeOf((*MockUserDeletionRepository)(nil).ListDue), ctx, before, limit)
+}
+
+// Schedule mocks base method.
+func (m *MockUserDeletionRepository) Schedule(ctx context.Context, id int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schedule", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Schedule indicates an expected call of Schedule.
+func (mr *MockUserDeletionRepositoryMockRecorder) Schedule(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockUserDeletionRepository)(nil).Schedule), ctx, id, at)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/user_merge.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/user_merge.go -package=repomocks -destination=./internal/repository/mocks/user_merge.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserMergeRepository is a mock of UserMergeRepository interface.
+type MockUserMergeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMergeRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserMergeRepositoryMockRecorder is the mock recorder for MockUserMergeRepository.
+type MockUserMergeRepositoryMockRecorder struct {
+	mock *MockUserMergeRepository
+}
+
+// NewMockUserMergeRepository creates a new mock instance.
+func NewMockUserMergeRepository(ctrl *gomock.Controller) *MockUserMergeRepository {
+	mock := &MockUserMergeRepository{ctrl: ctrl}
+	mock.recorder = &MockUserMergeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserMergeRepository) EXPECT() *MockUserMergeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListLogs mocks base method.
+func (m *MockUserMergeRepository) ListLogs(ctx context.Context, uid int64) ([]domain.UserMergeLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLogs", ctx, uid)
+	ret0, _ := ret[0].([]domain.UserMergeLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLogs indicates an expected call of ListLogs.
+func (mr *MockUserMergeRepositoryMockRecorder) ListLogs(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLogs", reflect.TypeOf((*MockUserMergeRepository)(nil).ListLogs), ctx, uid)
+}
+
+// Merge mocks base method.
+func (m *MockUserMergeRepository) Merge(ctx context.Context, survivorId, mergedId int64) (domain.UserMergeLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", ctx, survivorId, mergedId)
+	ret0, _ := ret[0].(domain.UserMergeLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockUserMergeRepositoryMockRecorder) Merge(ctx, survivorId, mergedId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockUserMergeRepository)(nil).Merge), ctx, survivorId, mergedId)
+}
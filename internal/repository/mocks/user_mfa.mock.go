@@ -0,0 +1,187 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/user_mfa.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/user_mfa.go -package=repomocks -destination=./internal/repository/mocks/user_mfa.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserMFARepository is a mock of UserMFARepository interface.
+type MockUserMFARepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMFARepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserMFARepositoryMockRecorder is the mock recorder for MockUserMFARepository.
+type MockUserMFARepositoryMockRecorder struct {
+	mock *MockUserMFARepository
+}
+
+// NewMockUserMFARepository creates a new mock instance.
+func NewMockUserMFARepository(ctrl *gomock.Controller) *MockUserMFARepository {
+	mock := &MockUserMFARepository{ctrl: ctrl}
+	mock.recorder = &MockUserMFARepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserMFARepository) EXPECT() *MockUserMFARepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockUserMFARepository) Delete(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserMFARepositoryMockRecorder) Delete(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserMFARepository)(nil).Delete), ctx, uid)
+}
+
+// DeletePending mocks base method.
+func (m *MockUserMFARepository) DeletePending(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePending", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePending indicates an expected call of DeletePending.
+func (mr *MockUserMFARepositoryMockRecorder) DeletePending(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePending", reflect.TypeOf((*MockUserMFARepository)(nil).DeletePending), ctx, token)
+}
+
+// Enable mocks base method.
+func (m *MockUserMFARepository) Enable(ctx context.Context, uid, step int64, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, uid, step, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockUserMFARepositoryMockRecorder) Enable(ctx, uid, step, codeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockUserMFARepository)(nil).Enable), ctx, uid, step, codeHashes)
+}
+
+// FindByUid mocks base method.
+func (m *MockUserMFARepository) FindByUid(ctx context.Context, uid int64) (domain.UserMFA, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUid", ctx, uid)
+	ret0, _ := ret[0].(domain.UserMFA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUid indicates an expected call of FindByUid.
+func (mr *MockUserMFARepositoryMockRecorder) FindByUid(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUid", reflect.TypeOf((*MockUserMFARepository)(nil).FindByUid), ctx, uid)
+}
+
+// FindPending mocks base method.
+func (m *MockUserMFARepository) FindPending(ctx context.Context, token string) (domain.MFAPending, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPending", ctx, token)
+	ret0, _ := ret[0].(domain.MFAPending)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPending indicates an expected call of FindPending.
+func (mr *MockUserMFARepositoryMockRecorder) FindPending(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPending", reflect.TypeOf((*MockUserMFARepository)(nil).FindPending), ctx, token)
+}
+
+// IncrPendingAttempts mocks base method.
+func (m *MockUserMFARepository) IncrPendingAttempts(ctx context.Context, token string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrPendingAttempts", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrPendingAttempts indicates an expected call of IncrPendingAttempts.
+func (mr *MockUserMFARepositoryMockRecorder) IncrPendingAttempts(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrPendingAttempts", reflect.TypeOf((*MockUserMFARepository)(nil).IncrPendingAttempts), ctx, token)
+}
+
+// SavePending mocks base method.
+func (m *MockUserMFARepository) SavePending(ctx context.Context, p domain.MFAPending) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePending", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePending indicates an expected call of SavePending.
+func (mr *MockUserMFARepositoryMockRecorder) SavePending(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePending", reflect.TypeOf((*MockUserMFARepository)(nil).SavePending), ctx, p)
+}
+
+// SaveSecret mocks base method.
+func (m *MockUserMFARepository) SaveSecret(ctx context.Context, uid int64, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecret", ctx, uid, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecret indicates an expected call of SaveSecret.
+func (mr *MockUserMFARepositoryMockRecorder) SaveSecret(ctx, uid, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecret", reflect.TypeOf((*MockUserMFARepository)(nil).SaveSecret), ctx, uid, secret)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockUserMFARepository) UseRecoveryCode(ctx context.Context, uid int64, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, uid, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockUserMFARepositoryMockRecorder) UseRecoveryCode(ctx, uid, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockUserMFARepository)(nil).UseRecoveryCode), ctx, uid, codeHash)
+}
+
+// UseStep mocks base method.
+func (m *MockUserMFARepository) UseStep(ctx context.Context, uid, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseStep", ctx, uid, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseStep indicates an expected call of UseStep.
+func (mr *MockUserMFARepositoryMockRecorder) UseStep(ctx, uid, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseStep", reflect.TypeOf((*MockUserMFARepository)(nil).UseStep), ctx, uid, step)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/repository/user_role.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/repository/user_role.go -package=repomocks -destination=./internal/repository/mocks/user_role.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserRoleRepository is a mock of UserRoleRepository interface.
+type MockUserRoleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRoleRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserRoleRepositoryMockRecorder is the mock recorder for MockUserRoleRepository.
+type MockUserRoleRepositoryMockRecorder struct {
+	mock *MockUserRoleRepository
+}
+
+// NewMockUserRoleRepository creates a new mock instance.
+func NewMockUserRoleRepository(ctrl *gomock.Controller) *MockUserRoleRepository {
+	mock := &MockUserRoleRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRoleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRoleRepository) EXPECT() *MockUserRoleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindRoles mocks base method.
+func (m *MockUserRoleRepository) FindRoles(ctx context.Context, uid int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoles", ctx, uid)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoles indicates an expected call of FindRoles.
+func (mr *MockUserRoleRepositoryMockRecorder) FindRoles(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoles", reflect.TypeOf((*MockUserRoleRepository)(nil).FindRoles), ctx, uid)
+}
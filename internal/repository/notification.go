@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+	"xiaoweishu/internal/repository/dao"
+)
+
+type NotificationRepository interface {
+	// Upsert 写入通知，能聚合的会聚合到已有的未读通知上
+	Upsert(ctx context.Context, n domain.Notification) error
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error)
+	UnreadCount(ctx context.Context, uid int64) (int64, error)
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	MarkAllRead(ctx context.Context, uid int64) error
+}
+
+type CachedNotificationRepository struct {
+	dao   dao.NotificationDao
+	cache cache.NotificationCache
+}
+
+func NewNotificationRepository(dao dao.NotificationDao, c cache.NotificationCache) NotificationRepository {
+	return &CachedNotificationRepository{
+		dao:   dao,
+		cache: c,
+	}
+}
+
+func (r *CachedNotificationRepository) Upsert(ctx context.Context, n domain.Notification) error {
+	created, err := r.dao.Upsert(ctx, dao.Notification{
+		Uid:     n.Uid,
+		Biz:     n.Biz,
+		AggKey:  n.AggKey,
+		Actor:   n.Actor,
+		Content: n.Content,
+	})
+	if err != nil || !created {
+		return err
+	}
+	// 聚合到已有的通知上未读数不变，只有新增的时候才需要让缓存失效
+	return r.cache.DeleteUnread(ctx, n.Uid)
+}
+
+func (r *CachedNotificationRepository) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error) {
+	ns, err := r.dao.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Notification, 0, len(ns))
+	for _, n := range ns {
+		res = append(res, r.toDomain(n))
+	}
+	return res, nil
+}
+
+func (r *CachedNotificationRepository) UnreadCount(ctx context.Context, uid int64) (int64, error) {
+	cnt, err := r.cache.GetUnread(ctx, uid)
+	if err == nil {
+		return cnt, nil
+	}
+	cnt, err = r.dao.CountUnread(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	// 缓存失败不影响返回结果
+	_ = r.cache.SetUnread(ctx, uid, cnt)
+	return cnt, nil
+}
+
+func (r *CachedNotificationRepository) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	err := r.dao.MarkRead(ctx, uid, ids)
+	if err != nil {
+		return err
+	}
+	return r.cache.DeleteUnread(ctx, uid)
+}
+
+func (r *CachedNotificationRepository) MarkAllRead(ctx context.Context, uid int64) error {
+	err := r.dao.MarkAllRead(ctx, uid)
+	if err != nil {
+		return err
+	}
+	return r.cache.DeleteUnread(ctx, uid)
+}
+
+func (r *CachedNotificationRepository) toDomain(n dao.Notification) domain.Notification {
+	return domain.Notification{
+		Id:       n.Id,
+		Uid:      n.Uid,
+		Biz:      n.Biz,
+		AggKey:   n.AggKey,
+		Actor:    n.Actor,
+		Content:  n.Content,
+		Count:    n.Cnt,
+		ActorCnt: n.ActorCnt,
+		Read:     n.Read,
+		Ctime:    time.UnixMilli(n.Ctime),
+		Utime:    time.UnixMilli(n.Utime),
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+	cachemocks "xiaoweishu/internal/repository/cache/mocks"
+	"xiaoweishu/internal/repository/dao"
+	daomocks "xiaoweishu/internal/repository/dao/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCachedNotificationRepository_Upsert(t *testing.T) {
+	n := domain.Notification{
+		Uid:     123,
+		Biz:     domain.NotificationBizLogin,
+		AggKey:  domain.NotificationBizLogin,
+		Content: "你的账号有新的登录",
+	}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.NotificationDao, cache.NotificationCache)
+		wantErr error
+	}{
+		{
+			name: "新的通知，未读数缓存失效",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDao, cache.NotificationCache) {
+				d := daomocks.NewMockNotificationDao(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), dao.Notification{
+					Uid:     123,
+					Biz:     domain.NotificationBizLogin,
+					AggKey:  domain.NotificationBizLogin,
+					Content: "你的账号有新的登录",
+				}).Return(true, nil)
+				c := cachemocks.NewMockNotificationCache(ctrl)
+				c.EXPECT().DeleteUnread(gomock.Any(), int64(123)).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "聚合到已有的通知，未读数不变",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDao, cache.NotificationCache) {
+				d := daomocks.NewMockNotificationDao(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(false, nil)
+				return d, cachemocks.NewMockNotificationCache(ctrl)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewNotificationRepository(tc.mock(ctrl))
+			err := repo.Upsert(context.Background(), n)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCachedNotificationRepository_UnreadCount(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.NotificationDao, cache.NotificationCache)
+		wantCnt int64
+		wantErr error
+	}{
+		{
+			name: "命中缓存",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDao, cache.NotificationCache) {
+				c := cachemocks.NewMockNotificationCache(ctrl)
+				c.EXPECT().GetUnread(gomock.Any(), int64(123)).Return(int64(3), nil)
+				return daomocks.NewMockNotificationDao(ctrl), c
+			},
+			wantCnt: 3,
+		},
+		{
+			name: "缓存未命中，查询数据库并回写",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDao, cache.NotificationCache) {
+				c := cachemocks.NewMockNotificationCache(ctrl)
+				c.EXPECT().GetUnread(gomock.Any(), int64(123)).Return(int64(0), cache.ErrKeyNotFound)
+				c.EXPECT().SetUnread(gomock.Any(), int64(123), int64(5)).Return(nil)
+				d := daomocks.NewMockNotificationDao(ctrl)
+				d.EXPECT().CountUnread(gomock.Any(), int64(123)).Return(int64(5), nil)
+				return d, c
+			},
+			wantCnt: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewNotificationRepository(tc.mock(ctrl))
+			cnt, err := repo.UnreadCount(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
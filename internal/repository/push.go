@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+)
+
+type PushRepository interface {
+	Publish(ctx context.Context, evt domain.PushEvent) error
+	Subscribe(ctx context.Context) (<-chan domain.PushEvent, error)
+}
+
+type pushRepository struct {
+	cache cache.PushCache
+}
+
+func NewPushRepository(c cache.PushCache) PushRepository {
+	return &pushRepository{
+		cache: c,
+	}
+}
+
+func (r *pushRepository) Publish(ctx context.Context, evt domain.PushEvent) error {
+	return r.cache.Publish(ctx, evt)
+}
+
+func (r *pushRepository) Subscribe(ctx context.Context) (<-chan domain.PushEvent, error) {
+	return r.cache.Subscribe(ctx)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/dao"
+)
+
+var (
+	ErrReportDuplicate      = dao.ErrReportDuplicate
+	ErrReportNotFound       = dao.ErrReportNotFound
+	ErrReportStatusConflict = dao.ErrReportStatusConflict
+)
+
+type ReportRepository interface {
+	Create(ctx context.Context, r domain.Report) (int64, error)
+	FindById(ctx context.Context, id int64) (domain.Report, error)
+	ListByStatus(ctx context.Context, status domain.ReportStatus, offset, limit int) ([]domain.Report, error)
+	ListByReporter(ctx context.Context, reporterId int64, offset, limit int) ([]domain.Report, error)
+	Transit(ctx context.Context, d domain.ReportDecision) error
+}
+
+type reportRepository struct {
+	dao dao.ReportDao
+}
+
+func NewReportRepository(dao dao.ReportDao) ReportRepository {
+	return &reportRepository{
+		dao: dao,
+	}
+}
+
+func (r *reportRepository) Create(ctx context.Context, rp domain.Report) (int64, error) {
+	return r.dao.Insert(ctx, dao.Report{
+		ReporterId: rp.Reporter,
+		Biz:        rp.Biz,
+		BizId:      rp.BizId,
+		Field:      rp.Field,
+		Reason:     rp.Reason,
+		Status:     rp.Status.ToUint8(),
+	})
+}
+
+func (r *reportRepository) FindById(ctx context.Context, id int64) (domain.Report, error) {
+	rp, err := r.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Report{}, err
+	}
+	return r.toDomain(rp), nil
+}
+
+func (r *reportRepository) ListByStatus(ctx context.Context, status domain.ReportStatus, offset, limit int) ([]domain.Report, error) {
+	rps, err := r.dao.ListByStatus(ctx, status.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Report, 0, len(rps))
+	for _, rp := range rps {
+		res = append(res, r.toDomain(rp))
+	}
+	return res, nil
+}
+
+func (r *reportRepository) ListByReporter(ctx context.Context, reporterId int64, offset, limit int) ([]domain.Report, error) {
+	rps, err := r.dao.ListByReporter(ctx, reporterId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Report, 0, len(rps))
+	for _, rp := range rps {
+		res = append(res, r.toDomain(rp))
+	}
+	return res, nil
+}
+
+func (r *reportRepository) Transit(ctx context.Context, d domain.ReportDecision) error {
+	return r.dao.Transit(ctx, d.ReportId, d.From.ToUint8(), d.To.ToUint8(), dao.ReportDecision{
+		ReviewerId: d.Reviewer,
+		Note:       d.Note,
+	})
+}
+
+func (r *reportRepository) toDomain(rp dao.Report) domain.Report {
+	return domain.Report{
+		Id:       rp.Id,
+		Reporter: rp.ReporterId,
+		Biz:      rp.Biz,
+		BizId:    rp.BizId,
+		Field:    rp.Field,
+		Reason:   rp.Reason,
+		Status:   domain.ReportStatus(rp.Status),
+		Ctime:    time.UnixMilli(rp.Ctime),
+		Utime:    time.UnixMilli(rp.Utime),
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/dao"
+)
+
+var (
+	ErrSeriesNotFound         = dao.ErrSeriesNotFound
+	ErrSeriesArticleDuplicate = dao.ErrSeriesArticleDuplicate
+	ErrSeriesVersionConflict  = dao.ErrSeriesVersionConflict
+)
+
+type SeriesRepository interface {
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	UpdateTitle(ctx context.Context, s domain.Series) error
+	// FindById 返回的专栏带有按照顺序排列的文章 id
+	FindById(ctx context.Context, id int64) (domain.Series, error)
+	ListByAuthor(ctx context.Context, authorId int64) ([]domain.Series, error)
+	// SetArticleIds version 是读取专栏时候的版本号，期间专栏被修改过返回 ErrSeriesVersionConflict
+	SetArticleIds(ctx context.Context, id, version int64, articleIds []int64) error
+	// FindByArticle 查找文章所在的专栏，文章不在任何专栏中返回 ErrSeriesNotFound
+	FindByArticle(ctx context.Context, articleId int64) (domain.Series, error)
+}
+
+type seriesRepository struct {
+	dao dao.SeriesDao
+}
+
+func NewSeriesRepository(dao dao.SeriesDao) SeriesRepository {
+	return &seriesRepository{
+		dao: dao,
+	}
+}
+
+func (r *seriesRepository) Create(ctx context.Context, s domain.Series) (int64, error) {
+	return r.dao.Insert(ctx, dao.Series{
+		AuthorId: s.Author.Id,
+		Title:    s.Title,
+	})
+}
+
+func (r *seriesRepository) UpdateTitle(ctx context.Context, s domain.Series) error {
+	return r.dao.UpdateTitle(ctx, s.Id, s.Author.Id, s.Title)
+}
+
+func (r *seriesRepository) FindById(ctx context.Context, id int64) (domain.Series, error) {
+	s, err := r.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	ids, err := r.dao.ListArticleIds(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	res := r.toDomain(s)
+	res.ArticleIds = ids
+	return res, nil
+}
+
+func (r *seriesRepository) ListByAuthor(ctx context.Context, authorId int64) ([]domain.Series, error) {
+	ss, err := r.dao.ListByAuthor(ctx, authorId)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Series, 0, len(ss))
+	for _, s := range ss {
+		res = append(res, r.toDomain(s))
+	}
+	return res, nil
+}
+
+func (r *seriesRepository) SetArticleIds(ctx context.Context, id, version int64, articleIds []int64) error {
+	return r.dao.SetArticleIds(ctx, id, version, articleIds)
+}
+
+func (r *seriesRepository) FindByArticle(ctx context.Context, articleId int64) (domain.Series, error) {
+	sa, err := r.dao.FindByArticle(ctx, articleId)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	return r.FindById(ctx, sa.SeriesId)
+}
+
+func (r *seriesRepository) toDomain(s dao.Series) domain.Series {
+	return domain.Series{
+		Id:    s.Id,
+		Title: s.Title,
+		Author: domain.Author{
+			Id: s.AuthorId,
+		},
+		Version: s.Version,
+		Ctime:   time.UnixMilli(s.Ctime),
+		Utime:   time.UnixMilli(s.Utime),
+	}
+}
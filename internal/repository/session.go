@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+)
+
+// ErrSessionNotFound 会话不存在，或者已经退出登录
+var ErrSessionNotFound = cache.ErrKeyNotFound
+
+type SessionRepository interface {
+	Create(ctx context.Context, s domain.Session) error
+	Find(ctx context.Context, uid int64, ssid string) (domain.Session, error)
+	List(ctx context.Context, uid int64) ([]domain.Session, error)
+	Touch(ctx context.Context, uid int64, ssid string, t time.Time) error
+	Delete(ctx context.Context, uid int64, ssids ...string) error
+	DeleteAll(ctx context.Context, uid int64) error
+}
+
+type sessionRepository struct {
+	cache cache.SessionCache
+}
+
+func NewSessionRepository(c cache.SessionCache) SessionRepository {
+	return &sessionRepository{
+		cache: c,
+	}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, s domain.Session) error {
+	return r.cache.Set(ctx, s)
+}
+
+func (r *sessionRepository) Find(ctx context.Context, uid int64, ssid string) (domain.Session, error) {
+	return r.cache.Get(ctx, uid, ssid)
+}
+
+func (r *sessionRepository) List(ctx context.Context, uid int64) ([]domain.Session, error) {
+	return r.cache.List(ctx, uid)
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, uid int64, ssid string, t time.Time) error {
+	return r.cache.Touch(ctx, uid, ssid, t)
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, uid int64, ssids ...string) error {
+	return r.cache.Delete(ctx, uid, ssids...)
+}
+
+func (r *sessionRepository) DeleteAll(ctx context.Context, uid int64) error {
+	return r.cache.DeleteAll(ctx, uid)
+}
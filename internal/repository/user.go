@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/repository/cache"
@@ -10,8 +12,14 @@ import (
 )
 
 var (
-	ErrUserDuplicate = dao.ErrUserDuplicate
-	ErrUserNotFound  = dao.ErrUserNotFound
+	ErrUserDuplicate       = dao.ErrUserDuplicate
+	ErrUserNotFound        = dao.ErrUserNotFound
+	ErrUserPhoneDuplicate  = dao.ErrUserPhoneDuplicate
+	ErrUserEmailDuplicate  = dao.ErrUserEmailDuplicate
+	ErrUserWechatDuplicate = dao.ErrUserWechatDuplicate
+	ErrUserLastLoginMethod = dao.ErrUserLastLoginMethod
+	ErrUserHandleDuplicate = dao.ErrUserHandleDuplicate
+	ErrUserHandleCooldown  = dao.ErrUserHandleCooldown
 )
 
 type UserRepository interface {
@@ -20,6 +28,32 @@ type UserRepository interface {
 	FindByPhone(ctx context.Context, phone string) (domain.User, error)
 	FindById(ctx context.Context, id int64) (domain.User, error)
 	FindByWechat(ctx context.Context, openID string) (domain.User, error)
+	// ClearProfileField 清空个人资料中的某个字段，field 取值见 domain.ProfileFieldXXX
+	ClearProfileField(ctx context.Context, id int64, field string) error
+	// UpdateNonSensitiveInfo 修改昵称、生日和个人简介，生日的格式是 YYYY-MM-DD
+	UpdateNonSensitiveInfo(ctx context.Context, u domain.User) error
+	// UpdateAvatar 修改头像，key 是头像在文件存储中的前缀
+	UpdateAvatar(ctx context.Context, id int64, key string) error
+	// UpdatePassword password 是加密之后的密码
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	MarkEmailVerified(ctx context.Context, id int64) error
+	// DeleteUnverified 删除 before 之前注册并且一直没有验证邮箱的用户和他们的数据，返回删除的用户 id
+	DeleteUnverified(ctx context.Context, before time.Time, limit int) ([]int64, error)
+	UpdatePhone(ctx context.Context, id int64, phone string) error
+	// UpdateEmail 绑定的邮箱已经验证过了
+	UpdateEmail(ctx context.Context, id int64, email string) error
+	UpdateWechat(ctx context.Context, id int64, info domain.WechatInfo) error
+	// Unbind 解绑登录方式，method 取值见 domain.LoginMethodXXX
+	Unbind(ctx context.Context, id int64, method string) error
+	// Search 按照 id、邮箱、手机号或者昵称搜索用户
+	Search(ctx context.Context, keyword string, offset, limit int) ([]domain.User, error)
+	// UpdateBan 解除封禁的时候传入零值
+	UpdateBan(ctx context.Context, id int64, ban domain.UserBan) error
+	// FindByHandle 不区分大小写
+	FindByHandle(ctx context.Context, handle string) (domain.User, error)
+	// UpdateHandle 上一次修改在 before 之前才能修改
+	UpdateHandle(ctx context.Context, id int64, handle string, before time.Time) error
+	UpdatePrivacy(ctx context.Context, id int64, p domain.UserPrivacy) error
 }
 
 type CachedUserRepository struct {
@@ -123,19 +157,209 @@ func (r *CachedUserRepository) FindByWechat(ctx context.Context, openID string)
 	return u, nil
 }
 
-func (r *CachedUserRepository) entityToDomain(u dao.User) domain.User {
-	return domain.User{
+func (r *CachedUserRepository) ClearProfileField(ctx context.Context, id int64, field string) error {
+	var column string
+	switch field {
+	case domain.ProfileFieldNickName:
+		column = "nic_name"
+	case domain.ProfileFieldAboutMe:
+		column = "about_me"
+	default:
+		return fmt.Errorf("不支持清空的字段 %s", field)
+	}
+	err := r.dao.ClearProfileField(ctx, id, column)
+	if err != nil {
+		return err
+	}
+	// 先更新数据库，再删除缓存
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) UpdateNonSensitiveInfo(ctx context.Context, u domain.User) error {
+	var birthday time.Time
+	if u.Birthday != "" {
+		var err error
+		birthday, err = time.ParseInLocation(time.DateOnly, u.Birthday, time.Local)
+		if err != nil {
+			return err
+		}
+	}
+	err := r.dao.UpdateNonSensitiveInfo(ctx, dao.User{
 		Id:       u.Id,
-		Email:    u.Email.String,
-		Phone:    u.Phone.String,
-		Password: u.Password,
-		NickName: u.NicName,
-		Birthday: u.BirthDay.String(),
+		NicName:  u.NickName,
+		BirthDay: birthday,
 		AboutMe:  u.AboutMe,
+	})
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, u.Id)
+}
+
+func (r *CachedUserRepository) UpdateAvatar(ctx context.Context, id int64, key string) error {
+	err := r.dao.UpdateAvatar(ctx, id, key)
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+	err := r.dao.UpdatePassword(ctx, id, password)
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	err := r.dao.MarkEmailVerified(ctx, id)
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+// DeleteUnverified 没有验证的用户不能修改资料，查询的时候可能放进了缓存，缓存过期之后自然就没了
+func (r *CachedUserRepository) DeleteUnverified(ctx context.Context, before time.Time, limit int) ([]int64, error) {
+	return r.dao.DeleteUnverified(ctx, before.UnixMilli(), limit)
+}
+
+func (r *CachedUserRepository) UpdatePhone(ctx context.Context, id int64, phone string) error {
+	err := r.dao.UpdatePhone(ctx, id, phone)
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) UpdateEmail(ctx context.Context, id int64, email string) error {
+	err := r.dao.UpdateEmail(ctx, id, email)
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) UpdateWechat(ctx context.Context, id int64, info domain.WechatInfo) error {
+	err := r.dao.UpdateWechat(ctx, id, info.OpenID, info.UnionID)
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) Unbind(ctx context.Context, id int64, method string) error {
+	var m string
+	switch method {
+	case domain.LoginMethodPhone:
+		m = dao.LoginMethodPhone
+	case domain.LoginMethodEmail:
+		m = dao.LoginMethodEmail
+	case domain.LoginMethodWechat:
+		m = dao.LoginMethodWechat
+	default:
+		return fmt.Errorf("不支持解绑的登录方式 %s", method)
+	}
+	err := r.dao.Unbind(ctx, id, m)
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) Search(ctx context.Context, keyword string, offset, limit int) ([]domain.User, error) {
+	us, err := r.dao.Search(ctx, keyword, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.User, 0, len(us))
+	for _, u := range us {
+		res = append(res, r.entityToDomain(u))
+	}
+	return res, nil
+}
+
+func (r *CachedUserRepository) UpdateBan(ctx context.Context, id int64, ban domain.UserBan) error {
+	var until int64
+	if !ban.Until.IsZero() {
+		until = ban.Until.UnixMilli()
+	}
+	err := r.dao.UpdateBan(ctx, id, ban.Status.ToUint8(), ban.Reason, until)
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) FindByHandle(ctx context.Context, handle string) (domain.User, error) {
+	u, err := r.dao.FindByHandle(ctx, strings.ToLower(handle))
+	if err != nil {
+		return domain.User{}, err
+	}
+	return r.entityToDomain(u), nil
+}
+
+func (r *CachedUserRepository) UpdateHandle(ctx context.Context, id int64, handle string, before time.Time) error {
+	err := r.dao.UpdateHandle(ctx, id, handle, strings.ToLower(handle), before.UnixMilli())
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) UpdatePrivacy(ctx context.Context, id int64, p domain.UserPrivacy) error {
+	err := r.dao.UpdatePrivacy(ctx, id, p.Email.ToUint8(), p.Phone.ToUint8())
+	if err != nil {
+		return err
+	}
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *CachedUserRepository) entityToDomain(u dao.User) domain.User {
+	var birthday string
+	if !u.BirthDay.IsZero() {
+		birthday = u.BirthDay.Format(time.DateOnly)
+	}
+	var deleteAt time.Time
+	if u.DeleteAt > 0 {
+		deleteAt = time.UnixMilli(u.DeleteAt)
+	}
+	var banUntil time.Time
+	if u.BanUntil > 0 {
+		banUntil = time.UnixMilli(u.BanUntil)
+	}
+	var handleUtime time.Time
+	if u.HandleUtime > 0 {
+		handleUtime = time.UnixMilli(u.HandleUtime)
+	}
+	return domain.User{
+		Id:            u.Id,
+		Handle:        u.Handle,
+		HandleUtime:   handleUtime,
+		Email:         u.Email.String,
+		EmailVerified: !u.EmailUnverified,
+		Phone:         u.Phone.String,
+		Password:      u.Password,
+		NickName:      u.NicName,
+		Birthday:      birthday,
+		AboutMe:       u.AboutMe,
+		Avatar:        domain.Avatar{Key: u.Avatar},
 		WechatInfo: domain.WechatInfo{
 			OpenID:  u.WechatOpenID.String,
 			UnionID: u.WechatUnionID.String,
 		},
+		MergedInto: u.MergedInto,
+		DeleteAt:   deleteAt,
+		Ban: domain.UserBan{
+			Status: domain.UserBanStatus(u.BanStatus),
+			Reason: u.BanReason,
+			Until:  banUntil,
+		},
+		Privacy: domain.UserPrivacy{
+			Email: domain.PrivacyLevel(u.EmailPrivacy),
+			Phone: domain.PrivacyLevel(u.PhonePrivacy),
+		},
 		Ctime: time.UnixMilli(u.Ctime),
 	}
 }
@@ -152,7 +376,8 @@ func (r *CachedUserRepository) domainToEntity(u domain.User) dao.User {
 			String: u.Phone,
 			Valid:  u.Phone != "",
 		},
-		Password: u.Password,
+		Password:        u.Password,
+		EmailUnverified: u.Email != "" && !u.EmailVerified,
 		WechatOpenID: sql.NullString{
 			String: u.WechatInfo.OpenID,
 			Valid:  u.WechatInfo.OpenID != "",
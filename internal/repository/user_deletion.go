@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/repository/cache"
+	"xiaoweishu/internal/repository/dao"
+)
+
+var (
+	ErrUserDeleted      = dao.ErrUserDeleted
+	ErrUserDeleteNotDue = dao.ErrUserDeleteNotDue
+)
+
+type UserDeletionRepository interface {
+	Schedule(ctx context.Context, id int64, at time.Time) error
+	Cancel(ctx context.Context, id int64) error
+	ListDue(ctx context.Context, before time.Time, limit int) ([]int64, error)
+	// Anonymize 整个注销过程在一个事务里面，返回需要删除文件的头像
+	Anonymize(ctx context.Context, id int64, before time.Time, nickname string, deleteArticles bool) ([]string, error)
+}
+
+type CachedUserDeletionRepository struct {
+	dao         dao.UserDeletionDao
+	userCache   cache.UserCache
+	notifyCache cache.NotificationCache
+}
+
+func NewUserDeletionRepository(d dao.UserDeletionDao,
+	userCache cache.UserCache,
+	notifyCache cache.NotificationCache) UserDeletionRepository {
+	return &CachedUserDeletionRepository{
+		dao:         d,
+		userCache:   userCache,
+		notifyCache: notifyCache,
+	}
+}
+
+func (r *CachedUserDeletionRepository) Schedule(ctx context.Context, id int64, at time.Time) error {
+	err := r.dao.Schedule(ctx, id, at.UnixMilli())
+	if err != nil {
+		return err
+	}
+	return r.userCache.Delete(ctx, id)
+}
+
+func (r *CachedUserDeletionRepository) Cancel(ctx context.Context, id int64) error {
+	err := r.dao.Cancel(ctx, id)
+	if err != nil {
+		return err
+	}
+	return r.userCache.Delete(ctx, id)
+}
+
+func (r *CachedUserDeletionRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]int64, error) {
+	return r.dao.ListDue(ctx, before.UnixMilli(), limit)
+}
+
+func (r *CachedUserDeletionRepository) Anonymize(ctx context.Context, id int64, before time.Time,
+	nickname string, deleteArticles bool) ([]string, error) {
+	avatars, err := r.dao.Anonymize(ctx, id, before.UnixMilli(), nickname, deleteArticles)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.userCache.Delete(ctx, id); err != nil {
+		return avatars, err
+	}
+	return avatars, r.notifyCache.DeleteUnread(ctx, id)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+	"xiaoweishu/internal/repository/dao"
+)
+
+var (
+	ErrUserMerged          = dao.ErrUserMerged
+	ErrUserMergeBanned     = dao.ErrUserMergeBanned
+	ErrUserMergeUnverified = dao.ErrUserMergeUnverified
+	ErrUserMergeDeleting   = dao.ErrUserMergeDeleting
+	ErrUserMergeMFA        = dao.ErrUserMergeMFA
+)
+
+type UserMergeRepository interface {
+	// Merge 把 mergedId 合并到 survivorId，整个过程在一个事务里面
+	Merge(ctx context.Context, survivorId, mergedId int64) (domain.UserMergeLog, error)
+	// ListLogs 查询用户作为保留账号或者被合并账号的合并记录
+	ListLogs(ctx context.Context, uid int64) ([]domain.UserMergeLog, error)
+}
+
+type CachedUserMergeRepository struct {
+	dao         dao.UserMergeDao
+	userCache   cache.UserCache
+	notifyCache cache.NotificationCache
+}
+
+func NewUserMergeRepository(d dao.UserMergeDao,
+	userCache cache.UserCache,
+	notifyCache cache.NotificationCache) UserMergeRepository {
+	return &CachedUserMergeRepository{
+		dao:         d,
+		userCache:   userCache,
+		notifyCache: notifyCache,
+	}
+}
+
+func (r *CachedUserMergeRepository) Merge(ctx context.Context, survivorId, mergedId int64) (domain.UserMergeLog, error) {
+	log, err := r.dao.Merge(ctx, survivorId, mergedId)
+	if err != nil {
+		return domain.UserMergeLog{}, err
+	}
+	// 两个账号的资料和未读通知数都变了
+	for _, uid := range []int64{survivorId, mergedId} {
+		if err = r.userCache.Delete(ctx, uid); err != nil {
+			return r.toDomain(log), err
+		}
+		if err = r.notifyCache.DeleteUnread(ctx, uid); err != nil {
+			return r.toDomain(log), err
+		}
+	}
+	return r.toDomain(log), nil
+}
+
+func (r *CachedUserMergeRepository) ListLogs(ctx context.Context, uid int64) ([]domain.UserMergeLog, error) {
+	logs, err := r.dao.ListLogs(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.UserMergeLog, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, r.toDomain(l))
+	}
+	return res, nil
+}
+
+func (r *CachedUserMergeRepository) toDomain(l dao.UserMergeLog) domain.UserMergeLog {
+	return domain.UserMergeLog{
+		Id:            l.Id,
+		SurvivorId:    l.SurvivorId,
+		MergedId:      l.MergedId,
+		MovedFields:   l.Detail.Val.MovedFields,
+		DroppedFields: l.Detail.Val.DroppedFields,
+		ArticleCnt:    len(l.Detail.Val.ArticleIds),
+		SeriesCnt:     len(l.Detail.Val.SeriesIds),
+		Ctime:         time.UnixMilli(l.Ctime),
+	}
+}
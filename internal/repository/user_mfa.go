@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository/cache"
+	"xiaoweishu/internal/repository/dao"
+)
+
+var (
+	ErrUserMFANotFound = dao.ErrUserMFANotFound
+	ErrUserMFAEnabled  = dao.ErrUserMFAEnabled
+	// ErrMFAPendingNotFound 等待两步验证的登录不存在或者已经过期
+	ErrMFAPendingNotFound = cache.ErrKeyNotFound
+)
+
+type UserMFARepository interface {
+	FindByUid(ctx context.Context, uid int64) (domain.UserMFA, error)
+	SaveSecret(ctx context.Context, uid int64, secret string) error
+	Enable(ctx context.Context, uid int64, step int64, codeHashes []string) error
+	UseStep(ctx context.Context, uid int64, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, uid int64, codeHash string) (bool, error)
+	Delete(ctx context.Context, uid int64) error
+	// 等待两步验证的登录只放在缓存里面
+	SavePending(ctx context.Context, p domain.MFAPending) error
+	FindPending(ctx context.Context, token string) (domain.MFAPending, error)
+	IncrPendingAttempts(ctx context.Context, token string) (int64, error)
+	DeletePending(ctx context.Context, token string) error
+}
+
+type userMFARepository struct {
+	dao   dao.UserMFADao
+	cache cache.MFAPendingCache
+}
+
+func NewUserMFARepository(d dao.UserMFADao, c cache.MFAPendingCache) UserMFARepository {
+	return &userMFARepository{
+		dao:   d,
+		cache: c,
+	}
+}
+
+func (r *userMFARepository) FindByUid(ctx context.Context, uid int64) (domain.UserMFA, error) {
+	m, err := r.dao.FindByUid(ctx, uid)
+	if err != nil {
+		return domain.UserMFA{}, err
+	}
+	return domain.UserMFA{
+		Uid:      m.Uid,
+		Secret:   m.Secret,
+		Enabled:  m.Enabled,
+		LastStep: m.LastStep,
+	}, nil
+}
+
+func (r *userMFARepository) SaveSecret(ctx context.Context, uid int64, secret string) error {
+	return r.dao.SaveSecret(ctx, uid, secret)
+}
+
+func (r *userMFARepository) Enable(ctx context.Context, uid int64, step int64, codeHashes []string) error {
+	return r.dao.Enable(ctx, uid, step, codeHashes)
+}
+
+func (r *userMFARepository) UseStep(ctx context.Context, uid int64, step int64) (bool, error) {
+	return r.dao.UseStep(ctx, uid, step)
+}
+
+func (r *userMFARepository) UseRecoveryCode(ctx context.Context, uid int64, codeHash string) (bool, error) {
+	return r.dao.UseRecoveryCode(ctx, uid, codeHash)
+}
+
+func (r *userMFARepository) Delete(ctx context.Context, uid int64) error {
+	return r.dao.Delete(ctx, uid)
+}
+
+func (r *userMFARepository) SavePending(ctx context.Context, p domain.MFAPending) error {
+	return r.cache.Set(ctx, p)
+}
+
+func (r *userMFARepository) FindPending(ctx context.Context, token string) (domain.MFAPending, error) {
+	return r.cache.Get(ctx, token)
+}
+
+func (r *userMFARepository) IncrPendingAttempts(ctx context.Context, token string) (int64, error) {
+	return r.cache.IncrAttempts(ctx, token)
+}
+
+func (r *userMFARepository) DeletePending(ctx context.Context, token string) error {
+	return r.cache.Delete(ctx, token)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"xiaoweishu/internal/repository/dao"
+)
+
+type UserRoleRepository interface {
+	FindRoles(ctx context.Context, uid int64) ([]string, error)
+}
+
+// userRoleRepository 只在签发 token 的时候查询，不需要缓存
+type userRoleRepository struct {
+	dao dao.UserRoleDao
+}
+
+func NewUserRoleRepository(d dao.UserRoleDao) UserRoleRepository {
+	return &userRoleRepository{
+		dao: d,
+	}
+}
+
+func (r *userRoleRepository) FindRoles(ctx context.Context, uid int64) ([]string, error) {
+	return r.dao.FindRoles(ctx, uid)
+}
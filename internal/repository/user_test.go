@@ -178,26 +178,28 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 				}, nil).Times(1)
 
 				uc.EXPECT().Set(gomock.Any(), domain.User{
-					Id:       1,
-					Email:    "1234@qq.com",
-					Phone:    "15972887248",
-					Password: "123456qwe",
-					NickName: "xiaoweishu",
-					Birthday: now.String(),
-					AboutMe:  "hello world",
-					Ctime:    now,
+					Id:            1,
+					Email:         "1234@qq.com",
+					EmailVerified: true,
+					Phone:         "15972887248",
+					Password:      "123456qwe",
+					NickName:      "xiaoweishu",
+					Birthday:      now.Format(time.DateOnly),
+					AboutMe:       "hello world",
+					Ctime:         now,
 				}).Return(nil).Times(1)
 				return ud, uc
 			},
 			wantUser: domain.User{
-				Id:       1,
-				Email:    "1234@qq.com",
-				Phone:    "15972887248",
-				Password: "123456qwe",
-				NickName: "xiaoweishu",
-				Birthday: now.String(),
-				AboutMe:  "hello world",
-				Ctime:    now,
+				Id:            1,
+				Email:         "1234@qq.com",
+				EmailVerified: true,
+				Phone:         "15972887248",
+				Password:      "123456qwe",
+				NickName:      "xiaoweishu",
+				Birthday:      now.Format(time.DateOnly),
+				AboutMe:       "hello world",
+				Ctime:         now,
 			},
 			wantErr: nil,
 		},
@@ -213,7 +215,7 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 					Phone:    "15972887248",
 					Password: "123456qwe",
 					NickName: "xiaoweishu",
-					Birthday: now.String(),
+					Birthday: now.Format(time.DateOnly),
 					AboutMe:  "hello world",
 					Ctime:    now,
 				}, nil).Times(1)
@@ -226,7 +228,7 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 				Phone:    "15972887248",
 				Password: "123456qwe",
 				NickName: "xiaoweishu",
-				Birthday: now.String(),
+				Birthday: now.Format(time.DateOnly),
 				AboutMe:  "hello world",
 				Ctime:    now,
 			},
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/email"
+)
+
+// bindPhoneBiz 绑定手机号的验证码，和登录验证码分开
+const bindPhoneBiz = "bind_phone"
+
+var (
+	ErrUserPhoneDuplicate  = repository.ErrUserPhoneDuplicate
+	ErrUserEmailDuplicate  = repository.ErrUserEmailDuplicate
+	ErrUserWechatDuplicate = repository.ErrUserWechatDuplicate
+	ErrUserLastLoginMethod = repository.ErrUserLastLoginMethod
+	ErrBindCodeInvalid     = errors.New("绑定验证码不对")
+)
+
+// AccountBindService 给已经登录的账号绑定和解绑手机号、邮箱和微信
+// 绑定之后用任意一种方式登录的都是同一个账号
+type AccountBindService interface {
+	// SendPhoneCode 给要绑定的手机号发送验证码，已经被其它账号绑定返回 ErrUserPhoneDuplicate
+	SendPhoneCode(ctx context.Context, uid int64, phone string) error
+	// BindPhone 校验验证码并绑定，原来绑定的手机号会被替换
+	BindPhone(ctx context.Context, uid int64, phone, code string) error
+	// SendEmailCode 给要绑定的邮箱发送验证码，已经被其它账号绑定返回 ErrUserEmailDuplicate
+	SendEmailCode(ctx context.Context, uid int64, addr string) error
+	// BindEmail 校验验证码并绑定，绑定之后邮箱就是验证过的
+	BindEmail(ctx context.Context, uid int64, addr, code string) error
+	// BindWechat 绑定微信，info 来自微信授权回调
+	BindWechat(ctx context.Context, uid int64, info domain.WechatInfo) error
+	// Unbind 解绑登录方式，method 取值见 domain.LoginMethodXXX
+	// 解绑之后没有办法登录返回 ErrUserLastLoginMethod
+	Unbind(ctx context.Context, uid int64, method string) error
+}
+
+type accountBindService struct {
+	repo    repository.UserRepository
+	codeSvc CodeService
+}
+
+func NewAccountBindService(repo repository.UserRepository, codeSvc CodeService) AccountBindService {
+	return &accountBindService{
+		repo:    repo,
+		codeSvc: codeSvc,
+	}
+}
+
+func (svc *accountBindService) SendPhoneCode(ctx context.Context, uid int64, phone string) error {
+	// 提前检查一下，省得用户收到验证码之后才发现绑定不了，真正的保证还是唯一索引
+	u, err := svc.repo.FindByPhone(ctx, phone)
+	if err = svc.checkOwner(uid, u, err, ErrUserPhoneDuplicate); err != nil {
+		return err
+	}
+	return svc.codeSvc.Send(ctx, bindPhoneBiz, phone)
+}
+
+func (svc *accountBindService) BindPhone(ctx context.Context, uid int64, phone, code string) error {
+	if err := svc.verify(ctx, bindPhoneBiz, phone, code); err != nil {
+		return err
+	}
+	return svc.repo.UpdatePhone(ctx, uid, phone)
+}
+
+func (svc *accountBindService) SendEmailCode(ctx context.Context, uid int64, addr string) error {
+	u, err := svc.repo.FindByEmail(ctx, addr)
+	if err = svc.checkOwner(uid, u, err, ErrUserEmailDuplicate); err != nil {
+		return err
+	}
+	return svc.codeSvc.SendEmail(ctx, email.BizBindEmail, addr)
+}
+
+func (svc *accountBindService) BindEmail(ctx context.Context, uid int64, addr, code string) error {
+	if err := svc.verify(ctx, email.BizBindEmail, addr, code); err != nil {
+		return err
+	}
+	return svc.repo.UpdateEmail(ctx, uid, addr)
+}
+
+func (svc *accountBindService) BindWechat(ctx context.Context, uid int64, info domain.WechatInfo) error {
+	return svc.repo.UpdateWechat(ctx, uid, info)
+}
+
+func (svc *accountBindService) Unbind(ctx context.Context, uid int64, method string) error {
+	return svc.repo.Unbind(ctx, uid, method)
+}
+
+func (svc *accountBindService) verify(ctx context.Context, biz, target, code string) error {
+	ok, err := svc.codeSvc.Verify(ctx, biz, target, code)
+	if errors.Is(err, repository.ErrCodeVerifyTooMany) {
+		return ErrBindCodeInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBindCodeInvalid
+	}
+	return nil
+}
+
+// checkOwner 手机号或者邮箱被其它账号使用的时候返回 dupErr，没有被使用或者就是自己的返回 nil
+func (svc *accountBindService) checkOwner(uid int64, u domain.User, err error, dupErr error) error {
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.Id != uid {
+		return dupErr
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_accountBindService_SendPhoneCode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.UserRepository, CodeService)
+		wantErr error
+	}{
+		{
+			name: "没有被绑定",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), bindPhoneBiz, "15212345678").Return(nil)
+				return repo, codeSvc
+			},
+		},
+		{
+			name: "自己已经绑定过",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 1, Phone: "15212345678"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), bindPhoneBiz, "15212345678").Return(nil)
+				return repo, codeSvc
+			},
+		},
+		{
+			name: "被其它账号绑定",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 2, Phone: "15212345678"}, nil)
+				return repo, svcmocks.NewMockCodeService(ctrl)
+			},
+			wantErr: ErrUserPhoneDuplicate,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, codeSvc := tc.mock(ctrl)
+			svc := NewAccountBindService(repo, codeSvc)
+			err := svc.SendPhoneCode(context.Background(), 1, "15212345678")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_accountBindService_BindPhone(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.UserRepository, CodeService)
+		wantErr error
+	}{
+		{
+			name: "绑定成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), bindPhoneBiz, "15212345678", "123456").Return(true, nil)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().UpdatePhone(gomock.Any(), int64(1), "15212345678").Return(nil)
+				return repo, codeSvc
+			},
+		},
+		{
+			name: "验证码不对",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), bindPhoneBiz, "15212345678", "123456").Return(false, nil)
+				return repomocks.NewMockUserRepository(ctrl), codeSvc
+			},
+			wantErr: ErrBindCodeInvalid,
+		},
+		{
+			name: "并发被其它账号绑定",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), bindPhoneBiz, "15212345678", "123456").Return(true, nil)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().UpdatePhone(gomock.Any(), int64(1), "15212345678").
+					Return(repository.ErrUserPhoneDuplicate)
+				return repo, codeSvc
+			},
+			wantErr: ErrUserPhoneDuplicate,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, codeSvc := tc.mock(ctrl)
+			svc := NewAccountBindService(repo, codeSvc)
+			err := svc.BindPhone(context.Background(), 1, "15212345678", "123456")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
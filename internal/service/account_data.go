@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/storage"
+)
+
+var (
+	ErrUserDeleted = repository.ErrUserDeleted
+)
+
+const (
+	// deletedUserNickname 注销之后的昵称，保留下来的文章作者显示这个名字
+	deletedUserNickname = "已注销用户"
+	accountDataPageSize = 100
+)
+
+// 注销之后怎么处理用户的文章
+const (
+	// AccountDeletionKeepArticles 文章保留，作者显示为已注销用户
+	AccountDeletionKeepArticles = "keep"
+	// AccountDeletionDeleteArticles 文章和专栏全部删除
+	AccountDeletionDeleteArticles = "delete"
+)
+
+type AccountDeletionConfig struct {
+	// Cooldown 冷静期，申请注销之后过了这么久才真正注销，期间可以取消
+	Cooldown time.Duration `yaml:"cooldown"`
+	// Articles 取值见 AccountDeletionXXXArticles
+	Articles string `yaml:"articles"`
+}
+
+// AccountDataService 用户导出自己的全部数据，以及注销账号
+type AccountDataService interface {
+	// Export 把用户的个人资料、文章、专栏、通知、举报、合并记录和登录记录导出成 JSON 写入 w
+	Export(ctx context.Context, uid int64, w io.Writer) error
+	// ScheduleDeletion 申请注销，返回真正注销的时间，已经申请过的返回之前的时间
+	ScheduleDeletion(ctx context.Context, uid int64) (time.Time, error)
+	CancelDeletion(ctx context.Context, uid int64) error
+	// ListDueDeletion 已经过了冷静期，等待注销的用户
+	ListDueDeletion(ctx context.Context, limit int) ([]int64, error)
+	// Delete 注销账号，清空个人信息，按照配置处理文章，用户已经取消注销的时候什么也不做
+	// 调用之前需要先让用户的登录失效
+	Delete(ctx context.Context, uid int64) error
+}
+
+type accountDataService struct {
+	userSvc    UserService
+	repo       repository.UserDeletionRepository
+	artRepo    repository.ArticleRepository
+	seriesRepo repository.SeriesRepository
+	notifyRepo repository.NotificationRepository
+	reportRepo repository.ReportRepository
+	mergeRepo  repository.UserMergeRepository
+	loginRepo  repository.LoginLogRepository
+	store      storage.Service
+	cfg        AccountDeletionConfig
+	l          logger.LoggerV1
+}
+
+func NewAccountDataService(userSvc UserService,
+	repo repository.UserDeletionRepository,
+	artRepo repository.ArticleRepository,
+	seriesRepo repository.SeriesRepository,
+	notifyRepo repository.NotificationRepository,
+	reportRepo repository.ReportRepository,
+	mergeRepo repository.UserMergeRepository,
+	loginRepo repository.LoginLogRepository,
+	store storage.Service,
+	cfg AccountDeletionConfig,
+	l logger.LoggerV1) AccountDataService {
+	return &accountDataService{
+		userSvc:    userSvc,
+		repo:       repo,
+		artRepo:    artRepo,
+		seriesRepo: seriesRepo,
+		notifyRepo: notifyRepo,
+		reportRepo: reportRepo,
+		mergeRepo:  mergeRepo,
+		loginRepo:  loginRepo,
+		store:      store,
+		cfg:        cfg,
+		l:          l,
+	}
+}
+
+// accountExport 导出文件的格式，字段名是对用户公开的，修改的时候注意兼容
+type accountExport struct {
+	ExportedAt    time.Time            `json:"exported_at"`
+	Profile       profileExport        `json:"profile"`
+	Articles      []articleExport      `json:"articles"`
+	Series        []seriesExport       `json:"series"`
+	Notifications []notificationExport `json:"notifications"`
+	Reports       []reportExport       `json:"reports"`
+	MergeLogs     []mergeLogExport     `json:"merge_logs"`
+	LoginLogs     []loginLogExport     `json:"login_logs"`
+}
+
+type profileExport struct {
+	Id            int64  `json:"id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Phone         string `json:"phone"`
+	// 密码只导出有没有设置过
+	HasPassword   bool       `json:"has_password"`
+	Nickname      string     `json:"nickname"`
+	Birthday      string     `json:"birthday"`
+	AboutMe       string     `json:"about_me"`
+	Avatar        string     `json:"avatar"`
+	WechatOpenID  string     `json:"wechat_open_id"`
+	WechatUnionID string     `json:"wechat_union_id"`
+	DeleteAt      *time.Time `json:"delete_at,omitempty"`
+	Ctime         time.Time  `json:"ctime"`
+}
+
+type articleExport struct {
+	Id        int64      `json:"id"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Tags      []string   `json:"tags"`
+	Status    string     `json:"status"`
+	Hidden    bool       `json:"hidden"`
+	ReadCnt   int64      `json:"read_cnt"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Ctime     time.Time  `json:"ctime"`
+	Utime     time.Time  `json:"utime"`
+}
+
+type seriesExport struct {
+	Id         int64     `json:"id"`
+	Title      string    `json:"title"`
+	ArticleIds []int64   `json:"article_ids"`
+	Ctime      time.Time `json:"ctime"`
+	Utime      time.Time `json:"utime"`
+}
+
+type notificationExport struct {
+	Id    int64     `json:"id"`
+	Biz   string    `json:"biz"`
+	Text  string    `json:"text"`
+	Read  bool      `json:"read"`
+	Ctime time.Time `json:"ctime"`
+	Utime time.Time `json:"utime"`
+}
+
+type reportExport struct {
+	Id     int64     `json:"id"`
+	Biz    string    `json:"biz"`
+	BizId  int64     `json:"biz_id"`
+	Field  string    `json:"field,omitempty"`
+	Reason string    `json:"reason"`
+	Status uint8     `json:"status"`
+	Ctime  time.Time `json:"ctime"`
+}
+
+type mergeLogExport struct {
+	Id            int64     `json:"id"`
+	SurvivorId    int64     `json:"survivor_id"`
+	MergedId      int64     `json:"merged_id"`
+	MovedFields   []string  `json:"moved_fields"`
+	DroppedFields []string  `json:"dropped_fields"`
+	Ctime         time.Time `json:"ctime"`
+}
+
+type loginLogExport struct {
+	Id        int64     `json:"id"`
+	Account   string    `json:"account"`
+	Method    string    `json:"method"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Ctime     time.Time `json:"ctime"`
+}
+
+func (svc *accountDataService) Export(ctx context.Context, uid int64, w io.Writer) error {
+	u, err := svc.userSvc.Profile(ctx, uid)
+	if err != nil {
+		return err
+	}
+	res := accountExport{
+		ExportedAt: time.Now(),
+		Profile:    svc.toProfileExport(u),
+	}
+	if res.Articles, err = svc.exportArticles(ctx, uid); err != nil {
+		return err
+	}
+	if res.Series, err = svc.exportSeries(ctx, uid); err != nil {
+		return err
+	}
+	if res.Notifications, err = svc.exportNotifications(ctx, uid); err != nil {
+		return err
+	}
+	if res.Reports, err = svc.exportReports(ctx, uid); err != nil {
+		return err
+	}
+	logs, err := svc.mergeRepo.ListLogs(ctx, uid)
+	if err != nil {
+		return err
+	}
+	res.MergeLogs = make([]mergeLogExport, 0, len(logs))
+	for _, l := range logs {
+		res.MergeLogs = append(res.MergeLogs, mergeLogExport{
+			Id:            l.Id,
+			SurvivorId:    l.SurvivorId,
+			MergedId:      l.MergedId,
+			MovedFields:   l.MovedFields,
+			DroppedFields: l.DroppedFields,
+			Ctime:         l.Ctime,
+		})
+	}
+	if res.LoginLogs, err = svc.exportLoginLogs(ctx, uid); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+func (svc *accountDataService) toProfileExport(u domain.User) profileExport {
+	res := profileExport{
+		Id:            u.Id,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+		Phone:         u.Phone,
+		HasPassword:   u.Password != "",
+		Nickname:      u.NickName,
+		Birthday:      u.Birthday,
+		AboutMe:       u.AboutMe,
+		Avatar:        u.Avatar.Large,
+		WechatOpenID:  u.WechatInfo.OpenID,
+		WechatUnionID: u.WechatInfo.UnionID,
+		Ctime:         u.Ctime,
+	}
+	if !u.DeleteAt.IsZero() {
+		res.DeleteAt = &u.DeleteAt
+	}
+	return res
+}
+
+// exportArticles 正常的文章和回收站里面的文章
+func (svc *accountDataService) exportArticles(ctx context.Context, uid int64) ([]articleExport, error) {
+	res := make([]articleExport, 0)
+	for offset := 0; ; offset += accountDataPageSize {
+		arts, err := svc.artRepo.ListByAuthor(ctx, uid, offset, accountDataPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			res = append(res, svc.toArticleExport(art))
+		}
+		if len(arts) < accountDataPageSize {
+			break
+		}
+	}
+	for offset := 0; ; offset += accountDataPageSize {
+		arts, err := svc.artRepo.ListDeleted(ctx, uid, time.UnixMilli(0), offset, accountDataPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			res = append(res, svc.toArticleExport(art))
+		}
+		if len(arts) < accountDataPageSize {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (svc *accountDataService) toArticleExport(art domain.Article) articleExport {
+	res := articleExport{
+		Id:      art.Id,
+		Title:   art.Title,
+		Content: art.Content,
+		Tags:    art.Tags,
+		Status:  art.Status.String(),
+		Hidden:  art.Hidden,
+		ReadCnt: art.ReadCnt,
+		Ctime:   art.Ctime,
+		Utime:   art.Utime,
+	}
+	if !art.DeletedAt.IsZero() {
+		res.DeletedAt = &art.DeletedAt
+	}
+	return res
+}
+
+func (svc *accountDataService) exportSeries(ctx context.Context, uid int64) ([]seriesExport, error) {
+	ss, err := svc.seriesRepo.ListByAuthor(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]seriesExport, 0, len(ss))
+	for _, s := range ss {
+		// 列表里面没有文章，需要单独查询
+		s, err = svc.seriesRepo.FindById(ctx, s.Id)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, seriesExport{
+			Id:         s.Id,
+			Title:      s.Title,
+			ArticleIds: s.ArticleIds,
+			Ctime:      s.Ctime,
+			Utime:      s.Utime,
+		})
+	}
+	return res, nil
+}
+
+func (svc *accountDataService) exportNotifications(ctx context.Context, uid int64) ([]notificationExport, error) {
+	res := make([]notificationExport, 0)
+	for offset := 0; ; offset += accountDataPageSize {
+		ns, err := svc.notifyRepo.List(ctx, uid, offset, accountDataPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range ns {
+			res = append(res, notificationExport{
+				Id:    n.Id,
+				Biz:   n.Biz,
+				Text:  n.Text(),
+				Read:  n.Read,
+				Ctime: n.Ctime,
+				Utime: n.Utime,
+			})
+		}
+		if len(ns) < accountDataPageSize {
+			return res, nil
+		}
+	}
+}
+
+func (svc *accountDataService) exportReports(ctx context.Context, uid int64) ([]reportExport, error) {
+	res := make([]reportExport, 0)
+	for offset := 0; ; offset += accountDataPageSize {
+		rps, err := svc.reportRepo.ListByReporter(ctx, uid, offset, accountDataPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, rp := range rps {
+			res = append(res, reportExport{
+				Id:     rp.Id,
+				Biz:    rp.Biz,
+				BizId:  rp.BizId,
+				Field:  rp.Field,
+				Reason: rp.Reason,
+				Status: rp.Status.ToUint8(),
+				Ctime:  rp.Ctime,
+			})
+		}
+		if len(rps) < accountDataPageSize {
+			return res, nil
+		}
+	}
+}
+
+func (svc *accountDataService) exportLoginLogs(ctx context.Context, uid int64) ([]loginLogExport, error) {
+	res := make([]loginLogExport, 0)
+	for offset := 0; ; offset += accountDataPageSize {
+		logs, err := svc.loginRepo.ListByUid(ctx, uid, offset, accountDataPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range logs {
+			res = append(res, loginLogExport{
+				Id:        l.Id,
+				Account:   l.Account,
+				Method:    l.Method,
+				Status:    l.Status.String(),
+				Reason:    l.Reason,
+				IP:        l.IP,
+				UserAgent: l.UserAgent,
+				Ctime:     l.Ctime,
+			})
+		}
+		if len(logs) < accountDataPageSize {
+			return res, nil
+		}
+	}
+}
+
+func (svc *accountDataService) ScheduleDeletion(ctx context.Context, uid int64) (time.Time, error) {
+	u, err := svc.userSvc.Profile(ctx, uid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// 重复申请不延长冷静期
+	if !u.DeleteAt.IsZero() {
+		return u.DeleteAt, nil
+	}
+	at := time.Now().Add(svc.cfg.Cooldown)
+	return at, svc.repo.Schedule(ctx, uid, at)
+}
+
+func (svc *accountDataService) CancelDeletion(ctx context.Context, uid int64) error {
+	return svc.repo.Cancel(ctx, uid)
+}
+
+func (svc *accountDataService) ListDueDeletion(ctx context.Context, limit int) ([]int64, error) {
+	return svc.repo.ListDue(ctx, time.Now(), limit)
+}
+
+func (svc *accountDataService) Delete(ctx context.Context, uid int64) error {
+	deleteArticles := svc.cfg.Articles == AccountDeletionDeleteArticles
+	avatars, err := svc.repo.Anonymize(ctx, uid, time.Now(), deletedUserNickname, deleteArticles)
+	if errors.Is(err, repository.ErrUserDeleteNotDue) {
+		// 用户在冷静期的最后一刻取消了注销
+		return nil
+	}
+	// 缓存删除失败的时候数据库已经提交了，头像文件照样要删
+	for _, key := range avatars {
+		if er := svc.store.Delete(ctx, avatarFileKeys(key)...); er != nil {
+			svc.l.Error("删除注销用户的头像文件失败", logger.String("key", key), logger.Error(er))
+		}
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	svcmocks "xiaoweishu/internal/service/mocks"
+	"xiaoweishu/internal/service/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_accountDataService_ScheduleDeletion(t *testing.T) {
+	deleteAt := time.UnixMilli(1700000000000)
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (UserService, repository.UserDeletionRepository)
+		wantAt  func(at time.Time) bool
+		wantErr error
+	}{
+		{
+			name: "申请注销",
+			mock: func(ctrl *gomock.Controller) (UserService, repository.UserDeletionRepository) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1}, nil)
+				repo := repomocks.NewMockUserDeletionRepository(ctrl)
+				repo.EXPECT().Schedule(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				return userSvc, repo
+			},
+			wantAt: func(at time.Time) bool {
+				return at.After(time.Now().Add(time.Hour*24 - time.Minute))
+			},
+		},
+		{
+			name: "重复申请不延长冷静期",
+			mock: func(ctrl *gomock.Controller) (UserService, repository.UserDeletionRepository) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1, DeleteAt: deleteAt}, nil)
+				return userSvc, repomocks.NewMockUserDeletionRepository(ctrl)
+			},
+			wantAt: func(at time.Time) bool {
+				return at.Equal(deleteAt)
+			},
+		},
+		{
+			name: "已经注销",
+			mock: func(ctrl *gomock.Controller) (UserService, repository.UserDeletionRepository) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1}, nil)
+				repo := repomocks.NewMockUserDeletionRepository(ctrl)
+				repo.EXPECT().Schedule(gomock.Any(), int64(1), gomock.Any()).Return(repository.ErrUserDeleted)
+				return userSvc, repo
+			},
+			wantAt: func(at time.Time) bool {
+				return true
+			},
+			wantErr: ErrUserDeleted,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc, repo := tc.mock(ctrl)
+			svc := NewAccountDataService(userSvc, repo, nil, nil, nil, nil, nil, nil, nil,
+				AccountDeletionConfig{Cooldown: time.Hour * 24}, &logger.NopLogger{})
+			at, err := svc.ScheduleDeletion(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.True(t, tc.wantAt(at))
+		})
+	}
+}
+
+func Test_accountDataService_Delete(t *testing.T) {
+	testCases := []struct {
+		name     string
+		articles string
+		mock     func(ctrl *gomock.Controller) repository.UserDeletionRepository
+		wantErr  error
+		// wantGone 注销之后应该被删除的头像
+		wantGone []string
+	}{
+		{
+			name:     "保留文章，删除头像文件",
+			articles: AccountDeletionKeepArticles,
+			mock: func(ctrl *gomock.Controller) repository.UserDeletionRepository {
+				repo := repomocks.NewMockUserDeletionRepository(ctrl)
+				repo.EXPECT().Anonymize(gomock.Any(), int64(1), gomock.Any(), deletedUserNickname, false).
+					Return([]string{"avatar/1"}, nil)
+				return repo
+			},
+			wantGone: []string{"avatar/1"},
+		},
+		{
+			name:     "删除文章",
+			articles: AccountDeletionDeleteArticles,
+			mock: func(ctrl *gomock.Controller) repository.UserDeletionRepository {
+				repo := repomocks.NewMockUserDeletionRepository(ctrl)
+				repo.EXPECT().Anonymize(gomock.Any(), int64(1), gomock.Any(), deletedUserNickname, true).
+					Return(nil, nil)
+				return repo
+			},
+		},
+		{
+			name:     "已经取消注销",
+			articles: AccountDeletionKeepArticles,
+			mock: func(ctrl *gomock.Controller) repository.UserDeletionRepository {
+				repo := repomocks.NewMockUserDeletionRepository(ctrl)
+				repo.EXPECT().Anonymize(gomock.Any(), int64(1), gomock.Any(), deletedUserNickname, false).
+					Return(nil, repository.ErrUserDeleteNotDue)
+				return repo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := memory.NewService()
+			for _, key := range avatarFileKeys("avatar/1") {
+				require.NoError(t, store.Put(context.Background(), key, []byte("old"), "image/png"))
+			}
+			svc := NewAccountDataService(nil, tc.mock(ctrl), nil, nil, nil, nil, nil, nil, store,
+				AccountDeletionConfig{Articles: tc.articles}, &logger.NopLogger{})
+			err := svc.Delete(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			for _, key := range tc.wantGone {
+				for _, file := range avatarFileKeys(key) {
+					_, ok := store.Get(file)
+					assert.False(t, ok)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ratelimit"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/email"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// accountMergeBiz 合并账号的验证码，和登录、绑定的验证码分开
+const accountMergeBiz = "account_merge"
+
+var (
+	ErrUserMerged = repository.ErrUserMerged
+	// ErrMergeBanned 当前账号或者要合并的账号被封禁、限制，合并会让封禁失效
+	ErrMergeBanned = repository.ErrUserMergeBanned
+	// ErrMergeUnverified 当前账号的邮箱没有验证，需要先验证邮箱才能合并
+	ErrMergeUnverified = repository.ErrUserMergeUnverified
+	// ErrMergeDeleting 当前账号或者要合并的账号申请了注销
+	ErrMergeDeleting = repository.ErrUserMergeDeleting
+	// ErrMergeMFA 要合并的账号开启了两步验证，当前账号没有开启，需要先开启两步验证
+	ErrMergeMFA = repository.ErrUserMergeMFA
+	// ErrMergeTargetInvalid 要合并的账号不存在，或者就是当前登录的账号
+	ErrMergeTargetInvalid = errors.New("要合并的账号不存在")
+	// ErrMergeProofInvalid 验证码或者密码不对，不能证明拥有要合并的账号
+	ErrMergeProofInvalid = errors.New("验证码或者密码不对")
+	ErrMergeTooMany      = errors.New("合并账号尝试太频繁")
+)
+
+// AccountMergeService 把同一个人用不同方式注册的账号合并到当前登录的账号
+// 当前账号保留下来，被合并的账号只留下指向当前账号的墓碑记录
+type AccountMergeService interface {
+	// SendCode 给要合并的账号发送验证码，target 是手机号或者邮箱
+	SendCode(ctx context.Context, uid int64, target string) error
+	// Merge 证明拥有 target 对应的账号之后合并，code 和 password 二选一
+	// password 只能证明邮箱账号，手机号注册的账号没有密码
+	Merge(ctx context.Context, uid int64, target, code, password string) (domain.UserMergeLog, error)
+	// MergeWechat 微信授权已经证明了拥有微信对应的账号
+	MergeWechat(ctx context.Context, uid int64, info domain.WechatInfo) (domain.UserMergeLog, error)
+	ListLogs(ctx context.Context, uid int64) ([]domain.UserMergeLog, error)
+}
+
+type accountMergeService struct {
+	repo     repository.UserMergeRepository
+	userRepo repository.UserRepository
+	codeSvc  CodeService
+	limiter  ratelimit.Limiter
+}
+
+func NewAccountMergeService(repo repository.UserMergeRepository,
+	userRepo repository.UserRepository,
+	codeSvc CodeService,
+	limiter ratelimit.Limiter) AccountMergeService {
+	return &accountMergeService{
+		repo:     repo,
+		userRepo: userRepo,
+		codeSvc:  codeSvc,
+		limiter:  limiter,
+	}
+}
+
+func (svc *accountMergeService) SendCode(ctx context.Context, uid int64, target string) error {
+	if _, err := svc.findTarget(ctx, uid, target); err != nil {
+		return err
+	}
+	if isEmail(target) {
+		return svc.codeSvc.SendEmail(ctx, email.BizAccountMerge, target)
+	}
+	return svc.codeSvc.Send(ctx, accountMergeBiz, target)
+}
+
+func (svc *accountMergeService) Merge(ctx context.Context, uid int64, target, code, password string) (domain.UserMergeLog, error) {
+	// 密码可以被暴力猜测，验证码自己有次数限制，这里统一按照当前账号限流
+	limited, err := svc.limiter.Limit(ctx, fmt.Sprintf("account-merge:%d", uid))
+	if err != nil {
+		return domain.UserMergeLog{}, err
+	}
+	if limited {
+		return domain.UserMergeLog{}, ErrMergeTooMany
+	}
+	u, err := svc.findTarget(ctx, uid, target)
+	if err != nil {
+		return domain.UserMergeLog{}, err
+	}
+	if code != "" {
+		err = svc.verifyCode(ctx, u, target, code)
+	} else {
+		err = svc.verifyPassword(u, target, password)
+	}
+	if err != nil {
+		return domain.UserMergeLog{}, err
+	}
+	return svc.repo.Merge(ctx, uid, u.Id)
+}
+
+func (svc *accountMergeService) MergeWechat(ctx context.Context, uid int64, info domain.WechatInfo) (domain.UserMergeLog, error) {
+	u, err := svc.userRepo.FindByWechat(ctx, info.OpenID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return domain.UserMergeLog{}, ErrMergeTargetInvalid
+	}
+	if err != nil {
+		return domain.UserMergeLog{}, err
+	}
+	if u.Id == uid {
+		return domain.UserMergeLog{}, ErrMergeTargetInvalid
+	}
+	if u.Ban.Effective(time.Now()) != domain.UserBanStatusNone {
+		return domain.UserMergeLog{}, ErrMergeBanned
+	}
+	if !u.DeleteAt.IsZero() {
+		return domain.UserMergeLog{}, ErrMergeDeleting
+	}
+	return svc.repo.Merge(ctx, uid, u.Id)
+}
+
+func (svc *accountMergeService) ListLogs(ctx context.Context, uid int64) ([]domain.UserMergeLog, error) {
+	return svc.repo.ListLogs(ctx, uid)
+}
+
+func (svc *accountMergeService) findTarget(ctx context.Context, uid int64, target string) (domain.User, error) {
+	var (
+		u   domain.User
+		err error
+	)
+	if isEmail(target) {
+		u, err = svc.userRepo.FindByEmail(ctx, target)
+	} else {
+		u, err = svc.userRepo.FindByPhone(ctx, target)
+	}
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return domain.User{}, ErrMergeTargetInvalid
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+	if u.Id == uid {
+		return domain.User{}, ErrMergeTargetInvalid
+	}
+	// 当前账号的状态在合并的事务里面检查，这里提前拦住不用发验证码
+	if u.Ban.Effective(time.Now()) != domain.UserBanStatusNone {
+		return domain.User{}, ErrMergeBanned
+	}
+	if !u.DeleteAt.IsZero() {
+		return domain.User{}, ErrMergeDeleting
+	}
+	return u, nil
+}
+
+func (svc *accountMergeService) verifyCode(ctx context.Context, u domain.User, target, code string) error {
+	biz := accountMergeBiz
+	if isEmail(target) {
+		biz = email.BizAccountMerge
+	}
+	ok, err := svc.codeSvc.Verify(ctx, biz, target, code)
+	if errors.Is(err, repository.ErrCodeVerifyTooMany) {
+		return ErrMergeProofInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMergeProofInvalid
+	}
+	// 收到了邮件就说明邮箱是真的，合并的时候没有验证过的邮箱不会转移
+	if isEmail(target) && !u.EmailVerified {
+		return svc.userRepo.MarkEmailVerified(ctx, u.Id)
+	}
+	return nil
+}
+
+func (svc *accountMergeService) verifyPassword(u domain.User, target, password string) error {
+	if !isEmail(target) || u.Password == "" || password == "" {
+		return ErrMergeProofInvalid
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) != nil {
+		return ErrMergeProofInvalid
+	}
+	return nil
+}
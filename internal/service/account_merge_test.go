@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ratelimit"
+	limitmocks "xiaoweishu/internal/pkg/ratelimit/mocks"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	"xiaoweishu/internal/service/email"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_accountMergeService_Merge(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("hello@world123"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+			CodeService, ratelimit.Limiter)
+		target   string
+		code     string
+		password string
+		wantLog  domain.UserMergeLog
+		wantErr  error
+	}{
+		{
+			name: "手机验证码合并",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+				CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "account-merge:1").Return(false, nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 2, Phone: "15212345678"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), accountMergeBiz, "15212345678", "123456").Return(true, nil)
+				repo := repomocks.NewMockUserMergeRepository(ctrl)
+				repo.EXPECT().Merge(gomock.Any(), int64(1), int64(2)).
+					Return(domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2}, nil)
+				return repo, userRepo, codeSvc, limiter
+			},
+			target:  "15212345678",
+			code:    "123456",
+			wantLog: domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2},
+		},
+		{
+			name: "邮箱验证码合并，没有验证过的邮箱变成验证过的",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+				CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 2, Email: "123@qq.com"}, nil)
+				userRepo.EXPECT().MarkEmailVerified(gomock.Any(), int64(2)).Return(nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), email.BizAccountMerge, "123@qq.com", "123456").Return(true, nil)
+				repo := repomocks.NewMockUserMergeRepository(ctrl)
+				repo.EXPECT().Merge(gomock.Any(), int64(1), int64(2)).
+					Return(domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2}, nil)
+				return repo, userRepo, codeSvc, limiter
+			},
+			target:  "123@qq.com",
+			code:    "123456",
+			wantLog: domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2},
+		},
+		{
+			name: "密码合并",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+				CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 2, Email: "123@qq.com", Password: string(hash)}, nil)
+				repo := repomocks.NewMockUserMergeRepository(ctrl)
+				repo.EXPECT().Merge(gomock.Any(), int64(1), int64(2)).
+					Return(domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2}, nil)
+				return repo, userRepo, svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			target:   "123@qq.com",
+			password: "hello@world123",
+			wantLog:  domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2},
+		},
+		{
+			name: "密码不对",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+				CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 2, Email: "123@qq.com", Password: string(hash)}, nil)
+				return repomocks.NewMockUserMergeRepository(ctrl), userRepo, svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			target:   "123@qq.com",
+			password: "hello@world456",
+			wantErr:  ErrMergeProofInvalid,
+		},
+		{
+			name: "合并自己",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+				CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 1, Phone: "15212345678"}, nil)
+				return repomocks.NewMockUserMergeRepository(ctrl), userRepo, svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			target:  "15212345678",
+			code:    "123456",
+			wantErr: ErrMergeTargetInvalid,
+		},
+		{
+			name: "要合并的账号被封禁",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+				CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 2, Phone: "15212345678",
+						Ban: domain.UserBan{Status: domain.UserBanStatusBanned, Reason: "发广告"}}, nil)
+				return repomocks.NewMockUserMergeRepository(ctrl), userRepo, svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			target:  "15212345678",
+			code:    "123456",
+			wantErr: ErrMergeBanned,
+		},
+		{
+			name: "要合并的账号申请了注销",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+				CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 2, Phone: "15212345678", DeleteAt: time.Now().Add(time.Hour)}, nil)
+				return repomocks.NewMockUserMergeRepository(ctrl), userRepo, svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			target:  "15212345678",
+			code:    "123456",
+			wantErr: ErrMergeDeleting,
+		},
+		{
+			name: "限流",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository,
+				CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(true, nil)
+				return repomocks.NewMockUserMergeRepository(ctrl), repomocks.NewMockUserRepository(ctrl),
+					svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			target:   "123@qq.com",
+			password: "hello@world123",
+			wantErr:  ErrMergeTooMany,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, userRepo, codeSvc, limiter := tc.mock(ctrl)
+			svc := NewAccountMergeService(repo, userRepo, codeSvc, limiter)
+			log, err := svc.Merge(context.Background(), 1, tc.target, tc.code, tc.password)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLog, log)
+		})
+	}
+}
+
+func Test_accountMergeService_MergeWechat(t *testing.T) {
+	info := domain.WechatInfo{OpenID: "open-id"}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository)
+
+		wantLog domain.UserMergeLog
+		wantErr error
+	}{
+		{
+			name: "合并成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByWechat(gomock.Any(), "open-id").Return(domain.User{Id: 2}, nil)
+				repo := repomocks.NewMockUserMergeRepository(ctrl)
+				repo.EXPECT().Merge(gomock.Any(), int64(1), int64(2)).
+					Return(domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2}, nil)
+				return repo, userRepo
+			},
+			wantLog: domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2},
+		},
+		{
+			name: "微信账号被限制",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByWechat(gomock.Any(), "open-id").Return(domain.User{Id: 2,
+					Ban: domain.UserBan{Status: domain.UserBanStatusRestricted, Until: time.Now().Add(time.Hour)}}, nil)
+				return repomocks.NewMockUserMergeRepository(ctrl), userRepo
+			},
+			wantErr: ErrMergeBanned,
+		},
+		{
+			name: "限制已经到期",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByWechat(gomock.Any(), "open-id").Return(domain.User{Id: 2,
+					Ban: domain.UserBan{Status: domain.UserBanStatusRestricted, Until: time.Now().Add(-time.Hour)}}, nil)
+				repo := repomocks.NewMockUserMergeRepository(ctrl)
+				repo.EXPECT().Merge(gomock.Any(), int64(1), int64(2)).
+					Return(domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2}, nil)
+				return repo, userRepo
+			},
+			wantLog: domain.UserMergeLog{Id: 1, SurvivorId: 1, MergedId: 2},
+		},
+		{
+			name: "当前账号被封禁",
+			mock: func(ctrl *gomock.Controller) (repository.UserMergeRepository, repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByWechat(gomock.Any(), "open-id").Return(domain.User{Id: 2}, nil)
+				repo := repomocks.NewMockUserMergeRepository(ctrl)
+				repo.EXPECT().Merge(gomock.Any(), int64(1), int64(2)).
+					Return(domain.UserMergeLog{}, repository.ErrUserMergeBanned)
+				return repo, userRepo
+			},
+			wantErr: ErrMergeBanned,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, userRepo := tc.mock(ctrl)
+			svc := NewAccountMergeService(repo, userRepo, nil, nil)
+			log, err := svc.MergeWechat(context.Background(), 1, info)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLog, log)
+		})
+	}
+}
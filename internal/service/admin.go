@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+)
+
+var (
+	// ErrAdminTargetSelf 管理员不能封禁自己，避免误操作之后没有人能恢复
+	ErrAdminTargetSelf = errors.New("不能对自己操作")
+	ErrUserBanInvalid  = errors.New("封禁状态不对")
+)
+
+// AdminService 管理后台，权限在接口上校验，这里只负责执行操作和记录审计日志
+// 每个操作都是先记录审计日志再执行，记录失败就不执行，所以操作失败的时候也会留下记录
+type AdminService interface {
+	SearchUsers(ctx context.Context, op domain.AdminOperator, keyword string, offset, limit int) ([]domain.User, error)
+	// BanUser 封禁或者限制用户，封禁之后不能再登录，已经签发的 token 由调用方撤销
+	BanUser(ctx context.Context, op domain.AdminOperator, uid int64, ban domain.UserBan) error
+	UnbanUser(ctx context.Context, op domain.AdminOperator, uid int64) error
+	// ViewArticle 查看任意文章，包括草稿和被隐藏的文章
+	ViewArticle(ctx context.Context, op domain.AdminOperator, id int64) (domain.Article, error)
+	// HideArticle 隐藏之后只有作者自己能看到，hidden 为 false 的时候取消隐藏
+	HideArticle(ctx context.Context, op domain.AdminOperator, id int64, hidden bool, reason string) error
+	// AuditLogs 审计日志，operatorId 为 0 的时候查询所有管理员的记录
+	AuditLogs(ctx context.Context, operatorId int64, offset, limit int) ([]domain.AdminAuditLog, error)
+}
+
+type adminService struct {
+	userRepo repository.UserRepository
+	artRepo  repository.ArticleRepository
+	logRepo  repository.AdminAuditLogRepository
+}
+
+func NewAdminService(userRepo repository.UserRepository,
+	artRepo repository.ArticleRepository,
+	logRepo repository.AdminAuditLogRepository) AdminService {
+	return &adminService{
+		userRepo: userRepo,
+		artRepo:  artRepo,
+		logRepo:  logRepo,
+	}
+}
+
+func (svc *adminService) SearchUsers(ctx context.Context, op domain.AdminOperator, keyword string, offset, limit int) ([]domain.User, error) {
+	err := svc.audit(ctx, op, domain.AdminActionSearchUsers, 0, keyword)
+	if err != nil {
+		return nil, err
+	}
+	return svc.userRepo.Search(ctx, keyword, offset, limit)
+}
+
+func (svc *adminService) BanUser(ctx context.Context, op domain.AdminOperator, uid int64, ban domain.UserBan) error {
+	if uid == op.Uid {
+		return ErrAdminTargetSelf
+	}
+	var action string
+	switch ban.Status {
+	case domain.UserBanStatusBanned:
+		action = domain.AdminActionBanUser
+	case domain.UserBanStatusRestricted:
+		action = domain.AdminActionRestrictUser
+	default:
+		return ErrUserBanInvalid
+	}
+	detail := ban.Reason
+	if !ban.Until.IsZero() {
+		detail = fmt.Sprintf("%s，到期时间 %s", ban.Reason, ban.Until.Format(time.DateTime))
+	}
+	return svc.updateBan(ctx, op, action, uid, ban, detail)
+}
+
+func (svc *adminService) UnbanUser(ctx context.Context, op domain.AdminOperator, uid int64) error {
+	return svc.updateBan(ctx, op, domain.AdminActionUnbanUser, uid, domain.UserBan{}, "")
+}
+
+func (svc *adminService) updateBan(ctx context.Context, op domain.AdminOperator,
+	action string, uid int64, ban domain.UserBan, detail string) error {
+	// 用户不存在的时候不留下没有意义的记录
+	if _, err := svc.userRepo.FindById(ctx, uid); err != nil {
+		return err
+	}
+	if err := svc.audit(ctx, op, action, uid, detail); err != nil {
+		return err
+	}
+	return svc.userRepo.UpdateBan(ctx, uid, ban)
+}
+
+func (svc *adminService) ViewArticle(ctx context.Context, op domain.AdminOperator, id int64) (domain.Article, error) {
+	err := svc.audit(ctx, op, domain.AdminActionViewArticle, id, "")
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return svc.artRepo.FindById(ctx, id)
+}
+
+func (svc *adminService) HideArticle(ctx context.Context, op domain.AdminOperator, id int64, hidden bool, reason string) error {
+	if _, err := svc.artRepo.FindById(ctx, id); err != nil {
+		return err
+	}
+	action := domain.AdminActionHideArticle
+	if !hidden {
+		action = domain.AdminActionShowArticle
+	}
+	if err := svc.audit(ctx, op, action, id, reason); err != nil {
+		return err
+	}
+	return svc.artRepo.SetHidden(ctx, id, hidden)
+}
+
+func (svc *adminService) AuditLogs(ctx context.Context, operatorId int64, offset, limit int) ([]domain.AdminAuditLog, error) {
+	return svc.logRepo.List(ctx, operatorId, offset, limit)
+}
+
+func (svc *adminService) audit(ctx context.Context, op domain.AdminOperator, action string, targetId int64, detail string) error {
+	return svc.logRepo.Create(ctx, domain.AdminAuditLog{
+		Operator: op,
+		Action:   action,
+		TargetId: targetId,
+		Detail:   detail,
+		Ctime:    time.Now(),
+	})
+}
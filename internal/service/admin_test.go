@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_adminService_BanUser(t *testing.T) {
+	op := domain.AdminOperator{Uid: 1, IP: "127.0.0.1"}
+	banned := domain.UserBan{
+		Status: domain.UserBanStatusBanned,
+		Reason: "发广告",
+		Until:  time.Date(2030, 1, 2, 3, 4, 5, 0, time.Local),
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.UserRepository, repository.AdminAuditLogRepository)
+		uid  int64
+		ban  domain.UserBan
+
+		wantErr error
+	}{
+		{
+			name: "封禁成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.AdminAuditLogRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				logRepo := repomocks.NewMockAdminAuditLogRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				// 先记录再封禁
+				gomock.InOrder(
+					logRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(ctx context.Context, l domain.AdminAuditLog) error {
+							assert.Equal(t, op, l.Operator)
+							assert.Equal(t, domain.AdminActionBanUser, l.Action)
+							assert.Equal(t, int64(2), l.TargetId)
+							assert.Equal(t, "发广告，到期时间 2030-01-02 03:04:05", l.Detail)
+							return nil
+						}),
+					userRepo.EXPECT().UpdateBan(gomock.Any(), int64(2), banned).Return(nil),
+				)
+				return userRepo, logRepo
+			},
+			uid: 2,
+			ban: banned,
+		},
+		{
+			name: "只能封禁或者限制",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.AdminAuditLogRepository) {
+				return repomocks.NewMockUserRepository(ctrl), repomocks.NewMockAdminAuditLogRepository(ctrl)
+			},
+			uid:     2,
+			ban:     domain.UserBan{Reason: "发广告"},
+			wantErr: ErrUserBanInvalid,
+		},
+		{
+			name: "不能封禁自己",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.AdminAuditLogRepository) {
+				return repomocks.NewMockUserRepository(ctrl), repomocks.NewMockAdminAuditLogRepository(ctrl)
+			},
+			uid:     1,
+			ban:     banned,
+			wantErr: ErrAdminTargetSelf,
+		},
+		{
+			name: "用户不存在",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.AdminAuditLogRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{}, repository.ErrUserNotFound)
+				return userRepo, repomocks.NewMockAdminAuditLogRepository(ctrl)
+			},
+			uid:     2,
+			ban:     banned,
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name: "记录失败就不封禁",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.AdminAuditLogRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				logRepo := repomocks.NewMockAdminAuditLogRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				logRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("mock db 错误"))
+				return userRepo, logRepo
+			},
+			uid:     2,
+			ban:     banned,
+			wantErr: errors.New("mock db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo, logRepo := tc.mock(ctrl)
+			svc := NewAdminService(userRepo, nil, logRepo)
+			err := svc.BanUser(context.Background(), op, tc.uid, tc.ban)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -2,13 +2,39 @@ package service
 
 import (
 	"context"
+	"time"
 	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/repository"
 )
 
+var (
+	ErrArticleNotFound = repository.ErrArticleNotFound
+	// ErrArticleVersionConflict 文章已经在别的地方被修改过，需要重新打开之后再保存
+	ErrArticleVersionConflict = repository.ErrArticleVersionConflict
+)
+
+const (
+	// ArticleRecycleRetention 文章在回收站里保留的时间，超过之后不能恢复，会被定时任务彻底删除
+	ArticleRecycleRetention = time.Hour * 24 * 30
+	articlePurgeBatchSize   = 100
+)
+
 type ArticleService interface {
-	Save(ctx context.Context, article domain.Article) (int64, error)
-	Publish(ctx context.Context, article domain.Article) (int64, error)
+	// Save 保存草稿，修改已有的文章时 article.Version 必须是读到的版本号
+	// 保存成功之后版本号加一，新建的文章版本号是 1，返回的文章带上 id 和新的版本号
+	Save(ctx context.Context, article domain.Article) (domain.Article, error)
+	// Publish 发表文章，版本号的要求和 Save 一样
+	Publish(ctx context.Context, article domain.Article) (domain.Article, error)
+	// GetPublishedById 读者查看文章，uid 是当前查看的用户
+	// 未发表的文章和被审核隐藏的文章，只有作者本人能看到
+	GetPublishedById(ctx context.Context, id, uid int64) (domain.Article, error)
+	// Delete 作者删除文章，文章会先放入回收站
+	Delete(ctx context.Context, id, uid int64) error
+	ListRecycleBin(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error)
+	// Restore 从回收站恢复，version 是回收站中看到的版本号
+	Restore(ctx context.Context, id, uid, version int64) error
+	// PurgeExpired 彻底删除回收站中过期的文章，返回删除的数量
+	PurgeExpired(ctx context.Context) (int64, error)
 }
 
 type articleService struct {
@@ -20,15 +46,77 @@ func NewArticleService(repo repository.ArticleRepository) ArticleService {
 		repo: repo,
 	}
 }
-func (a *articleService) Save(ctx context.Context, article domain.Article) (int64, error) {
+func (a *articleService) Save(ctx context.Context, article domain.Article) (domain.Article, error) {
+	article.Status = domain.ArticleStatusUnpublished
+	return a.save(ctx, article)
+}
+
+func (a *articleService) Publish(ctx context.Context, article domain.Article) (domain.Article, error) {
+	article.Status = domain.ArticleStatusPublished
+	return a.save(ctx, article)
+}
+
+func (a *articleService) save(ctx context.Context, article domain.Article) (domain.Article, error) {
 	if article.Id > 0 {
-		err := a.repo.Update(ctx, article)
-		return article.Id, err
-	} else {
-		return a.repo.Create(ctx, article)
+		if err := a.repo.Update(ctx, article); err != nil {
+			return domain.Article{}, err
+		}
+		article.Version++
+		return article, nil
+	}
+	id, err := a.repo.Create(ctx, article)
+	if err != nil {
+		return domain.Article{}, err
 	}
+	article.Id = id
+	article.Version = 1
+	return article, nil
 }
 
-func (a *articleService) Publish(ctx context.Context, article domain.Article) (int64, error) {
-	return 0, nil
+func (a *articleService) GetPublishedById(ctx context.Context, id, uid int64) (domain.Article, error) {
+	art, err := a.repo.FindById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if art.Author.Id == uid {
+		return art, nil
+	}
+	if art.Status != domain.ArticleStatusPublished || art.Hidden {
+		return domain.Article{}, ErrArticleNotFound
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		// 阅读数只用于热榜，少算几次问题不大，忽略错误
+		_ = a.repo.IncrReadCnt(ctx, id, uid)
+	}()
+	return art, nil
+}
+
+func (a *articleService) Delete(ctx context.Context, id, uid int64) error {
+	return a.repo.Delete(ctx, id, uid)
+}
+
+func (a *articleService) ListRecycleBin(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error) {
+	return a.repo.ListDeleted(ctx, uid, time.Now().Add(-ArticleRecycleRetention), offset, limit)
+}
+
+func (a *articleService) Restore(ctx context.Context, id, uid, version int64) error {
+	return a.repo.Restore(ctx, id, uid, version, time.Now().Add(-ArticleRecycleRetention))
+}
+
+func (a *articleService) PurgeExpired(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-ArticleRecycleRetention)
+	var total int64
+	for {
+		// 分批删除，避免一次删除太多数据导致长时间锁表
+		cnt, err := a.repo.Purge(ctx, before, articlePurgeBatchSize)
+		total += cnt
+		if err != nil {
+			return total, err
+		}
+		if cnt < articlePurgeBatchSize {
+			return total, nil
+		}
+	}
 }
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidArchive      = errors.New("不是合法的 zip 压缩包")
+	ErrArchiveFileInvalid  = errors.New("文件格式错误")
+	ErrArchiveFileConflict = errors.New("文章重复")
+)
+
+const (
+	archiveMaxFiles    = 500
+	archiveMaxFileSize = 1 << 20
+	archivePageSize    = 100
+	// articleTitleMaxLen 和 articles.title 的长度保持一致
+	articleTitleMaxLen = 1024
+	articleMaxTags     = 10
+	articleTagMaxLen   = 32
+
+	frontMatterDelimiter = "---"
+)
+
+type ArticleArchiveService interface {
+	// Export 把作者所有的文章打包成 zip 写入 w，每篇文章一个 Markdown 文件
+	// 文章的元数据放在 YAML front matter 里面
+	Export(ctx context.Context, uid int64, w io.Writer) error
+	// Import 导入 Export 格式的 zip，所有文章都通过 Save 作为草稿保存
+	// front matter 中的 status 和时间戳只用于展示，导入时会被忽略
+	Import(ctx context.Context, uid int64, r io.ReaderAt, size int64) ([]domain.ArticleImportResult, error)
+}
+
+type articleArchiveService struct {
+	svc  ArticleService
+	repo repository.ArticleRepository
+}
+
+func NewArticleArchiveService(svc ArticleService, repo repository.ArticleRepository) ArticleArchiveService {
+	return &articleArchiveService{
+		svc:  svc,
+		repo: repo,
+	}
+}
+
+// frontMatter Markdown 文件头部的元数据
+type frontMatter struct {
+	Title  string    `yaml:"title"`
+	Status string    `yaml:"status"`
+	Tags   []string  `yaml:"tags"`
+	Ctime  time.Time `yaml:"ctime"`
+	Utime  time.Time `yaml:"utime"`
+}
+
+func (a *articleArchiveService) Export(ctx context.Context, uid int64, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for offset := 0; ; offset += archivePageSize {
+		arts, err := a.repo.ListByAuthor(ctx, uid, offset, archivePageSize)
+		if err != nil {
+			return err
+		}
+		for _, art := range arts {
+			if err = a.writeArticle(zw, art); err != nil {
+				return err
+			}
+		}
+		if len(arts) < archivePageSize {
+			break
+		}
+	}
+	return zw.Close()
+}
+
+func (a *articleArchiveService) writeArticle(zw *zip.Writer, art domain.Article) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     fmt.Sprintf("article-%d.md", art.Id),
+		Method:   zip.Deflate,
+		Modified: art.Utime,
+	})
+	if err != nil {
+		return err
+	}
+	data, err := encodeMarkdown(art)
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+func (a *articleArchiveService) Import(ctx context.Context, uid int64, r io.ReaderAt, size int64) ([]domain.ArticleImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	if len(zr.File) > archiveMaxFiles {
+		return nil, fmt.Errorf("%w: 文件数量超过 %d", ErrInvalidArchive, archiveMaxFiles)
+	}
+	titles, err := a.existingTitles(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先全部校验一遍，再逐个保存
+	results := make([]domain.ArticleImportResult, 0, len(zr.File))
+	arts := make([]domain.Article, 0, len(zr.File))
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		art, err := a.readArticle(f)
+		if err == nil {
+			if _, ok := titles[art.Title]; ok {
+				err = fmt.Errorf("%w: 已经存在标题为 %q 的文章", ErrArchiveFileConflict, art.Title)
+			}
+		}
+		if err == nil {
+			titles[art.Title] = struct{}{}
+		}
+		art.Author = domain.Author{Id: uid}
+		results = append(results, domain.ArticleImportResult{File: f.Name, Err: err})
+		arts = append(arts, art)
+	}
+
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		art, err := a.svc.Save(ctx, arts[i])
+		results[i].Id, results[i].Err = art.Id, err
+	}
+	return results, nil
+}
+
+func (a *articleArchiveService) existingTitles(ctx context.Context, uid int64) (map[string]struct{}, error) {
+	titles := make(map[string]struct{})
+	for offset := 0; ; offset += archivePageSize {
+		arts, err := a.repo.ListByAuthor(ctx, uid, offset, archivePageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			titles[art.Title] = struct{}{}
+		}
+		if len(arts) < archivePageSize {
+			return titles, nil
+		}
+	}
+}
+
+func (a *articleArchiveService) readArticle(f *zip.File) (domain.Article, error) {
+	if path.Ext(f.Name) != ".md" {
+		return domain.Article{}, fmt.Errorf("%w: 只支持 .md 文件", ErrArchiveFileInvalid)
+	}
+	if f.UncompressedSize64 > archiveMaxFileSize {
+		return domain.Article{}, fmt.Errorf("%w: 文件超过 %d 字节", ErrArchiveFileInvalid, archiveMaxFileSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return domain.Article{}, fmt.Errorf("%w: %w", ErrArchiveFileInvalid, err)
+	}
+	defer rc.Close()
+	// 不能相信压缩包里面声明的大小，多读一个字节用来判断是否超出
+	data, err := io.ReadAll(io.LimitReader(rc, archiveMaxFileSize+1))
+	if err != nil {
+		return domain.Article{}, fmt.Errorf("%w: %w", ErrArchiveFileInvalid, err)
+	}
+	if len(data) > archiveMaxFileSize {
+		return domain.Article{}, fmt.Errorf("%w: 文件超过 %d 字节", ErrArchiveFileInvalid, archiveMaxFileSize)
+	}
+	return decodeMarkdown(data)
+}
+
+func encodeMarkdown(art domain.Article) ([]byte, error) {
+	fm, err := yaml.Marshal(frontMatter{
+		Title:  art.Title,
+		Status: art.Status.String(),
+		Tags:   art.Tags,
+		Ctime:  art.Ctime,
+		Utime:  art.Utime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.Write(fm)
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.WriteString(art.Content)
+	return buf.Bytes(), nil
+}
+
+func decodeMarkdown(data []byte) (domain.Article, error) {
+	if !utf8.Valid(data) {
+		return domain.Article{}, fmt.Errorf("%w: 文件不是 UTF-8 编码", ErrArchiveFileInvalid)
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, frontMatterDelimiter+"\n") {
+		return domain.Article{}, fmt.Errorf("%w: 缺少 front matter", ErrArchiveFileInvalid)
+	}
+	text = strings.TrimPrefix(text, frontMatterDelimiter+"\n")
+	head, content, ok := strings.Cut(text, "\n"+frontMatterDelimiter+"\n")
+	if !ok {
+		return domain.Article{}, fmt.Errorf("%w: front matter 没有结束", ErrArchiveFileInvalid)
+	}
+	var fm frontMatter
+	if err := yaml.Unmarshal([]byte(head), &fm); err != nil {
+		return domain.Article{}, fmt.Errorf("%w: front matter 解析失败 %w", ErrArchiveFileInvalid, err)
+	}
+	fm.Title = strings.TrimSpace(fm.Title)
+	if fm.Title == "" {
+		return domain.Article{}, fmt.Errorf("%w: 标题不能为空", ErrArchiveFileInvalid)
+	}
+	if utf8.RuneCountInString(fm.Title) > articleTitleMaxLen {
+		return domain.Article{}, fmt.Errorf("%w: 标题太长", ErrArchiveFileInvalid)
+	}
+	if strings.TrimSpace(content) == "" {
+		return domain.Article{}, fmt.Errorf("%w: 内容不能为空", ErrArchiveFileInvalid)
+	}
+	if len(fm.Tags) > articleMaxTags {
+		return domain.Article{}, fmt.Errorf("%w: 标签不能超过 %d 个", ErrArchiveFileInvalid, articleMaxTags)
+	}
+	for _, tag := range fm.Tags {
+		if tag == "" || utf8.RuneCountInString(tag) > articleTagMaxLen {
+			return domain.Article{}, fmt.Errorf("%w: 标签 %q 不合法", ErrArchiveFileInvalid, tag)
+		}
+	}
+	return domain.Article{
+		Title:   fm.Title,
+		Content: content,
+		Tags:    fm.Tags,
+	}, nil
+}
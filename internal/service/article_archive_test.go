@@ -0,0 +1,128 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_articleArchiveService_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := time.UnixMilli(time.Now().UnixMilli()).UTC()
+	repo := repomocks.NewMockArticleRepository(ctrl)
+	repo.EXPECT().ListByAuthor(gomock.Any(), int64(123), 0, archivePageSize).Return([]domain.Article{
+		{
+			Id:      1,
+			Title:   "标题: 带冒号",
+			Content: "# 内容\n正文",
+			Tags:    []string{"go", "gin"},
+			Status:  domain.ArticleStatusPublished,
+			Ctime:   now,
+			Utime:   now,
+		},
+	}, nil)
+	svc := NewArticleArchiveService(nil, repo)
+
+	var buf bytes.Buffer
+	err := svc.Export(context.Background(), 123, &buf)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	assert.Equal(t, "article-1.md", zr.File[0].Name)
+	rc, err := zr.File[0].Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	art, err := decodeMarkdown(data)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Article{
+		Title:   "标题: 带冒号",
+		Content: "# 内容\n正文",
+		Tags:    []string{"go", "gin"},
+	}, art)
+}
+
+func Test_articleArchiveService_Import(t *testing.T) {
+	files := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "ok.md",
+			content: "---\ntitle: 新文章\nstatus: published\ntags: [go]\n---\n正文",
+		},
+		{
+			name:    "dup.md",
+			content: "---\ntitle: 已有文章\n---\n正文",
+		},
+		{
+			name:    "dup-in-archive.md",
+			content: "---\ntitle: 新文章\n---\n另外一个正文",
+		},
+		{
+			name:    "no-front-matter.md",
+			content: "正文",
+		},
+		{
+			name:    "empty-title.md",
+			content: "---\ntitle: \"\"\n---\n正文",
+		},
+		{
+			name:    "image.png",
+			content: "png",
+		},
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockArticleRepository(ctrl)
+	repo.EXPECT().ListByAuthor(gomock.Any(), int64(123), 0, archivePageSize).
+		Return([]domain.Article{{Id: 9, Title: "已有文章"}}, nil)
+	artSvc := svcmocks.NewMockArticleService(ctrl)
+	artSvc.EXPECT().Save(gomock.Any(), domain.Article{
+		Title:   "新文章",
+		Content: "正文",
+		Tags:    []string{"go"},
+		Author:  domain.Author{Id: 123},
+	}).Return(domain.Article{Id: 10, Version: 1}, nil)
+	svc := NewArticleArchiveService(artSvc, repo)
+
+	results, err := svc.Import(context.Background(), 123, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, results, len(files))
+	assert.Equal(t, domain.ArticleImportResult{File: "ok.md", Id: 10}, results[0])
+	assert.ErrorIs(t, results[1].Err, ErrArchiveFileConflict)
+	assert.ErrorIs(t, results[2].Err, ErrArchiveFileConflict)
+	assert.ErrorIs(t, results[3].Err, ErrArchiveFileInvalid)
+	assert.ErrorIs(t, results[4].Err, ErrArchiveFileInvalid)
+	assert.ErrorIs(t, results[5].Err, ErrArchiveFileInvalid)
+}
+
+func Test_articleArchiveService_ImportInvalidArchive(t *testing.T) {
+	svc := NewArticleArchiveService(nil, nil)
+	data := []byte("not a zip")
+	_, err := svc.Import(context.Background(), 123, bytes.NewReader(data), int64(len(data)))
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+)
+
+var ErrArticleDraftNotFound = repository.ErrArticleDraftNotFound
+
+const (
+	articleDraftFlushBatchSize = 100
+	// articleDraftFlushMaxFailures 连续失败这么多次之后不再重试，避免一直占住批次的位置
+	articleDraftFlushMaxFailures = 5
+)
+
+// ArticleDraftService 编辑器的自动保存
+// 自动保存只写 redis，定时任务再把草稿持久化到 MySQL
+type ArticleDraftService interface {
+	// Autosave 自动保存，新文章第一次自动保存的时候会直接创建草稿，返回文章 id
+	Autosave(ctx context.Context, art domain.Article) (int64, error)
+	// Open 打开编辑器，返回自动保存的草稿和 MySQL 中较新的那份
+	// autosaved 表示返回的是自动保存的草稿，版本号总是 MySQL 中的版本号
+	Open(ctx context.Context, id, uid int64) (art domain.Article, autosaved bool, err error)
+	// Discard 丢弃自动保存的草稿
+	Discard(ctx context.Context, id, uid int64) error
+	// FlushDirty 把还没有持久化的草稿写入 MySQL，返回写入的数量
+	// 已经发表的文章不会被写入，避免没有编辑完的内容被读者看到
+	FlushDirty(ctx context.Context) (int64, error)
+}
+
+type articleDraftService struct {
+	repo    repository.ArticleDraftRepository
+	artRepo repository.ArticleRepository
+	artSvc  ArticleService
+	l       logger.LoggerV1
+}
+
+func NewArticleDraftService(repo repository.ArticleDraftRepository,
+	artRepo repository.ArticleRepository,
+	artSvc ArticleService,
+	l logger.LoggerV1) ArticleDraftService {
+	return &articleDraftService{
+		repo:    repo,
+		artRepo: artRepo,
+		artSvc:  artSvc,
+		l:       l,
+	}
+}
+
+func (svc *articleDraftService) Autosave(ctx context.Context, art domain.Article) (int64, error) {
+	if art.Id == 0 {
+		// 新文章还没有 id，直接保存一次草稿
+		art, err := svc.artSvc.Save(ctx, art)
+		return art.Id, err
+	}
+	// key 里面带了作者 id，不是作者本人的文章在持久化的时候也会被拒绝，所以这里不用查 MySQL
+	art.Utime = time.Now()
+	return art.Id, svc.repo.Save(ctx, art)
+}
+
+func (svc *articleDraftService) Open(ctx context.Context, id, uid int64) (domain.Article, bool, error) {
+	art, err := svc.artRepo.FindById(ctx, id)
+	if err != nil {
+		return domain.Article{}, false, err
+	}
+	if art.Author.Id != uid {
+		return domain.Article{}, false, ErrArticleNotFound
+	}
+	draft, err := svc.repo.Find(ctx, uid, id)
+	if errors.Is(err, ErrArticleDraftNotFound) {
+		return art, false, nil
+	}
+	if err != nil {
+		// redis 出问题也不影响打开编辑器
+		svc.l.Error("查询自动保存的草稿失败",
+			logger.Int64("id", id), logger.Int64("uid", uid), logger.Error(err))
+		return art, false, nil
+	}
+	if !draft.Utime.After(art.Utime) {
+		return art, false, nil
+	}
+	art.Title = draft.Title
+	art.Content = draft.Content
+	art.Tags = draft.Tags
+	art.Utime = draft.Utime
+	return art, true, nil
+}
+
+func (svc *articleDraftService) Discard(ctx context.Context, id, uid int64) error {
+	return svc.repo.Delete(ctx, uid, id)
+}
+
+func (svc *articleDraftService) FlushDirty(ctx context.Context) (int64, error) {
+	dirty, err := svc.repo.ListDirty(ctx, articleDraftFlushBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	var cnt int64
+	for _, d := range dirty {
+		ok, err := svc.flush(ctx, d.Author.Id, d.Id)
+		if err != nil {
+			// 单篇失败不影响其它的，下次再重试
+			svc.l.Error("持久化自动保存的草稿失败",
+				logger.Int64("id", d.Id), logger.Int64("uid", d.Author.Id), logger.Error(err))
+			svc.recordFailure(ctx, d.Author.Id, d.Id)
+			continue
+		}
+		if ok {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+// recordFailure 连续失败太多次的草稿不再重试，草稿本身还在 redis 里面
+func (svc *articleDraftService) recordFailure(ctx context.Context, uid, id int64) {
+	cnt, err := svc.repo.IncrFlushFailure(ctx, uid, id)
+	if err != nil {
+		svc.l.Error("记录草稿持久化失败次数失败",
+			logger.Int64("id", id), logger.Int64("uid", uid), logger.Error(err))
+		return
+	}
+	if cnt < articleDraftFlushMaxFailures {
+		return
+	}
+	svc.l.Error("草稿多次持久化失败，不再重试",
+		logger.Int64("id", id), logger.Int64("uid", uid), logger.Int64("failures", cnt))
+	if err = svc.repo.DropDirty(ctx, uid, id); err != nil {
+		svc.l.Error("移除草稿持久化标记失败",
+			logger.Int64("id", id), logger.Int64("uid", uid), logger.Error(err))
+	}
+}
+
+// flush 持久化一篇草稿，返回是否写入了 MySQL
+func (svc *articleDraftService) flush(ctx context.Context, uid, id int64) (bool, error) {
+	draft, err := svc.repo.Find(ctx, uid, id)
+	if errors.Is(err, ErrArticleDraftNotFound) {
+		// 草稿已经过期
+		return false, svc.repo.Delete(ctx, uid, id)
+	}
+	if err != nil {
+		return false, err
+	}
+	art, err := svc.artRepo.FindById(ctx, id)
+	if errors.Is(err, ErrArticleNotFound) {
+		return false, svc.repo.Delete(ctx, uid, id)
+	}
+	if err != nil {
+		return false, err
+	}
+	// MySQL 中的已经比草稿新，或者文章已经发表，只保留 redis 中的草稿等作者自己处理
+	if art.Author.Id != uid || art.Status == domain.ArticleStatusPublished || !draft.Utime.After(art.Utime) {
+		return false, svc.repo.ClearDirty(ctx, draft)
+	}
+	err = svc.artRepo.SaveDraft(ctx, draft)
+	if errors.Is(err, ErrArticleVersionConflict) {
+		return false, svc.repo.ClearDirty(ctx, draft)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, svc.repo.ClearDirty(ctx, draft)
+}
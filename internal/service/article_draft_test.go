@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_articleDraftService_Open(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	art := domain.Article{
+		Id:      1,
+		Title:   "MySQL 标题",
+		Content: "MySQL 内容",
+		Author:  domain.Author{Id: 123},
+		Status:  domain.ArticleStatusPublished,
+		Version: 3,
+		Utime:   now,
+	}
+	testCases := []struct {
+		name          string
+		mock          func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository)
+		uid           int64
+		wantArt       domain.Article
+		wantAutosaved bool
+		wantErr       error
+	}{
+		{
+			name: "自动保存的草稿更新",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(art, nil)
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).Return(domain.Article{
+					Id:      1,
+					Title:   "草稿标题",
+					Content: "草稿内容",
+					Author:  domain.Author{Id: 123},
+					Version: 2,
+					Utime:   now.Add(time.Minute),
+				}, nil)
+				return repo, artRepo
+			},
+			uid: 123,
+			wantArt: domain.Article{
+				Id:      1,
+				Title:   "草稿标题",
+				Content: "草稿内容",
+				Author:  domain.Author{Id: 123},
+				Status:  domain.ArticleStatusPublished,
+				// 版本号用 MySQL 中的
+				Version: 3,
+				Utime:   now.Add(time.Minute),
+			},
+			wantAutosaved: true,
+		},
+		{
+			name: "MySQL 中的更新",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(art, nil)
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).Return(domain.Article{
+					Id:     1,
+					Title:  "草稿标题",
+					Author: domain.Author{Id: 123},
+					Utime:  now.Add(-time.Minute),
+				}, nil)
+				return repo, artRepo
+			},
+			uid:     123,
+			wantArt: art,
+		},
+		{
+			name: "没有自动保存的草稿",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(art, nil)
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).
+					Return(domain.Article{}, repository.ErrArticleDraftNotFound)
+				return repo, artRepo
+			},
+			uid:     123,
+			wantArt: art,
+		},
+		{
+			name: "不是自己的文章",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(art, nil)
+				return repomocks.NewMockArticleDraftRepository(ctrl), artRepo
+			},
+			uid:     456,
+			wantErr: ErrArticleNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewArticleDraftService(repo, artRepo, nil, &logger.NopLogger{})
+			got, autosaved, err := svc.Open(context.Background(), 1, tc.uid)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArt, got)
+			assert.Equal(t, tc.wantAutosaved, autosaved)
+		})
+	}
+}
+
+func Test_articleDraftService_FlushDirty(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	draft := domain.Article{
+		Id:      1,
+		Title:   "草稿标题",
+		Author:  domain.Author{Id: 123},
+		Version: 2,
+		Utime:   now,
+	}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository)
+		wantCnt int64
+	}{
+		{
+			name: "持久化成功",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().ListDirty(gomock.Any(), articleDraftFlushBatchSize).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 123}}}, nil)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).Return(draft, nil)
+				repo.EXPECT().ClearDirty(gomock.Any(), draft).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusUnpublished,
+					Utime:  now.Add(-time.Minute),
+				}, nil)
+				artRepo.EXPECT().SaveDraft(gomock.Any(), draft).Return(nil)
+				return repo, artRepo
+			},
+			wantCnt: 1,
+		},
+		{
+			name: "已经发表的文章不持久化",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().ListDirty(gomock.Any(), articleDraftFlushBatchSize).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 123}}}, nil)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).Return(draft, nil)
+				repo.EXPECT().ClearDirty(gomock.Any(), draft).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+					Utime:  now.Add(-time.Minute),
+				}, nil)
+				return repo, artRepo
+			},
+		},
+		{
+			name: "MySQL 中的更新",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().ListDirty(gomock.Any(), articleDraftFlushBatchSize).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 123}}}, nil)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).Return(draft, nil)
+				repo.EXPECT().ClearDirty(gomock.Any(), draft).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusUnpublished,
+					Utime:  now.Add(time.Minute),
+				}, nil)
+				return repo, artRepo
+			},
+		},
+		{
+			name: "版本冲突保留草稿",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().ListDirty(gomock.Any(), articleDraftFlushBatchSize).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 123}}}, nil)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).Return(draft, nil)
+				repo.EXPECT().ClearDirty(gomock.Any(), draft).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusUnpublished,
+					Utime:  now.Add(-time.Minute),
+				}, nil)
+				artRepo.EXPECT().SaveDraft(gomock.Any(), draft).Return(repository.ErrArticleVersionConflict)
+				return repo, artRepo
+			},
+		},
+		{
+			name: "草稿已经过期",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().ListDirty(gomock.Any(), articleDraftFlushBatchSize).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 123}}}, nil)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).
+					Return(domain.Article{}, repository.ErrArticleDraftNotFound)
+				repo.EXPECT().Delete(gomock.Any(), int64(123), int64(1)).Return(nil)
+				return repo, repomocks.NewMockArticleRepository(ctrl)
+			},
+		},
+		{
+			name: "持久化失败，下次重试",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().ListDirty(gomock.Any(), articleDraftFlushBatchSize).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 123}}}, nil)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).Return(draft, nil)
+				repo.EXPECT().IncrFlushFailure(gomock.Any(), int64(123), int64(1)).Return(int64(1), nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Article{}, errors.New("mock db 错误"))
+				return repo, artRepo
+			},
+		},
+		{
+			name: "连续失败太多次，不再重试",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleDraftRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleDraftRepository(ctrl)
+				repo.EXPECT().ListDirty(gomock.Any(), articleDraftFlushBatchSize).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 123}}}, nil)
+				repo.EXPECT().Find(gomock.Any(), int64(123), int64(1)).Return(draft, nil)
+				repo.EXPECT().IncrFlushFailure(gomock.Any(), int64(123), int64(1)).
+					Return(int64(articleDraftFlushMaxFailures), nil)
+				repo.EXPECT().DropDirty(gomock.Any(), int64(123), int64(1)).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Article{}, errors.New("mock db 错误"))
+				return repo, artRepo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewArticleDraftService(repo, artRepo, nil, &logger.NopLogger{})
+			cnt, err := svc.FlushDirty(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
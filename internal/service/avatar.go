@@ -0,0 +1,127 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrAvatarInvalidFormat = errors.New("头像只支持 JPEG、PNG 和 WebP")
+	ErrAvatarInvalidSize   = errors.New("头像尺寸不合法")
+)
+
+const (
+	// avatarMinSide 图片短边的最小值，太小的图片放大之后很模糊
+	avatarMinSide = 96
+	// avatarMaxSide 图片长边的最大值，解码之前就检查，防止超大图片耗尽内存
+	avatarMaxSide = 4096
+)
+
+// avatarSizes 依次对应 domain.Avatar 的 Small、Medium、Large
+var avatarSizes = [...]int{48, 96, 256}
+
+func (svc *userService) UploadAvatar(ctx context.Context, uid int64, data []byte) (domain.Avatar, error) {
+	files, err := svc.resizeAvatar(data)
+	if err != nil {
+		return domain.Avatar{}, err
+	}
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return domain.Avatar{}, err
+	}
+	// 每次上传都用新的 key，避免 CDN 和浏览器缓存了旧的头像
+	key := fmt.Sprintf("avatar/%d/%s", uid, uuid.New().String())
+	keys := avatarFileKeys(key)
+	for i, file := range files {
+		err = svc.store.Put(ctx, keys[i], file, "image/png")
+		if err != nil {
+			svc.deleteAvatarFiles(ctx, key)
+			return domain.Avatar{}, err
+		}
+	}
+	err = svc.repo.UpdateAvatar(ctx, uid, key)
+	if err != nil {
+		svc.deleteAvatarFiles(ctx, key)
+		return domain.Avatar{}, err
+	}
+	if u.Avatar.Key != "" {
+		svc.deleteAvatarFiles(ctx, u.Avatar.Key)
+	}
+	return svc.avatar(key), nil
+}
+
+// resizeAvatar 居中裁剪成正方形，再缩放成各个尺寸，统一编码成 PNG
+func (svc *userService) resizeAvatar(data []byte) ([][]byte, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarInvalidFormat
+	}
+	if format != "jpeg" && format != "png" && format != "webp" {
+		return nil, ErrAvatarInvalidFormat
+	}
+	if min(cfg.Width, cfg.Height) < avatarMinSide || max(cfg.Width, cfg.Height) > avatarMaxSide {
+		return nil, ErrAvatarInvalidSize
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarInvalidFormat
+	}
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	crop := image.Rect(x0, y0, x0+side, y0+side)
+
+	res := make([][]byte, 0, len(avatarSizes))
+	for _, size := range avatarSizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+		var buf bytes.Buffer
+		if err = png.Encode(&buf, dst); err != nil {
+			return nil, err
+		}
+		res = append(res, buf.Bytes())
+	}
+	return res, nil
+}
+
+func avatarFileKeys(key string) []string {
+	keys := make([]string, 0, len(avatarSizes))
+	for _, size := range avatarSizes {
+		keys = append(keys, fmt.Sprintf("%s_%d.png", key, size))
+	}
+	return keys
+}
+
+// avatar 根据 key 生成各个尺寸的地址，没有头像的时候返回零值
+func (svc *userService) avatar(key string) domain.Avatar {
+	if key == "" {
+		return domain.Avatar{}
+	}
+	keys := avatarFileKeys(key)
+	return domain.Avatar{
+		Key:    key,
+		Small:  svc.store.URL(keys[0]),
+		Medium: svc.store.URL(keys[1]),
+		Large:  svc.store.URL(keys[2]),
+	}
+}
+
+// deleteAvatarFiles 清理头像文件失败只会留下无用的文件，不影响业务
+func (svc *userService) deleteAvatarFiles(ctx context.Context, key string) {
+	err := svc.store.Delete(ctx, avatarFileKeys(key)...)
+	if err != nil {
+		svc.l.Error("删除头像文件失败", logger.String("key", key), logger.Error(err))
+	}
+}
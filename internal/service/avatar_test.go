@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	"xiaoweishu/internal/service/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_userService_UploadAvatar(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.UserRepository
+		// before 预先放入存储的文件
+		before []string
+		data   []byte
+
+		wantErr error
+		// wantDeleted 上传之后应该被删除的文件
+		wantDeleted []string
+	}{
+		{
+			name: "上传 PNG 并删除旧头像",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{
+					Id:     123,
+					Avatar: domain.Avatar{Key: "avatar/123/old"},
+				}, nil)
+				repo.EXPECT().UpdateAvatar(gomock.Any(), int64(123), gomock.Any()).Return(nil)
+				return repo
+			},
+			before:      []string{"avatar/123/old_48.png", "avatar/123/old_96.png", "avatar/123/old_256.png"},
+			data:        encodeTestImage(t, "png", 300, 200),
+			wantDeleted: []string{"avatar/123/old_48.png", "avatar/123/old_96.png", "avatar/123/old_256.png"},
+		},
+		{
+			name: "上传 JPEG",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{Id: 123}, nil)
+				repo.EXPECT().UpdateAvatar(gomock.Any(), int64(123), gomock.Any()).Return(nil)
+				return repo
+			},
+			data: encodeTestImage(t, "jpeg", 200, 400),
+		},
+		{
+			name: "不支持的格式",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			data:    []byte("GIF89a not really an image"),
+			wantErr: ErrAvatarInvalidFormat,
+		},
+		{
+			name: "图片太小",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			data:    encodeTestImage(t, "png", 300, 50),
+			wantErr: ErrAvatarInvalidSize,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := memory.NewService()
+			for _, key := range tc.before {
+				require.NoError(t, store.Put(context.Background(), key, []byte("old"), "image/png"))
+			}
+			svc := NewUserService(tc.mock(ctrl), nil, store, &logger.NopLogger{})
+			avatar, err := svc.UploadAvatar(context.Background(), 123, tc.data)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			for _, key := range tc.wantDeleted {
+				_, ok := store.Get(key)
+				assert.False(t, ok, key)
+			}
+			assert.True(t, strings.HasPrefix(avatar.Key, "avatar/123/"))
+			urls := []string{avatar.Small, avatar.Medium, avatar.Large}
+			for i, size := range avatarSizes {
+				key := fmt.Sprintf("%s_%d.png", avatar.Key, size)
+				assert.Equal(t, store.URL(key), urls[i])
+				data, ok := store.Get(key)
+				require.True(t, ok)
+				cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+				require.NoError(t, err)
+				assert.Equal(t, "png", format)
+				assert.Equal(t, size, cfg.Width)
+				assert.Equal(t, size, cfg.Height)
+			}
+		})
+	}
+}
+
+func encodeTestImage(t *testing.T, format string, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, nil)
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	require.NoError(t, err)
+	return buf.Bytes()
+}
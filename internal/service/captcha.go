@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/captcha"
+	"xiaoweishu/internal/pkg/ratelimit"
+	"xiaoweishu/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// CaptchaService 图片验证码
+type CaptchaService interface {
+	Generate(ctx context.Context) (domain.Captcha, error)
+	// Verify 每个验证码只能验证一次，答错了需要重新获取
+	Verify(ctx context.Context, id, answer string) (bool, error)
+	// RequiredForSMS 同一个手机号或者同一个 IP 发送登录短信太多次之后，需要先通过图片验证码
+	// 每次调用都会计入发送次数
+	RequiredForSMS(ctx context.Context, phone, ip string) (bool, error)
+}
+
+type captchaService struct {
+	repo         repository.CaptchaRepository
+	gen          *captcha.Generator
+	phoneLimiter ratelimit.Limiter
+	ipLimiter    ratelimit.Limiter
+}
+
+func NewCaptchaService(repo repository.CaptchaRepository,
+	phoneLimiter ratelimit.Limiter,
+	ipLimiter ratelimit.Limiter) CaptchaService {
+	return &captchaService{
+		repo:         repo,
+		gen:          captcha.NewGenerator(),
+		phoneLimiter: phoneLimiter,
+		ipLimiter:    ipLimiter,
+	}
+}
+
+func (svc *captchaService) Generate(ctx context.Context) (domain.Captcha, error) {
+	answer := svc.gen.Digits()
+	img, err := svc.gen.Draw(answer)
+	if err != nil {
+		return domain.Captcha{}, err
+	}
+	id := uuid.New().String()
+	if err = svc.repo.Store(ctx, id, answer); err != nil {
+		return domain.Captcha{}, err
+	}
+	return domain.Captcha{Id: id, Image: img}, nil
+}
+
+func (svc *captchaService) Verify(ctx context.Context, id, answer string) (bool, error) {
+	if id == "" || answer == "" {
+		return false, nil
+	}
+	return svc.repo.Verify(ctx, id, answer)
+}
+
+func (svc *captchaService) RequiredForSMS(ctx context.Context, phone, ip string) (bool, error) {
+	// 两个都要调用，都计入次数
+	phoneLimited, err := svc.phoneLimiter.Limit(ctx, fmt.Sprintf("captcha:sms:phone:%s", phone))
+	if err != nil {
+		return false, err
+	}
+	ipLimited, err := svc.ipLimiter.Limit(ctx, fmt.Sprintf("captcha:sms:ip:%s", ip))
+	if err != nil {
+		return false, err
+	}
+	return phoneLimited || ipLimited, nil
+}
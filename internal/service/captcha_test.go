@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"xiaoweishu/internal/pkg/ratelimit"
+	limitmocks "xiaoweishu/internal/pkg/ratelimit/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_captchaService_RequiredForSMS(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (ratelimit.Limiter, ratelimit.Limiter)
+
+		wantRequired bool
+		wantErr      error
+	}{
+		{
+			name: "没有超过阈值",
+			mock: func(ctrl *gomock.Controller) (ratelimit.Limiter, ratelimit.Limiter) {
+				phoneLimiter := limitmocks.NewMockLimiter(ctrl)
+				phoneLimiter.EXPECT().Limit(gomock.Any(), "captcha:sms:phone:13800000000").Return(false, nil)
+				ipLimiter := limitmocks.NewMockLimiter(ctrl)
+				ipLimiter.EXPECT().Limit(gomock.Any(), "captcha:sms:ip:10.0.0.1").Return(false, nil)
+				return phoneLimiter, ipLimiter
+			},
+		},
+		{
+			name: "手机号超过阈值",
+			mock: func(ctrl *gomock.Controller) (ratelimit.Limiter, ratelimit.Limiter) {
+				phoneLimiter := limitmocks.NewMockLimiter(ctrl)
+				phoneLimiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(true, nil)
+				// IP 也要计入次数
+				ipLimiter := limitmocks.NewMockLimiter(ctrl)
+				ipLimiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				return phoneLimiter, ipLimiter
+			},
+			wantRequired: true,
+		},
+		{
+			name: "IP 超过阈值",
+			mock: func(ctrl *gomock.Controller) (ratelimit.Limiter, ratelimit.Limiter) {
+				phoneLimiter := limitmocks.NewMockLimiter(ctrl)
+				phoneLimiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil)
+				ipLimiter := limitmocks.NewMockLimiter(ctrl)
+				ipLimiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(true, nil)
+				return phoneLimiter, ipLimiter
+			},
+			wantRequired: true,
+		},
+		{
+			name: "限流器出错",
+			mock: func(ctrl *gomock.Controller) (ratelimit.Limiter, ratelimit.Limiter) {
+				phoneLimiter := limitmocks.NewMockLimiter(ctrl)
+				phoneLimiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, errors.New("mock error"))
+				return phoneLimiter, limitmocks.NewMockLimiter(ctrl)
+			},
+			wantErr: errors.New("mock error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			phoneLimiter, ipLimiter := tc.mock(ctrl)
+			svc := NewCaptchaService(nil, phoneLimiter, ipLimiter)
+			required, err := svc.RequiredForSMS(context.Background(), "13800000000", "10.0.0.1")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRequired, required)
+		})
+	}
+}
@@ -2,15 +2,18 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
-	"math/rand"
-	"time"
+	"math/big"
 	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/email"
 	"xiaoweishu/internal/service/sms"
 )
 
 const (
 	codeTplId = "1877556"
+	// codeExpireMinutes 和 set_code.lua 里面的过期时间保持一致
+	codeExpireMinutes = 10
 )
 
 var (
@@ -20,27 +23,35 @@ var (
 
 type CodeService interface {
 	Send(ctx context.Context, biz string, phone string) error
+	// SendEmail 通过邮件发送验证码，biz 同时也是邮件模板的 biz
+	// 验证的时候调用 Verify，phone 传邮箱
+	SendEmail(ctx context.Context, biz string, addr string) error
 	Verify(ctx context.Context, biz string, phone string, inputCode string) (bool, error)
 }
 
 type codeService struct {
-	repo   repository.CodeRepository
-	smsSvc sms.Service
+	repo     repository.CodeRepository
+	smsSvc   sms.Service
+	emailSvc email.Service
 }
 
-func NewCodeService(repo repository.CodeRepository, smsSvc sms.Service) CodeService {
+func NewCodeService(repo repository.CodeRepository, smsSvc sms.Service, emailSvc email.Service) CodeService {
 	return &codeService{
-		repo:   repo,
-		smsSvc: smsSvc,
+		repo:     repo,
+		smsSvc:   smsSvc,
+		emailSvc: emailSvc,
 	}
 }
 
 // Send 发送验证码 biz 区分业务场景
 func (c *codeService) Send(ctx context.Context, biz string, phone string) error {
 	// 生成验证码
-	vcode := c.generateCode()
+	vcode, err := c.generateCode()
+	if err != nil {
+		return err
+	}
 	// 放入redis
-	err := c.repo.Store(ctx, biz, phone, vcode)
+	err = c.repo.Store(ctx, biz, phone, vcode)
 	if err != nil {
 		return err
 	}
@@ -53,13 +64,32 @@ func (c *codeService) Send(ctx context.Context, biz string, phone string) error
 	return nil
 }
 
+func (c *codeService) SendEmail(ctx context.Context, biz string, addr string) error {
+	vcode, err := c.generateCode()
+	if err != nil {
+		return err
+	}
+	// 和短信共用同一套存储和发送频率限制
+	err = c.repo.Store(ctx, biz, addr, vcode)
+	if err != nil {
+		return err
+	}
+	return c.emailSvc.Send(ctx, biz, email.CodeData{
+		Code:    vcode,
+		Minutes: codeExpireMinutes,
+	}, addr)
+}
+
 // Verify 验证验证码 biz 区分业务场景
 func (c *codeService) Verify(ctx context.Context, biz string, phone string, inputCode string) (bool, error) {
 	return c.repo.Verify(ctx, biz, phone, inputCode)
 }
 
-func (c *codeService) generateCode() string {
-	randSeed := rand.New(rand.NewSource(time.Now().UnixNano()))
-	vcode := fmt.Sprintf("%06d", randSeed.Int31n(1000000))
-	return vcode
+// generateCode 验证码要用 crypto/rand 生成，math/rand 的结果可以被预测
+func (c *codeService) generateCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
 }
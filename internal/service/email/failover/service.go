@@ -0,0 +1,39 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"xiaoweishu/internal/service/email"
+)
+
+// FailoverEmailService 轮询多个邮件服务商，一个失败了就换下一个
+type FailoverEmailService struct {
+	svcs []email.Service
+	idx  uint64
+}
+
+func NewFailoverEmailService(svcs ...email.Service) email.Service {
+	return &FailoverEmailService{
+		svcs: svcs,
+	}
+}
+
+func (f *FailoverEmailService) Send(ctx context.Context, biz string, data any, to ...string) error {
+	idx := atomic.AddUint64(&f.idx, 1)
+	length := uint64(len(f.svcs))
+	for i := idx; i < idx+length; i++ {
+		svc := f.svcs[i%length]
+		err := svc.Send(ctx, biz, data, to...)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+			return err
+		default:
+			log.Printf("failover email send failed, err: %v\n", err)
+		}
+	}
+	return errors.New("all email send failed")
+}
@@ -0,0 +1,67 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"xiaoweishu/internal/service/email"
+	emailmocks "xiaoweishu/internal/service/email/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFailoverEmailService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) []email.Service
+		wantErr error
+	}{
+		{
+			name: "第一个失败换下一个",
+			mock: func(ctrl *gomock.Controller) []email.Service {
+				svc0 := emailmocks.NewMockService(ctrl)
+				svc1 := emailmocks.NewMockService(ctrl)
+				// 轮询从 idx 加一之后的位置开始，也就是 svc1
+				svc1.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(errors.New("网络错误"))
+				svc0.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(nil)
+				return []email.Service{svc0, svc1}
+			},
+		},
+		{
+			name: "全部失败",
+			mock: func(ctrl *gomock.Controller) []email.Service {
+				svc0 := emailmocks.NewMockService(ctrl)
+				svc1 := emailmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(errors.New("网络错误"))
+				svc0.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(errors.New("网络错误"))
+				return []email.Service{svc0, svc1}
+			},
+			wantErr: errors.New("all email send failed"),
+		},
+		{
+			name: "超时不再换下一个",
+			mock: func(ctrl *gomock.Controller) []email.Service {
+				svc0 := emailmocks.NewMockService(ctrl)
+				svc1 := emailmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(context.DeadlineExceeded)
+				return []email.Service{svc0, svc1}
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewFailoverEmailService(tc.mock(ctrl)...)
+			err := svc.Send(context.Background(), email.BizPasswordReset, email.CodeData{Code: "123456"}, "user@qq.com")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"xiaoweishu/internal/service/email"
+)
+
+// Service 不真正发送，只是把渲染好的邮件打印出来并保存在内存里，用于开发和测试
+type Service struct {
+	tpls *email.Templates
+
+	mu   sync.Mutex
+	msgs []email.Message
+}
+
+func NewService(tpls *email.Templates) *Service {
+	return &Service{
+		tpls: tpls,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, biz string, data any, to ...string) error {
+	msg, err := s.tpls.Render(biz, data, to...)
+	if err != nil {
+		return err
+	}
+	fmt.Println(msg.To, msg.Subject, msg.Text)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+// Messages 已经发送的邮件
+func (s *Service) Messages() []email.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]email.Message, len(s.msgs))
+	copy(res, s.msgs)
+	return res
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"xiaoweishu/internal/pkg/ratelimit"
+	"xiaoweishu/internal/service/email"
+)
+
+var errLimited = fmt.Errorf("触发了限流")
+
+type RatelimitEmailService struct {
+	svc     email.Service
+	limiter ratelimit.Limiter
+	key     string
+}
+
+// NewRatelimitEmailService key 区分不同的邮件服务商，同一个服务商的所有实例共享限流的额度
+func NewRatelimitEmailService(svc email.Service, limiter ratelimit.Limiter, key string) email.Service {
+	return &RatelimitEmailService{
+		svc:     svc,
+		limiter: limiter,
+		key:     key,
+	}
+}
+
+func (r *RatelimitEmailService) Send(ctx context.Context, biz string, data any, to ...string) error {
+	limited, err := r.limiter.Limit(ctx, "email:"+r.key)
+	if err != nil {
+		// 和短信一样采用保守策略，判断不了就不发
+		return fmt.Errorf("邮件服务判断是否限流出现错误: %w", err)
+	}
+	if limited {
+		return errLimited
+	}
+	return r.svc.Send(ctx, biz, data, to...)
+}
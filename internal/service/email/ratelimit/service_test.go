@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"xiaoweishu/internal/pkg/ratelimit"
+	limitmocks "xiaoweishu/internal/pkg/ratelimit/mocks"
+	"xiaoweishu/internal/service/email"
+	emailmocks "xiaoweishu/internal/service/email/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRatelimitEmailService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (email.Service, ratelimit.Limiter)
+		wantErr error
+	}{
+		{
+			name: "没有触发限流",
+			mock: func(ctrl *gomock.Controller) (email.Service, ratelimit.Limiter) {
+				svc := emailmocks.NewMockService(ctrl)
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "email:smtp").Return(false, nil)
+				svc.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").Return(nil)
+				return svc, limiter
+			},
+		},
+		{
+			name: "触发了限流",
+			mock: func(ctrl *gomock.Controller) (email.Service, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "email:smtp").Return(true, nil)
+				return emailmocks.NewMockService(ctrl), limiter
+			},
+			wantErr: errLimited,
+		},
+		{
+			name: "限流器出错不发送",
+			mock: func(ctrl *gomock.Controller) (email.Service, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "email:smtp").Return(false, errors.New("redis 错误"))
+				return emailmocks.NewMockService(ctrl), limiter
+			},
+			wantErr: fmt.Errorf("邮件服务判断是否限流出现错误: %w", errors.New("redis 错误")),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			emailSvc, limiter := tc.mock(ctrl)
+			svc := NewRatelimitEmailService(emailSvc, limiter, "smtp")
+			err := svc.Send(context.Background(), email.BizPasswordReset, email.CodeData{Code: "123456"}, "user@qq.com")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package retryable
+
+import (
+	"context"
+	"fmt"
+	"xiaoweishu/internal/service/email"
+)
+
+type Service struct {
+	svc      email.Service
+	retryCnt int
+}
+
+// NewService retryCnt 是包括第一次在内的总的发送次数
+func NewService(svc email.Service, retryCnt int) *Service {
+	return &Service{
+		svc:      svc,
+		retryCnt: retryCnt,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, biz string, data any, to ...string) error {
+	var err error
+	for i := 0; i < s.retryCnt; i++ {
+		err = s.svc.Send(ctx, biz, data, to...)
+		if err == nil {
+			return nil
+		}
+		// 超时或者取消了就没必要再重试
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("重试 %d 次之后发送邮件仍然失败: %w", s.retryCnt, err)
+}
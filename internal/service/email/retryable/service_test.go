@@ -0,0 +1,70 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"xiaoweishu/internal/service/email"
+	emailmocks "xiaoweishu/internal/service/email/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) email.Service
+		ctx     func() context.Context
+		wantErr error
+	}{
+		{
+			name: "重试之后成功",
+			mock: func(ctrl *gomock.Controller) email.Service {
+				svc := emailmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(errors.New("网络错误"))
+				svc.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(nil)
+				return svc
+			},
+			ctx: context.Background,
+		},
+		{
+			name: "重试次数用完",
+			mock: func(ctrl *gomock.Controller) email.Service {
+				svc := emailmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(errors.New("网络错误")).Times(3)
+				return svc
+			},
+			ctx:     context.Background,
+			wantErr: fmt.Errorf("重试 %d 次之后发送邮件仍然失败: %w", 3, errors.New("网络错误")),
+		},
+		{
+			name: "已经超时不再重试",
+			mock: func(ctrl *gomock.Controller) email.Service {
+				svc := emailmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), email.BizPasswordReset, gomock.Any(), "user@qq.com").
+					Return(context.Canceled)
+				return svc
+			},
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewService(tc.mock(ctrl), 3)
+			err := svc.Send(tc.ctx(), email.BizPasswordReset, email.CodeData{Code: "123456"}, "user@qq.com")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+	"xiaoweishu/internal/service/email"
+)
+
+type Config struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// From 发件人，比如 小微书 <noreply@example.com>
+	From string `yaml:"from"`
+}
+
+// Service 通过 SMTP 发送邮件，服务器支持 STARTTLS 的时候会先升级成 TLS 再认证
+type Service struct {
+	cfg  Config
+	from *mail.Address
+	tpls *email.Templates
+	// timeout ctx 没有设置超时的时候，整个发送过程的超时时间
+	timeout time.Duration
+}
+
+func NewService(cfg Config, tpls *email.Templates) (*Service, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("发件人 %s 不合法: %w", cfg.From, err)
+	}
+	return &Service{
+		cfg:     cfg,
+		from:    from,
+		tpls:    tpls,
+		timeout: time.Second * 10,
+	}, nil
+}
+
+func (s *Service) Send(ctx context.Context, biz string, data any, to ...string) error {
+	if len(to) == 0 {
+		return errors.New("没有收件人")
+	}
+	rcpts := make([]string, 0, len(to))
+	for _, addr := range to {
+		// 顺便防止收件人里面带换行，篡改邮件头
+		a, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("收件人 %s 不合法: %w", addr, err)
+		}
+		rcpts = append(rcpts, a.Address)
+	}
+	msg, err := s.tpls.Render(biz, data, rcpts...)
+	if err != nil {
+		return err
+	}
+	raw, err := s.build(msg)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, rcpts, raw)
+}
+
+func (s *Service) send(ctx context.Context, rcpts []string, raw []byte) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.timeout)
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ = c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		// PlainAuth 只允许在 TLS 或者本机的连接上发送密码
+		if err = c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err = c.Mail(s.from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range rcpts {
+		if err = c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(raw); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// build 生成 multipart/alternative 格式的邮件，纯文本在前，HTML 在后
+func (s *Service) build(msg email.Message) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{contentType: "text/plain; charset=utf-8", content: msg.Text},
+		{contentType: "text/html; charset=utf-8", content: msg.HTML},
+	}
+	for _, p := range parts {
+		if p.content == "" {
+			continue
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err = qw.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err = qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", s.from.String()},
+		{"To", strings.Join(msg.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	}
+	for _, h := range headers {
+		buf.WriteString(h[0] + ": " + h[1] + "\r\n")
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
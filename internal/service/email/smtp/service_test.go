@@ -0,0 +1,176 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+	"xiaoweishu/internal/service/email"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Send(t *testing.T) {
+	srv := newFakeSMTPServer(t)
+	defer srv.Close()
+
+	tpls, err := email.NewBuiltinTemplates()
+	require.NoError(t, err)
+	host, port := srv.addr()
+	svc, err := NewService(Config{
+		Host: host,
+		Port: port,
+		From: "小微书 <noreply@xiaoweishu.com>",
+	}, tpls)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = svc.Send(ctx, email.BizPasswordReset, email.CodeData{Code: "123456", Minutes: 10}, "user@qq.com")
+	require.NoError(t, err)
+
+	got := srv.wait(t)
+	assert.Equal(t, "noreply@xiaoweishu.com", got.from)
+	assert.Equal(t, []string{"user@qq.com"}, got.rcpts)
+
+	msg, err := mail.ReadMessage(strings.NewReader(got.data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "【小微书】找回密码验证码 123456", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	bodies := map[string]string{}
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(quotedprintable.NewReader(p))
+		require.NoError(t, err)
+		bodies[strings.Split(p.Header.Get("Content-Type"), ";")[0]] = string(data)
+	}
+	assert.Contains(t, bodies["text/plain"], "验证码是 123456，10 分钟内有效")
+	assert.Contains(t, bodies["text/html"], "<strong>123456</strong>")
+}
+
+func TestService_SendUnknownBiz(t *testing.T) {
+	svc, err := NewService(Config{Host: "127.0.0.1", Port: 1, From: "noreply@xiaoweishu.com"}, email.NewTemplates())
+	require.NoError(t, err)
+	err = svc.Send(context.Background(), "unknown", nil, "user@qq.com")
+	assert.Error(t, err)
+}
+
+// fakeSMTPServer 只实现了发送一封邮件需要的几个命令
+type fakeSMTPServer struct {
+	ln   net.Listener
+	mail chan receivedMail
+}
+
+type receivedMail struct {
+	from  string
+	rcpts []string
+	data  string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTPServer{
+		ln:   ln,
+		mail: make(chan receivedMail, 1),
+	}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) addr() (string, int) {
+	a := s.ln.Addr().(*net.TCPAddr)
+	return a.IP.String(), a.Port
+}
+
+func (s *fakeSMTPServer) Close() {
+	_ = s.ln.Close()
+}
+
+func (s *fakeSMTPServer) wait(t *testing.T) receivedMail {
+	select {
+	case m := <-s.mail:
+		return m
+	case <-time.After(time.Second * 5):
+		t.Fatal("没有收到邮件")
+		return receivedMail{}
+	}
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+	reply("220 fake smtp ready")
+	var m receivedMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250 fake")
+		case "MAIL":
+			m.from = strings.Trim(strings.TrimPrefix(line[len("MAIL "):], "FROM:"), "<> ")
+			reply("250 OK")
+		case "RCPT":
+			m.rcpts = append(m.rcpts, strings.Trim(strings.TrimPrefix(line[len("RCPT "):], "TO:"), "<> "))
+			reply("250 OK")
+		case "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				// 去掉 dot stuffing
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			m.data = data.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			s.mail <- m
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+const (
+	// BizPasswordReset 找回密码的验证码，模板数据见 CodeData
+	BizPasswordReset = "password_reset"
+	// BizSignupVerify 注册之后验证邮箱的验证码，模板数据见 CodeData
+	BizSignupVerify = "signup_verify"
+	// BizBindEmail 绑定邮箱的验证码，模板数据见 CodeData
+	BizBindEmail = "bind_email"
+	// BizAccountMerge 合并账号的验证码，模板数据见 CodeData
+	BizAccountMerge = "account_merge"
+	// BizLoginUnlock 登录失败太多次被锁定之后解锁的验证码，模板数据见 CodeData
+	BizLoginUnlock = "login_unlock"
+	// BizLoginStepUp 可疑登录二次验证的验证码，模板数据见 CodeData
+	BizLoginStepUp = "login_step_up"
+)
+
+// CodeData 验证码类邮件的模板数据
+type CodeData struct {
+	Code string
+	// Minutes 验证码的有效期
+	Minutes int
+}
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// Template 一个业务的邮件模板，Subject 和 Text 用 text/template 渲染，HTML 用 html/template 渲染
+type Template struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Templates 按照 biz 注册的邮件模板
+type Templates struct {
+	mu   sync.RWMutex
+	tpls map[string]compiledTemplate
+}
+
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+func NewTemplates() *Templates {
+	return &Templates{
+		tpls: make(map[string]compiledTemplate),
+	}
+}
+
+// NewBuiltinTemplates 注册了 templates 目录下所有内置业务的模板
+// 目录下每个业务有三个文件 biz.subject.txt、biz.html 和 biz.txt
+func NewBuiltinTemplates() (*Templates, error) {
+	t := NewTemplates()
+	for _, biz := range []string{BizPasswordReset, BizSignupVerify, BizBindEmail, BizAccountMerge, BizLoginUnlock, BizLoginStepUp} {
+		var tpl Template
+		for _, f := range []struct {
+			name string
+			dst  *string
+		}{
+			{name: biz + ".subject.txt", dst: &tpl.Subject},
+			{name: biz + ".html", dst: &tpl.HTML},
+			{name: biz + ".txt", dst: &tpl.Text},
+		} {
+			data, err := builtinTemplates.ReadFile("templates/" + f.name)
+			if err != nil {
+				return nil, err
+			}
+			*f.dst = string(data)
+		}
+		if err := t.Register(biz, tpl); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Register 注册模板，同一个 biz 重复注册会覆盖，HTML 和 Text 至少要有一个
+func (t *Templates) Register(biz string, tpl Template) error {
+	if tpl.HTML == "" && tpl.Text == "" {
+		return fmt.Errorf("邮件模板 %s 没有内容", biz)
+	}
+	var (
+		c   compiledTemplate
+		err error
+	)
+	// 主题只有一行，去掉文件末尾的换行
+	c.subject, err = texttemplate.New(biz + ".subject").Parse(strings.TrimSpace(tpl.Subject))
+	if err != nil {
+		return err
+	}
+	if tpl.HTML != "" {
+		c.html, err = htmltemplate.New(biz + ".html").Parse(tpl.HTML)
+		if err != nil {
+			return err
+		}
+	}
+	if tpl.Text != "" {
+		c.text, err = texttemplate.New(biz + ".text").Parse(tpl.Text)
+		if err != nil {
+			return err
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tpls[biz] = c
+	return nil
+}
+
+// Render 渲染邮件，biz 没有注册模板的时候返回错误
+func (t *Templates) Render(biz string, data any, to ...string) (Message, error) {
+	t.mu.RLock()
+	c, ok := t.tpls[biz]
+	t.mu.RUnlock()
+	if !ok {
+		return Message{}, fmt.Errorf("邮件模板 %s 不存在", biz)
+	}
+	msg := Message{To: to}
+	var buf bytes.Buffer
+	if err := c.subject.Execute(&buf, data); err != nil {
+		return Message{}, err
+	}
+	msg.Subject = buf.String()
+	if c.html != nil {
+		buf.Reset()
+		if err := c.html.Execute(&buf, data); err != nil {
+			return Message{}, err
+		}
+		msg.HTML = buf.String()
+	}
+	if c.text != nil {
+		buf.Reset()
+		if err := c.text.Execute(&buf, data); err != nil {
+			return Message{}, err
+		}
+		msg.Text = buf.String()
+	}
+	return msg, nil
+}
@@ -0,0 +1,17 @@
+package email
+
+import "context"
+
+type Service interface {
+	// Send 发送邮件，biz 对应通过 Templates.Register 注册的模板，data 用来渲染模板
+	Send(ctx context.Context, biz string, data any, to ...string) error
+}
+
+// Message 渲染好的一封邮件
+type Message struct {
+	To      []string
+	Subject string
+	// HTML 和 Text 是同一份内容的两种格式，客户端自己选择展示哪一种
+	HTML string
+	Text string
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/email"
+)
+
+var (
+	ErrEmailAlreadyVerified = errors.New("邮箱已经验证过了")
+	ErrEmailVerifyInvalid   = errors.New("邮箱验证码不对")
+)
+
+// emailVerifyPurgeBatch 每次删除的用户数，避免一次删除太多锁表
+const emailVerifyPurgeBatch = 100
+
+// EmailVerifyService 邮箱注册之后验证邮箱
+// 验证码和发送间隔复用 CodeService，同一个邮箱一分钟只能发一次
+type EmailVerifyService interface {
+	// SendCode 给用户的邮箱重新发送验证码，发送太频繁返回 ErrCodeSendTooMany
+	SendCode(ctx context.Context, uid int64) error
+	// Verify 校验验证码，通过之后用户变成已验证的状态
+	Verify(ctx context.Context, uid int64, code string) error
+	// PurgeUnverified 删除 before 之前注册并且一直没有验证邮箱的用户，返回删除的用户 id
+	// 出错的时候也返回已经删除的部分，调用方需要让这些用户的登录失效
+	PurgeUnverified(ctx context.Context, before time.Time) ([]int64, error)
+}
+
+type emailVerifyService struct {
+	repo    repository.UserRepository
+	codeSvc CodeService
+}
+
+func NewEmailVerifyService(repo repository.UserRepository, codeSvc CodeService) EmailVerifyService {
+	return &emailVerifyService{
+		repo:    repo,
+		codeSvc: codeSvc,
+	}
+}
+
+func (svc *emailVerifyService) SendCode(ctx context.Context, uid int64) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+	return svc.codeSvc.SendEmail(ctx, email.BizSignupVerify, u.Email)
+}
+
+func (svc *emailVerifyService) Verify(ctx context.Context, uid int64, code string) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+	ok, err := svc.codeSvc.Verify(ctx, email.BizSignupVerify, u.Email, code)
+	if errors.Is(err, repository.ErrCodeVerifyTooMany) {
+		// 验证次数用完了，只能重新发送
+		return ErrEmailVerifyInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrEmailVerifyInvalid
+	}
+	return svc.repo.MarkEmailVerified(ctx, uid)
+}
+
+func (svc *emailVerifyService) PurgeUnverified(ctx context.Context, before time.Time) ([]int64, error) {
+	var res []int64
+	for {
+		ids, err := svc.repo.DeleteUnverified(ctx, before, emailVerifyPurgeBatch)
+		res = append(res, ids...)
+		if err != nil {
+			return res, err
+		}
+		if len(ids) < emailVerifyPurgeBatch {
+			return res, nil
+		}
+	}
+}
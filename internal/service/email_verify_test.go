@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	"xiaoweishu/internal/service/email"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_emailVerifyService_Verify(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.UserRepository, CodeService)
+		wantErr error
+	}{
+		{
+			name: "验证成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+				repo.EXPECT().MarkEmailVerified(gomock.Any(), int64(1)).Return(nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), email.BizSignupVerify, "123@qq.com", "123456").Return(true, nil)
+				return repo, codeSvc
+			},
+		},
+		{
+			name: "已经验证过",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.User{Id: 1, Email: "123@qq.com", EmailVerified: true}, nil)
+				return repo, svcmocks.NewMockCodeService(ctrl)
+			},
+			wantErr: ErrEmailAlreadyVerified,
+		},
+		{
+			name: "验证码不对",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), email.BizSignupVerify, "123@qq.com", "123456").Return(false, nil)
+				return repo, codeSvc
+			},
+			wantErr: ErrEmailVerifyInvalid,
+		},
+		{
+			name: "验证次数用完",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), email.BizSignupVerify, "123@qq.com", "123456").
+					Return(false, repository.ErrCodeVerifyTooMany)
+				return repo, codeSvc
+			},
+			wantErr: ErrEmailVerifyInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, codeSvc := tc.mock(ctrl)
+			svc := NewEmailVerifyService(repo, codeSvc)
+			err := svc.Verify(context.Background(), 1, "123456")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_emailVerifyService_PurgeUnverified(t *testing.T) {
+	before := time.Now()
+	fullBatch := make([]int64, 0, emailVerifyPurgeBatch)
+	for i := 0; i < emailVerifyPurgeBatch; i++ {
+		fullBatch = append(fullBatch, int64(i+1))
+	}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UserRepository
+		wantIds []int64
+		wantErr error
+	}{
+		{
+			name: "分批删除",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				gomock.InOrder(
+					repo.EXPECT().DeleteUnverified(gomock.Any(), before, emailVerifyPurgeBatch).
+						Return(fullBatch, nil),
+					repo.EXPECT().DeleteUnverified(gomock.Any(), before, emailVerifyPurgeBatch).
+						Return([]int64{1001, 1002, 1003}, nil),
+				)
+				return repo
+			},
+			wantIds: append(append([]int64{}, fullBatch...), 1001, 1002, 1003),
+		},
+		{
+			name: "删除失败返回已经删除的部分",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				gomock.InOrder(
+					repo.EXPECT().DeleteUnverified(gomock.Any(), before, emailVerifyPurgeBatch).
+						Return(fullBatch, nil),
+					repo.EXPECT().DeleteUnverified(gomock.Any(), before, emailVerifyPurgeBatch).
+						Return(nil, errors.New("mock db error")),
+				)
+				return repo
+			},
+			wantIds: fullBatch,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewEmailVerifyService(tc.mock(ctrl), svcmocks.NewMockCodeService(ctrl))
+			ids, err := svc.PurgeUnverified(context.Background(), before)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
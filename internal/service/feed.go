@@ -0,0 +1,279 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+)
+
+const (
+	FeedFormatRSS  = "rss"
+	FeedFormatAtom = "atom"
+
+	feedAuthorSize  = 20
+	feedHotSize     = 50
+	feedAbstractLen = 128
+)
+
+var ErrUnknownFeedFormat = errors.New("未知的订阅格式")
+
+type FeedConfig struct {
+	// SiteName 站点名字，用于热榜的标题
+	SiteName string `yaml:"siteName"`
+	// BaseURL 生成文章链接使用，不要以 / 结尾
+	BaseURL string `yaml:"baseURL"`
+}
+
+// FeedService 生成 RSS 2.0 和 Atom 订阅
+type FeedService interface {
+	// AuthorFeed 作者最近发表的文章
+	AuthorFeed(ctx context.Context, authorId int64, format string) ([]byte, error)
+	// HotFeed 全站热榜
+	HotFeed(ctx context.Context, format string) ([]byte, error)
+}
+
+type feedService struct {
+	repo     repository.FeedRepository
+	artRepo  repository.ArticleRepository
+	userRepo repository.UserRepository
+	cfg      FeedConfig
+	l        logger.LoggerV1
+}
+
+func NewFeedService(repo repository.FeedRepository,
+	artRepo repository.ArticleRepository,
+	userRepo repository.UserRepository,
+	cfg FeedConfig,
+	l logger.LoggerV1) FeedService {
+	return &feedService{
+		repo:     repo,
+		artRepo:  artRepo,
+		userRepo: userRepo,
+		cfg:      cfg,
+		l:        l,
+	}
+}
+
+// feedChannel 渲染前的中间结构，RSS 和 Atom 共用
+type feedChannel struct {
+	id      string
+	title   string
+	link    string
+	author  string
+	updated time.Time
+	items   []domain.Article
+}
+
+func (f *feedService) AuthorFeed(ctx context.Context, authorId int64, format string) ([]byte, error) {
+	if format != FeedFormatRSS && format != FeedFormatAtom {
+		return nil, ErrUnknownFeedFormat
+	}
+	data, err := f.repo.GetAuthorFeed(ctx, format, authorId)
+	if err == nil {
+		return data, nil
+	}
+	u, err := f.userRepo.FindById(ctx, authorId)
+	if err != nil {
+		return nil, err
+	}
+	arts, err := f.artRepo.ListPublishedByAuthor(ctx, authorId, feedAuthorSize)
+	if err != nil {
+		return nil, err
+	}
+	name := u.NickName
+	if name == "" {
+		name = fmt.Sprintf("用户 %d", u.Id)
+	}
+	data, err = f.render(format, feedChannel{
+		id:     fmt.Sprintf("%s/users/%d", f.cfg.BaseURL, authorId),
+		title:  fmt.Sprintf("%s 的文章", name),
+		link:   fmt.Sprintf("%s/users/%d", f.cfg.BaseURL, authorId),
+		author: name,
+		items:  arts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = f.repo.SetAuthorFeed(ctx, format, authorId, data); err != nil {
+		f.l.Warn("缓存作者订阅失败", logger.Int64("author_id", authorId), logger.Error(err))
+	}
+	return data, nil
+}
+
+func (f *feedService) HotFeed(ctx context.Context, format string) ([]byte, error) {
+	if format != FeedFormatRSS && format != FeedFormatAtom {
+		return nil, ErrUnknownFeedFormat
+	}
+	data, err := f.repo.GetHotFeed(ctx, format)
+	if err == nil {
+		return data, nil
+	}
+	arts, err := f.artRepo.ListHot(ctx, feedHotSize)
+	if err != nil {
+		return nil, err
+	}
+	data, err = f.render(format, feedChannel{
+		id:     f.cfg.BaseURL + "/hot",
+		title:  f.cfg.SiteName + " 热榜",
+		link:   f.cfg.BaseURL + "/hot",
+		author: f.cfg.SiteName,
+		items:  arts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = f.repo.SetHotFeed(ctx, format, data); err != nil {
+		f.l.Warn("缓存热榜订阅失败", logger.Error(err))
+	}
+	return data, nil
+}
+
+func (f *feedService) render(format string, ch feedChannel) ([]byte, error) {
+	// 频道的更新时间取最新发表的一篇文章，修改旧文章不算频道有新内容
+	for _, art := range ch.items {
+		if art.Ctime.After(ch.updated) {
+			ch.updated = art.Ctime
+		}
+	}
+	if ch.updated.IsZero() {
+		ch.updated = time.Now()
+	}
+	var v any
+	if format == FeedFormatAtom {
+		v = f.toAtom(ch)
+	} else {
+		v = f.toRSS(ch)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	// encoding/xml 会负责转义标题和摘要中的特殊字符
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// articleLink 文章详情不需要登录就能看，订阅阅读器可以直接打开
+func (f *feedService) articleLink(art domain.Article) string {
+	return fmt.Sprintf("%s/articles/pub/%d", f.cfg.BaseURL, art.Id)
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Guid        rssGuid `xml:"guid"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (f *feedService) toRSS(ch feedChannel) rss {
+	items := make([]rssItem, 0, len(ch.items))
+	for _, art := range ch.items {
+		link := f.articleLink(art)
+		items = append(items, rssItem{
+			Title:       art.Title,
+			Link:        link,
+			Guid:        rssGuid{IsPermaLink: true, Value: link},
+			Description: abstract(art.Content),
+			PubDate:     art.Ctime.Format(time.RFC1123Z),
+		})
+	}
+	return rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         ch.title,
+			Link:          ch.link,
+			Description:   ch.title,
+			LastBuildDate: ch.updated.Format(time.RFC1123Z),
+			Items:         items,
+		},
+	}
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Id        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Link      atomLink `xml:"link"`
+	Summary   string   `xml:"summary"`
+}
+
+func (f *feedService) toAtom(ch feedChannel) atomFeed {
+	entries := make([]atomEntry, 0, len(ch.items))
+	for _, art := range ch.items {
+		link := f.articleLink(art)
+		entries = append(entries, atomEntry{
+			Id:        link,
+			Title:     art.Title,
+			Published: art.Ctime.Format(time.RFC3339),
+			Updated:   art.Utime.Format(time.RFC3339),
+			Link:      atomLink{Href: link, Rel: "alternate"},
+			Summary:   abstract(art.Content),
+		})
+	}
+	return atomFeed{
+		Id:      ch.id,
+		Title:   ch.title,
+		Updated: ch.updated.Format(time.RFC3339),
+		Link:    atomLink{Href: ch.link, Rel: "alternate"},
+		Author:  atomAuthor{Name: ch.author},
+		Entries: entries,
+	}
+}
+
+// abstract 取正文的前 feedAbstractLen 个字符作为摘要，连续的空白会被合并
+func abstract(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(content) <= feedAbstractLen {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:feedAbstractLen]) + "..."
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	repomocks "xiaoweishu/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_feedService_AuthorFeed(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	cfg := FeedConfig{SiteName: "小微书", BaseURL: "http://example.com"}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (*repomocks.MockFeedRepository, *repomocks.MockArticleRepository, *repomocks.MockUserRepository)
+		format  string
+		check   func(t *testing.T, data []byte)
+		wantErr error
+	}{
+		{
+			name: "命中缓存",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFeedRepository, *repomocks.MockArticleRepository, *repomocks.MockUserRepository) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().GetAuthorFeed(gomock.Any(), FeedFormatRSS, int64(123)).Return([]byte("cached"), nil)
+				return repo, repomocks.NewMockArticleRepository(ctrl), repomocks.NewMockUserRepository(ctrl)
+			},
+			format: FeedFormatRSS,
+			check: func(t *testing.T, data []byte) {
+				assert.Equal(t, "cached", string(data))
+			},
+		},
+		{
+			name: "生成 RSS，特殊字符被转义",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFeedRepository, *repomocks.MockArticleRepository, *repomocks.MockUserRepository) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().GetAuthorFeed(gomock.Any(), FeedFormatRSS, int64(123)).Return(nil, errors.New("缓存未命中"))
+				repo.EXPECT().SetAuthorFeed(gomock.Any(), FeedFormatRSS, int64(123), gomock.Any()).Return(nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{Id: 123, NickName: "Tom & Jerry"}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().ListPublishedByAuthor(gomock.Any(), int64(123), feedAuthorSize).Return([]domain.Article{
+					{
+						Id:      1,
+						Title:   "<script>alert(1)</script>",
+						Content: "a < b &&\n\n  b > c",
+						Ctime:   now.Add(-time.Hour),
+						Utime:   now,
+					},
+				}, nil)
+				return repo, artRepo, userRepo
+			},
+			format: FeedFormatRSS,
+			check: func(t *testing.T, data []byte) {
+				assert.False(t, strings.Contains(string(data), "<script>"))
+				var res rss
+				require.NoError(t, xml.Unmarshal(data, &res))
+				assert.Equal(t, "Tom & Jerry 的文章", res.Channel.Title)
+				require.Len(t, res.Channel.Items, 1)
+				item := res.Channel.Items[0]
+				assert.Equal(t, "<script>alert(1)</script>", item.Title)
+				assert.Equal(t, "a < b && b > c", item.Description)
+				assert.Equal(t, "http://example.com/articles/pub/1", item.Link)
+				// 发表时间和频道的更新时间都不受修改文章影响
+				assert.Equal(t, now.Add(-time.Hour).Format(time.RFC1123Z), item.PubDate)
+				assert.Equal(t, now.Add(-time.Hour).Format(time.RFC1123Z), res.Channel.LastBuildDate)
+			},
+		},
+		{
+			name: "生成 Atom，区分发表时间和修改时间",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFeedRepository, *repomocks.MockArticleRepository, *repomocks.MockUserRepository) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().GetAuthorFeed(gomock.Any(), FeedFormatAtom, int64(123)).Return(nil, errors.New("缓存未命中"))
+				repo.EXPECT().SetAuthorFeed(gomock.Any(), FeedFormatAtom, int64(123), gomock.Any()).Return(nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{Id: 123, NickName: "Tom"}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().ListPublishedByAuthor(gomock.Any(), int64(123), feedAuthorSize).Return([]domain.Article{
+					{
+						Id:    1,
+						Title: "标题",
+						Ctime: now.Add(-time.Hour),
+						Utime: now,
+					},
+				}, nil)
+				return repo, artRepo, userRepo
+			},
+			format: FeedFormatAtom,
+			check: func(t *testing.T, data []byte) {
+				var res atomFeed
+				require.NoError(t, xml.Unmarshal(data, &res))
+				assert.Equal(t, now.Add(-time.Hour).Format(time.RFC3339), res.Updated)
+				require.Len(t, res.Entries, 1)
+				entry := res.Entries[0]
+				assert.Equal(t, now.Add(-time.Hour).Format(time.RFC3339), entry.Published)
+				assert.Equal(t, now.Format(time.RFC3339), entry.Updated)
+			},
+		},
+		{
+			name: "作者不存在",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFeedRepository, *repomocks.MockArticleRepository, *repomocks.MockUserRepository) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().GetAuthorFeed(gomock.Any(), FeedFormatAtom, int64(123)).Return(nil, errors.New("缓存未命中"))
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{}, ErrUserNotFound)
+				return repo, repomocks.NewMockArticleRepository(ctrl), userRepo
+			},
+			format:  FeedFormatAtom,
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name: "未知格式",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFeedRepository, *repomocks.MockArticleRepository, *repomocks.MockUserRepository) {
+				return repomocks.NewMockFeedRepository(ctrl), repomocks.NewMockArticleRepository(ctrl), repomocks.NewMockUserRepository(ctrl)
+			},
+			format:  "json",
+			wantErr: ErrUnknownFeedFormat,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo, userRepo := tc.mock(ctrl)
+			svc := NewFeedService(repo, artRepo, userRepo, cfg, &logger.NopLogger{})
+			data, err := svc.AuthorFeed(context.Background(), 123, tc.format)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			tc.check(t, data)
+		})
+	}
+}
+
+func Test_abstract(t *testing.T) {
+	long := strings.Repeat("字", feedAbstractLen+10)
+	assert.Equal(t, strings.Repeat("字", feedAbstractLen)+"...", abstract(long))
+	assert.Equal(t, "a b", abstract("  a\n\t b "))
+}
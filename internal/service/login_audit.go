@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/email"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLoginChallengeNotFound = repository.ErrLoginChallengeNotFound
+	// ErrLoginChallengeCodeInvalid 二次验证的验证码不对
+	ErrLoginChallengeCodeInvalid = errors.New("验证码不对")
+)
+
+const (
+	// loginStepUpBiz 可疑登录二次验证的验证码，和登录验证码分开，短信和邮件共用一个 biz
+	loginStepUpBiz = email.BizLoginStepUp
+	// 一小时之内，上一次成功登录之后失败了这么多次，接下来的成功登录就是可疑的
+	suspiciousFailureWindow = time.Hour
+	suspiciousFailureCnt    = 5
+	loginHistoryLimit       = 50
+	loginLogPurgeBatchSize  = 1000
+)
+
+// 可疑登录的原因，会展示在登录记录里面
+const (
+	suspiciousReasonFailures  = "多次登录失败之后登录成功"
+	suspiciousReasonNewDevice = "新设备并且不在常用的地点"
+)
+
+// LoginAuditService 记录每一次登录尝试，发现可疑的登录要求短信或者邮件二次验证
+type LoginAuditService interface {
+	// RecordFailure 记录失败的登录，l.Uid 为 0 的时候根据 l.Account 找到对应的用户
+	RecordFailure(ctx context.Context, l domain.LoginLog) error
+	// RecordSuccess 两步验证通过之后记录成功的登录
+	RecordSuccess(ctx context.Context, l domain.LoginLog) error
+	// Check 密码或者验证码验证通过之后调用
+	// 正常的登录记录为成功，返回 false；可疑的登录给用户的手机发送验证码，返回 true 和二次验证
+	// 没有绑定手机号的时候发到验证过的邮箱，两个都没有的时候没有办法二次验证，只会提醒用户
+	// 开启了两步验证的用户记录为等待两步验证，不算成功的登录
+	Check(ctx context.Context, u domain.User, l domain.LoginLog) (domain.LoginChallenge, bool, error)
+	// VerifyChallenge 验证码正确之后记录为成功或者等待两步验证，调用方再签发 token
+	// userAgent 必须和发起登录的设备一致
+	VerifyChallenge(ctx context.Context, token, code, userAgent string) (domain.LoginChallenge, error)
+	// History 最近的登录记录
+	History(ctx context.Context, uid int64) ([]domain.LoginLog, error)
+	// PurgeExpired 删除 before 之前的登录记录，返回删除的数量
+	PurgeExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type loginAuditService struct {
+	repo      repository.LoginLogRepository
+	userRepo  repository.UserRepository
+	mfaRepo   repository.UserMFARepository
+	codeSvc   CodeService
+	notifySvc NotificationService
+	l         logger.LoggerV1
+}
+
+func NewLoginAuditService(repo repository.LoginLogRepository,
+	userRepo repository.UserRepository,
+	mfaRepo repository.UserMFARepository,
+	codeSvc CodeService,
+	notifySvc NotificationService,
+	l logger.LoggerV1) LoginAuditService {
+	return &loginAuditService{
+		repo:      repo,
+		userRepo:  userRepo,
+		mfaRepo:   mfaRepo,
+		codeSvc:   codeSvc,
+		notifySvc: notifySvc,
+		l:         l,
+	}
+}
+
+func (svc *loginAuditService) RecordFailure(ctx context.Context, l domain.LoginLog) error {
+	if l.Uid == 0 && l.Account != "" {
+		var (
+			u   domain.User
+			err error
+		)
+		if isEmail(l.Account) {
+			u, err = svc.userRepo.FindByEmail(ctx, l.Account)
+		} else {
+			u, err = svc.userRepo.FindByPhone(ctx, l.Account)
+		}
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return err
+		}
+		l.Uid = u.Id
+	}
+	l.Status = domain.LoginStatusFailed
+	return svc.create(ctx, l)
+}
+
+func (svc *loginAuditService) RecordSuccess(ctx context.Context, l domain.LoginLog) error {
+	l.Status = domain.LoginStatusSuccess
+	return svc.create(ctx, l)
+}
+
+func (svc *loginAuditService) Check(ctx context.Context, u domain.User, l domain.LoginLog) (domain.LoginChallenge, bool, error) {
+	l.Uid = u.Id
+	if l.Ctime.IsZero() {
+		l.Ctime = time.Now()
+	}
+	reason, err := svc.suspiciousReason(ctx, l)
+	if err != nil {
+		return domain.LoginChallenge{}, false, err
+	}
+	c := domain.LoginChallenge{
+		Token:     uuid.New().String(),
+		Uid:       u.Id,
+		Method:    l.Method,
+		IP:        l.IP,
+		UserAgent: l.UserAgent,
+		Reason:    reason,
+	}
+	switch {
+	case u.Phone != "":
+		c.Phone = u.Phone
+	case u.Email != "" && u.EmailVerified:
+		// 没有验证过的邮箱不一定是用户自己的，不能用来二次验证
+		c.Email = u.Email
+	}
+	if reason == "" || (c.Phone == "" && c.Email == "") {
+		l.Status, err = svc.passedStatus(ctx, u.Id)
+		if err != nil {
+			return domain.LoginChallenge{}, false, err
+		}
+		if reason != "" {
+			l.Reason = reason
+			svc.notifySuspicious(ctx, l)
+		}
+		return domain.LoginChallenge{}, false, svc.create(ctx, l)
+	}
+	l.Reason = reason
+	if c.Phone != "" {
+		err = svc.codeSvc.Send(ctx, loginStepUpBiz, c.Phone)
+	} else {
+		err = svc.codeSvc.SendEmail(ctx, loginStepUpBiz, c.Email)
+	}
+	if err != nil {
+		return domain.LoginChallenge{}, false, err
+	}
+	if err = svc.repo.SaveChallenge(ctx, c); err != nil {
+		return domain.LoginChallenge{}, false, err
+	}
+	l.Status = domain.LoginStatusChallenged
+	return c, true, svc.create(ctx, l)
+}
+
+// suspiciousReason 返回空字符串表示登录正常
+func (svc *loginAuditService) suspiciousReason(ctx context.Context, l domain.LoginLog) (string, error) {
+	cnt, err := svc.repo.CountFailedSinceSuccess(ctx, l.Uid, l.Ctime.Add(-suspiciousFailureWindow))
+	if err != nil {
+		return "", err
+	}
+	if cnt >= suspiciousFailureCnt {
+		return suspiciousReasonFailures, nil
+	}
+	f, err := svc.repo.Familiarity(ctx, l)
+	if err != nil {
+		return "", err
+	}
+	// 第一次登录没有可以比较的
+	if f.Total > 0 && f.SameDevice == 0 && f.SameRange == 0 {
+		return suspiciousReasonNewDevice, nil
+	}
+	return "", nil
+}
+
+func (svc *loginAuditService) notifySuspicious(ctx context.Context, l domain.LoginLog) {
+	err := svc.notifySvc.Notify(ctx, domain.Notification{
+		Uid: l.Uid,
+		Biz: domain.NotificationBizSuspiciousLogin,
+		Content: fmt.Sprintf("你的账号有一次可疑的登录（%s），设备 %s，IP %s，如果不是你本人操作，请立即修改密码并绑定手机号",
+			l.Reason, l.UserAgent, l.IP),
+	})
+	if err != nil {
+		svc.l.Error("发送可疑登录提醒失败", logger.Int64("uid", l.Uid), logger.Error(err))
+	}
+}
+
+func (svc *loginAuditService) VerifyChallenge(ctx context.Context, token, code, userAgent string) (domain.LoginChallenge, error) {
+	c, err := svc.repo.FindChallenge(ctx, token)
+	if err != nil {
+		return domain.LoginChallenge{}, err
+	}
+	if c.UserAgent != userAgent {
+		return domain.LoginChallenge{}, ErrLoginChallengeNotFound
+	}
+	target, reason := c.Phone, "短信二次验证通过"
+	if target == "" {
+		target, reason = c.Email, "邮件二次验证通过"
+	}
+	ok, err := svc.codeSvc.Verify(ctx, loginStepUpBiz, target, code)
+	if errors.Is(err, repository.ErrCodeVerifyTooMany) {
+		// 验证码已经作废了，只能重新登录
+		if err = svc.repo.DeleteChallenge(ctx, token); err != nil {
+			return domain.LoginChallenge{}, err
+		}
+		return domain.LoginChallenge{}, ErrLoginChallengeNotFound
+	}
+	if err != nil {
+		return domain.LoginChallenge{}, err
+	}
+	if !ok {
+		return domain.LoginChallenge{}, ErrLoginChallengeCodeInvalid
+	}
+	if err = svc.repo.DeleteChallenge(ctx, token); err != nil {
+		return domain.LoginChallenge{}, err
+	}
+	status, err := svc.passedStatus(ctx, c.Uid)
+	if err != nil {
+		return domain.LoginChallenge{}, err
+	}
+	return c, svc.create(ctx, domain.LoginLog{
+		Uid:       c.Uid,
+		Method:    c.Method,
+		Status:    status,
+		Reason:    reason,
+		IP:        c.IP,
+		UserAgent: c.UserAgent,
+	})
+}
+
+// passedStatus 验证通过之后的状态，开启了两步验证的还不算登录成功
+// 否则只知道密码的人也会留下成功的记录，他的设备和 IP 还会变成常用的
+func (svc *loginAuditService) passedStatus(ctx context.Context, uid int64) (domain.LoginStatus, error) {
+	m, err := svc.mfaRepo.FindByUid(ctx, uid)
+	if errors.Is(err, repository.ErrUserMFANotFound) {
+		return domain.LoginStatusSuccess, nil
+	}
+	if err != nil {
+		return domain.LoginStatusUnknown, err
+	}
+	if m.Enabled {
+		return domain.LoginStatusMFAPending, nil
+	}
+	return domain.LoginStatusSuccess, nil
+}
+
+func (svc *loginAuditService) History(ctx context.Context, uid int64) ([]domain.LoginLog, error) {
+	return svc.repo.ListByUid(ctx, uid, 0, loginHistoryLimit)
+}
+
+func (svc *loginAuditService) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		cnt, err := svc.repo.Purge(ctx, before, loginLogPurgeBatchSize)
+		total += cnt
+		if err != nil {
+			return total, err
+		}
+		if cnt < loginLogPurgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (svc *loginAuditService) create(ctx context.Context, l domain.LoginLog) error {
+	if l.Ctime.IsZero() {
+		l.Ctime = time.Now()
+	}
+	return svc.repo.Create(ctx, l)
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_loginAuditService_Check(t *testing.T) {
+	now := time.Now()
+	log := domain.LoginLog{
+		Method:    domain.LoginMethodEmail,
+		IP:        "10.0.0.1",
+		UserAgent: "Chrome",
+		Ctime:     now,
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.LoginLogRepository, CodeService, NotificationService)
+		user domain.User
+		// mfaEnabled 用户是否开启了两步验证
+		mfaEnabled bool
+
+		wantChallenged bool
+		wantErr        error
+	}{
+		{
+			name: "常用设备正常登录",
+			mock: func(ctrl *gomock.Controller) (repository.LoginLogRepository, CodeService, NotificationService) {
+				repo := repomocks.NewMockLoginLogRepository(ctrl)
+				repo.EXPECT().CountFailedSinceSuccess(gomock.Any(), int64(1), now.Add(-suspiciousFailureWindow)).Return(int64(1), nil)
+				repo.EXPECT().Familiarity(gomock.Any(), gomock.Any()).Return(domain.LoginFamiliarity{Total: 3, SameDevice: 1}, nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, l domain.LoginLog) error {
+					assert.Equal(t, domain.LoginStatusSuccess, l.Status)
+					return nil
+				})
+				return repo, nil, nil
+			},
+			user: domain.User{Id: 1, Phone: "13800000000"},
+		},
+		{
+			name: "第一次登录",
+			mock: func(ctrl *gomock.Controller) (repository.LoginLogRepository, CodeService, NotificationService) {
+				repo := repomocks.NewMockLoginLogRepository(ctrl)
+				repo.EXPECT().CountFailedSinceSuccess(gomock.Any(), int64(1), gomock.Any()).Return(int64(0), nil)
+				repo.EXPECT().Familiarity(gomock.Any(), gomock.Any()).Return(domain.LoginFamiliarity{}, nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				return repo, nil, nil
+			},
+			user: domain.User{Id: 1, Phone: "13800000000"},
+		},
+		{
+			name: "多次失败之后登录，需要二次验证",
+			mock: func(ctrl *gomock.Controller) (repository.LoginLogRepository, CodeService, NotificationService) {
+				repo := repomocks.NewMockLoginLogRepository(ctrl)
+				repo.EXPECT().CountFailedSinceSuccess(gomock.Any(), int64(1), gomock.Any()).Return(int64(suspiciousFailureCnt), nil)
+				repo.EXPECT().SaveChallenge(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, c domain.LoginChallenge) error {
+					assert.Equal(t, int64(1), c.Uid)
+					assert.Equal(t, "Chrome", c.UserAgent)
+					assert.Equal(t, suspiciousReasonFailures, c.Reason)
+					return nil
+				})
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, l domain.LoginLog) error {
+					assert.Equal(t, domain.LoginStatusChallenged, l.Status)
+					return nil
+				})
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), loginStepUpBiz, "13800000000").Return(nil)
+				return repo, codeSvc, nil
+			},
+			user:           domain.User{Id: 1, Phone: "13800000000"},
+			wantChallenged: true,
+		},
+		{
+			name: "新设备新地点，没有手机号，邮箱也没有验证过，只提醒",
+			mock: func(ctrl *gomock.Controller) (repository.LoginLogRepository, CodeService, NotificationService) {
+				repo := repomocks.NewMockLoginLogRepository(ctrl)
+				repo.EXPECT().CountFailedSinceSuccess(gomock.Any(), int64(1), gomock.Any()).Return(int64(0), nil)
+				repo.EXPECT().Familiarity(gomock.Any(), gomock.Any()).Return(domain.LoginFamiliarity{Total: 3}, nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, l domain.LoginLog) error {
+					assert.Equal(t, domain.LoginStatusSuccess, l.Status)
+					assert.Equal(t, suspiciousReasonNewDevice, l.Reason)
+					return nil
+				})
+				notifySvc := svcmocks.NewMockNotificationService(ctrl)
+				notifySvc.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, n domain.Notification) error {
+					assert.Equal(t, domain.NotificationBizSuspiciousLogin, n.Biz)
+					return nil
+				})
+				return repo, nil, notifySvc
+			},
+			user: domain.User{Id: 1, Email: "a@qq.com"},
+		},
+		{
+			name: "没有手机号，发到验证过的邮箱",
+			mock: func(ctrl *gomock.Controller) (repository.LoginLogRepository, CodeService, NotificationService) {
+				repo := repomocks.NewMockLoginLogRepository(ctrl)
+				repo.EXPECT().CountFailedSinceSuccess(gomock.Any(), int64(1), gomock.Any()).Return(int64(0), nil)
+				repo.EXPECT().Familiarity(gomock.Any(), gomock.Any()).Return(domain.LoginFamiliarity{Total: 3}, nil)
+				repo.EXPECT().SaveChallenge(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, c domain.LoginChallenge) error {
+					assert.Equal(t, "", c.Phone)
+					assert.Equal(t, "a@qq.com", c.Email)
+					return nil
+				})
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, l domain.LoginLog) error {
+					assert.Equal(t, domain.LoginStatusChallenged, l.Status)
+					return nil
+				})
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().SendEmail(gomock.Any(), loginStepUpBiz, "a@qq.com").Return(nil)
+				return repo, codeSvc, nil
+			},
+			user:           domain.User{Id: 1, Email: "a@qq.com", EmailVerified: true},
+			wantChallenged: true,
+		},
+		{
+			name: "开启了两步验证，还不算登录成功",
+			mock: func(ctrl *gomock.Controller) (repository.LoginLogRepository, CodeService, NotificationService) {
+				repo := repomocks.NewMockLoginLogRepository(ctrl)
+				repo.EXPECT().CountFailedSinceSuccess(gomock.Any(), int64(1), gomock.Any()).Return(int64(0), nil)
+				repo.EXPECT().Familiarity(gomock.Any(), gomock.Any()).Return(domain.LoginFamiliarity{Total: 3, SameDevice: 1}, nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, l domain.LoginLog) error {
+					assert.Equal(t, domain.LoginStatusMFAPending, l.Status)
+					return nil
+				})
+				return repo, nil, nil
+			},
+			user:       domain.User{Id: 1, Phone: "13800000000"},
+			mfaEnabled: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, codeSvc, notifySvc := tc.mock(ctrl)
+			mfaRepo := repomocks.NewMockUserMFARepository(ctrl)
+			if tc.mfaEnabled {
+				mfaRepo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(domain.UserMFA{Uid: 1, Enabled: true}, nil).AnyTimes()
+			} else {
+				mfaRepo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(domain.UserMFA{}, repository.ErrUserMFANotFound).AnyTimes()
+			}
+			svc := NewLoginAuditService(repo, nil, mfaRepo, codeSvc, notifySvc, &logger.NopLogger{})
+			c, challenged, err := svc.Check(context.Background(), tc.user, log)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantChallenged, challenged)
+			if challenged {
+				assert.NotEmpty(t, c.Token)
+			}
+		})
+	}
+}
+
+func Test_loginAuditService_VerifyChallenge(t *testing.T) {
+	testCases := []struct {
+		name      string
+		challenge domain.LoginChallenge
+		// target 校验验证码用的手机号或者邮箱
+		target     string
+		wantReason string
+	}{
+		{
+			name:       "短信验证码",
+			challenge:  domain.LoginChallenge{Token: "abc", Uid: 1, Phone: "13800000000", UserAgent: "Chrome"},
+			target:     "13800000000",
+			wantReason: "短信二次验证通过",
+		},
+		{
+			name:       "邮件验证码",
+			challenge:  domain.LoginChallenge{Token: "abc", Uid: 1, Email: "a@qq.com", UserAgent: "Chrome"},
+			target:     "a@qq.com",
+			wantReason: "邮件二次验证通过",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := repomocks.NewMockLoginLogRepository(ctrl)
+			repo.EXPECT().FindChallenge(gomock.Any(), "abc").Return(tc.challenge, nil)
+			repo.EXPECT().DeleteChallenge(gomock.Any(), "abc").Return(nil)
+			repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, l domain.LoginLog) error {
+				assert.Equal(t, domain.LoginStatusSuccess, l.Status)
+				assert.Equal(t, tc.wantReason, l.Reason)
+				return nil
+			})
+			codeSvc := svcmocks.NewMockCodeService(ctrl)
+			codeSvc.EXPECT().Verify(gomock.Any(), loginStepUpBiz, tc.target, "123456").Return(true, nil)
+			mfaRepo := repomocks.NewMockUserMFARepository(ctrl)
+			mfaRepo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(domain.UserMFA{}, repository.ErrUserMFANotFound)
+			svc := NewLoginAuditService(repo, nil, mfaRepo, codeSvc, nil, &logger.NopLogger{})
+			c, err := svc.VerifyChallenge(context.Background(), "abc", "123456", "Chrome")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.challenge, c)
+		})
+	}
+}
+
+func Test_loginAuditService_PurgeExpired(t *testing.T) {
+	before := time.UnixMilli(1700000000000)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.LoginLogRepository
+
+		wantCnt int64
+		wantErr error
+	}{
+		{
+			name: "分批删除直到删完",
+			mock: func(ctrl *gomock.Controller) repository.LoginLogRepository {
+				repo := repomocks.NewMockLoginLogRepository(ctrl)
+				repo.EXPECT().Purge(gomock.Any(), before, loginLogPurgeBatchSize).Return(int64(loginLogPurgeBatchSize), nil)
+				repo.EXPECT().Purge(gomock.Any(), before, loginLogPurgeBatchSize).Return(int64(3), nil)
+				return repo
+			},
+			wantCnt: loginLogPurgeBatchSize + 3,
+		},
+		{
+			name: "删除失败",
+			mock: func(ctrl *gomock.Controller) repository.LoginLogRepository {
+				repo := repomocks.NewMockLoginLogRepository(ctrl)
+				repo.EXPECT().Purge(gomock.Any(), before, loginLogPurgeBatchSize).Return(int64(0), errors.New("mock db 错误"))
+				return repo
+			},
+			wantErr: errors.New("mock db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewLoginAuditService(tc.mock(ctrl), nil, nil, nil, nil, &logger.NopLogger{})
+			cnt, err := svc.PurgeExpired(context.Background(), before)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
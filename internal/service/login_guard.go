@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/email"
+)
+
+var (
+	// ErrLoginTooFrequent 失败之后还没有到下一次可以尝试的时间，或者这个 IP 失败太多次
+	ErrLoginTooFrequent = errors.New("登录太频繁")
+	ErrLoginLocked      = errors.New("登录失败次数太多，账号已经被锁定")
+	// ErrLoginUnlockInvalid 账号不存在和验证码不对都返回这个错误，避免泄露账号是否存在
+	ErrLoginUnlockInvalid = errors.New("账号或者验证码不对")
+)
+
+const (
+	// 连续失败这么多次之后，每次失败都要等待更久才能再试
+	loginDelayAfter = 3
+	loginMaxDelay   = time.Second * 30
+	// 连续失败这么多次之后锁定账号
+	loginLockAfter    = 10
+	loginLockDuration = time.Minute * 30
+	// 同一个 IP 在统计窗口里面最多失败这么多次，防止换着账号猜
+	loginIPMaxFailures = 100
+	// loginUnlockBiz 短信和邮件的解锁验证码共用一个 biz，邮件模板也是这个 biz
+	loginUnlockBiz = email.BizLoginUnlock
+	// loginUnlockSendTimeout 异步发送解锁验证码的超时时间
+	loginUnlockSendTimeout = time.Second * 10
+)
+
+// LoginGuardService 防止暴力猜测密码
+// 按照输入的邮箱统计，账号不存在也一样延迟和锁定，没有办法通过返回结果判断账号是否存在
+type LoginGuardService interface {
+	// Allow 校验密码之前调用，被拒绝的时候同时返回还需要等待多久
+	Allow(ctx context.Context, account, ip string) (time.Duration, error)
+	// Fail 密码不对的时候调用，失败次数到了上限会锁定账号
+	Fail(ctx context.Context, account, ip string) error
+	// Succeed 登录成功之后清空失败次数
+	Succeed(ctx context.Context, account string) error
+	// SendUnlockCode 给账号的邮箱或者手机号发送解锁验证码，channel 取值见 domain.LoginUnlockChannelXXX
+	// 账号不存在、没有绑定对应的联系方式或者发送太频繁也返回 nil，
+	// 验证码是异步发送的，响应时间也看不出账号是否存在
+	SendUnlockCode(ctx context.Context, account, channel string) error
+	// Unlock 验证码正确之后解除锁定并清空失败次数
+	Unlock(ctx context.Context, account, channel, code string) error
+}
+
+type loginGuardService struct {
+	repo     repository.LoginGuardRepository
+	userRepo repository.UserRepository
+	codeSvc  CodeService
+	l        logger.LoggerV1
+}
+
+func NewLoginGuardService(repo repository.LoginGuardRepository,
+	userRepo repository.UserRepository,
+	codeSvc CodeService,
+	l logger.LoggerV1) LoginGuardService {
+	return &loginGuardService{
+		repo:     repo,
+		userRepo: userRepo,
+		codeSvc:  codeSvc,
+		l:        l,
+	}
+}
+
+func (svc *loginGuardService) Allow(ctx context.Context, account, ip string) (time.Duration, error) {
+	g, err := svc.repo.Get(ctx, normalizeLoginAccount(account), ip)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	if g.LockedUntil.After(now) {
+		return g.LockedUntil.Sub(now), ErrLoginLocked
+	}
+	if g.IPFailures >= loginIPMaxFailures {
+		return loginMaxDelay, ErrLoginTooFrequent
+	}
+	if wait := g.LastFailure.Add(loginDelay(g.Failures)).Sub(now); wait > 0 {
+		return wait, ErrLoginTooFrequent
+	}
+	return 0, nil
+}
+
+// loginDelay 从 1 秒开始每多失败一次翻倍，最多 loginMaxDelay
+func loginDelay(failures int64) time.Duration {
+	if failures < loginDelayAfter {
+		return 0
+	}
+	d := time.Second << (failures - loginDelayAfter)
+	if d <= 0 || d > loginMaxDelay {
+		return loginMaxDelay
+	}
+	return d
+}
+
+func (svc *loginGuardService) Fail(ctx context.Context, account, ip string) error {
+	account = normalizeLoginAccount(account)
+	cnt, err := svc.repo.IncrFailure(ctx, account, ip, time.Now())
+	if err != nil {
+		return err
+	}
+	if cnt < loginLockAfter {
+		return nil
+	}
+	return svc.repo.Lock(ctx, account, loginLockDuration)
+}
+
+func (svc *loginGuardService) Succeed(ctx context.Context, account string) error {
+	return svc.repo.Reset(ctx, normalizeLoginAccount(account))
+}
+
+func (svc *loginGuardService) SendUnlockCode(ctx context.Context, account, channel string) error {
+	u, err := svc.userRepo.FindByEmail(ctx, account)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if channel == domain.LoginUnlockChannelSMS && u.Phone == "" {
+		return nil
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), loginUnlockSendTimeout)
+		defer cancel()
+		var err error
+		if channel == domain.LoginUnlockChannelSMS {
+			err = svc.codeSvc.Send(ctx, loginUnlockBiz, u.Phone)
+		} else {
+			err = svc.codeSvc.SendEmail(ctx, loginUnlockBiz, u.Email)
+		}
+		// 发送太频繁也不能告诉调用者，不然就能知道账号存在
+		if err != nil && !errors.Is(err, ErrCodeSendTooMany) {
+			svc.l.Error("发送登录解锁验证码失败", logger.Int64("uid", u.Id), logger.Error(err))
+		}
+	}()
+	return nil
+}
+
+func (svc *loginGuardService) Unlock(ctx context.Context, account, channel, code string) error {
+	u, err := svc.userRepo.FindByEmail(ctx, account)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrLoginUnlockInvalid
+	}
+	if err != nil {
+		return err
+	}
+	target := u.Email
+	if channel == domain.LoginUnlockChannelSMS {
+		target = u.Phone
+	}
+	if target == "" {
+		return ErrLoginUnlockInvalid
+	}
+	ok, err := svc.codeSvc.Verify(ctx, loginUnlockBiz, target, code)
+	if errors.Is(err, repository.ErrCodeVerifyTooMany) {
+		return ErrLoginUnlockInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLoginUnlockInvalid
+	}
+	return svc.repo.Reset(ctx, normalizeLoginAccount(account))
+}
+
+// normalizeLoginAccount 邮箱不区分大小写，换个大小写不能绕过限制
+func normalizeLoginAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
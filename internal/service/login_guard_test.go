@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_loginGuardService_Allow(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name  string
+		guard domain.LoginGuard
+
+		wantErr error
+		// wantWait 大于 0 的时候只检查不超过这个值
+		wantWait time.Duration
+	}{
+		{
+			name:  "失败次数不多",
+			guard: domain.LoginGuard{Failures: loginDelayAfter - 1, LastFailure: now},
+		},
+		{
+			name:     "失败之后还没有到等待时间",
+			guard:    domain.LoginGuard{Failures: loginDelayAfter + 2, LastFailure: now},
+			wantErr:  ErrLoginTooFrequent,
+			wantWait: time.Second * 4,
+		},
+		{
+			name:  "已经过了等待时间",
+			guard: domain.LoginGuard{Failures: loginDelayAfter + 2, LastFailure: now.Add(-time.Second * 5)},
+		},
+		{
+			name:     "账号被锁定",
+			guard:    domain.LoginGuard{LockedUntil: now.Add(time.Minute)},
+			wantErr:  ErrLoginLocked,
+			wantWait: time.Minute,
+		},
+		{
+			name:     "IP 失败太多次",
+			guard:    domain.LoginGuard{IPFailures: loginIPMaxFailures},
+			wantErr:  ErrLoginTooFrequent,
+			wantWait: loginMaxDelay,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := repomocks.NewMockLoginGuardRepository(ctrl)
+			// 邮箱统一转成小写
+			repo.EXPECT().Get(gomock.Any(), "a@qq.com", "10.0.0.1").Return(tc.guard, nil)
+			svc := NewLoginGuardService(repo, nil, nil, &logger.NopLogger{})
+			wait, err := svc.Allow(context.Background(), "A@qq.com", "10.0.0.1")
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantWait > 0 {
+				assert.True(t, wait > 0 && wait <= tc.wantWait)
+			} else {
+				assert.Equal(t, time.Duration(0), wait)
+			}
+		})
+	}
+}
+
+func Test_loginGuardService_Fail(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.LoginGuardRepository
+	}{
+		{
+			name: "没有到上限",
+			mock: func(ctrl *gomock.Controller) repository.LoginGuardRepository {
+				repo := repomocks.NewMockLoginGuardRepository(ctrl)
+				repo.EXPECT().IncrFailure(gomock.Any(), "a@qq.com", "10.0.0.1", gomock.Any()).Return(int64(loginLockAfter-1), nil)
+				return repo
+			},
+		},
+		{
+			name: "到了上限锁定账号",
+			mock: func(ctrl *gomock.Controller) repository.LoginGuardRepository {
+				repo := repomocks.NewMockLoginGuardRepository(ctrl)
+				repo.EXPECT().IncrFailure(gomock.Any(), "a@qq.com", "10.0.0.1", gomock.Any()).Return(int64(loginLockAfter), nil)
+				repo.EXPECT().Lock(gomock.Any(), "a@qq.com", loginLockDuration).Return(nil)
+				return repo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewLoginGuardService(tc.mock(ctrl), nil, nil, &logger.NopLogger{})
+			err := svc.Fail(context.Background(), " a@qq.com", "10.0.0.1")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_loginGuardService_SendUnlockCode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		channel string
+		mock    func(ctrl *gomock.Controller, sent chan struct{}) (repository.UserRepository, CodeService)
+		// wantSent 是否会异步发送验证码
+		wantSent bool
+	}{
+		{
+			name: "账号不存在",
+			mock: func(ctrl *gomock.Controller, sent chan struct{}) (repository.UserRepository, CodeService) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "a@qq.com").Return(domain.User{}, repository.ErrUserNotFound)
+				return userRepo, svcmocks.NewMockCodeService(ctrl)
+			},
+		},
+		{
+			name:    "没有绑定手机号",
+			channel: domain.LoginUnlockChannelSMS,
+			mock: func(ctrl *gomock.Controller, sent chan struct{}) (repository.UserRepository, CodeService) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "a@qq.com").Return(domain.User{Id: 1, Email: "a@qq.com"}, nil)
+				return userRepo, svcmocks.NewMockCodeService(ctrl)
+			},
+		},
+		{
+			name: "发送太频繁也返回 nil",
+			mock: func(ctrl *gomock.Controller, sent chan struct{}) (repository.UserRepository, CodeService) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "a@qq.com").Return(domain.User{Id: 1, Email: "a@qq.com"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().SendEmail(gomock.Any(), loginUnlockBiz, "a@qq.com").
+					DoAndReturn(func(ctx context.Context, biz, target string) error {
+						close(sent)
+						return ErrCodeSendTooMany
+					})
+				return userRepo, codeSvc
+			},
+			wantSent: true,
+		},
+		{
+			name:    "发送短信",
+			channel: domain.LoginUnlockChannelSMS,
+			mock: func(ctrl *gomock.Controller, sent chan struct{}) (repository.UserRepository, CodeService) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "a@qq.com").Return(domain.User{Id: 1, Email: "a@qq.com", Phone: "13800000000"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), loginUnlockBiz, "13800000000").
+					DoAndReturn(func(ctx context.Context, biz, target string) error {
+						close(sent)
+						return nil
+					})
+				return userRepo, codeSvc
+			},
+			wantSent: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			sent := make(chan struct{})
+			userRepo, codeSvc := tc.mock(ctrl, sent)
+			svc := NewLoginGuardService(nil, userRepo, codeSvc, &logger.NopLogger{})
+			err := svc.SendUnlockCode(context.Background(), "a@qq.com", tc.channel)
+			assert.NoError(t, err)
+			if !tc.wantSent {
+				return
+			}
+			select {
+			case <-sent:
+			case <-time.After(time.Second):
+				t.Fatal("没有发送验证码")
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ratelimit"
+	"xiaoweishu/internal/pkg/totp"
+	"xiaoweishu/internal/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrMFAEnabled = repository.ErrUserMFAEnabled
+	// ErrMFANotEnrolled 还没有开始绑定就确认
+	ErrMFANotEnrolled = errors.New("还没有绑定两步验证")
+	ErrMFANotEnabled  = errors.New("没有开启两步验证")
+	ErrMFACodeInvalid = errors.New("两步验证码不对")
+	// ErrMFAPendingNotFound 两步验证已经过期或者错误次数太多，需要重新登录
+	ErrMFAPendingNotFound = repository.ErrMFAPendingNotFound
+	// ErrMFADisableTooMany 关闭两步验证尝试太多次，防止拿到登录态的人暴力猜测密码和验证码
+	ErrMFADisableTooMany = errors.New("关闭两步验证太频繁")
+)
+
+const (
+	mfaIssuer = "小微书"
+	// 恢复码的个数，每个恢复码 10 个字符，50 位随机数
+	mfaRecoveryCodeCnt = 10
+	mfaRecoveryCodeLen = 10
+	// 一次登录最多尝试这么多次两步验证码
+	mfaMaxAttempts = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAService TOTP 两步验证
+type MFAService interface {
+	// Enroll 生成新的密钥，返回密钥和给验证器应用扫描的 otpauth 地址
+	// 确认之前可以重复调用，每次都会换一个密钥
+	Enroll(ctx context.Context, uid int64) (string, string, error)
+	// Confirm 用验证器应用生成的第一个验证码确认，开启两步验证并返回恢复码
+	// 恢复码只在这里返回一次，服务端只保存哈希
+	Confirm(ctx context.Context, uid int64, code string) ([]string, error)
+	Enabled(ctx context.Context, uid int64) (bool, error)
+	// StartLogin 第一步验证通过之后调用，返回的 Token 用来完成第二步
+	StartLogin(ctx context.Context, p domain.MFAPending) (domain.MFAPending, error)
+	// VerifyLogin code 可以是验证码也可以是恢复码，通过之后调用方再签发 token
+	// userAgent 必须和发起登录的设备一致
+	// 验证码不对或者尝试次数用完的时候也返回等待验证的登录，调用方用来记录失败
+	VerifyLogin(ctx context.Context, token, code, userAgent string) (domain.MFAPending, error)
+	// Disable 关闭两步验证，需要重新验证身份：设置了密码的要输入密码，同时还要输入验证码或者恢复码
+	// 同一个用户尝试太多次返回 ErrMFADisableTooMany
+	Disable(ctx context.Context, uid int64, password, code string) error
+}
+
+type mfaService struct {
+	repo           repository.UserMFARepository
+	userRepo       repository.UserRepository
+	disableLimiter ratelimit.Limiter
+}
+
+func NewMFAService(repo repository.UserMFARepository,
+	userRepo repository.UserRepository,
+	disableLimiter ratelimit.Limiter) MFAService {
+	return &mfaService{
+		repo:           repo,
+		userRepo:       userRepo,
+		disableLimiter: disableLimiter,
+	}
+}
+
+func (svc *mfaService) Enroll(ctx context.Context, uid int64) (string, string, error) {
+	u, err := svc.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err = svc.repo.SaveSecret(ctx, uid, secret); err != nil {
+		return "", "", err
+	}
+	// 验证器应用里面显示的账号名
+	account := u.Email
+	if account == "" {
+		account = u.Phone
+	}
+	if account == "" {
+		account = strconv.FormatInt(uid, 10)
+	}
+	return secret, totp.URI(mfaIssuer, account, secret), nil
+}
+
+func (svc *mfaService) Confirm(ctx context.Context, uid int64, code string) ([]string, error) {
+	m, err := svc.repo.FindByUid(ctx, uid)
+	if errors.Is(err, repository.ErrUserMFANotFound) {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.Enabled {
+		return nil, ErrMFAEnabled
+	}
+	step, ok := totp.Validate(m.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrMFACodeInvalid
+	}
+	codes := make([]string, 0, mfaRecoveryCodeCnt)
+	hashes := make([]string, 0, mfaRecoveryCodeCnt)
+	for i := 0; i < mfaRecoveryCodeCnt; i++ {
+		c, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+		hashes = append(hashes, hashRecoveryCode(c))
+	}
+	if err = svc.repo.Enable(ctx, uid, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (svc *mfaService) Enabled(ctx context.Context, uid int64) (bool, error) {
+	m, err := svc.repo.FindByUid(ctx, uid)
+	if errors.Is(err, repository.ErrUserMFANotFound) {
+		return false, nil
+	}
+	return m.Enabled, err
+}
+
+func (svc *mfaService) StartLogin(ctx context.Context, p domain.MFAPending) (domain.MFAPending, error) {
+	p.Token = uuid.New().String()
+	return p, svc.repo.SavePending(ctx, p)
+}
+
+func (svc *mfaService) VerifyLogin(ctx context.Context, token, code, userAgent string) (domain.MFAPending, error) {
+	p, err := svc.repo.FindPending(ctx, token)
+	if err != nil {
+		return domain.MFAPending{}, err
+	}
+	if p.UserAgent != userAgent {
+		return domain.MFAPending{}, ErrMFAPendingNotFound
+	}
+	cnt, err := svc.repo.IncrPendingAttempts(ctx, token)
+	if err != nil {
+		return domain.MFAPending{}, err
+	}
+	if cnt > mfaMaxAttempts {
+		// 不能对着同一次登录一直猜，只能重新输入密码
+		if err = svc.repo.DeletePending(ctx, token); err != nil {
+			return domain.MFAPending{}, err
+		}
+		return p, ErrMFAPendingNotFound
+	}
+	m, err := svc.repo.FindByUid(ctx, p.Uid)
+	if errors.Is(err, repository.ErrUserMFANotFound) {
+		// 等待验证的时候在别的设备上关闭了两步验证
+		return domain.MFAPending{}, ErrMFAPendingNotFound
+	}
+	if err != nil {
+		return domain.MFAPending{}, err
+	}
+	if err = svc.verify(ctx, m, code); err != nil {
+		return p, err
+	}
+	return p, svc.repo.DeletePending(ctx, token)
+}
+
+func (svc *mfaService) Disable(ctx context.Context, uid int64, password, code string) error {
+	m, err := svc.repo.FindByUid(ctx, uid)
+	if errors.Is(err, repository.ErrUserMFANotFound) || (err == nil && !m.Enabled) {
+		return ErrMFANotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	// 不管密码和验证码对不对都算一次
+	limited, err := svc.disableLimiter.Limit(ctx, fmt.Sprintf("mfa-disable:%d", uid))
+	if err != nil {
+		return err
+	}
+	if limited {
+		return ErrMFADisableTooMany
+	}
+	u, err := svc.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.Password != "" && bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) != nil {
+		return ErrInvalidUserOrPassword
+	}
+	if err = svc.verify(ctx, m, code); err != nil {
+		return err
+	}
+	return svc.repo.Delete(ctx, uid)
+}
+
+// verify 6 位数字按照验证码校验，其它的按照恢复码校验，用过的都会作废
+func (svc *mfaService) verify(ctx context.Context, m domain.UserMFA, code string) error {
+	if !m.Enabled {
+		return ErrMFANotEnabled
+	}
+	code = strings.TrimSpace(code)
+	var (
+		ok  bool
+		err error
+	)
+	if len(code) == totp.Digits {
+		step, valid := totp.Validate(m.Secret, code, time.Now())
+		if !valid {
+			return ErrMFACodeInvalid
+		}
+		ok, err = svc.repo.UseStep(ctx, m.Uid, step)
+	} else {
+		ok, err = svc.repo.UseRecoveryCode(ctx, m.Uid, hashRecoveryCode(code))
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMFACodeInvalid
+	}
+	return nil
+}
+
+// newRecoveryCode 形如 abcde-fghij，方便抄写
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	s := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:mfaRecoveryCodeLen]
+	return s[:mfaRecoveryCodeLen/2] + "-" + s[mfaRecoveryCodeLen/2:], nil
+}
+
+// hashRecoveryCode 忽略大小写和分隔符
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
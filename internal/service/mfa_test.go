@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ratelimit"
+	limitmocks "xiaoweishu/internal/pkg/ratelimit/mocks"
+	"xiaoweishu/internal/pkg/totp"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_mfaService_VerifyLogin(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := totp.Code(secret, totp.Step(now))
+	require.NoError(t, err)
+	pending := domain.MFAPending{Token: "abc", Uid: 1, Method: domain.LoginMethodEmail, UserAgent: "Chrome"}
+	mfa := domain.UserMFA{Uid: 1, Secret: secret, Enabled: true}
+
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) repository.UserMFARepository
+		code      string
+		userAgent string
+
+		// wantUid 失败的时候也要返回等待验证的用户，用来记录登录失败
+		wantUid int64
+		wantErr error
+	}{
+		{
+			name: "验证码正确",
+			mock: func(ctrl *gomock.Controller) repository.UserMFARepository {
+				repo := repomocks.NewMockUserMFARepository(ctrl)
+				repo.EXPECT().FindPending(gomock.Any(), "abc").Return(pending, nil)
+				repo.EXPECT().IncrPendingAttempts(gomock.Any(), "abc").Return(int64(1), nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(mfa, nil)
+				repo.EXPECT().UseStep(gomock.Any(), int64(1), gomock.Any()).Return(true, nil)
+				repo.EXPECT().DeletePending(gomock.Any(), "abc").Return(nil)
+				return repo
+			},
+			code:      code,
+			userAgent: "Chrome",
+			wantUid:   1,
+		},
+		{
+			name: "验证码已经用过了",
+			mock: func(ctrl *gomock.Controller) repository.UserMFARepository {
+				repo := repomocks.NewMockUserMFARepository(ctrl)
+				repo.EXPECT().FindPending(gomock.Any(), "abc").Return(pending, nil)
+				repo.EXPECT().IncrPendingAttempts(gomock.Any(), "abc").Return(int64(1), nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(mfa, nil)
+				repo.EXPECT().UseStep(gomock.Any(), int64(1), gomock.Any()).Return(false, nil)
+				return repo
+			},
+			code:      code,
+			userAgent: "Chrome",
+			wantUid:   1,
+			wantErr:   ErrMFACodeInvalid,
+		},
+		{
+			name: "使用恢复码",
+			mock: func(ctrl *gomock.Controller) repository.UserMFARepository {
+				repo := repomocks.NewMockUserMFARepository(ctrl)
+				repo.EXPECT().FindPending(gomock.Any(), "abc").Return(pending, nil)
+				repo.EXPECT().IncrPendingAttempts(gomock.Any(), "abc").Return(int64(1), nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(mfa, nil)
+				// 大小写和分隔符不影响
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(1), hashRecoveryCode("abcdefghij")).Return(true, nil)
+				repo.EXPECT().DeletePending(gomock.Any(), "abc").Return(nil)
+				return repo
+			},
+			code:      "ABCDE-fghij",
+			userAgent: "Chrome",
+			wantUid:   1,
+		},
+		{
+			name: "换了设备",
+			mock: func(ctrl *gomock.Controller) repository.UserMFARepository {
+				repo := repomocks.NewMockUserMFARepository(ctrl)
+				repo.EXPECT().FindPending(gomock.Any(), "abc").Return(pending, nil)
+				return repo
+			},
+			code:      code,
+			userAgent: "Firefox",
+			wantErr:   ErrMFAPendingNotFound,
+		},
+		{
+			name: "错误次数太多",
+			mock: func(ctrl *gomock.Controller) repository.UserMFARepository {
+				repo := repomocks.NewMockUserMFARepository(ctrl)
+				repo.EXPECT().FindPending(gomock.Any(), "abc").Return(pending, nil)
+				repo.EXPECT().IncrPendingAttempts(gomock.Any(), "abc").Return(int64(mfaMaxAttempts+1), nil)
+				repo.EXPECT().DeletePending(gomock.Any(), "abc").Return(nil)
+				return repo
+			},
+			code:      code,
+			userAgent: "Chrome",
+			wantUid:   1,
+			wantErr:   ErrMFAPendingNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewMFAService(tc.mock(ctrl), nil, nil)
+			p, err := svc.VerifyLogin(context.Background(), "abc", tc.code, tc.userAgent)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUid, p.Uid)
+			if err == nil {
+				assert.Equal(t, pending, p)
+			}
+		})
+	}
+}
+
+func Test_mfaService_Confirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+
+	repo := repomocks.NewMockUserMFARepository(ctrl)
+	repo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(domain.UserMFA{Uid: 1, Secret: secret}, nil)
+	var hashes []string
+	repo.EXPECT().Enable(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, uid int64, step int64, codeHashes []string) error {
+			hashes = codeHashes
+			return nil
+		})
+	svc := NewMFAService(repo, nil, nil)
+	codes, err := svc.Confirm(context.Background(), 1, code)
+	require.NoError(t, err)
+	require.Len(t, codes, mfaRecoveryCodeCnt)
+	// 只保存哈希
+	for i, c := range codes {
+		assert.Equal(t, hashRecoveryCode(c), hashes[i])
+		assert.NotEqual(t, c, hashes[i])
+	}
+}
+
+func Test_mfaService_Disable(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.UserMFARepository, repository.UserRepository, ratelimit.Limiter)
+		code    string
+		wantErr error
+	}{
+		{
+			name: "尝试太多次",
+			mock: func(ctrl *gomock.Controller) (repository.UserMFARepository, repository.UserRepository, ratelimit.Limiter) {
+				repo := repomocks.NewMockUserMFARepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(domain.UserMFA{Uid: 1, Secret: secret, Enabled: true}, nil)
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "mfa-disable:1").Return(true, nil)
+				return repo, repomocks.NewMockUserRepository(ctrl), limiter
+			},
+			code:    "000000",
+			wantErr: ErrMFADisableTooMany,
+		},
+		{
+			name: "验证码不对",
+			mock: func(ctrl *gomock.Controller) (repository.UserMFARepository, repository.UserRepository, ratelimit.Limiter) {
+				repo := repomocks.NewMockUserMFARepository(ctrl)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(domain.UserMFA{Uid: 1, Secret: secret, Enabled: true}, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(1), gomock.Any()).Return(false, nil)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.User{Id: 1}, nil)
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "mfa-disable:1").Return(false, nil)
+				return repo, userRepo, limiter
+			},
+			code:    "abcde-fghij",
+			wantErr: ErrMFACodeInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, userRepo, limiter := tc.mock(ctrl)
+			svc := NewMFAService(repo, userRepo, limiter)
+			err := svc.Disable(context.Background(), 1, "", tc.code)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
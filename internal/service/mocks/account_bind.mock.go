@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/account_bind.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/account_bind.go -package=svcmocks -destination=./internal/service/mocks/account_bind.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAccountBindService is a mock of AccountBindService interface.
+type MockAccountBindService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountBindServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAccountBindServiceMockRecorder is the mock recorder for MockAccountBindService.
+type MockAccountBindServiceMockRecorder struct {
+	mock *MockAccountBindService
+}
+
+// NewMockAccountBindService creates a new mock instance.
+func NewMockAccountBindService(ctrl *gomock.Controller) *MockAccountBindService {
+	mock := &MockAccountBindService{ctrl: ctrl}
+	mock.recorder = &MockAccountBindServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountBindService) EXPECT() *MockAccountBindServiceMockRecorder {
+	return m.recorder
+}
+
+// BindEmail mocks base method.
+func (m *MockAccountBindService) BindEmail(ctx context.Context, uid int64, addr, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BindEmail", ctx, uid, addr, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BindEmail indicates an expected call of BindEmail.
+func (mr *MockAccountBindServiceMockRecorder) BindEmail(ctx, uid, addr, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindEmail", reflect.TypeOf((*MockAccountBindService)(nil).BindEmail), ctx, uid, addr, code)
+}
+
+// BindPhone mocks base method.
+func (m *MockAccountBindService) BindPhone(ctx context.Context, uid int64, phone, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BindPhone", ctx, uid, phone, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BindPhone indicates an expected call of BindPhone.
+func (mr *MockAccountBindServiceMockRecorder) BindPhone(ctx, uid, phone, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindPhone", reflect.TypeOf((*MockAccountBindService)(nil).BindPhone), ctx, uid, phone, code)
+}
+
+// BindWechat mocks base method.
+func (m *MockAccountBindService) BindWechat(ctx context.Context, uid int64, info domain.WechatInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BindWechat", ctx, uid, info)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BindWechat indicates an expected call of BindWechat.
+func (mr *MockAccountBindServiceMockRecorder) BindWechat(ctx, uid, info any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindWechat", reflect.TypeOf((*MockAccountBindService)(nil).BindWechat), ctx, uid, info)
+}
+
+// SendEmailCode mocks base method.
+func (m *MockAccountBindService) SendEmailCode(ctx context.Context, uid int64, addr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailCode", ctx, uid, addr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmailCode indicates an expected call of SendEmailCode.
+func (mr *MockAccountBindServiceMockRecorder) SendEmailCode(ctx, uid, addr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailCode", reflect.TypeOf((*MockAccountBindService)(nil).SendEmailCode), ctx, uid, addr)
+}
+
+// SendPhoneCode mocks base method.
+func (m *MockAccountBindService) SendPhoneCode(ctx context.Context, uid int64, phone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPhoneCode", ctx, uid, phone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendPhoneCode indicates an expected call of SendPhoneCode.
+func (mr *MockAccountBindServiceMockRecorder) SendPhoneCode(ctx, uid, phone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPhoneCode", reflect.TypeOf((*MockAccountBindService)(nil).SendPhoneCode), ctx, uid, phone)
+}
+
+// Unbind mocks base method.
+func (m *MockAccountBindService) Unbind(ctx context.Context, uid int64, method string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unbind", ctx, uid, method)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unbind indicates an expected call of Unbind.
+func (mr *MockAccountBindServiceMockRecorder) Unbind(ctx, uid, method any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unbind", reflect.TypeOf((*MockAccountBindService)(nil).Unbind), ctx, uid, method)
+}
@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/account_data.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/account_data.go -package=svcmocks -destination=./internal/service/mocks/account_data.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAccountDataService is a mock of AccountDataService interface.
+type MockAccountDataService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountDataServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAccountDataServiceMockRecorder is the mock recorder for MockAccountDataService.
+type MockAccountDataServiceMockRecorder struct {
+	mock *MockAccountDataService
+}
+
+// NewMockAccountDataService creates a new mock instance.
+func NewMockAccountDataService(ctrl *gomock.Controller) *MockAccountDataService {
+	mock := &MockAccountDataService{ctrl: ctrl}
+	mock.recorder = &MockAccountDataServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountDataService) EXPECT() *MockAccountDataServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelDeletion mocks base method.
+func (m *MockAccountDataService) CancelDeletion(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockAccountDataServiceMockRecorder) CancelDeletion(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockAccountDataService)(nil).CancelDeletion), ctx, uid)
+}
+
+// Delete mocks base method.
+func (m *MockAccountDataService) Delete(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAccountDataServiceMockRecorder) Delete(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAccountDataService)(nil).Delete), ctx, uid)
+}
+
+// Export mocks base method.
+func (m *MockAccountDataService) Export(ctx context.Context, uid int64, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, uid, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockAccountDataServiceMockRecorder) Export(ctx, uid, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockAccountDataService)(nil).Export), ctx, uid, w)
+}
+
+// ListDueDeletion mocks base method.
+func (m *MockAccountDataService) ListDueDeletion(ctx context.Context, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueDeletion", ctx, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueDeletion indicates an expected call of ListDueDeletion.
+func (mr *MockAccountDataServiceMockRecorder) ListDueDeletion(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueDeletion", reflect.TypeOf((*MockAccountDataService)(nil).ListDueDeletion), ctx, limit)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockAccountDataService) ScheduleDeletion(ctx context.Context, uid int64) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", ctx, uid)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockAccountDataServiceMockRecorder) ScheduleDeletion(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockAccountDataService)(nil).ScheduleDeletion), ctx, uid)
+}
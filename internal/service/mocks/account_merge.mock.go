@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/account_merge.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/account_merge.go -package=svcmocks -destination=./internal/service/mocks/account_merge.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAccountMergeService is a mock of AccountMergeService interface.
+type MockAccountMergeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountMergeServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAccountMergeServiceMockRecorder is the mock recorder for MockAccountMergeService.
+type MockAccountMergeServiceMockRecorder struct {
+	mock *MockAccountMergeService
+}
+
+// NewMockAccountMergeService creates a new mock instance.
+func NewMockAccountMergeService(ctrl *gomock.Controller) *MockAccountMergeService {
+	mock := &MockAccountMergeService{ctrl: ctrl}
+	mock.recorder = &MockAccountMergeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountMergeService) EXPECT() *MockAccountMergeServiceMockRecorder {
+	return m.recorder
+}
+
+// ListLogs mocks base method.
+func (m *MockAccountMergeService) ListLogs(ctx context.Context, uid int64) ([]domain.UserMergeLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLogs", ctx, uid)
+	ret0, _ := ret[0].([]domain.UserMergeLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLogs indicates an expected call of ListLogs.
+func (mr *MockAccountMergeServiceMockRecorder) ListLogs(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLogs", reflect.TypeOf((*MockAccountMergeService)(nil).ListLogs), ctx, uid)
+}
+
+// Merge mocks base method.
+func (m *MockAccountMergeService) Merge(ctx context.Context, uid int64, target, code, password string) (domain.UserMergeLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", ctx, uid, target, code, password)
+	ret0, _ := ret[0].(domain.UserMergeLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockAccountMergeServiceMockRecorder) Merge(ctx, uid, target, code, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockAccountMergeService)(nil).Merge), ctx, uid, target, code, password)
+}
+
+// MergeWechat mocks base method.
+func (m *MockAccountMergeService) MergeWechat(ctx context.Context, uid int64, info domain.WechatInfo) (domain.UserMergeLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeWechat", ctx, uid, info)
+	ret0, _ := ret[0].(domain.UserMergeLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeWechat indicates an expected call of MergeWechat.
+func (mr *MockAccountMergeServiceMockRecorder) MergeWechat(ctx, uid, info any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeWechat", reflect.TypeOf((*MockAccountMergeService)(nil).MergeWechat), ctx, uid, info)
+}
+
+// SendCode mocks base method.
+func (m *MockAccountMergeService) SendCode(ctx context.Context, uid int64, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCode", ctx, uid, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendCode indicates an expected call of SendCode.
+func (mr *MockAccountMergeServiceMockRecorder) SendCode(ctx, uid, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCode", reflect.TypeOf((*MockAccountMergeService)(nil).SendCode), ctx, uid, target)
+}
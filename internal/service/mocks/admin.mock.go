@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/admin.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/admin.go -package=svcmocks -destination=./internal/service/mocks/admin.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAdminService is a mock of AdminService interface.
+type MockAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockAdminServiceMockRecorder is the mock recorder for MockAdminService.
+type MockAdminServiceMockRecorder struct {
+	mock *MockAdminService
+}
+
+// NewMockAdminService creates a new mock instance.
+func NewMockAdminService(ctrl *gomock.Controller) *MockAdminService {
+	mock := &MockAdminService{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// AuditLogs mocks base method.
+func (m *MockAdminService) AuditLogs(ctx context.Context, operatorId int64, offset, limit int) ([]domain.AdminAuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLogs", ctx, operatorId, offset, limit)
+	ret0, _ := ret[0].([]domain.AdminAuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLogs indicates an expected call of AuditLogs.
+func (mr *MockAdminServiceMockRecorder) AuditLogs(ctx, operatorId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLogs", reflect.TypeOf((*MockAdminService)(nil).AuditLogs), ctx, operatorId, offset, limit)
+}
+
+// BanUser mocks base method.
+func (m *MockAdminService) BanUser(ctx context.Context, op domain.AdminOperator, uid int64, ban domain.UserBan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanUser", ctx, op, uid, ban)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BanUser indicates an expected call of BanUser.
+func (mr *MockAdminServiceMockRecorder) BanUser(ctx, op, uid, ban any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanUser", reflect.TypeOf((*MockAdminService)(nil).BanUser), ctx, op, uid, ban)
+}
+
+// HideArticle mocks base method.
+func (m *MockAdminService) HideArticle(ctx context.Context, op domain.AdminOperator, id int64, hidden bool, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HideArticle", ctx, op, id, hidden, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HideArticle indicates an expected call of HideArticle.
+func (mr *MockAdminServiceMockRecorder) HideArticle(ctx, op, id, hidden, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HideArticle", reflect.TypeOf((*MockAdminService)(nil).HideArticle), ctx, op, id, hidden, reason)
+}
+
+// SearchUsers mocks base method.
+func (m *MockAdminService) SearchUsers(ctx context.Context, op domain.AdminOperator, keyword string, offset, limit int) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, op, keyword, offset, limit)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockAdminServiceMockRecorder) SearchUsers(ctx, op, keyword, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockAdminService)(nil).SearchUsers), ctx, op, keyword, offset, limit)
+}
+
+// UnbanUser mocks base method.
+func (m *MockAdminService) UnbanUser(ctx context.Context, op domain.AdminOperator, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanUser", ctx, op, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanUser indicates an expected call of UnbanUser.
+func (mr *MockAdminServiceMockRecorder) UnbanUser(ctx, op, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanUser", reflect.TypeOf((*MockAdminService)(nil).UnbanUser), ctx, op, uid)
+}
+
+// ViewArticle mocks base method.
+func (m *MockAdminService) ViewArticle(ctx context.Context, op domain.AdminOperator, id int64) (domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewArticle", ctx, op, id)
+	ret0, _ := ret[0].(domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ViewArticle indicates an expected call of ViewArticle.
+func (mr *MockAdminServiceMockRecorder) ViewArticle(ctx, op, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewArticle", reflect.TypeOf((*MockAdminService)(nil).ViewArticle), ctx, op, id)
+}
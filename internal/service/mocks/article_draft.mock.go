@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/article_draft.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/article_draft.go -package=svcmocks -destination=./internal/service/mocks/article_draft.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleDraftService is a mock of ArticleDraftService interface.
+type MockArticleDraftService struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleDraftServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockArticleDraftServiceMockRecorder is the mock recorder for MockArticleDraftService.
+type MockArticleDraftServiceMockRecorder struct {
+	mock *MockArticleDraftService
+}
+
+// NewMockArticleDraftService creates a new mock instance.
+func NewMockArticleDraftService(ctrl *gomock.Controller) *MockArticleDraftService {
+	mock := &MockArticleDraftService{ctrl: ctrl}
+	mock.recorder = &MockArticleDraftServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleDraftService) EXPECT() *MockArticleDraftServiceMockRecorder {
+	return m.recorder
+}
+
+// Autosave mocks base method.
+func (m *MockArticleDraftService) Autosave(ctx context.Context, art domain.Article) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Autosave", ctx, art)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Autosave indicates an expected call of Autosave.
+func (mr *MockArticleDraftServiceMockRecorder) Autosave(ctx, art any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Autosave", reflect.TypeOf((*MockArticleDraftService)(nil).Autosave), ctx, art)
+}
+
+// Discard mocks base method.
+func (m *MockArticleDraftService) Discard(ctx context.Context, id, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Discard", ctx, id, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Discard indicates an expected call of Discard.
+func (mr *MockArticleDraftServiceMockRecorder) Discard(ctx, id, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discard", reflect.TypeOf((*MockArticleDraftService)(nil).Discard), ctx, id, uid)
+}
+
+// FlushDirty mocks base method.
+func (m *MockArticleDraftService) FlushDirty(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlushDirty", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlushDirty indicates an expected call of FlushDirty.
+func (mr *MockArticleDraftServiceMockRecorder) FlushDirty(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushDirty", reflect.TypeOf((*MockArticleDraftService)(nil).FlushDirty), ctx)
+}
+
+// Open mocks base method.
+func (m *MockArticleDraftService) Open(ctx context.Context, id, uid int64) (domain.Article, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, id, uid)
+	ret0, _ := ret[0].(domain.Article)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockArticleDraftServiceMockRecorder) Open(ctx, id, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockArticleDraftService)(nil).Open), ctx, id, uid)
+}
@@ -54,6 +54,20 @@ func (mr *MockCodeServiceMockRecorder) Send(ctx, biz, phone any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockCodeService)(nil).Send), ctx, biz, phone)
 }
 
+// SendEmail mocks base method.
+func (m *MockCodeService) SendEmail(ctx context.Context, biz, addr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmail", ctx, biz, addr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmail indicates an expected call of SendEmail.
+func (mr *MockCodeServiceMockRecorder) SendEmail(ctx, biz, addr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmail", reflect.TypeOf((*MockCodeService)(nil).SendEmail), ctx, biz, addr)
+}
+
 // Verify mocks base method.
 func (m *MockCodeService) Verify(ctx context.Context, biz, phone, inputCode string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/email_verify.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/email_verify.go -package=svcmocks -destination=./internal/service/mocks/email_verify.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEmailVerifyService is a mock of EmailVerifyService interface.
+type MockEmailVerifyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailVerifyServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockEmailVerifyServiceMockRecorder is the mock recorder for MockEmailVerifyService.
+type MockEmailVerifyServiceMockRecorder struct {
+	mock *MockEmailVerifyService
+}
+
+// NewMockEmailVerifyService creates a new mock instance.
+func NewMockEmailVerifyService(ctrl *gomock.Controller) *MockEmailVerifyService {
+	mock := &MockEmailVerifyService{ctrl: ctrl}
+	mock.recorder = &MockEmailVerifyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailVerifyService) EXPECT() *MockEmailVerifyServiceMockRecorder {
+	return m.recorder
+}
+
+// PurgeUnverified mocks base method.
+func (m *MockEmailVerifyService) PurgeUnverified(ctx context.Context, before time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUnverified", ctx, before)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeUnverified indicates an expected call of PurgeUnverified.
+func (mr *MockEmailVerifyServiceMockRecorder) PurgeUnverified(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUnverified", reflect.TypeOf((*MockEmailVerifyService)(nil).PurgeUnverified), ctx, before)
+}
+
+// SendCode mocks base method.
+func (m *MockEmailVerifyService) SendCode(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCode", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendCode indicates an expected call of SendCode.
+func (mr *MockEmailVerifyServiceMockRecorder) SendCode(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCode", reflect.TypeOf((*MockEmailVerifyService)(nil).SendCode), ctx, uid)
+}
+
+// Verify mocks base method.
+func (m *MockEmailVerifyService) Verify(ctx context.Context, uid int64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, uid, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockEmailVerifyServiceMockRecorder) Verify(ctx, uid, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockEmailVerifyService)(nil).Verify), ctx, uid, code)
+}
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/feed.go -package=svcmocks -destination=./internal/service/mocks/feed.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedService is a mock of FeedService interface.
+type MockFeedService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockFeedServiceMockRecorder is the mock recorder for MockFeedService.
+type MockFeedServiceMockRecorder struct {
+	mock *MockFeedService
+}
+
+// NewMockFeedService creates a new mock instance.
+func NewMockFeedService(ctrl *gomock.Controller) *MockFeedService {
+	mock := &MockFeedService{ctrl: ctrl}
+	mock.recorder = &MockFeedServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedService) EXPECT() *MockFeedServiceMockRecorder {
+	return m.recorder
+}
+
+// AuthorFeed mocks base method.
+func (m *MockFeedService) AuthorFeed(ctx context.Context, authorId int64, format string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorFeed", ctx, authorId, format)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorFeed indicates an expected call of AuthorFeed.
+func (mr *MockFeedServiceMockRecorder) AuthorFeed(ctx, authorId, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorFeed", reflect.TypeOf((*MockFeedService)(nil).AuthorFeed), ctx, authorId, format)
+}
+
+// HotFeed mocks base method.
+func (m *MockFeedService) HotFeed(ctx context.Context, format string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HotFeed", ctx, format)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HotFeed indicates an expected call of HotFeed.
+func (mr *MockFeedServiceMockRecorder) HotFeed(ctx, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HotFeed", reflect.TypeOf((*MockFeedService)(nil).HotFeed), ctx, format)
+}
@@ -0,0 +1,132 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/login_audit.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/login_audit.go -package=svcmocks -destination=./internal/service/mocks/login_audit.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLoginAuditService is a mock of LoginAuditService interface.
+type MockLoginAuditService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAuditServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockLoginAuditServiceMockRecorder is the mock recorder for MockLoginAuditService.
+type MockLoginAuditServiceMockRecorder struct {
+	mock *MockLoginAuditService
+}
+
+// NewMockLoginAuditService creates a new mock instance.
+func NewMockLoginAuditService(ctrl *gomock.Controller) *MockLoginAuditService {
+	mock := &MockLoginAuditService{ctrl: ctrl}
+	mock.recorder = &MockLoginAuditServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAuditService) EXPECT() *MockLoginAuditServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockLoginAuditService) Check(ctx context.Context, u domain.User, l domain.LoginLog) (domain.LoginChallenge, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, u, l)
+	ret0, _ := ret[0].(domain.LoginChallenge)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockLoginAuditServiceMockRecorder) Check(ctx, u, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockLoginAuditService)(nil).Check), ctx, u, l)
+}
+
+// History mocks base method.
+func (m *MockLoginAuditService) History(ctx context.Context, uid int64) ([]domain.LoginLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, uid)
+	ret0, _ := ret[0].([]domain.LoginLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockLoginAuditServiceMockRecorder) History(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockLoginAuditService)(nil).History), ctx, uid)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockLoginAuditService) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockLoginAuditServiceMockRecorder) PurgeExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockLoginAuditService)(nil).PurgeExpired), ctx, before)
+}
+
+// RecordFailure mocks base method.
+func (m *MockLoginAuditService) RecordFailure(ctx context.Context, l domain.LoginLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockLoginAuditServiceMockRecorder) RecordFailure(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockLoginAuditService)(nil).RecordFailure), ctx, l)
+}
+
+// RecordSuccess mocks base method.
+func (m *MockLoginAuditService) RecordSuccess(ctx context.Context, l domain.LoginLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSuccess", ctx, l)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSuccess indicates an expected call of RecordSuccess.
+func (mr *MockLoginAuditServiceMockRecorder) RecordSuccess(ctx, l any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSuccess", reflect.TypeOf((*MockLoginAuditService)(nil).RecordSuccess), ctx, l)
+}
+
+// VerifyChallenge mocks base method.
+func (m *MockLoginAuditService) VerifyChallenge(ctx context.Context, token, code, userAgent string) (domain.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChallenge", ctx, token, code, userAgent)
+	ret0, _ := ret[0].(domain.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyChallenge indicates an expected call of VerifyChallenge.
+func (mr *MockLoginAuditServiceMockRecorder) VerifyChallenge(ctx, token, code, userAgent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChallenge", reflect.TypeOf((*MockLoginAuditService)(nil).VerifyChallenge), ctx, token, code, userAgent)
+}
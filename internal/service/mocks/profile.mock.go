@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/profile.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/profile.go -package=svcmocks -destination=./internal/service/mocks/profile.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProfileService is a mock of ProfileService interface.
+type MockProfileService struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockProfileServiceMockRecorder is the mock recorder for MockProfileService.
+type MockProfileServiceMockRecorder struct {
+	mock *MockProfileService
+}
+
+// NewMockProfileService creates a new mock instance.
+func NewMockProfileService(ctrl *gomock.Controller) *MockProfileService {
+	mock := &MockProfileService{ctrl: ctrl}
+	mock.recorder = &MockProfileServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileService) EXPECT() *MockProfileServiceMockRecorder {
+	return m.recorder
+}
+
+// PublicProfile mocks base method.
+func (m *MockProfileService) PublicProfile(ctx context.Context, uid int64) (domain.PublicProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicProfile", ctx, uid)
+	ret0, _ := ret[0].(domain.PublicProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublicProfile indicates an expected call of PublicProfile.
+func (mr *MockProfileServiceMockRecorder) PublicProfile(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicProfile", reflect.TypeOf((*MockProfileService)(nil).PublicProfile), ctx, uid)
+}
+
+// PublicProfileByHandle mocks base method.
+func (m *MockProfileService) PublicProfileByHandle(ctx context.Context, handle string) (domain.PublicProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicProfileByHandle", ctx, handle)
+	ret0, _ := ret[0].(domain.PublicProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublicProfileByHandle indicates an expected call of PublicProfileByHandle.
+func (mr *MockProfileServiceMockRecorder) PublicProfileByHandle(ctx, handle any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicProfileByHandle", reflect.TypeOf((*MockProfileService)(nil).PublicProfileByHandle), ctx, handle)
+}
+
+// SetHandle mocks base method.
+func (m *MockProfileService) SetHandle(ctx context.Context, uid int64, handle string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHandle", ctx, uid, handle)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHandle indicates an expected call of SetHandle.
+func (mr *MockProfileServiceMockRecorder) SetHandle(ctx, uid, handle any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHandle", reflect.TypeOf((*MockProfileService)(nil).SetHandle), ctx, uid, handle)
+}
+
+// UpdatePrivacy mocks base method.
+func (m *MockProfileService) UpdatePrivacy(ctx context.Context, uid int64, p domain.UserPrivacy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrivacy", ctx, uid, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePrivacy indicates an expected call of UpdatePrivacy.
+func (mr *MockProfileServiceMockRecorder) UpdatePrivacy(ctx, uid, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrivacy", reflect.TypeOf((*MockProfileService)(nil).UpdatePrivacy), ctx, uid, p)
+}
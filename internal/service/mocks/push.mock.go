@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/push.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/push.go -package=svcmocks -destination=./internal/service/mocks/push.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPushService is a mock of PushService interface.
+type MockPushService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPushServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockPushServiceMockRecorder is the mock recorder for MockPushService.
+type MockPushServiceMockRecorder struct {
+	mock *MockPushService
+}
+
+// NewMockPushService creates a new mock instance.
+func NewMockPushService(ctrl *gomock.Controller) *MockPushService {
+	mock := &MockPushService{ctrl: ctrl}
+	mock.recorder = &MockPushServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPushService) EXPECT() *MockPushServiceMockRecorder {
+	return m.recorder
+}
+
+// Push mocks base method.
+func (m *MockPushService) Push(ctx context.Context, evt domain.PushEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Push", ctx, evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Push indicates an expected call of Push.
+func (mr *MockPushServiceMockRecorder) Push(ctx, evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockPushService)(nil).Push), ctx, evt)
+}
+
+// Run mocks base method.
+func (m *MockPushService) Run(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockPushServiceMockRecorder) Run(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockPushService)(nil).Run), ctx)
+}
+
+// Subscribe mocks base method.
+func (m *MockPushService) Subscribe(uid int64) (<-chan domain.PushEvent, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", uid)
+	ret0, _ := ret[0].(<-chan domain.PushEvent)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockPushServiceMockRecorder) Subscribe(uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockPushService)(nil).Subscribe), uid)
+}
@@ -0,0 +1,158 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/series.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/series.go -package=svcmocks -destination=./internal/service/mocks/series.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "xiaoweishu/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSeriesService is a mock of SeriesService interface.
+type MockSeriesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSeriesServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockSeriesServiceMockRecorder is the mock recorder for MockSeriesService.
+type MockSeriesServiceMockRecorder struct {
+	mock *MockSeriesService
+}
+
+// NewMockSeriesService creates a new mock instance.
+func NewMockSeriesService(ctrl *gomock.Controller) *MockSeriesService {
+	mock := &MockSeriesService{ctrl: ctrl}
+	mock.recorder = &MockSeriesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSeriesService) EXPECT() *MockSeriesServiceMockRecorder {
+	return m.recorder
+}
+
+// AddArticle mocks base method.
+func (m *MockSeriesService) AddArticle(ctx context.Context, id, uid, articleId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddArticle", ctx, id, uid, articleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddArticle indicates an expected call of AddArticle.
+func (mr *MockSeriesServiceMockRecorder) AddArticle(ctx, id, uid, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddArticle", reflect.TypeOf((*MockSeriesService)(nil).AddArticle), ctx, id, uid, articleId)
+}
+
+// Create mocks base method.
+func (m *MockSeriesService) Create(ctx context.Context, s domain.Series) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, s)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSeriesServiceMockRecorder) Create(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSeriesService)(nil).Create), ctx, s)
+}
+
+// FindById mocks base method.
+func (m *MockSeriesService) FindById(ctx context.Context, id int64) (domain.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(domain.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockSeriesServiceMockRecorder) FindById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockSeriesService)(nil).FindById), ctx, id)
+}
+
+// ListByAuthor mocks base method.
+func (m *MockSeriesService) ListByAuthor(ctx context.Context, uid int64) ([]domain.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByAuthor", ctx, uid)
+	ret0, _ := ret[0].([]domain.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByAuthor indicates an expected call of ListByAuthor.
+func (mr *MockSeriesServiceMockRecorder) ListByAuthor(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByAuthor", reflect.TypeOf((*MockSeriesService)(nil).ListByAuthor), ctx, uid)
+}
+
+// Navigation mocks base method.
+func (m *MockSeriesService) Navigation(ctx context.Context, articleId int64) (domain.SeriesNavigation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Navigation", ctx, articleId)
+	ret0, _ := ret[0].(domain.SeriesNavigation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Navigation indicates an expected call of Navigation.
+func (mr *MockSeriesServiceMockRecorder) Navigation(ctx, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Navigation", reflect.TypeOf((*MockSeriesService)(nil).Navigation), ctx, articleId)
+}
+
+// RemoveArticle mocks base method.
+func (m *MockSeriesService) RemoveArticle(ctx context.Context, id, uid, articleId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveArticle", ctx, id, uid, articleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveArticle indicates an expected call of RemoveArticle.
+func (mr *MockSeriesServiceMockRecorder) RemoveArticle(ctx, id, uid, articleId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveArticle", reflect.TypeOf((*MockSeriesService)(nil).RemoveArticle), ctx, id, uid, articleId)
+}
+
+// Rename mocks base method.
+func (m *MockSeriesService) Rename(ctx context.Context, s domain.Series) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockSeriesServiceMockRecorder) Rename(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockSeriesService)(nil).Rename), ctx, s)
+}
+
+// Reorder mocks base method.
+func (m *MockSeriesService) Reorder(ctx context.Context, id, uid int64, articleIds []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reorder", ctx, id, uid, articleIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reorder indicates an expected call of Reorder.
+func (mr *MockSeriesServiceMockRecorder) Reorder(ctx, id, uid, articleIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reorder", reflect.TypeOf((*MockSeriesService)(nil).Reorder), ctx, id, uid, articleIds)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/service/user_role.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./internal/service/user_role.go -package=svcmocks -destination=./internal/service/mocks/user_role.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserRoleService is a mock of UserRoleService interface.
+type MockUserRoleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRoleServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockUserRoleServiceMockRecorder is the mock recorder for MockUserRoleService.
+type MockUserRoleServiceMockRecorder struct {
+	mock *MockUserRoleService
+}
+
+// NewMockUserRoleService creates a new mock instance.
+func NewMockUserRoleService(ctrl *gomock.Controller) *MockUserRoleService {
+	mock := &MockUserRoleService{ctrl: ctrl}
+	mock.recorder = &MockUserRoleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRoleService) EXPECT() *MockUserRoleServiceMockRecorder {
+	return m.recorder
+}
+
+// Roles mocks base method.
+func (m *MockUserRoleService) Roles(ctx context.Context, uid int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Roles", ctx, uid)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Roles indicates an expected call of Roles.
+func (mr *MockUserRoleServiceMockRecorder) Roles(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Roles", reflect.TypeOf((*MockUserRoleService)(nil).Roles), ctx, uid)
+}
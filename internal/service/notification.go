@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+)
+
+var ErrInvalidNotification = errors.New("通知参数不合法")
+
+// NotificationService 站内通知，其它模块通过 Notify 给用户发通知
+type NotificationService interface {
+	// Notify 发送通知，Uid、Biz 和 Content 必须有
+	// 设置了 AggKey 的通知，用户还没有读过之前重复发送会被聚合成一条
+	Notify(ctx context.Context, n domain.Notification) error
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error)
+	UnreadCount(ctx context.Context, uid int64) (int64, error)
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	MarkAllRead(ctx context.Context, uid int64) error
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+}
+
+func NewNotificationService(repo repository.NotificationRepository) NotificationService {
+	return &notificationService{
+		repo: repo,
+	}
+}
+
+func (svc *notificationService) Notify(ctx context.Context, n domain.Notification) error {
+	if n.Uid <= 0 || n.Biz == "" || n.Content == "" {
+		return ErrInvalidNotification
+	}
+	return svc.repo.Upsert(ctx, n)
+}
+
+func (svc *notificationService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error) {
+	return svc.repo.List(ctx, uid, offset, limit)
+}
+
+func (svc *notificationService) UnreadCount(ctx context.Context, uid int64) (int64, error) {
+	return svc.repo.UnreadCount(ctx, uid)
+}
+
+func (svc *notificationService) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return svc.repo.MarkRead(ctx, uid, ids)
+}
+
+func (svc *notificationService) MarkAllRead(ctx context.Context, uid int64) error {
+	return svc.repo.MarkAllRead(ctx, uid)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ratelimit"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/email"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordResetBiz 找回密码的验证码和登录验证码分开，互相不能通用
+const passwordResetBiz = "password_reset"
+
+var (
+	ErrPasswordResetTooMany = errors.New("找回密码太频繁")
+	// ErrPasswordResetInvalid 账号不存在和验证码不对都返回这个错误，避免泄露账号是否存在
+	ErrPasswordResetInvalid = errors.New("账号或者验证码不对")
+)
+
+// PasswordResetService 忘记密码的时候通过验证码重新设置密码
+type PasswordResetService interface {
+	// SendCode 发送验证码，account 是邮箱的时候发邮件，是手机号的时候发短信
+	// 账号不存在或者给这个账号发送太频繁的时候也返回 nil
+	SendCode(ctx context.Context, account, ip string) error
+	// Reset 校验验证码并设置新密码，返回用户 id
+	Reset(ctx context.Context, account, code, password, ip string) (int64, error)
+}
+
+type passwordResetService struct {
+	repo           repository.UserRepository
+	codeSvc        CodeService
+	accountLimiter ratelimit.Limiter
+	ipLimiter      ratelimit.Limiter
+}
+
+func NewPasswordResetService(repo repository.UserRepository,
+	codeSvc CodeService,
+	accountLimiter ratelimit.Limiter,
+	ipLimiter ratelimit.Limiter) PasswordResetService {
+	return &passwordResetService{
+		repo:           repo,
+		codeSvc:        codeSvc,
+		accountLimiter: accountLimiter,
+		ipLimiter:      ipLimiter,
+	}
+}
+
+func (svc *passwordResetService) SendCode(ctx context.Context, account, ip string) error {
+	if err := svc.limit(ctx, account, ip); err != nil {
+		return err
+	}
+	u, err := svc.findByAccount(ctx, account)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if isEmail(account) {
+		err = svc.codeSvc.SendEmail(ctx, email.BizPasswordReset, u.Email)
+	} else {
+		err = svc.codeSvc.Send(ctx, passwordResetBiz, u.Phone)
+	}
+	// 只有存在的账号才会发送太频繁，返回出去就能知道账号是否存在
+	if errors.Is(err, ErrCodeSendTooMany) {
+		return nil
+	}
+	return err
+}
+
+func (svc *passwordResetService) Reset(ctx context.Context, account, code, password, ip string) (int64, error) {
+	if err := svc.limit(ctx, account, ip); err != nil {
+		return 0, err
+	}
+	u, err := svc.findByAccount(ctx, account)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return 0, ErrPasswordResetInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	biz, target := passwordResetBiz, u.Phone
+	if isEmail(account) {
+		biz, target = email.BizPasswordReset, u.Email
+	}
+	ok, err := svc.codeSvc.Verify(ctx, biz, target, code)
+	if errors.Is(err, repository.ErrCodeVerifyTooMany) {
+		return 0, ErrPasswordResetInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrPasswordResetInvalid
+	}
+	// 收到了邮件就说明邮箱是真的
+	if isEmail(account) && !u.EmailVerified {
+		if err = svc.repo.MarkEmailVerified(ctx, u.Id); err != nil {
+			return 0, err
+		}
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	return u.Id, svc.repo.UpdatePassword(ctx, u.Id, string(hash))
+}
+
+// limit 同时按照账号和 IP 限流，防止暴力猜测验证码和滥发短信
+func (svc *passwordResetService) limit(ctx context.Context, account, ip string) error {
+	limited, err := svc.accountLimiter.Limit(ctx, fmt.Sprintf("password-reset:account:%s", account))
+	if err != nil {
+		return err
+	}
+	if limited {
+		return ErrPasswordResetTooMany
+	}
+	limited, err = svc.ipLimiter.Limit(ctx, fmt.Sprintf("password-reset:ip:%s", ip))
+	if err != nil {
+		return err
+	}
+	if limited {
+		return ErrPasswordResetTooMany
+	}
+	return nil
+}
+
+func (svc *passwordResetService) findByAccount(ctx context.Context, account string) (domain.User, error) {
+	if isEmail(account) {
+		return svc.repo.FindByEmail(ctx, account)
+	}
+	return svc.repo.FindByPhone(ctx, account)
+}
+
+func isEmail(account string) bool {
+	return strings.Contains(account, "@")
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ratelimit"
+	limitmocks "xiaoweishu/internal/pkg/ratelimit/mocks"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	"xiaoweishu/internal/service/email"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_passwordResetService_Reset(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter)
+		account string
+		wantUid int64
+		wantErr error
+	}{
+		{
+			name: "手机号重置成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 1, Email: "123@qq.com", Phone: "15212345678"}, nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), passwordResetBiz, "15212345678", "123456").Return(true, nil)
+				return repo, codeSvc, limiter
+			},
+			account: "15212345678",
+			wantUid: 1,
+		},
+		{
+			name: "邮箱重置成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 1, Email: "123@qq.com", EmailVerified: true}, nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), email.BizPasswordReset, "123@qq.com", "123456").Return(true, nil)
+				return repo, codeSvc, limiter
+			},
+			account: "123@qq.com",
+			wantUid: 1,
+		},
+		{
+			name: "邮箱重置，没有验证过的邮箱变成验证过的",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+				repo.EXPECT().MarkEmailVerified(gomock.Any(), int64(1)).Return(nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), email.BizPasswordReset, "123@qq.com", "123456").Return(true, nil)
+				return repo, codeSvc, limiter
+			},
+			account: "123@qq.com",
+			wantUid: 1,
+		},
+		{
+			name: "邮箱验证码不对",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), email.BizPasswordReset, "123@qq.com", "123456").Return(false, nil)
+				return repo, codeSvc, limiter
+			},
+			account: "123@qq.com",
+			wantErr: ErrPasswordResetInvalid,
+		},
+		{
+			name: "限流",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "password-reset:account:123@qq.com").Return(true, nil)
+				return repomocks.NewMockUserRepository(ctrl), svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			account: "123@qq.com",
+			wantErr: ErrPasswordResetTooMany,
+		},
+		{
+			name: "账号不存在",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return repo, svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			account: "15212345678",
+			wantErr: ErrPasswordResetInvalid,
+		},
+		{
+			name: "验证码不对",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 1, Phone: "15212345678"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), passwordResetBiz, "15212345678", "123456").Return(false, nil)
+				return repo, codeSvc, limiter
+			},
+			account: "15212345678",
+			wantErr: ErrPasswordResetInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, codeSvc, limiter := tc.mock(ctrl)
+			svc := NewPasswordResetService(repo, codeSvc, limiter, limiter)
+			uid, err := svc.Reset(context.Background(), tc.account, "123456", "hello@world123", "127.0.0.1")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUid, uid)
+		})
+	}
+}
+
+func Test_passwordResetService_SendCode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter)
+		account string
+		wantErr error
+	}{
+		{
+			name: "邮箱发邮件",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().SendEmail(gomock.Any(), email.BizPasswordReset, "123@qq.com").Return(nil)
+				return repo, codeSvc, limiter
+			},
+			account: "123@qq.com",
+		},
+		{
+			name: "手机号发短信",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 1, Phone: "15212345678"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), passwordResetBiz, "15212345678").Return(nil)
+				return repo, codeSvc, limiter
+			},
+			account: "15212345678",
+		},
+		{
+			name: "发送太频繁也不报错",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().SendEmail(gomock.Any(), email.BizPasswordReset, "123@qq.com").Return(ErrCodeSendTooMany)
+				return repo, codeSvc, limiter
+			},
+			account: "123@qq.com",
+		},
+		{
+			name: "账号不存在也不报错",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, CodeService, ratelimit.Limiter) {
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return repo, svcmocks.NewMockCodeService(ctrl), limiter
+			},
+			account: "123@qq.com",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, codeSvc, limiter := tc.mock(ctrl)
+			svc := NewPasswordResetService(repo, codeSvc, limiter, limiter)
+			err := svc.SendCode(context.Background(), tc.account, "127.0.0.1")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
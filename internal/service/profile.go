@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+)
+
+var (
+	ErrHandleInvalid  = errors.New("用户名格式不对")
+	ErrHandleReserved = errors.New("用户名已被系统保留")
+	ErrHandleTaken    = repository.ErrUserHandleDuplicate
+	ErrHandleCooldown = repository.ErrUserHandleCooldown
+)
+
+// HandleCooldown 两次修改用户名至少间隔这么久，第一次设置不受限制
+const HandleCooldown = time.Hour * 24 * 30
+
+// handleExp 字母开头，和数字 id 区分开，个人主页的地址里面两者都可以用
+var handleExp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{2,19}$`)
+
+// reservedHandles 会和路由、系统账号混淆的用户名，比较的时候不区分大小写
+var reservedHandles = map[string]struct{}{
+	"admin": {}, "administrator": {}, "root": {}, "system": {}, "official": {},
+	"moderator": {}, "support": {}, "help": {}, "security": {}, "xiaoweishu": {},
+	"api": {}, "www": {}, "users": {}, "articles": {}, "series": {}, "feed": {},
+	"login": {}, "logout": {}, "signup": {}, "settings": {}, "profile": {}, "me": {},
+	"null": {}, "undefined": {},
+}
+
+// ProfileService 个人主页和用户名
+type ProfileService interface {
+	// PublicProfile 其他用户看到的个人主页，被合并的账号只返回 MergedInto
+	PublicProfile(ctx context.Context, uid int64) (domain.PublicProfile, error)
+	// PublicProfileByHandle 用户名不区分大小写
+	PublicProfileByHandle(ctx context.Context, handle string) (domain.PublicProfile, error)
+	// SetHandle 设置或者修改用户名，距离上一次修改不到 HandleCooldown 返回 ErrHandleCooldown
+	SetHandle(ctx context.Context, uid int64, handle string) error
+	UpdatePrivacy(ctx context.Context, uid int64, p domain.UserPrivacy) error
+}
+
+type profileService struct {
+	userSvc    UserService
+	userRepo   repository.UserRepository
+	artRepo    repository.ArticleRepository
+	seriesRepo repository.SeriesRepository
+}
+
+func NewProfileService(userSvc UserService,
+	userRepo repository.UserRepository,
+	artRepo repository.ArticleRepository,
+	seriesRepo repository.SeriesRepository) ProfileService {
+	return &profileService{
+		userSvc:    userSvc,
+		userRepo:   userRepo,
+		artRepo:    artRepo,
+		seriesRepo: seriesRepo,
+	}
+}
+
+func (svc *profileService) PublicProfile(ctx context.Context, uid int64) (domain.PublicProfile, error) {
+	// 头像的地址由 UserService 生成
+	u, err := svc.userSvc.Profile(ctx, uid)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	if u.MergedInto > 0 {
+		return domain.PublicProfile{Id: u.Id, MergedInto: u.MergedInto}, nil
+	}
+	stats, err := svc.artRepo.AuthorStats(ctx, uid)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	series, err := svc.seriesRepo.ListByAuthor(ctx, uid)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	p := domain.PublicProfile{
+		Id:         u.Id,
+		Handle:     u.Handle,
+		NickName:   u.NickName,
+		AboutMe:    u.AboutMe,
+		Avatar:     u.Avatar,
+		ArticleCnt: stats.ArticleCnt,
+		ReadCnt:    stats.ReadCnt,
+		SeriesCnt:  int64(len(series)),
+		Ctime:      u.Ctime,
+	}
+	if u.Privacy.Email == domain.PrivacyMasked {
+		p.Email = domain.MaskEmail(u.Email)
+	}
+	if u.Privacy.Phone == domain.PrivacyMasked {
+		p.Phone = domain.MaskPhone(u.Phone)
+	}
+	return p, nil
+}
+
+func (svc *profileService) PublicProfileByHandle(ctx context.Context, handle string) (domain.PublicProfile, error) {
+	// 格式不对的肯定不存在，不用查数据库
+	if !handleExp.MatchString(handle) {
+		return domain.PublicProfile{}, ErrUserNotFound
+	}
+	u, err := svc.userRepo.FindByHandle(ctx, handle)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	return svc.PublicProfile(ctx, u.Id)
+}
+
+func (svc *profileService) SetHandle(ctx context.Context, uid int64, handle string) error {
+	if !handleExp.MatchString(handle) {
+		return ErrHandleInvalid
+	}
+	if _, ok := reservedHandles[strings.ToLower(handle)]; ok {
+		return ErrHandleReserved
+	}
+	return svc.userRepo.UpdateHandle(ctx, uid, handle, time.Now().Add(-HandleCooldown))
+}
+
+func (svc *profileService) UpdatePrivacy(ctx context.Context, uid int64, p domain.UserPrivacy) error {
+	return svc.userRepo.UpdatePrivacy(ctx, uid, p)
+}
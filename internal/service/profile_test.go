@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_profileService_PublicProfile(t *testing.T) {
+	ctime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	user := domain.User{
+		Id:       2,
+		Email:    "alice@qq.com",
+		Phone:    "13812345678",
+		Handle:   "Alice",
+		NickName: "爱丽丝",
+		AboutMe:  "写点东西",
+		Ctime:    ctime,
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (UserService, repository.ArticleRepository, repository.SeriesRepository)
+		uid  int64
+
+		wantProfile domain.PublicProfile
+		wantErr     error
+	}{
+		{
+			name: "默认不展示邮箱和手机号",
+			mock: func(ctrl *gomock.Controller) (UserService, repository.ArticleRepository, repository.SeriesRepository) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				seriesRepo := repomocks.NewMockSeriesRepository(ctrl)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(2)).Return(user, nil)
+				artRepo.EXPECT().AuthorStats(gomock.Any(), int64(2)).
+					Return(domain.AuthorStats{ArticleCnt: 3, ReadCnt: 100}, nil)
+				seriesRepo.EXPECT().ListByAuthor(gomock.Any(), int64(2)).
+					Return([]domain.Series{{Id: 1}, {Id: 2}}, nil)
+				return userSvc, artRepo, seriesRepo
+			},
+			uid: 2,
+			wantProfile: domain.PublicProfile{
+				Id:         2,
+				Handle:     "Alice",
+				NickName:   "爱丽丝",
+				AboutMe:    "写点东西",
+				ArticleCnt: 3,
+				ReadCnt:    100,
+				SeriesCnt:  2,
+				Ctime:      ctime,
+			},
+		},
+		{
+			name: "设置了打码展示",
+			mock: func(ctrl *gomock.Controller) (UserService, repository.ArticleRepository, repository.SeriesRepository) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				seriesRepo := repomocks.NewMockSeriesRepository(ctrl)
+				u := user
+				u.Privacy = domain.UserPrivacy{Email: domain.PrivacyMasked, Phone: domain.PrivacyMasked}
+				userSvc.EXPECT().Profile(gomock.Any(), int64(2)).Return(u, nil)
+				artRepo.EXPECT().AuthorStats(gomock.Any(), int64(2)).Return(domain.AuthorStats{}, nil)
+				seriesRepo.EXPECT().ListByAuthor(gomock.Any(), int64(2)).Return(nil, nil)
+				return userSvc, artRepo, seriesRepo
+			},
+			uid: 2,
+			wantProfile: domain.PublicProfile{
+				Id:       2,
+				Handle:   "Alice",
+				NickName: "爱丽丝",
+				AboutMe:  "写点东西",
+				Email:    "a***@qq.com",
+				Phone:    "138****5678",
+				Ctime:    ctime,
+			},
+		},
+		{
+			name: "被合并的账号",
+			mock: func(ctrl *gomock.Controller) (UserService, repository.ArticleRepository, repository.SeriesRepository) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				u := user
+				u.MergedInto = 3
+				userSvc.EXPECT().Profile(gomock.Any(), int64(2)).Return(u, nil)
+				return userSvc, repomocks.NewMockArticleRepository(ctrl), repomocks.NewMockSeriesRepository(ctrl)
+			},
+			uid:         2,
+			wantProfile: domain.PublicProfile{Id: 2, MergedInto: 3},
+		},
+		{
+			name: "用户不存在",
+			mock: func(ctrl *gomock.Controller) (UserService, repository.ArticleRepository, repository.SeriesRepository) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(2)).Return(domain.User{}, ErrUserNotFound)
+				return userSvc, repomocks.NewMockArticleRepository(ctrl), repomocks.NewMockSeriesRepository(ctrl)
+			},
+			uid:     2,
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc, artRepo, seriesRepo := tc.mock(ctrl)
+			svc := NewProfileService(userSvc, repomocks.NewMockUserRepository(ctrl), artRepo, seriesRepo)
+			p, err := svc.PublicProfile(context.Background(), tc.uid)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantProfile, p)
+		})
+	}
+}
+
+func Test_profileService_SetHandle(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) repository.UserRepository
+		handle string
+
+		wantErr error
+	}{
+		{
+			name: "设置成功",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().UpdateHandle(gomock.Any(), int64(2), "Alice_01", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int64, handle string, before time.Time) error {
+						// 上一次修改要早于 HandleCooldown 之前
+						assert.WithinDuration(t, time.Now().Add(-HandleCooldown), before, time.Minute)
+						return nil
+					})
+				return repo
+			},
+			handle: "Alice_01",
+		},
+		{
+			name: "数字开头",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			handle:  "123abc",
+			wantErr: ErrHandleInvalid,
+		},
+		{
+			name: "太短",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			handle:  "ab",
+			wantErr: ErrHandleInvalid,
+		},
+		{
+			name: "保留的用户名不区分大小写",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			handle:  "Admin",
+			wantErr: ErrHandleReserved,
+		},
+		{
+			name: "已经被使用",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().UpdateHandle(gomock.Any(), int64(2), "alice", gomock.Any()).
+					Return(repository.ErrUserHandleDuplicate)
+				return repo
+			},
+			handle:  "alice",
+			wantErr: ErrHandleTaken,
+		},
+		{
+			name: "还在冷却期",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().UpdateHandle(gomock.Any(), int64(2), "alice", gomock.Any()).
+					Return(repository.ErrUserHandleCooldown)
+				return repo
+			},
+			handle:  "alice",
+			wantErr: ErrHandleCooldown,
+		},
+		{
+			name: "数据库错误",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().UpdateHandle(gomock.Any(), int64(2), "alice", gomock.Any()).
+					Return(errors.New("mock db 错误"))
+				return repo
+			},
+			handle:  "alice",
+			wantErr: errors.New("mock db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewProfileService(nil, tc.mock(ctrl), nil, nil)
+			err := svc.SetHandle(context.Background(), 2, tc.handle)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+)
+
+var ErrTooManyPushConns = errors.New("实时连接数过多")
+
+const (
+	// pushMaxConnsPerUser 每个用户在单个实例上最多的连接数
+	pushMaxConnsPerUser = 5
+	// pushConnBufferSize 连接的缓冲区，客户端太慢缓冲区满了就丢弃事件
+	pushConnBufferSize = 16
+)
+
+// PushService 把事件实时推送给用户所有在线的连接
+// 事件先通过 redis 广播到所有实例，每个实例再分发给本地的连接
+type PushService interface {
+	// Push 推送事件，可以在任意实例上调用
+	Push(ctx context.Context, evt domain.PushEvent) error
+	// Subscribe 注册一个连接，连接断开的时候必须调用返回的 cancel
+	Subscribe(uid int64) (<-chan domain.PushEvent, func(), error)
+	// Run 监听 redis 中的事件并分发，阻塞直到 ctx 结束
+	Run(ctx context.Context) error
+}
+
+type pushService struct {
+	repo repository.PushRepository
+	l    logger.LoggerV1
+
+	mu    sync.Mutex
+	conns map[int64]map[*pushConn]struct{}
+}
+
+type pushConn struct {
+	ch chan domain.PushEvent
+}
+
+func NewPushService(repo repository.PushRepository, l logger.LoggerV1) PushService {
+	return &pushService{
+		repo:  repo,
+		l:     l,
+		conns: make(map[int64]map[*pushConn]struct{}),
+	}
+}
+
+func (svc *pushService) Push(ctx context.Context, evt domain.PushEvent) error {
+	if evt.Ctime.IsZero() {
+		evt.Ctime = time.Now()
+	}
+	return svc.repo.Publish(ctx, evt)
+}
+
+func (svc *pushService) Subscribe(uid int64) (<-chan domain.PushEvent, func(), error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	conns, ok := svc.conns[uid]
+	if !ok {
+		conns = make(map[*pushConn]struct{})
+		svc.conns[uid] = conns
+	}
+	if len(conns) >= pushMaxConnsPerUser {
+		return nil, nil, ErrTooManyPushConns
+	}
+	conn := &pushConn{ch: make(chan domain.PushEvent, pushConnBufferSize)}
+	conns[conn] = struct{}{}
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			svc.mu.Lock()
+			defer svc.mu.Unlock()
+			delete(conns, conn)
+			if len(conns) == 0 {
+				delete(svc.conns, uid)
+			}
+		})
+	}
+	return conn.ch, cancel, nil
+}
+
+func (svc *pushService) Run(ctx context.Context) error {
+	for {
+		err := svc.run(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		// redis 断开之后过一会重新订阅
+		svc.l.Error("订阅推送事件失败", logger.Error(err))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (svc *pushService) run(ctx context.Context) error {
+	events, err := svc.repo.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	for evt := range events {
+		svc.dispatch(evt)
+	}
+	return errors.New("推送事件订阅被关闭")
+}
+
+func (svc *pushService) dispatch(evt domain.PushEvent) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	for conn := range svc.conns[evt.Uid] {
+		select {
+		case conn.ch <- evt:
+		default:
+			svc.l.Warn("推送缓冲区已满，丢弃事件",
+				logger.Int64("uid", evt.Uid),
+				logger.String("type", evt.Type))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	repomocks "xiaoweishu/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_pushService_Subscribe(t *testing.T) {
+	svc := NewPushService(nil, &logger.NopLogger{})
+	cancels := make([]func(), 0, pushMaxConnsPerUser)
+	for i := 0; i < pushMaxConnsPerUser; i++ {
+		_, cancel, err := svc.Subscribe(123)
+		require.NoError(t, err)
+		cancels = append(cancels, cancel)
+	}
+	_, _, err := svc.Subscribe(123)
+	assert.Equal(t, ErrTooManyPushConns, err)
+	// 其它用户不受影响
+	_, _, err = svc.Subscribe(456)
+	assert.NoError(t, err)
+
+	// 断开一个之后又可以连接了，重复 cancel 不能多释放
+	cancels[0]()
+	cancels[0]()
+	_, _, err = svc.Subscribe(123)
+	assert.NoError(t, err)
+	_, _, err = svc.Subscribe(123)
+	assert.Equal(t, ErrTooManyPushConns, err)
+}
+
+func Test_pushService_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	events := make(chan domain.PushEvent)
+	repo := repomocks.NewMockPushRepository(ctrl)
+	repo.EXPECT().Subscribe(gomock.Any()).Return((<-chan domain.PushEvent)(events), nil)
+	svc := NewPushService(repo, &logger.NopLogger{})
+
+	ch1, cancel1, err := svc.Subscribe(123)
+	require.NoError(t, err)
+	defer cancel1()
+	ch2, cancel2, err := svc.Subscribe(123)
+	require.NoError(t, err)
+	defer cancel2()
+	other, cancel3, err := svc.Subscribe(456)
+	require.NoError(t, err)
+	defer cancel3()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = svc.Run(ctx)
+	}()
+
+	evt := domain.PushEvent{Uid: 123, Type: domain.PushEventSessionLogout}
+	events <- evt
+	// 同一个用户的所有连接都能收到
+	for _, ch := range []<-chan domain.PushEvent{ch1, ch2} {
+		select {
+		case got := <-ch:
+			assert.Equal(t, evt, got)
+		case <-time.After(time.Second):
+			t.Fatal("没有收到事件")
+		}
+	}
+	select {
+	case <-other:
+		t.Fatal("其它用户不应该收到事件")
+	default:
+	}
+
+	cancel()
+	close(events)
+	<-done
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+)
+
+var (
+	ErrReportDuplicate      = repository.ErrReportDuplicate
+	ErrReportNotFound       = repository.ErrReportNotFound
+	ErrReportStatusConflict = repository.ErrReportStatusConflict
+	ErrInvalidReport        = errors.New("举报参数不合法")
+)
+
+// reportReasonMaxLen 和 reports.reason 的长度保持一致
+const reportReasonMaxLen = 1024
+
+type ReportService interface {
+	// Report 提交举报，同一个人对同一个对象只能举报一次
+	// 被举报的对象不存在、举报自己或者自己的文章都返回 ErrInvalidReport
+	Report(ctx context.Context, r domain.Report) (int64, error)
+	// ListPending 待审核队列，按照举报时间先后排序
+	ListPending(ctx context.Context, offset, limit int) ([]domain.Report, error)
+	// Accept 和 Reject 只能由有 domain.PermReportReview 权限的管理员调用，都会记录审计日志
+	Accept(ctx context.Context, op domain.AdminOperator, id int64, note string) error
+	Reject(ctx context.Context, op domain.AdminOperator, id int64, note string) error
+}
+
+type reportService struct {
+	repo     repository.ReportRepository
+	artRepo  repository.ArticleRepository
+	userRepo repository.UserRepository
+	logRepo  repository.AdminAuditLogRepository
+	l        logger.LoggerV1
+}
+
+func NewReportService(repo repository.ReportRepository,
+	artRepo repository.ArticleRepository,
+	userRepo repository.UserRepository,
+	logRepo repository.AdminAuditLogRepository,
+	l logger.LoggerV1) ReportService {
+	return &reportService{
+		repo:     repo,
+		artRepo:  artRepo,
+		userRepo: userRepo,
+		logRepo:  logRepo,
+		l:        l,
+	}
+}
+
+func (svc *reportService) Report(ctx context.Context, r domain.Report) (int64, error) {
+	switch r.Biz {
+	case domain.ReportBizArticle:
+		r.Field = ""
+	case domain.ReportBizUser:
+		if r.Field != domain.ProfileFieldNickName && r.Field != domain.ProfileFieldAboutMe {
+			return 0, ErrInvalidReport
+		}
+	default:
+		return 0, ErrInvalidReport
+	}
+	if r.BizId <= 0 || utf8.RuneCountInString(r.Reason) > reportReasonMaxLen {
+		return 0, ErrInvalidReport
+	}
+	if err := svc.checkTarget(ctx, r); err != nil {
+		return 0, err
+	}
+	r.Status = domain.ReportStatusPending
+	return svc.repo.Create(ctx, r)
+}
+
+// checkTarget 只能举报别人的、存在的对象，文章必须是已经发表的
+func (svc *reportService) checkTarget(ctx context.Context, r domain.Report) error {
+	var owner int64
+	switch r.Biz {
+	case domain.ReportBizArticle:
+		art, err := svc.artRepo.FindById(ctx, r.BizId)
+		if errors.Is(err, repository.ErrArticleNotFound) {
+			return ErrInvalidReport
+		}
+		if err != nil {
+			return err
+		}
+		if art.Status != domain.ArticleStatusPublished {
+			return ErrInvalidReport
+		}
+		owner = art.Author.Id
+	case domain.ReportBizUser:
+		u, err := svc.userRepo.FindById(ctx, r.BizId)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidReport
+		}
+		if err != nil {
+			return err
+		}
+		owner = u.Id
+	}
+	if owner == r.Reporter {
+		return ErrInvalidReport
+	}
+	return nil
+}
+
+func (svc *reportService) ListPending(ctx context.Context, offset, limit int) ([]domain.Report, error) {
+	return svc.repo.ListByStatus(ctx, domain.ReportStatusPending, offset, limit)
+}
+
+func (svc *reportService) Accept(ctx context.Context, op domain.AdminOperator, id int64, note string) error {
+	r, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	// 和其它管理员操作一样，先记录审计日志再执行
+	if err = svc.audit(ctx, op, domain.AdminActionAcceptReport, id, note); err != nil {
+		return err
+	}
+	if r.Status != domain.ReportStatusPending {
+		return ErrReportStatusConflict
+	}
+	// 先处置再流转状态，处置失败的时候举报还在待审核队列里面，可以重新处理
+	// 处置都是幂等的，并发审核同一个举报的时候只有一个能流转成功
+	err = svc.punish(ctx, r)
+	if err != nil {
+		svc.l.Error("举报成立，但是处置失败",
+			logger.Int64("report_id", id),
+			logger.Error(err))
+		return err
+	}
+	return svc.repo.Transit(ctx, domain.ReportDecision{
+		ReportId: id,
+		Reviewer: op.Uid,
+		From:     domain.ReportStatusPending,
+		To:       domain.ReportStatusAccepted,
+		Note:     note,
+	})
+}
+
+func (svc *reportService) Reject(ctx context.Context, op domain.AdminOperator, id int64, note string) error {
+	if _, err := svc.repo.FindById(ctx, id); err != nil {
+		return err
+	}
+	if err := svc.audit(ctx, op, domain.AdminActionRejectReport, id, note); err != nil {
+		return err
+	}
+	return svc.repo.Transit(ctx, domain.ReportDecision{
+		ReportId: id,
+		Reviewer: op.Uid,
+		From:     domain.ReportStatusPending,
+		To:       domain.ReportStatusRejected,
+		Note:     note,
+	})
+}
+
+// punish 举报成立之后的处置
+// 文章会被隐藏，只有作者自己能看到；个人资料会清空违规的字段
+func (svc *reportService) punish(ctx context.Context, r domain.Report) error {
+	switch r.Biz {
+	case domain.ReportBizArticle:
+		return svc.artRepo.SetHidden(ctx, r.BizId, true)
+	case domain.ReportBizUser:
+		return svc.userRepo.ClearProfileField(ctx, r.BizId, r.Field)
+	default:
+		return fmt.Errorf("未知的举报对象 %s", r.Biz)
+	}
+}
+
+func (svc *reportService) audit(ctx context.Context, op domain.AdminOperator, action string, id int64, note string) error {
+	return svc.logRepo.Create(ctx, domain.AdminAuditLog{
+		Operator: op,
+		Action:   action,
+		TargetId: id,
+		Detail:   note,
+		Ctime:    time.Now(),
+	})
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_reportService_Accept(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.ReportRepository,
+			repository.ArticleRepository, repository.UserRepository)
+		id      int64
+		wantErr error
+	}{
+		{
+			name: "举报文章成立, 隐藏文章",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				repo := repomocks.NewMockReportRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.Report{
+					Id:     1,
+					Biz:    domain.ReportBizArticle,
+					BizId:  11,
+					Status: domain.ReportStatusPending,
+				}, nil)
+				repo.EXPECT().Transit(gomock.Any(), domain.ReportDecision{
+					ReportId: 1,
+					Reviewer: 100,
+					From:     domain.ReportStatusPending,
+					To:       domain.ReportStatusAccepted,
+					Note:     "违规",
+				}).Return(nil)
+				artRepo.EXPECT().SetHidden(gomock.Any(), int64(11), true).Return(nil)
+				return repo, artRepo, userRepo
+			},
+			id: 1,
+		},
+		{
+			name: "举报资料成立, 清空昵称",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				repo := repomocks.NewMockReportRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.Report{
+					Id:     2,
+					Biz:    domain.ReportBizUser,
+					BizId:  22,
+					Field:  domain.ProfileFieldNickName,
+					Status: domain.ReportStatusPending,
+				}, nil)
+				repo.EXPECT().Transit(gomock.Any(), gomock.Any()).Return(nil)
+				userRepo.EXPECT().ClearProfileField(gomock.Any(), int64(22), domain.ProfileFieldNickName).Return(nil)
+				return repo, artRepo, userRepo
+			},
+			id: 2,
+		},
+		{
+			name: "已经被处理过",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				repo := repomocks.NewMockReportRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(3)).Return(domain.Report{
+					Id:     3,
+					Biz:    domain.ReportBizArticle,
+					BizId:  33,
+					Status: domain.ReportStatusRejected,
+				}, nil)
+				return repo, artRepo, userRepo
+			},
+			id:      3,
+			wantErr: ErrReportStatusConflict,
+		},
+		{
+			name: "处置之后发现已经被别人处理了",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				repo := repomocks.NewMockReportRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(5)).Return(domain.Report{
+					Id:     5,
+					Biz:    domain.ReportBizArticle,
+					BizId:  55,
+					Status: domain.ReportStatusPending,
+				}, nil)
+				artRepo.EXPECT().SetHidden(gomock.Any(), int64(55), true).Return(nil)
+				repo.EXPECT().Transit(gomock.Any(), gomock.Any()).Return(repository.ErrReportStatusConflict)
+				return repo, artRepo, userRepo
+			},
+			id:      5,
+			wantErr: ErrReportStatusConflict,
+		},
+		{
+			name: "处置失败",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				repo := repomocks.NewMockReportRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(4)).Return(domain.Report{
+					Id:     4,
+					Biz:    domain.ReportBizArticle,
+					BizId:  44,
+					Status: domain.ReportStatusPending,
+				}, nil)
+				// 处置失败的时候不流转状态，举报留在待审核队列里面
+				artRepo.EXPECT().SetHidden(gomock.Any(), int64(44), true).Return(errors.New("mock db error"))
+				return repo, artRepo, userRepo
+			},
+			id:      4,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo, userRepo := tc.mock(ctrl)
+			// 举报存在的时候不管结果如何都要留下审计日志
+			logRepo := repomocks.NewMockAdminAuditLogRepository(ctrl)
+			logRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, l domain.AdminAuditLog) error {
+				assert.Equal(t, int64(100), l.Operator.Uid)
+				assert.Equal(t, domain.AdminActionAcceptReport, l.Action)
+				assert.Equal(t, tc.id, l.TargetId)
+				return nil
+			})
+			svc := NewReportService(repo, artRepo, userRepo, logRepo, &logger.NopLogger{})
+			err := svc.Accept(context.Background(), domain.AdminOperator{Uid: 100}, tc.id, "违规")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_reportService_Report(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.ReportRepository,
+			repository.ArticleRepository, repository.UserRepository)
+		report  domain.Report
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "举报成功",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				repo := repomocks.NewMockReportRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.Report{
+					Reporter: 1,
+					Biz:      domain.ReportBizArticle,
+					BizId:    2,
+					Reason:   "广告",
+					Status:   domain.ReportStatusPending,
+				}).Return(int64(10), nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.Article{
+					Id:     2,
+					Author: domain.Author{Id: 3},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return repo, artRepo, nil
+			},
+			report: domain.Report{
+				Reporter: 1,
+				Biz:      domain.ReportBizArticle,
+				BizId:    2,
+				Field:    domain.ProfileFieldNickName,
+				Reason:   "广告",
+			},
+			wantId: 10,
+		},
+		{
+			name: "举报资料没有指定字段",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				return repomocks.NewMockReportRepository(ctrl), nil, nil
+			},
+			report: domain.Report{
+				Reporter: 1,
+				Biz:      domain.ReportBizUser,
+				BizId:    2,
+			},
+			wantErr: ErrInvalidReport,
+		},
+		{
+			name: "理由太长",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				return repomocks.NewMockReportRepository(ctrl), nil, nil
+			},
+			report: domain.Report{
+				Reporter: 1,
+				Biz:      domain.ReportBizArticle,
+				BizId:    2,
+				Reason:   strings.Repeat("广", reportReasonMaxLen+1),
+			},
+			wantErr: ErrInvalidReport,
+		},
+		{
+			name: "文章不存在",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.Article{}, repository.ErrArticleNotFound)
+				return repomocks.NewMockReportRepository(ctrl), artRepo, nil
+			},
+			report: domain.Report{
+				Reporter: 1,
+				Biz:      domain.ReportBizArticle,
+				BizId:    2,
+			},
+			wantErr: ErrInvalidReport,
+		},
+		{
+			name: "举报自己的文章",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.Article{
+					Id:     2,
+					Author: domain.Author{Id: 1},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return repomocks.NewMockReportRepository(ctrl), artRepo, nil
+			},
+			report: domain.Report{
+				Reporter: 1,
+				Biz:      domain.ReportBizArticle,
+				BizId:    2,
+			},
+			wantErr: ErrInvalidReport,
+		},
+		{
+			name: "举报自己",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(1)).Return(domain.User{Id: 1}, nil)
+				return repomocks.NewMockReportRepository(ctrl), nil, userRepo
+			},
+			report: domain.Report{
+				Reporter: 1,
+				Biz:      domain.ReportBizUser,
+				BizId:    1,
+				Field:    domain.ProfileFieldNickName,
+			},
+			wantErr: ErrInvalidReport,
+		},
+		{
+			name: "重复举报",
+			mock: func(ctrl *gomock.Controller) (repository.ReportRepository,
+				repository.ArticleRepository, repository.UserRepository) {
+				repo := repomocks.NewMockReportRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(0), repository.ErrReportDuplicate)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				return repo, nil, userRepo
+			},
+			report: domain.Report{
+				Reporter: 1,
+				Biz:      domain.ReportBizUser,
+				BizId:    2,
+				Field:    domain.ProfileFieldAboutMe,
+			},
+			wantErr: ErrReportDuplicate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo, userRepo := tc.mock(ctrl)
+			svc := NewReportService(repo, artRepo, userRepo, nil, &logger.NopLogger{})
+			id, err := svc.Report(context.Background(), tc.report)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+)
+
+var (
+	ErrSeriesNotFound         = repository.ErrSeriesNotFound
+	ErrSeriesArticleDuplicate = repository.ErrSeriesArticleDuplicate
+	ErrSeriesVersionConflict  = repository.ErrSeriesVersionConflict
+	ErrInvalidSeries          = errors.New("专栏参数不合法")
+	// ErrSeriesArticleInvalid 只能添加自己已经发表的文章
+	ErrSeriesArticleInvalid = errors.New("文章不存在或者没有发表")
+)
+
+const (
+	// seriesTitleMaxLen 和 series.title 的长度保持一致
+	seriesTitleMaxLen    = 256
+	seriesMaxArticleSize = 200
+	// seriesUpdateRetry 修改文章列表的时候版本号冲突，重新读取专栏再试的次数
+	seriesUpdateRetry = 3
+)
+
+type SeriesService interface {
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	Rename(ctx context.Context, s domain.Series) error
+	// Reorder 调整文章顺序，articleIds 必须正好是专栏中现有的文章
+	Reorder(ctx context.Context, id, uid int64, articleIds []int64) error
+	// AddArticle 把文章追加到专栏末尾
+	AddArticle(ctx context.Context, id, uid, articleId int64) error
+	RemoveArticle(ctx context.Context, id, uid, articleId int64) error
+	FindById(ctx context.Context, id int64) (domain.Series, error)
+	ListByAuthor(ctx context.Context, uid int64) ([]domain.Series, error)
+	// Navigation 文章在专栏中的上一篇和下一篇，读者看不到的文章会被跳过
+	// 文章不在任何专栏中返回 ErrSeriesNotFound
+	Navigation(ctx context.Context, articleId int64) (domain.SeriesNavigation, error)
+}
+
+type seriesService struct {
+	repo    repository.SeriesRepository
+	artRepo repository.ArticleRepository
+}
+
+func NewSeriesService(repo repository.SeriesRepository, artRepo repository.ArticleRepository) SeriesService {
+	return &seriesService{
+		repo:    repo,
+		artRepo: artRepo,
+	}
+}
+
+func (svc *seriesService) Create(ctx context.Context, s domain.Series) (int64, error) {
+	title, err := svc.checkTitle(s.Title)
+	if err != nil {
+		return 0, err
+	}
+	s.Title = title
+	return svc.repo.Create(ctx, s)
+}
+
+func (svc *seriesService) Rename(ctx context.Context, s domain.Series) error {
+	title, err := svc.checkTitle(s.Title)
+	if err != nil {
+		return err
+	}
+	s.Title = title
+	return svc.repo.UpdateTitle(ctx, s)
+}
+
+func (svc *seriesService) checkTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > seriesTitleMaxLen {
+		return "", ErrInvalidSeries
+	}
+	return title, nil
+}
+
+func (svc *seriesService) Reorder(ctx context.Context, id, uid int64, articleIds []int64) error {
+	return svc.updateArticleIds(ctx, id, uid, func(s domain.Series) ([]int64, error) {
+		if len(articleIds) != len(s.ArticleIds) {
+			return nil, ErrInvalidSeries
+		}
+		current := make(map[int64]struct{}, len(s.ArticleIds))
+		for _, aid := range s.ArticleIds {
+			current[aid] = struct{}{}
+		}
+		for _, aid := range articleIds {
+			if _, ok := current[aid]; !ok {
+				return nil, ErrInvalidSeries
+			}
+			// 去掉已经出现过的，防止重复的 id
+			delete(current, aid)
+		}
+		return articleIds, nil
+	})
+}
+
+func (svc *seriesService) AddArticle(ctx context.Context, id, uid, articleId int64) error {
+	return svc.updateArticleIds(ctx, id, uid, func(s domain.Series) ([]int64, error) {
+		for _, aid := range s.ArticleIds {
+			if aid == articleId {
+				return nil, nil
+			}
+		}
+		if len(s.ArticleIds) >= seriesMaxArticleSize {
+			return nil, ErrInvalidSeries
+		}
+		art, err := svc.artRepo.FindById(ctx, articleId)
+		if errors.Is(err, repository.ErrArticleNotFound) {
+			return nil, ErrSeriesArticleInvalid
+		}
+		if err != nil {
+			return nil, err
+		}
+		if art.Author.Id != uid || art.Status != domain.ArticleStatusPublished {
+			return nil, ErrSeriesArticleInvalid
+		}
+		return append(s.ArticleIds, articleId), nil
+	})
+}
+
+func (svc *seriesService) RemoveArticle(ctx context.Context, id, uid, articleId int64) error {
+	return svc.updateArticleIds(ctx, id, uid, func(s domain.Series) ([]int64, error) {
+		ids := make([]int64, 0, len(s.ArticleIds))
+		for _, aid := range s.ArticleIds {
+			if aid != articleId {
+				ids = append(ids, aid)
+			}
+		}
+		if len(ids) == len(s.ArticleIds) {
+			return nil, nil
+		}
+		return ids, nil
+	})
+}
+
+// updateArticleIds 读取专栏，用 fn 算出新的文章列表再写回去，fn 返回 nil 表示不需要修改
+// 期间专栏被并发修改了就重新读取再算一次，不会丢掉别人刚刚加进去的文章
+func (svc *seriesService) updateArticleIds(ctx context.Context, id, uid int64,
+	fn func(s domain.Series) ([]int64, error)) error {
+	for i := 0; i < seriesUpdateRetry; i++ {
+		s, err := svc.findOwned(ctx, id, uid)
+		if err != nil {
+			return err
+		}
+		ids, err := fn(s)
+		if err != nil || ids == nil {
+			return err
+		}
+		err = svc.repo.SetArticleIds(ctx, id, s.Version, ids)
+		if !errors.Is(err, ErrSeriesVersionConflict) {
+			return err
+		}
+	}
+	return ErrSeriesVersionConflict
+}
+
+func (svc *seriesService) FindById(ctx context.Context, id int64) (domain.Series, error) {
+	return svc.repo.FindById(ctx, id)
+}
+
+func (svc *seriesService) ListByAuthor(ctx context.Context, uid int64) ([]domain.Series, error) {
+	return svc.repo.ListByAuthor(ctx, uid)
+}
+
+// findOwned 查询专栏并校验作者，不是作者本人的专栏当作不存在
+func (svc *seriesService) findOwned(ctx context.Context, id, uid int64) (domain.Series, error) {
+	s, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	if s.Author.Id != uid {
+		return domain.Series{}, ErrSeriesNotFound
+	}
+	return s, nil
+}
+
+func (svc *seriesService) Navigation(ctx context.Context, articleId int64) (domain.SeriesNavigation, error) {
+	s, err := svc.repo.FindByArticle(ctx, articleId)
+	if err != nil {
+		return domain.SeriesNavigation{}, err
+	}
+	pos := -1
+	for i, aid := range s.ArticleIds {
+		if aid == articleId {
+			pos = i
+			break
+		}
+	}
+	nav := domain.SeriesNavigation{Series: s}
+	if pos < 0 {
+		return nav, nil
+	}
+	for i := pos - 1; i >= 0; i-- {
+		art, ok, err := svc.visible(ctx, s.ArticleIds[i])
+		if err != nil {
+			return domain.SeriesNavigation{}, err
+		}
+		if ok {
+			nav.Prev = art
+			break
+		}
+	}
+	for i := pos + 1; i < len(s.ArticleIds); i++ {
+		art, ok, err := svc.visible(ctx, s.ArticleIds[i])
+		if err != nil {
+			return domain.SeriesNavigation{}, err
+		}
+		if ok {
+			nav.Next = art
+			break
+		}
+	}
+	return nav, nil
+}
+
+// visible 文章是否对读者可见，加入专栏之后文章可能被撤回、隐藏或者删除
+func (svc *seriesService) visible(ctx context.Context, articleId int64) (domain.Article, bool, error) {
+	art, err := svc.artRepo.FindById(ctx, articleId)
+	if errors.Is(err, repository.ErrArticleNotFound) {
+		return domain.Article{}, false, nil
+	}
+	if err != nil {
+		return domain.Article{}, false, err
+	}
+	return art, art.Status == domain.ArticleStatusPublished && !art.Hidden, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_seriesService_AddArticle(t *testing.T) {
+	series := domain.Series{
+		Id:         1,
+		Author:     domain.Author{Id: 123},
+		ArticleIds: []int64{10},
+	}
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) (repository.SeriesRepository, repository.ArticleRepository)
+		uid       int64
+		articleId int64
+		wantErr   error
+	}{
+		{
+			name: "添加成功",
+			mock: func(ctrl *gomock.Controller) (repository.SeriesRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).Return(series, nil)
+				repo.EXPECT().SetArticleIds(gomock.Any(), int64(1), int64(0), []int64{10, 11}).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(11)).Return(domain.Article{
+					Id:     11,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return repo, artRepo
+			},
+			uid:       123,
+			articleId: 11,
+		},
+		{
+			name: "并发修改之后重新读取再添加",
+			mock: func(ctrl *gomock.Controller) (repository.SeriesRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).Return(series, nil)
+				repo.EXPECT().SetArticleIds(gomock.Any(), int64(1), int64(0), []int64{10, 11}).Return(ErrSeriesVersionConflict)
+				// 别人在这期间加了一篇文章
+				updated := series
+				updated.ArticleIds = []int64{10, 12}
+				updated.Version = 1
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).Return(updated, nil)
+				repo.EXPECT().SetArticleIds(gomock.Any(), int64(1), int64(1), []int64{10, 12, 11}).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(11)).Return(domain.Article{
+					Id:     11,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+				}, nil).Times(2)
+				return repo, artRepo
+			},
+			uid:       123,
+			articleId: 11,
+		},
+		{
+			name: "不是自己的专栏",
+			mock: func(ctrl *gomock.Controller) (repository.SeriesRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).Return(series, nil)
+				return repo, repomocks.NewMockArticleRepository(ctrl)
+			},
+			uid:       456,
+			articleId: 11,
+			wantErr:   ErrSeriesNotFound,
+		},
+		{
+			name: "不是自己的文章",
+			mock: func(ctrl *gomock.Controller) (repository.SeriesRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).Return(series, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(11)).Return(domain.Article{
+					Id:     11,
+					Author: domain.Author{Id: 456},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return repo, artRepo
+			},
+			uid:       123,
+			articleId: 11,
+			wantErr:   ErrSeriesArticleInvalid,
+		},
+		{
+			name: "文章没有发表",
+			mock: func(ctrl *gomock.Controller) (repository.SeriesRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).Return(series, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().FindById(gomock.Any(), int64(11)).Return(domain.Article{
+					Id:     11,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusUnpublished,
+				}, nil)
+				return repo, artRepo
+			},
+			uid:       123,
+			articleId: 11,
+			wantErr:   ErrSeriesArticleInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewSeriesService(tc.mock(ctrl))
+			err := svc.AddArticle(context.Background(), 1, tc.uid, tc.articleId)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_seriesService_Navigation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	series := domain.Series{
+		Id:         1,
+		Title:      "Go 入门",
+		ArticleIds: []int64{10, 11, 12, 13},
+	}
+	repo := repomocks.NewMockSeriesRepository(ctrl)
+	repo.EXPECT().FindByArticle(gomock.Any(), int64(12)).Return(series, nil)
+	artRepo := repomocks.NewMockArticleRepository(ctrl)
+	// 上一篇被隐藏了，要跳过去
+	artRepo.EXPECT().FindById(gomock.Any(), int64(11)).Return(domain.Article{
+		Id:     11,
+		Status: domain.ArticleStatusPublished,
+		Hidden: true,
+	}, nil)
+	artRepo.EXPECT().FindById(gomock.Any(), int64(10)).Return(domain.Article{
+		Id:     10,
+		Title:  "第一篇",
+		Status: domain.ArticleStatusPublished,
+	}, nil)
+	// 下一篇已经被删除
+	artRepo.EXPECT().FindById(gomock.Any(), int64(13)).Return(domain.Article{}, repository.ErrArticleNotFound)
+	svc := NewSeriesService(repo, artRepo)
+
+	nav, err := svc.Navigation(context.Background(), 12)
+	assert.NoError(t, err)
+	assert.Equal(t, series, nav.Series)
+	assert.Equal(t, int64(10), nav.Prev.Id)
+	assert.Equal(t, int64(0), nav.Next.Id)
+}
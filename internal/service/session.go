@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+)
+
+var ErrSessionNotFound = repository.ErrSessionNotFound
+
+// sessionLifetime 和 refresh token 的有效期一致，登录超过这么久的会话已经不能再刷新 token 了
+const sessionLifetime = time.Hour * 24 * 7
+
+// SessionService 登录设备管理，只负责记录会话，让 token 失效由 jwt 的 Handler 负责
+type SessionService interface {
+	// Create 登录成功之后记录会话
+	Create(ctx context.Context, s domain.Session) error
+	// Find 会话不存在返回 ErrSessionNotFound
+	Find(ctx context.Context, uid int64, ssid string) (domain.Session, error)
+	// List 用户还没有过期的会话，最近活跃的在前面
+	List(ctx context.Context, uid int64) ([]domain.Session, error)
+	// Touch 更新会话的最后活跃时间
+	Touch(ctx context.Context, uid int64, ssid string) error
+	Remove(ctx context.Context, uid int64, ssid string) error
+	RemoveAll(ctx context.Context, uid int64) error
+}
+
+type sessionService struct {
+	repo repository.SessionRepository
+}
+
+func NewSessionService(repo repository.SessionRepository) SessionService {
+	return &sessionService{
+		repo: repo,
+	}
+}
+
+func (svc *sessionService) Create(ctx context.Context, s domain.Session) error {
+	now := time.Now()
+	s.Ctime = now
+	s.LastActive = now
+	return svc.repo.Create(ctx, s)
+}
+
+func (svc *sessionService) Find(ctx context.Context, uid int64, ssid string) (domain.Session, error) {
+	return svc.repo.Find(ctx, uid, ssid)
+}
+
+func (svc *sessionService) List(ctx context.Context, uid int64) ([]domain.Session, error) {
+	sessions, err := svc.repo.List(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	expireBefore := time.Now().Add(-sessionLifetime)
+	res := make([]domain.Session, 0, len(sessions))
+	var expired []string
+	for _, s := range sessions {
+		if s.Ctime.Before(expireBefore) {
+			expired = append(expired, s.Ssid)
+			continue
+		}
+		res = append(res, s)
+	}
+	if len(expired) > 0 {
+		// 顺便清理过期的会话，失败了下一次查询的时候再清理
+		_ = svc.repo.Delete(ctx, uid, expired...)
+	}
+	slices.SortFunc(res, func(a, b domain.Session) int {
+		return b.LastActive.Compare(a.LastActive)
+	})
+	return res, nil
+}
+
+func (svc *sessionService) Touch(ctx context.Context, uid int64, ssid string) error {
+	return svc.repo.Touch(ctx, uid, ssid, time.Now())
+}
+
+func (svc *sessionService) Remove(ctx context.Context, uid int64, ssid string) error {
+	return svc.repo.Delete(ctx, uid, ssid)
+}
+
+func (svc *sessionService) RemoveAll(ctx context.Context, uid int64) error {
+	return svc.repo.DeleteAll(ctx, uid)
+}
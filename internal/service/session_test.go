@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+	repomocks "xiaoweishu/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_sessionService_List(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) repository.SessionRepository
+		wantSsids []string
+		wantErr   error
+	}{
+		{
+			name: "最近活跃的在前面",
+			mock: func(ctrl *gomock.Controller) repository.SessionRepository {
+				repo := repomocks.NewMockSessionRepository(ctrl)
+				repo.EXPECT().List(gomock.Any(), int64(1)).Return([]domain.Session{
+					{Ssid: "a", Ctime: now.Add(-time.Hour), LastActive: now.Add(-time.Minute * 30)},
+					{Ssid: "b", Ctime: now.Add(-time.Hour * 2), LastActive: now.Add(-time.Minute)},
+				}, nil)
+				return repo
+			},
+			wantSsids: []string{"b", "a"},
+		},
+		{
+			name: "清理过期的会话",
+			mock: func(ctrl *gomock.Controller) repository.SessionRepository {
+				repo := repomocks.NewMockSessionRepository(ctrl)
+				repo.EXPECT().List(gomock.Any(), int64(1)).Return([]domain.Session{
+					{Ssid: "a", Ctime: now.Add(-time.Hour), LastActive: now},
+					{Ssid: "b", Ctime: now.Add(-sessionLifetime - time.Hour), LastActive: now},
+				}, nil)
+				repo.EXPECT().Delete(gomock.Any(), int64(1), "b").Return(nil)
+				return repo
+			},
+			wantSsids: []string{"a"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewSessionService(tc.mock(ctrl))
+			sessions, err := svc.List(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			ssids := make([]string, 0, len(sessions))
+			for _, s := range sessions {
+				ssids = append(ssids, s.Ssid)
+			}
+			assert.Equal(t, tc.wantSsids, ssids)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Service 存储在本地磁盘上，由 web 服务器直接提供静态文件访问
+// 只适合单机部署和开发环境
+type Service struct {
+	dir     string
+	baseURL string
+}
+
+func NewService(dir, baseURL string) *Service {
+	return &Service{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (s *Service) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件再改名，避免读到写了一半的文件
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Service) Delete(ctx context.Context, keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		path, err := s.path(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path key 不能跳出存储目录
+func (s *Service) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", errors.New("非法的文件 key " + key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// Service 存储在内存里，用于测试
+type Service struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+func NewService() *Service {
+	return &Service{
+		files: make(map[string][]byte),
+	}
+}
+
+func (s *Service) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[key] = data
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.files, key)
+	}
+	return nil
+}
+
+func (s *Service) URL(key string) string {
+	return "/" + key
+}
+
+// Get 测试中检查写入的文件
+func (s *Service) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.files[key]
+	return data, ok
+}
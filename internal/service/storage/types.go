@@ -0,0 +1,13 @@
+package storage
+
+import "context"
+
+// Service 文件存储，key 是类似 avatar/123/xxx.png 这样的相对路径
+type Service interface {
+	// Put 写入文件，key 已经存在的时候覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete 删除文件，文件不存在不算错误
+	Delete(ctx context.Context, keys ...string) error
+	// URL 文件对外访问的地址
+	URL(key string) string
+}
@@ -6,32 +6,50 @@ import (
 	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/pkg/logger"
 	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service/storage"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrUserDuplicate         = repository.ErrUserDuplicate
+	ErrUserNotFound          = repository.ErrUserNotFound
 	ErrInvalidUserOrPassword = errors.New("账号/邮箱或密码不对")
 )
 
+// dummyPasswordHash 用来在账号不存在的时候做一次同样代价的比较，不对应任何用户的密码
+const dummyPasswordHash = "$2a$10$6RXOSIJyDS6aAGQb2ZcfPe0cpbiJSlbKCOgiM8d4MeDYUi2jS.Rmq"
+
 type UserService interface {
 	SignUp(ctx context.Context, user domain.User) error
 	Login(ctx context.Context, email, password string) (domain.User, error)
 	Profile(ctx context.Context, id int64) (domain.User, error)
 	FindOrCreate(ctx context.Context, phone string) (domain.User, error)
 	FindOrCreateByWechat(ctx context.Context, wechatInfo domain.WechatInfo) (domain.User, error)
+	// UpdateNonSensitiveInfo 修改昵称、生日和个人简介，调用方负责校验输入
+	UpdateNonSensitiveInfo(ctx context.Context, user domain.User) error
+	// UploadAvatar 上传头像，data 是原始的图片文件，成功之后会删除旧的头像
+	UploadAvatar(ctx context.Context, uid int64, data []byte) (domain.Avatar, error)
+	// ChangePassword 修改密码，旧密码不对返回 ErrInvalidUserOrPassword
+	ChangePassword(ctx context.Context, uid int64, oldPassword, newPassword string) error
 }
 
 type userService struct {
-	repo repository.UserRepository
-	l    logger.LoggerV1
+	repo      repository.UserRepository
+	notifySvc NotificationService
+	store     storage.Service
+	l         logger.LoggerV1
 }
 
-func NewUserService(repo repository.UserRepository, l logger.LoggerV1) UserService {
+func NewUserService(repo repository.UserRepository,
+	notifySvc NotificationService,
+	store storage.Service,
+	l logger.LoggerV1) UserService {
 	return &userService{
-		repo: repo,
-		l:    l,
+		repo:      repo,
+		notifySvc: notifySvc,
+		store:     store,
+		l:         l,
 	}
 }
 
@@ -44,18 +62,36 @@ func (svc *userService) SignUp(ctx context.Context, user domain.User) error {
 	return svc.repo.Create(ctx, user)
 }
 
+func (svc *userService) ChangePassword(ctx context.Context, uid int64, oldPassword, newPassword string) error {
+	u, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	// 手机号和微信注册的用户没有密码，只能通过找回密码设置
+	err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(oldPassword))
+	if err != nil {
+		return ErrInvalidUserOrPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return svc.repo.UpdatePassword(ctx, uid, string(hash))
+}
+
 func (svc *userService) Login(ctx context.Context, email, password string) (domain.User, error) {
 	// 先找到用户
 	u, err := svc.repo.FindByEmail(ctx, email)
-	if err == repository.ErrUserNotFound {
-		return domain.User{}, ErrInvalidUserOrPassword
-	}
-	if err != nil {
+	if err != nil && err != repository.ErrUserNotFound {
 		return domain.User{}, err
 	}
+	// 账号不存在或者没有设置密码也要比较一次，响应时间一样，没有办法据此判断账号是否存在
+	hash := dummyPasswordHash
+	if err == nil && u.Password != "" {
+		hash = u.Password
+	}
 	// 比较密码
-	err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
-	if err != nil {
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil || hash == dummyPasswordHash {
 		// 日志 DEBUG
 		return domain.User{}, ErrInvalidUserOrPassword
 	}
@@ -63,15 +99,24 @@ func (svc *userService) Login(ctx context.Context, email, password string) (doma
 }
 
 func (svc *userService) Profile(ctx context.Context, id int64) (domain.User, error) {
-	return svc.repo.FindById(ctx, id)
+	u, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return domain.User{}, err
+	}
+	u.Avatar = svc.avatar(u.Avatar.Key)
+	return u, nil
+}
+
+func (svc *userService) UpdateNonSensitiveInfo(ctx context.Context, user domain.User) error {
+	return svc.repo.UpdateNonSensitiveInfo(ctx, user)
 }
 
 func (svc *userService) FindOrCreate(ctx context.Context, phone string) (domain.User, error) {
 	u, err := svc.repo.FindByPhone(ctx, phone)
 	if err != repository.ErrUserNotFound {
-		// nil 会进入这里
+		// nil 会进入这里，说明已经注册过了
 		// 不为 ErrUserNotFound， 也会进来这里
-		return domain.User{}, err
+		return u, err
 		// 不存在，创建一个
 	}
 	u = domain.User{
@@ -89,9 +134,9 @@ func (svc *userService) FindOrCreateByWechat(ctx context.Context, wechatInfo dom
 	u, err := svc.repo.FindByWechat(ctx, wechatInfo.OpenID)
 	// 快路径
 	if !errors.Is(err, repository.ErrUserNotFound) {
-		// nil 会进入这里
+		// nil 会进入这里，说明已经注册过了
 		// 不为 ErrUserNotFound， 也会进来这里
-		return domain.User{}, err
+		return u, err
 		// 不存在，创建一个
 	}
 	u = domain.User{
@@ -101,6 +146,22 @@ func (svc *userService) FindOrCreateByWechat(ctx context.Context, wechatInfo dom
 	if err != nil && !errors.Is(err, ErrUserDuplicate) {
 		return u, err
 	}
+	// 并发的时候别人已经创建好了，就不需要再通知了
+	created := err == nil
 	// 此处会遇到主从延迟的问题，如果真的遇到，只能改 svc.repo.Create 方法，让它返回 id
-	return svc.repo.FindByWechat(ctx, wechatInfo.OpenID)
+	u, err = svc.repo.FindByWechat(ctx, wechatInfo.OpenID)
+	if err != nil || !created {
+		return u, err
+	}
+	// 通知失败不影响登录
+	err = svc.notifySvc.Notify(ctx, domain.Notification{
+		Uid:     u.Id,
+		Biz:     domain.NotificationBizWechatBind,
+		AggKey:  domain.NotificationBizWechatBind,
+		Content: "你的账号已经绑定微信",
+	})
+	if err != nil {
+		svc.l.Error("发送绑定微信通知失败", logger.Int64("uid", u.Id), logger.Error(err))
+	}
+	return u, nil
 }
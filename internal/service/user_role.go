@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/repository"
+)
+
+// UserRoleService 用户的角色，签发 token 的时候放进 UserClaims
+type UserRoleService interface {
+	// Roles 用户的角色，不认识的角色会被忽略，普通用户返回空切片
+	Roles(ctx context.Context, uid int64) ([]string, error)
+}
+
+type userRoleService struct {
+	repo repository.UserRoleRepository
+}
+
+func NewUserRoleService(repo repository.UserRoleRepository) UserRoleService {
+	return &userRoleService{
+		repo: repo,
+	}
+}
+
+func (svc *userRoleService) Roles(ctx context.Context, uid int64) ([]string, error) {
+	roles, err := svc.repo.FindRoles(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if domain.ValidRole(r) {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
 	"xiaoweishu/internal/repository"
 	repomocks "xiaoweishu/internal/repository/mocks"
 )
@@ -56,7 +57,7 @@ func Test_userService_Login(t *testing.T) {
 			mock: func(ctrl *gomock.Controller) repository.UserRepository {
 				userRepo := repomocks.NewMockUserRepository(ctrl)
 				userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
-					Return(domain.User{}, ErrInvalidUserOrPassword).Times(1)
+					Return(domain.User{}, repository.ErrUserNotFound).Times(1)
 				return userRepo
 			},
 			email:    "123@qq.com",
@@ -90,13 +91,26 @@ func Test_userService_Login(t *testing.T) {
 			wantUser: domain.User{},
 			wantErr:  ErrInvalidUserOrPassword,
 		},
+		{
+			name: "没有设置密码",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "1234@qq.com").
+					Return(domain.User{Email: "1234@qq.com"}, nil).Times(1)
+				return userRepo
+			},
+			email:    "1234@qq.com",
+			password: "hello@world123",
+			wantUser: domain.User{},
+			wantErr:  ErrInvalidUserOrPassword,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			svc := NewUserService(tc.mock(ctrl))
+			svc := NewUserService(tc.mock(ctrl), nil, nil, &logger.NopLogger{})
 			u, err := svc.Login(tc.ctx, tc.email, tc.password)
 			assert.Equal(t, tc.wantErr, err)
 			assert.Equal(t, tc.wantUser, u)
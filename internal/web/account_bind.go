@@ -0,0 +1,193 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	regexp "github.com/dlclark/regexp2"
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*AccountBindHandler)(nil)
+
+// AccountBindHandler 绑定和解绑登录方式，微信的绑定在 Oauth2WechatHandler 里面
+type AccountBindHandler struct {
+	svc      service.AccountBindService
+	emailExp *regexp.Regexp
+	l        logger.LoggerV1
+}
+
+func NewAccountBindHandler(svc service.AccountBindService, l logger.LoggerV1) *AccountBindHandler {
+	const emailRegexPattern = `^\w+([-+.]\w+)*@\w+([-.]\w+)*\.\w+([-.]\w+)*$`
+	return &AccountBindHandler{
+		svc:      svc,
+		emailExp: regexp.MustCompile(emailRegexPattern, regexp.None),
+		l:        l,
+	}
+}
+
+func (h *AccountBindHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/bind")
+	g.POST("/phone/send", h.SendPhoneCode)
+	g.POST("/phone", h.BindPhone)
+	g.POST("/email/send", h.SendEmailCode)
+	g.POST("/email", h.BindEmail)
+	server.POST("/users/unbind", h.Unbind)
+}
+
+type BindReq struct {
+	// Target 手机号或者邮箱
+	Target string `json:"target"`
+	Code   string `json:"code"`
+}
+
+type UnbindReq struct {
+	// Method 取值 phone、email、wechat
+	Method string `json:"method"`
+}
+
+func (h *AccountBindHandler) SendPhoneCode(ctx *gin.Context) {
+	var req BindReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Target == "" {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "手机号不能为空",
+		})
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.SendPhoneCode(ctx, claims.Uid, req.Target)
+	if err != nil {
+		h.handleErr(ctx, "发送绑定手机号验证码失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "验证码已经发送",
+	})
+}
+
+func (h *AccountBindHandler) BindPhone(ctx *gin.Context) {
+	var req BindReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.BindPhone(ctx, claims.Uid, req.Target, req.Code)
+	if err != nil {
+		h.handleErr(ctx, "绑定手机号失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *AccountBindHandler) SendEmailCode(ctx *gin.Context) {
+	var req BindReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	ok, err := h.emailExp.MatchString(req.Target)
+	if err != nil || !ok {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "你的邮箱格式不正确",
+		})
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err = h.svc.SendEmailCode(ctx, claims.Uid, req.Target)
+	if err != nil {
+		h.handleErr(ctx, "发送绑定邮箱验证码失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "验证码已经发送",
+	})
+}
+
+func (h *AccountBindHandler) BindEmail(ctx *gin.Context) {
+	var req BindReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.BindEmail(ctx, claims.Uid, req.Target, req.Code)
+	if err != nil {
+		h.handleErr(ctx, "绑定邮箱失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *AccountBindHandler) Unbind(ctx *gin.Context) {
+	var req UnbindReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	switch req.Method {
+	case domain.LoginMethodPhone, domain.LoginMethodEmail, domain.LoginMethodWechat:
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "不支持的登录方式",
+		})
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.Unbind(ctx, claims.Uid, req.Method)
+	if err != nil {
+		h.handleErr(ctx, "解绑失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+// handleErr 绑定相关的错误处理，唯一索引冲突使用单独的错误码
+func (h *AccountBindHandler) handleErr(ctx *gin.Context, msg string, uid int64, err error) {
+	switch {
+	case errors.Is(err, service.ErrBindCodeInvalid):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "验证码不对",
+		})
+	case errors.Is(err, service.ErrCodeSendTooMany):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "发送太频繁，请稍后再试",
+		})
+	case errors.Is(err, service.ErrUserPhoneDuplicate):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: CodeUserPhoneBound,
+			Msg:  "手机号已经被其它账号绑定",
+		})
+	case errors.Is(err, service.ErrUserEmailDuplicate):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: CodeUserEmailBound,
+			Msg:  "邮箱已经被其它账号绑定",
+		})
+	case errors.Is(err, service.ErrUserLastLoginMethod):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: CodeUserLastLoginMethod,
+			Msg:  "至少要保留一种登录方式",
+		})
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(msg, logger.Int64("uid", uid), logger.Error(err))
+	}
+}
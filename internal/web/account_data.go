@@ -0,0 +1,94 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*AccountDataHandler)(nil)
+
+// AccountDataHandler 导出个人数据和注销账号
+type AccountDataHandler struct {
+	svc service.AccountDataService
+	l   logger.LoggerV1
+}
+
+func NewAccountDataHandler(svc service.AccountDataService, l logger.LoggerV1) *AccountDataHandler {
+	return &AccountDataHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *AccountDataHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users")
+	g.GET("/export", h.Export)
+	g.POST("/delete", h.ScheduleDeletion)
+	g.POST("/delete/cancel", h.CancelDeletion)
+}
+
+func (h *AccountDataHandler) Export(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	filename := fmt.Sprintf("xiaoweishu-%d-%s.json", claims.Uid, time.Now().Format("20060102150405"))
+	ctx.Header("Content-Type", "application/json; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Status(http.StatusOK)
+	// 和导出文章一样，响应头已经发出去了，出错只能中断连接
+	err := h.svc.Export(ctx, claims.Uid, ctx.Writer)
+	if err != nil {
+		h.l.Error("导出个人数据失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		_ = ctx.Error(err)
+		ctx.Abort()
+	}
+}
+
+func (h *AccountDataHandler) ScheduleDeletion(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	at, err := h.svc.ScheduleDeletion(ctx, claims.Uid)
+	if err != nil {
+		h.handleErr(ctx, "申请注销失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+		Data: map[string]string{
+			"delete_at": at.Format(time.DateTime),
+		},
+	})
+}
+
+func (h *AccountDataHandler) CancelDeletion(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.CancelDeletion(ctx, claims.Uid)
+	if err != nil {
+		h.handleErr(ctx, "取消注销失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *AccountDataHandler) handleErr(ctx *gin.Context, msg string, uid int64, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserDeleted):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "账号已经注销",
+		})
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(msg, logger.Int64("uid", uid), logger.Error(err))
+	}
+}
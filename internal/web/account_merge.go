@@ -0,0 +1,182 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*AccountMergeHandler)(nil)
+
+// AccountMergeHandler 把其它账号合并到当前登录的账号，微信账号的合并在 Oauth2WechatHandler 里面
+type AccountMergeHandler struct {
+	svc    service.AccountMergeService
+	jwtHdl ijwt.Handler
+	l      logger.LoggerV1
+}
+
+func NewAccountMergeHandler(svc service.AccountMergeService, jwtHdl ijwt.Handler, l logger.LoggerV1) *AccountMergeHandler {
+	return &AccountMergeHandler{
+		svc:    svc,
+		jwtHdl: jwtHdl,
+		l:      l,
+	}
+}
+
+func (h *AccountMergeHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/merge")
+	g.POST("/send", h.SendCode)
+	g.POST("", h.Merge)
+	g.GET("/logs", h.Logs)
+}
+
+type MergeReq struct {
+	// Target 要合并的账号的手机号或者邮箱
+	Target string `json:"target"`
+	// Code 和 Password 二选一
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+type UserMergeLogVO struct {
+	Id            int64    `json:"id"`
+	SurvivorId    int64    `json:"survivor_id"`
+	MergedId      int64    `json:"merged_id"`
+	MovedFields   []string `json:"moved_fields"`
+	DroppedFields []string `json:"dropped_fields"`
+	ArticleCnt    int      `json:"article_cnt"`
+	SeriesCnt     int      `json:"series_cnt"`
+	Ctime         string   `json:"ctime"`
+}
+
+func newUserMergeLogVO(l domain.UserMergeLog) UserMergeLogVO {
+	return UserMergeLogVO{
+		Id:            l.Id,
+		SurvivorId:    l.SurvivorId,
+		MergedId:      l.MergedId,
+		MovedFields:   l.MovedFields,
+		DroppedFields: l.DroppedFields,
+		ArticleCnt:    l.ArticleCnt,
+		SeriesCnt:     l.SeriesCnt,
+		Ctime:         l.Ctime.Format(time.DateTime),
+	}
+}
+
+func (h *AccountMergeHandler) SendCode(ctx *gin.Context) {
+	var req MergeReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Target == "" {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "请输入要合并的账号的手机号或者邮箱",
+		})
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.SendCode(ctx, claims.Uid, req.Target)
+	if err != nil {
+		h.handleErr(ctx, "发送合并账号验证码失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "验证码已经发送",
+	})
+}
+
+func (h *AccountMergeHandler) Merge(ctx *gin.Context) {
+	var req MergeReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	log, err := h.svc.Merge(ctx, claims.Uid, req.Target, req.Code, req.Password)
+	if err != nil {
+		h.handleErr(ctx, "合并账号失败", claims.Uid, err)
+		return
+	}
+	// 被合并账号的登录全部失效
+	if err = h.jwtHdl.RevokeSessions(ctx, log.MergedId); err != nil {
+		// 合并已经完成，被合并账号的 token 过期之后也就失效了
+		h.l.Error("撤销被合并账号的登录失败", logger.Int64("uid", log.MergedId), logger.Error(err))
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg:  "OK",
+		Data: newUserMergeLogVO(log),
+	})
+}
+
+func (h *AccountMergeHandler) Logs(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	logs, err := h.svc.ListLogs(ctx, claims.Uid)
+	if err != nil {
+		h.handleErr(ctx, "查询合并记录失败", claims.Uid, err)
+		return
+	}
+	res := make([]UserMergeLogVO, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, newUserMergeLogVO(l))
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+func (h *AccountMergeHandler) handleErr(ctx *gin.Context, msg string, uid int64, err error) {
+	switch {
+	case errors.Is(err, service.ErrMergeTargetInvalid):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "要合并的账号不存在",
+		})
+	case errors.Is(err, service.ErrMergeProofInvalid):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "验证码或者密码不对",
+		})
+	case errors.Is(err, service.ErrMergeTooMany), errors.Is(err, service.ErrCodeSendTooMany):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "操作太频繁，请稍后再试",
+		})
+	case errors.Is(err, service.ErrMergeBanned):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "封禁或者限制中的账号不能合并",
+		})
+	case errors.Is(err, service.ErrMergeUnverified):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: CodeUserEmailUnverified,
+			Msg:  "请先验证邮箱再合并账号",
+		})
+	case errors.Is(err, service.ErrMergeDeleting):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "申请注销的账号不能合并",
+		})
+	case errors.Is(err, service.ErrMergeMFA):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "要合并的账号开启了两步验证，请先给当前账号开启两步验证",
+		})
+	case errors.Is(err, service.ErrUserMerged):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: CodeUserMerged,
+			Msg:  "账号已经被合并过了",
+		})
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(msg, logger.Int64("uid", uid), logger.Error(err))
+	}
+}
@@ -0,0 +1,329 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*AdminHandler)(nil)
+
+// AdminHandler 管理后台，权限由 PermissionMiddlewareBuilder 按照路径校验
+type AdminHandler struct {
+	svc    service.AdminService
+	jwtHdl ijwt.Handler
+	l      logger.LoggerV1
+}
+
+func NewAdminHandler(svc service.AdminService, jwtHdl ijwt.Handler, l logger.LoggerV1) *AdminHandler {
+	return &AdminHandler{
+		svc:    svc,
+		jwtHdl: jwtHdl,
+		l:      l,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/admin")
+	g.POST("/users/search", h.SearchUsers)
+	g.POST("/users/:id/ban", h.BanUser)
+	g.POST("/users/:id/unban", h.UnbanUser)
+	g.GET("/articles/:id", h.ViewArticle)
+	g.POST("/articles/:id/hide", h.HideArticle)
+	g.POST("/audit_logs", h.AuditLogs)
+}
+
+type AdminUserVO struct {
+	Id            int64  `json:"id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Phone         string `json:"phone"`
+	NickName      string `json:"nick_name"`
+	MergedInto    int64  `json:"merged_into,omitempty"`
+	// BanStatus 当前生效的封禁状态，取值 none、restricted、banned
+	BanStatus string `json:"ban_status"`
+	Ban       *BanVO `json:"ban,omitempty"`
+	Ctime     string `json:"ctime"`
+}
+
+type AdminArticleVO struct {
+	Id       int64    `json:"id"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	AuthorId int64    `json:"author_id"`
+	Tags     []string `json:"tags"`
+	Status   uint8    `json:"status"`
+	Hidden   bool     `json:"hidden"`
+	Deleted  bool     `json:"deleted"`
+	Ctime    string   `json:"ctime"`
+	Utime    string   `json:"utime"`
+}
+
+type AdminAuditLogVO struct {
+	Id         int64  `json:"id"`
+	OperatorId int64  `json:"operator_id"`
+	IP         string `json:"ip"`
+	Action     string `json:"action"`
+	TargetId   int64  `json:"target_id"`
+	Detail     string `json:"detail"`
+	Ctime      string `json:"ctime"`
+}
+
+func (h *AdminHandler) SearchUsers(ctx *gin.Context) {
+	type Req struct {
+		Keyword string `json:"keyword"`
+		PageReq
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Keyword == "" {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "请输入关键字",
+		})
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 100
+	}
+	op := adminOperator(ctx)
+	us, err := h.svc.SearchUsers(ctx, op, req.Keyword, req.Offset, req.Limit)
+	if err != nil {
+		h.handleErr(ctx, "搜索用户失败", op, 0, err)
+		return
+	}
+	now := time.Now()
+	res := make([]AdminUserVO, 0, len(us))
+	for _, u := range us {
+		status := u.Ban.Effective(now)
+		vo := AdminUserVO{
+			Id:            u.Id,
+			Email:         u.Email,
+			EmailVerified: u.EmailVerified,
+			Phone:         u.Phone,
+			NickName:      u.NickName,
+			MergedInto:    u.MergedInto,
+			BanStatus:     status.String(),
+			Ctime:         u.Ctime.Format(time.DateTime),
+		}
+		if status != domain.UserBanStatusNone {
+			ban := NewBanVO(u.Ban)
+			vo.Ban = &ban
+		}
+		res = append(res, vo)
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+func (h *AdminHandler) BanUser(ctx *gin.Context) {
+	type Req struct {
+		// Status 取值 banned 或者 restricted
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+		// Hours 封禁的小时数，0 表示永久
+		Hours int64 `json:"hours"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uid, ok := h.idParam(ctx)
+	if !ok {
+		return
+	}
+	ban := domain.UserBan{Reason: req.Reason}
+	switch req.Status {
+	case domain.UserBanStatusBanned.String():
+		ban.Status = domain.UserBanStatusBanned
+	case domain.UserBanStatusRestricted.String():
+		ban.Status = domain.UserBanStatusRestricted
+	}
+	if ban.Status == domain.UserBanStatusNone || req.Hours < 0 {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	if req.Reason == "" {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "请填写封禁的原因",
+		})
+		return
+	}
+	if req.Hours > 0 {
+		ban.Until = time.Now().Add(time.Duration(req.Hours) * time.Hour)
+	}
+	op := adminOperator(ctx)
+	if err := h.svc.BanUser(ctx, op, uid, ban); err != nil {
+		h.handleErr(ctx, "封禁账号失败", op, uid, err)
+		return
+	}
+	if ban.Status == domain.UserBanStatusBanned {
+		// 已经封禁了，撤销失败只是已经登录的设备还能用到 token 过期
+		if err := h.jwtHdl.RevokeSessions(ctx, uid); err != nil {
+			h.l.Error("封禁账号之后撤销会话失败", logger.Int64("uid", uid), logger.Error(err))
+		}
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *AdminHandler) UnbanUser(ctx *gin.Context) {
+	uid, ok := h.idParam(ctx)
+	if !ok {
+		return
+	}
+	op := adminOperator(ctx)
+	if err := h.svc.UnbanUser(ctx, op, uid); err != nil {
+		h.handleErr(ctx, "解除封禁失败", op, uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *AdminHandler) ViewArticle(ctx *gin.Context) {
+	id, ok := h.idParam(ctx)
+	if !ok {
+		return
+	}
+	op := adminOperator(ctx)
+	art, err := h.svc.ViewArticle(ctx, op, id)
+	if err != nil {
+		h.handleErr(ctx, "查看文章失败", op, id, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: AdminArticleVO{
+			Id:       art.Id,
+			Title:    art.Title,
+			Content:  art.Content,
+			AuthorId: art.Author.Id,
+			Tags:     art.Tags,
+			Status:   art.Status.ToUint8(),
+			Hidden:   art.Hidden,
+			Deleted:  !art.DeletedAt.IsZero(),
+			Ctime:    art.Ctime.Format(time.DateTime),
+			Utime:    art.Utime.Format(time.DateTime),
+		},
+	})
+}
+
+func (h *AdminHandler) HideArticle(ctx *gin.Context) {
+	type Req struct {
+		// Hidden 为 false 的时候取消隐藏
+		Hidden bool   `json:"hidden"`
+		Reason string `json:"reason"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	id, ok := h.idParam(ctx)
+	if !ok {
+		return
+	}
+	op := adminOperator(ctx)
+	if err := h.svc.HideArticle(ctx, op, id, req.Hidden, req.Reason); err != nil {
+		h.handleErr(ctx, "隐藏文章失败", op, id, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *AdminHandler) AuditLogs(ctx *gin.Context) {
+	type Req struct {
+		// OperatorId 为 0 的时候查询所有管理员的记录
+		OperatorId int64 `json:"operator_id"`
+		PageReq
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 100
+	}
+	logs, err := h.svc.AuditLogs(ctx, req.OperatorId, req.Offset, req.Limit)
+	if err != nil {
+		h.handleErr(ctx, "查询审计日志失败", adminOperator(ctx), 0, err)
+		return
+	}
+	res := make([]AdminAuditLogVO, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, AdminAuditLogVO{
+			Id:         l.Id,
+			OperatorId: l.Operator.Uid,
+			IP:         l.Operator.IP,
+			Action:     l.Action,
+			TargetId:   l.TargetId,
+			Detail:     l.Detail,
+			Ctime:      l.Ctime.Format(time.DateTime),
+		})
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+// adminOperator 管理员操作都要记录是谁、从哪里操作的
+func adminOperator(ctx *gin.Context) domain.AdminOperator {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	return domain.AdminOperator{
+		Uid: claims.Uid,
+		IP:  ctx.ClientIP(),
+	}
+}
+
+func (h *AdminHandler) idParam(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *AdminHandler) handleErr(ctx *gin.Context, msg string, op domain.AdminOperator, targetId int64, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		// 用户和文章的未找到是同一个 gorm.ErrRecordNotFound
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "找不到操作的对象",
+		})
+	case errors.Is(err, service.ErrAdminTargetSelf):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "不能封禁自己的账号",
+		})
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(msg, logger.Int64("operator", op.Uid),
+			logger.Int64("target", targetId), logger.Error(err))
+	}
+}
@@ -1,7 +1,10 @@
 package web
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/pkg/ginx"
 	"xiaoweishu/internal/pkg/logger"
@@ -14,14 +17,21 @@ import (
 var _ handler = (*ArticleHandler)(nil)
 
 type ArticleHandler struct {
-	svc service.ArticleService
-	l   logger.LoggerV1
+	svc       service.ArticleService
+	seriesSvc service.SeriesService
+	userSvc   service.UserService
+	l         logger.LoggerV1
 }
 
-func NewArticleHandler(svc service.ArticleService, l logger.LoggerV1) *ArticleHandler {
+func NewArticleHandler(svc service.ArticleService,
+	seriesSvc service.SeriesService,
+	userSvc service.UserService,
+	l logger.LoggerV1) *ArticleHandler {
 	return &ArticleHandler{
-		svc: svc,
-		l:   l,
+		svc:       svc,
+		seriesSvc: seriesSvc,
+		userSvc:   userSvc,
+		l:         l,
 	}
 }
 
@@ -29,12 +39,77 @@ func (a *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 	ug := server.Group("/articles")
 	ug.POST("/edit", a.Edit)
 	ug.POST("/publish", a.Publish)
+	ug.GET("/pub/:id", a.PubDetail)
+	ug.POST("/delete", a.Delete)
+	ug.POST("/recycle_bin", a.RecycleBin)
+	ug.POST("/restore", a.Restore)
 }
 
 type ArticleReq struct {
-	Id      int64  `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Id      int64    `json:"id"`
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+	// Version 修改已有文章时带上读到的版本号，保存成功之后版本号加一
+	Version int64 `json:"version"`
+}
+
+type ArticleVO struct {
+	Id       int64    `json:"id"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	AuthorId int64    `json:"author_id"`
+	Tags     []string `json:"tags"`
+	Status   uint8    `json:"status"`
+	Version  int64    `json:"version"`
+	// Author 作者的昵称和头像，只有读者查看文章的时候有
+	Author *AuthorVO `json:"author,omitempty"`
+	// Series 文章所在的专栏，不在专栏中就没有这个字段
+	Series *SeriesNavVO `json:"series,omitempty"`
+}
+
+type AuthorVO struct {
+	Id     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Avatar AvatarVO `json:"avatar"`
+}
+
+type SeriesNavVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	// Prev 和 Next 为空表示已经是第一篇或者最后一篇
+	Prev *SeriesArticleVO `json:"prev,omitempty"`
+	Next *SeriesArticleVO `json:"next,omitempty"`
+}
+
+type SeriesArticleVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type RecycledArticleVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	// DeletedAt 删除时间
+	DeletedAt string `json:"deleted_at"`
+	// ExpireAt 超过这个时间就不能恢复了
+	ExpireAt string `json:"expire_at"`
+	Version  int64  `json:"version"`
+}
+
+type ArticleIdReq struct {
+	Id int64 `json:"id"`
+}
+
+// ArticleSaveVO 保存和发表之后返回新的版本号，下一次保存要带上
+type ArticleSaveVO struct {
+	Id      int64 `json:"id"`
+	Version int64 `json:"version"`
+}
+
+type ArticleRestoreReq struct {
+	Id      int64 `json:"id"`
+	Version int64 `json:"version"`
 }
 
 func (req ArticleReq) toDomain(uid int64) domain.Article {
@@ -42,6 +117,8 @@ func (req ArticleReq) toDomain(uid int64) domain.Article {
 		Id:      req.Id,
 		Title:   req.Title,
 		Content: req.Content,
+		Tags:    req.Tags,
+		Version: req.Version,
 		Author: domain.Author{
 			Id: uid,
 		},
@@ -67,7 +144,14 @@ func (a *ArticleHandler) Edit(ctx *gin.Context) {
 	// 检测输入
 
 	// 调用 svc
-	id, err := a.svc.Save(ctx, req.toDomain(claims.Uid))
+	art, err := a.svc.Save(ctx, req.toDomain(claims.Uid))
+	if errors.Is(err, service.ErrArticleVersionConflict) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: CodeArticleVersionConflict,
+			Msg:  "文章已经在别处被修改，请刷新后再编辑",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, ginx.Result{
 			Code: 5,
@@ -77,8 +161,11 @@ func (a *ArticleHandler) Edit(ctx *gin.Context) {
 		return
 	}
 	ctx.JSON(http.StatusOK, ginx.Result{
-		Msg:  "OK",
-		Data: id,
+		Msg: "OK",
+		Data: ArticleSaveVO{
+			Id:      art.Id,
+			Version: art.Version,
+		},
 	})
 }
 
@@ -99,7 +186,14 @@ func (a *ArticleHandler) Publish(ctx *gin.Context) {
 		return
 	}
 	// 调用 svc
-	id, err := a.svc.Publish(ctx, req.toDomain(claims.Uid))
+	art, err := a.svc.Publish(ctx, req.toDomain(claims.Uid))
+	if errors.Is(err, service.ErrArticleVersionConflict) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: CodeArticleVersionConflict,
+			Msg:  "文章已经在别处被修改，请刷新后再编辑",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, ginx.Result{
 			Code: 5,
@@ -109,7 +203,186 @@ func (a *ArticleHandler) Publish(ctx *gin.Context) {
 		return
 	}
 	ctx.JSON(http.StatusOK, ginx.Result{
-		Msg:  "OK",
-		Data: id,
+		Msg: "OK",
+		Data: ArticleSaveVO{
+			Id:      art.Id,
+			Version: art.Version,
+		},
+	})
+}
+
+func (a *ArticleHandler) PubDetail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	// 没有登录的读者 uid 为 0，只能看到已经发表的文章
+	var uid int64
+	if val, ok := ctx.Get("claims"); ok {
+		uid = val.(*ijwt.UserClaims).Uid
+	}
+	art, err := a.svc.GetPublishedById(ctx, id, uid)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		a.l.Error("查询文章失败", logger.Int64("id", id), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: ArticleVO{
+			Id:       art.Id,
+			Title:    art.Title,
+			Content:  art.Content,
+			AuthorId: art.Author.Id,
+			Tags:     art.Tags,
+			Status:   art.Status.ToUint8(),
+			Version:  art.Version,
+			Author:   a.author(ctx, art.Author.Id),
+			Series:   a.seriesNav(ctx, art.Id),
+		},
+	})
+}
+
+// author 作者信息查询失败也不影响阅读文章
+func (a *ArticleHandler) author(ctx *gin.Context, uid int64) *AuthorVO {
+	u, err := a.userSvc.Profile(ctx, uid)
+	if err != nil {
+		a.l.Error("查询作者信息失败", logger.Int64("uid", uid), logger.Error(err))
+		return nil
+	}
+	return &AuthorVO{
+		Id:     u.Id,
+		Name:   u.NickName,
+		Avatar: newAvatarVO(u.Avatar),
+	}
+}
+
+// seriesNav 专栏导航只是辅助信息，查询失败也不影响阅读文章
+func (a *ArticleHandler) seriesNav(ctx *gin.Context, id int64) *SeriesNavVO {
+	nav, err := a.seriesSvc.Navigation(ctx, id)
+	if errors.Is(err, service.ErrSeriesNotFound) {
+		return nil
+	}
+	if err != nil {
+		a.l.Error("查询专栏导航失败", logger.Int64("id", id), logger.Error(err))
+		return nil
+	}
+	vo := &SeriesNavVO{
+		Id:    nav.Series.Id,
+		Title: nav.Series.Title,
+	}
+	if nav.Prev.Id > 0 {
+		vo.Prev = &SeriesArticleVO{Id: nav.Prev.Id, Title: nav.Prev.Title}
+	}
+	if nav.Next.Id > 0 {
+		vo.Next = &SeriesArticleVO{Id: nav.Next.Id, Title: nav.Next.Title}
+	}
+	return vo
+}
+
+func (a *ArticleHandler) Delete(ctx *gin.Context) {
+	var req ArticleIdReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := a.svc.Delete(ctx, req.Id, claims.Uid)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		a.l.Error("删除文章失败", logger.Int64("id", req.Id), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (a *ArticleHandler) RecycleBin(ctx *gin.Context) {
+	var req PageReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 100
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	arts, err := a.svc.ListRecycleBin(ctx, claims.Uid, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		a.l.Error("查询回收站失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	res := make([]RecycledArticleVO, 0, len(arts))
+	for _, art := range arts {
+		res = append(res, RecycledArticleVO{
+			Id:        art.Id,
+			Title:     art.Title,
+			DeletedAt: art.DeletedAt.Format(time.DateTime),
+			ExpireAt:  art.DeletedAt.Add(service.ArticleRecycleRetention).Format(time.DateTime),
+			Version:   art.Version,
+		})
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+func (a *ArticleHandler) Restore(ctx *gin.Context) {
+	var req ArticleRestoreReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := a.svc.Restore(ctx, req.Id, claims.Uid, req.Version)
+	if errors.Is(err, service.ErrArticleVersionConflict) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: CodeArticleVersionConflict,
+			Msg:  "文章已经在别处被修改，请刷新后再恢复",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "文章不存在或者已经超过可恢复时间",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		a.l.Error("恢复文章失败", logger.Int64("id", req.Id), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
 	})
 }
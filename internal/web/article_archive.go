@@ -0,0 +1,141 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*ArticleArchiveHandler)(nil)
+
+// archiveMaxUploadSize 导入的压缩包大小上限
+const archiveMaxUploadSize = 10 << 20
+
+// ArticleArchiveHandler 文章的导入导出
+type ArticleArchiveHandler struct {
+	svc service.ArticleArchiveService
+	l   logger.LoggerV1
+}
+
+func NewArticleArchiveHandler(svc service.ArticleArchiveService, l logger.LoggerV1) *ArticleArchiveHandler {
+	return &ArticleArchiveHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *ArticleArchiveHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles")
+	g.GET("/export", h.Export)
+	g.POST("/import", h.Import)
+}
+
+type ImportResultVO struct {
+	File  string `json:"file"`
+	Id    int64  `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+func (h *ArticleArchiveHandler) Export(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	filename := fmt.Sprintf("articles-%s.zip", time.Now().Format("20060102150405"))
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Status(http.StatusOK)
+	// 边查询边写，响应头已经发出去了，出错只能中断连接
+	err := h.svc.Export(ctx, claims.Uid, ctx.Writer)
+	if err != nil {
+		h.l.Error("导出文章失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		_ = ctx.Error(err)
+		ctx.Abort()
+	}
+}
+
+func (h *ArticleArchiveHandler) Import(ctx *gin.Context) {
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "请上传 zip 文件",
+		})
+		return
+	}
+	if fh.Size > archiveMaxUploadSize {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "文件太大",
+		})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("打开上传文件失败", logger.Error(err))
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, archiveMaxUploadSize))
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("读取上传文件失败", logger.Error(err))
+		return
+	}
+
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	results, err := h.svc.Import(ctx, claims.Uid, bytes.NewReader(data), int64(len(data)))
+	if errors.Is(err, service.ErrInvalidArchive) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("导入文章失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+
+	vos := make([]ImportResultVO, 0, len(results))
+	for _, res := range results {
+		vo := ImportResultVO{
+			File: res.File,
+			Id:   res.Id,
+		}
+		switch {
+		case res.Err == nil:
+		case errors.Is(res.Err, service.ErrArchiveFileInvalid),
+			errors.Is(res.Err, service.ErrArchiveFileConflict):
+			vo.Error = res.Err.Error()
+		default:
+			vo.Error = "系统错误"
+			h.l.Error("导入文章失败",
+				logger.Int64("uid", claims.Uid),
+				logger.String("file", res.File),
+				logger.Error(res.Err))
+		}
+		vos = append(vos, vo)
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg:  "OK",
+		Data: vos,
+	})
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*ArticleDraftHandler)(nil)
+
+// ArticleDraftHandler 编辑器自动保存
+type ArticleDraftHandler struct {
+	svc service.ArticleDraftService
+	l   logger.LoggerV1
+}
+
+func NewArticleDraftHandler(svc service.ArticleDraftService, l logger.LoggerV1) *ArticleDraftHandler {
+	return &ArticleDraftHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *ArticleDraftHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles")
+	g.POST("/autosave", h.Autosave)
+	g.GET("/draft/:id", h.Open)
+	g.POST("/draft/discard", h.Discard)
+}
+
+type ArticleDraftVO struct {
+	ArticleVO
+	// Autosaved 返回的是自动保存的内容，还没有保存到文章里
+	Autosaved bool   `json:"autosaved"`
+	Utime     string `json:"utime"`
+}
+
+func (h *ArticleDraftHandler) Autosave(ctx *gin.Context) {
+	var req ArticleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	id, err := h.svc.Autosave(ctx, req.toDomain(claims.Uid))
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("自动保存失败", logger.Int64("id", req.Id), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg:  "OK",
+		Data: id,
+	})
+}
+
+func (h *ArticleDraftHandler) Open(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	art, autosaved, err := h.svc.Open(ctx, id, claims.Uid)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("打开草稿失败", logger.Int64("id", id), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: ArticleDraftVO{
+			ArticleVO: ArticleVO{
+				Id:       art.Id,
+				Title:    art.Title,
+				Content:  art.Content,
+				AuthorId: art.Author.Id,
+				Tags:     art.Tags,
+				Status:   art.Status.ToUint8(),
+				Version:  art.Version,
+			},
+			Autosaved: autosaved,
+			Utime:     art.Utime.Format(time.DateTime),
+		},
+	})
+}
+
+func (h *ArticleDraftHandler) Discard(ctx *gin.Context) {
+	var req ArticleIdReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	if err := h.svc.Discard(ctx, req.Id, claims.Uid); err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("丢弃自动保存的草稿失败", logger.Int64("id", req.Id), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
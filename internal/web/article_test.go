@@ -45,15 +45,66 @@ func TestArticleHandler_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Return(int64(1), nil)
+				}).Return(domain.Article{Id: 1, Version: 1}, nil)
 				return svc
 			},
 			wantCode: http.StatusOK,
 			wantRes: ginx.Result{
-				Data: float64(1),
+				Data: map[string]any{"id": float64(1), "version": float64(1)},
 				Msg:  "OK",
 			},
 		},
+		{
+			name: "修改已有的文章，返回新的版本号",
+			reqBody: `
+{
+	"id": 1,
+	"title": "标题",
+	"content": "内容",
+	"version": 2
+}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), gomock.Any()).
+					Return(domain.Article{Id: 1, Version: 3}, nil)
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantRes: ginx.Result{
+				Data: map[string]any{"id": float64(1), "version": float64(3)},
+				Msg:  "OK",
+			},
+		},
+		{
+			name: "版本号不一致",
+			reqBody: `
+{
+	"id": 1,
+	"title": "标题",
+	"content": "内容",
+	"version": 2
+}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "标题",
+					Content: "内容",
+					Version: 2,
+					Author: domain.Author{
+						Id: 123,
+					},
+				}).Return(domain.Article{}, service.ErrArticleVersionConflict)
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantRes: ginx.Result{
+				Code: CodeArticleVersionConflict,
+				Msg:  "文章已经在别处被修改，请刷新后再编辑",
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -65,7 +116,7 @@ func TestArticleHandler_Publish(t *testing.T) {
 					Uid: 123,
 				})
 			})
-			h := NewArticleHandler(tc.mock(ctrl), &logger.NopLogger{})
+			h := NewArticleHandler(tc.mock(ctrl), nil, nil, &logger.NopLogger{})
 			h.RegisterRoutes(server)
 			req, err := http.NewRequest(http.MethodPost, "/articles/publish", bytes.NewBuffer([]byte(tc.reqBody)))
 			require.NoError(t, err)
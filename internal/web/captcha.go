@@ -0,0 +1,49 @@
+package web
+
+import (
+	"encoding/base64"
+	"net/http"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*CaptchaHandler)(nil)
+
+// CaptchaHandler 获取图片验证码，不需要登录
+type CaptchaHandler struct {
+	svc service.CaptchaService
+	l   logger.LoggerV1
+}
+
+func NewCaptchaHandler(svc service.CaptchaService, l logger.LoggerV1) *CaptchaHandler {
+	return &CaptchaHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *CaptchaHandler) RegisterRoutes(server *gin.Engine) {
+	server.GET("/captcha", h.Generate)
+}
+
+func (h *CaptchaHandler) Generate(ctx *gin.Context) {
+	c, err := h.svc.Generate(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("生成图片验证码失败", logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: gin.H{
+			"id": c.Id,
+			// 前端可以直接放到 img 的 src 里面
+			"image": "data:image/png;base64," + base64.StdEncoding.EncodeToString(c.Image),
+		},
+	})
+}
@@ -0,0 +1,38 @@
+package web
+
+// 业务错误码，放在 ginx.Result 的 Code 里面
+// 4 是通用的参数或者业务错误，5 是系统错误
+// 前端需要特殊处理的错误使用 6 位的错误码，前 3 位是模块，后 3 位是具体的错误
+const (
+	// CodeArticleVersionConflict 文章已经在别的地方被修改过，前端需要提示用户刷新
+	CodeArticleVersionConflict = 401001
+
+	// CodeUserEmailUnverified 邮箱还没有验证，前端需要引导用户去验证邮箱
+	CodeUserEmailUnverified = 402001
+	// CodeUserPhoneBound 手机号已经被其它账号绑定
+	CodeUserPhoneBound = 402002
+	// CodeUserEmailBound 邮箱已经被其它账号绑定
+	CodeUserEmailBound = 402003
+	// CodeUserWechatBound 微信已经被其它账号绑定
+	CodeUserWechatBound = 402004
+	// CodeUserLastLoginMethod 解绑之后账号就没有办法登录了
+	CodeUserLastLoginMethod = 402005
+	// CodeUserMerged 账号已经被合并到其它账号
+	CodeUserMerged = 402006
+	// CodeUserRestricted 账号被限制，只能浏览，Data 里面有原因和到期时间
+	CodeUserRestricted = 402007
+
+	// CodeLoginChallenge 登录被判定为可疑，需要输入短信或者邮件验证码之后才能拿到 token
+	// Data 里面有 token 和打码之后的 phone 或者 email
+	CodeLoginChallenge = 403001
+	// CodeLoginTooFrequent 密码输错之后需要等待一段时间才能再试，Data 里面有需要等待的秒数
+	CodeLoginTooFrequent = 403002
+	// CodeLoginLocked 密码输错太多次账号被锁定，前端需要引导用户通过验证码解锁
+	CodeLoginLocked = 403003
+	// CodeCaptchaRequired 需要先通过图片验证码，图片验证码答错了也返回这个错误码，前端需要换一张
+	CodeCaptchaRequired = 403004
+	// CodeLoginMFARequired 开启了两步验证，Data 里面的 mfa_token 用来提交两步验证码
+	CodeLoginMFARequired = 403005
+	// CodeLoginBanned 账号被封禁，Data 里面有原因和到期时间
+	CodeLoginBanned = 403006
+)
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*FeedHandler)(nil)
+
+// FeedHandler RSS 和 Atom 订阅，不需要登录
+type FeedHandler struct {
+	svc service.FeedService
+	l   logger.LoggerV1
+}
+
+func NewFeedHandler(svc service.FeedService, l logger.LoggerV1) *FeedHandler {
+	return &FeedHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *FeedHandler) RegisterRoutes(server *gin.Engine) {
+	server.GET("/users/:id/feed.xml", h.AuthorFeed(service.FeedFormatRSS))
+	server.GET("/users/:id/atom.xml", h.AuthorFeed(service.FeedFormatAtom))
+	server.GET("/feed.xml", h.HotFeed(service.FeedFormatRSS))
+	server.GET("/atom.xml", h.HotFeed(service.FeedFormatAtom))
+}
+
+func (h *FeedHandler) AuthorFeed(format string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+		data, err := h.svc.AuthorFeed(ctx, id, format)
+		if errors.Is(err, service.ErrUserNotFound) {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			ctx.Status(http.StatusInternalServerError)
+			h.l.Error("生成作者订阅失败", logger.Int64("author_id", id), logger.Error(err))
+			return
+		}
+		h.write(ctx, format, data)
+	}
+}
+
+func (h *FeedHandler) HotFeed(format string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		data, err := h.svc.HotFeed(ctx, format)
+		if err != nil {
+			ctx.Status(http.StatusInternalServerError)
+			h.l.Error("生成热榜订阅失败", logger.Error(err))
+			return
+		}
+		h.write(ctx, format, data)
+	}
+}
+
+// write 输出订阅内容，内容没有变化时返回 304，订阅器就不用重复下载了
+func (h *FeedHandler) write(ctx *gin.Context, format string, data []byte) {
+	sum := sha1.Sum(data)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	ctx.Header("ETag", etag)
+	// 和缓存的过期时间保持一致
+	ctx.Header("Cache-Control", "public, max-age=300")
+	if ctx.GetHeader("If-None-Match") == etag {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	contentType := "application/rss+xml; charset=utf-8"
+	if format == service.FeedFormatAtom {
+		contentType = "application/atom+xml; charset=utf-8"
+	}
+	ctx.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,95 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFeedHandler_AuthorFeed(t *testing.T) {
+	const data = `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"></rss>`
+	// sha1(data)
+	const etag = `"82d0d62fd45c4c6883887f4bcb8e761c2b56d535"`
+	testCases := []struct {
+		name        string
+		mock        func(ctrl *gomock.Controller) service.FeedService
+		path        string
+		ifNoneMatch string
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name: "获取成功",
+			mock: func(ctrl *gomock.Controller) service.FeedService {
+				svc := svcmocks.NewMockFeedService(ctrl)
+				svc.EXPECT().AuthorFeed(gomock.Any(), int64(123), service.FeedFormatRSS).Return([]byte(data), nil)
+				return svc
+			},
+			path:     "/users/123/feed.xml",
+			wantCode: http.StatusOK,
+			wantBody: data,
+		},
+		{
+			name: "内容没有变化",
+			mock: func(ctrl *gomock.Controller) service.FeedService {
+				svc := svcmocks.NewMockFeedService(ctrl)
+				svc.EXPECT().AuthorFeed(gomock.Any(), int64(123), service.FeedFormatRSS).Return([]byte(data), nil)
+				return svc
+			},
+			path:        "/users/123/feed.xml",
+			ifNoneMatch: etag,
+			wantCode:    http.StatusNotModified,
+		},
+		{
+			name: "用户不存在",
+			mock: func(ctrl *gomock.Controller) service.FeedService {
+				svc := svcmocks.NewMockFeedService(ctrl)
+				svc.EXPECT().AuthorFeed(gomock.Any(), int64(123), service.FeedFormatAtom).Return(nil, service.ErrUserNotFound)
+				return svc
+			},
+			path:     "/users/123/atom.xml",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "id 不合法",
+			mock: func(ctrl *gomock.Controller) service.FeedService {
+				return svcmocks.NewMockFeedService(ctrl)
+			},
+			path:     "/users/abc/feed.xml",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			h := NewFeedHandler(tc.mock(ctrl), &logger.NopLogger{})
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantBody, resp.Body.String())
+			if tc.wantCode == http.StatusOK {
+				assert.Equal(t, etag, resp.Header().Get("ETag"))
+				assert.Equal(t, "application/rss+xml; charset=utf-8", resp.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package jwt
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -19,32 +23,94 @@ var (
 	RefreshTokenKey = []byte("KntcTH88cXPKDRdFrXrQjh5yZpA7c5QQXKh3MHwYFnt2v43wGCy2d8JCSpmwPjFy")
 )
 
+var ErrInvalidToken = errors.New("token 不合法")
+
+// revokeExpiration 和 refresh token 的有效期一致，超过之后之前签发的 token 都已经过期了
+const revokeExpiration = time.Hour * 24 * 7
+
 type RedisJwtHandler struct {
-	cmd redis.Cmdable
+	cmd      redis.Cmdable
+	notifier Notifier
+	pusher   Pusher
+	sessions SessionStore
+	roles    RoleProvider
+	l        logger.LoggerV1
+}
+
+func NewRedisJwtHandler(cmd redis.Cmdable,
+	notifier Notifier,
+	pusher Pusher,
+	sessions SessionStore,
+	roles RoleProvider,
+	l logger.LoggerV1) Handler {
+	return &RedisJwtHandler{
+		cmd:      cmd,
+		notifier: notifier,
+		pusher:   pusher,
+		sessions: sessions,
+		roles:    roles,
+		l:        l,
+	}
 }
 
-func NewRedisJwtHandler(cmd redis.Cmdable) Handler {
-	return &RedisJwtHandler{cmd: cmd}
+func (r *RedisJwtHandler) SetLoginToken(c *gin.Context, uid int64, method string) error {
+	err := r.newSession(c, uid, method)
+	if err != nil {
+		return err
+	}
+	r.notifyLogin(c, uid)
+	return nil
 }
 
-func (r *RedisJwtHandler) SetLoginToken(c *gin.Context, uid int64) error {
+// newSession 记录会话并且签发一组新的 token
+func (r *RedisJwtHandler) newSession(c *gin.Context, uid int64, method string) error {
 	ssid := uuid.New().String()
-	err := r.SetJwtToken(c, uid, ssid)
+	err := r.sessions.Create(c, domain.Session{
+		Ssid:        ssid,
+		Uid:         uid,
+		UserAgent:   c.Request.UserAgent(),
+		IP:          c.ClientIP(),
+		LoginMethod: method,
+	})
+	if err != nil {
+		return err
+	}
+	err = r.SetJwtToken(c, uid, ssid)
 	if err != nil {
 		return err
 	}
-	err = r.SetRefreshToken(c, uid, ssid)
-	return err
+	return r.SetRefreshToken(c, uid, ssid)
+}
+
+// notifyLogin 提醒用户账号有新的登录，失败了也不影响登录
+func (r *RedisJwtHandler) notifyLogin(c *gin.Context, uid int64) {
+	err := r.notifier.Notify(c, domain.Notification{
+		Uid:     uid,
+		Biz:     domain.NotificationBizLogin,
+		AggKey:  domain.NotificationBizLogin,
+		Content: fmt.Sprintf("你的账号有新的登录，设备 %s，IP %s", c.Request.UserAgent(), c.ClientIP()),
+	})
+	if err != nil {
+		r.l.Error("发送登录通知失败", logger.Int64("uid", uid), logger.Error(err))
+	}
 }
 
+// SetJwtToken 刷新 token 的时候也会重新查询角色
 func (r *RedisJwtHandler) SetJwtToken(c *gin.Context, uid int64, ssid string) error {
+	roles, err := r.roles.Roles(c, uid)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
 	claims := UserClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 30)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute * 30)),
 		},
 		Uid:       uid,
 		Ssid:      ssid,
 		UserAgent: c.Request.UserAgent(),
+		Roles:     roles,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenStr, err := token.SignedString(AccessTokenKey)
@@ -56,9 +122,11 @@ func (r *RedisJwtHandler) SetJwtToken(c *gin.Context, uid int64, ssid string) er
 }
 
 func (r *RedisJwtHandler) SetRefreshToken(c *gin.Context, uid int64, ssid string) error {
+	now := time.Now()
 	claims := RefreshClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(revokeExpiration)),
 		},
 		Uid:  uid,
 		Ssid: ssid,
@@ -99,20 +167,142 @@ func (r *RedisJwtHandler) ClearToken(c *gin.Context) error {
 		})
 		return nil
 	}
-	return r.cmd.Set(c, fmt.Sprintf("users:ssid:%s", claims.Ssid), "", time.Hour*24*7).Err()
+	return r.logout(c, claims.Uid, claims.Ssid)
+}
+
+// logout 标记会话已经退出，这个会话的 access token 和 refresh token 都不能再用了
+func (r *RedisJwtHandler) logout(c *gin.Context, uid int64, ssid string) error {
+	err := r.cmd.Set(c, r.ssidKey(ssid), "", revokeExpiration).Err()
+	if err != nil {
+		return err
+	}
+	// 标记已经生效，会话列表里面删除失败只会多显示一个设备
+	if err = r.sessions.Remove(c, uid, ssid); err != nil {
+		r.l.Error("删除会话失败", logger.Int64("uid", uid), logger.Error(err))
+	}
+	// 通知这个用户其它在线的客户端，推送失败不影响退出登录
+	r.pushLogout(c, uid, ssid)
+	return nil
+}
+
+func (r *RedisJwtHandler) pushLogout(ctx context.Context, uid int64, ssid string) {
+	err := r.pusher.Push(ctx, domain.PushEvent{
+		Uid:  uid,
+		Type: domain.PushEventSessionLogout,
+		Data: map[string]string{"ssid": ssid},
+	})
+	if err != nil {
+		r.l.Error("推送退出登录事件失败", logger.Int64("uid", uid), logger.Error(err))
+	}
+}
+
+func (r *RedisJwtHandler) ParseAccessToken(c *gin.Context, tokenStr string) (*UserClaims, error) {
+	claims := &UserClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return AccessTokenKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || !token.Valid || claims.Uid == 0 {
+		return nil, ErrInvalidToken
+	}
+	if claims.UserAgent != c.Request.UserAgent() {
+		// 严重的安全问题
+		return nil, ErrInvalidToken
+	}
+	if err = r.CheckSession(c, claims.Uid, claims.Ssid, IssuedAt(claims.RegisteredClaims)); err != nil {
+		return nil, err
+	}
+	return claims, nil
 }
 
-func (r *RedisJwtHandler) CheckSession(c *gin.Context, ssid string) error {
-	val, err := r.cmd.Exists(c, fmt.Sprintf("users:ssid:%s", ssid)).Result()
-	switch {
-	case errors.Is(err, redis.Nil):
+func (r *RedisJwtHandler) CheckSession(c *gin.Context, uid int64, ssid string, issuedAt time.Time) error {
+	res, err := r.cmd.MGet(c, r.ssidKey(ssid), r.revokeKey(uid)).Result()
+	if err != nil {
+		return err
+	}
+	if res[0] != nil {
+		return errors.New("session already expired")
+	}
+	if res[1] == nil {
 		return nil
-	case errors.Is(err, nil):
-		if val == 0 {
-			return nil
+	}
+	revokedAt, err := strconv.ParseInt(res[1].(string), 10, 64)
+	if err != nil {
+		return err
+	}
+	// token 的签发时间只精确到秒，同一秒内签发的 token 不会被撤销
+	if issuedAt.Unix() < revokedAt {
+		return errors.New("session already revoked")
+	}
+	return nil
+}
+
+func (r *RedisJwtHandler) RevokeSessions(ctx context.Context, uid int64) error {
+	sessions, err := r.sessions.List(ctx, uid)
+	if err != nil {
+		return err
+	}
+	// 每个会话都标记为已经退出，和撤销时间一起保证同一秒内签发的 token 也不能再用
+	pipe := r.cmd.TxPipeline()
+	for _, s := range sessions {
+		pipe.Set(ctx, r.ssidKey(s.Ssid), "", revokeExpiration)
+	}
+	pipe.Set(ctx, r.revokeKey(uid), time.Now().Unix(), revokeExpiration)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if err = r.sessions.RemoveAll(ctx, uid); err != nil {
+		return err
+	}
+	// 断开这些会话的实时连接，推送失败的连接会在下一次心跳检查会话的时候断开
+	for _, s := range sessions {
+		r.pushLogout(ctx, uid, s.Ssid)
+	}
+	return nil
+}
+
+func (r *RedisJwtHandler) RevokeSession(c *gin.Context, uid int64, ssid string) error {
+	// 只能让自己的会话退出登录
+	_, err := r.sessions.Find(c, uid, ssid)
+	if err != nil {
+		return err
+	}
+	return r.logout(c, uid, ssid)
+}
+
+func (r *RedisJwtHandler) RevokeOtherSessions(c *gin.Context, claims *UserClaims) error {
+	sessions, err := r.sessions.List(c, claims.Uid)
+	if err != nil {
+		return err
+	}
+	// 当前会话可能是记录会话之前登录的，这个时候不在列表里面，不知道登录方式
+	var method string
+	for _, s := range sessions {
+		if s.Ssid == claims.Ssid {
+			method = s.LoginMethod
+			break
 		}
-		return errors.New("session already expired")
-	default:
+	}
+	if err = r.RevokeSessions(c, claims.Uid); err != nil {
 		return err
 	}
+	return r.newSession(c, claims.Uid, method)
+}
+
+func (r *RedisJwtHandler) revokeKey(uid int64) string {
+	return fmt.Sprintf("users:revoke:%d", uid)
+}
+
+func (r *RedisJwtHandler) ssidKey(ssid string) string {
+	return fmt.Sprintf("users:ssid:%s", ssid)
+}
+
+// IssuedAt 没有签发时间的 token 当作很早之前签发的
+func IssuedAt(claims jwt.RegisteredClaims) time.Time {
+	if claims.IssuedAt == nil {
+		return time.Time{}
+	}
+	return claims.IssuedAt.Time
 }
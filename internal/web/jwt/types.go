@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"context"
+	"time"
+	"xiaoweishu/internal/domain"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -10,8 +14,45 @@ type Handler interface {
 	SetJwtToken(c *gin.Context, uid int64, ssid string) error
 	SetRefreshToken(c *gin.Context, uid int64, ssid string) error
 	ClearToken(c *gin.Context) error
-	CheckSession(c *gin.Context, ssid string) error
-	SetLoginToken(c *gin.Context, uid int64) error
+	// CheckSession 检查会话是否已经退出登录，以及 token 是否在 RevokeSessions 之前签发
+	CheckSession(c *gin.Context, uid int64, ssid string, issuedAt time.Time) error
+	// SetLoginToken 登录成功之后创建会话并签发 token，method 是登录方式，取值见 domain.LoginMethodXXX
+	SetLoginToken(c *gin.Context, uid int64, method string) error
+	// ParseAccessToken 校验 access token，包括签名、过期时间、User-Agent 和会话是否已经退出
+	ParseAccessToken(c *gin.Context, tokenStr string) (*UserClaims, error)
+	// RevokeSessions 让用户在此之前签发的所有 token 失效，比如修改密码、封禁之后
+	// 所有的会话都会标记为已经退出，不依赖请求，定时任务里面也可以调用
+	RevokeSessions(ctx context.Context, uid int64) error
+	// RevokeSession 让用户的某个会话退出登录，会话不存在返回 SessionStore.Find 的错误
+	RevokeSession(c *gin.Context, uid int64, ssid string) error
+	// RevokeOtherSessions 除了当前设备之外全部退出登录，当前设备换一个新的会话继续使用
+	RevokeOtherSessions(c *gin.Context, claims *UserClaims) error
+}
+
+// 下面几个接口由 service 层实现，jwt 只声明自己用到的方法，不依赖 service 包
+
+// SessionStore 记录每一次登录的会话，展示在用户的设备列表里面
+type SessionStore interface {
+	Create(ctx context.Context, s domain.Session) error
+	Find(ctx context.Context, uid int64, ssid string) (domain.Session, error)
+	List(ctx context.Context, uid int64) ([]domain.Session, error)
+	Remove(ctx context.Context, uid int64, ssid string) error
+	RemoveAll(ctx context.Context, uid int64) error
+}
+
+// RoleProvider 签发 access token 的时候查询用户的角色
+type RoleProvider interface {
+	Roles(ctx context.Context, uid int64) ([]string, error)
+}
+
+// Notifier 有新登录的时候给用户发站内通知
+type Notifier interface {
+	Notify(ctx context.Context, n domain.Notification) error
+}
+
+// Pusher 退出登录之后通知用户其它在线的客户端
+type Pusher interface {
+	Push(ctx context.Context, evt domain.PushEvent) error
 }
 
 type UserClaims struct {
@@ -19,6 +60,8 @@ type UserClaims struct {
 	Uid       int64
 	UserAgent string
 	Ssid      string
+	// Roles 签发时用户的角色，角色变更之后要等 access token 刷新才生效
+	Roles []string `json:",omitempty"`
 }
 
 type RefreshClaims struct {
@@ -0,0 +1,286 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var _ handler = (*LoginAuditHandler)(nil)
+
+// LoginAuditHandler 登录记录，以及可疑登录的二次验证
+type LoginAuditHandler struct {
+	svc     service.LoginAuditService
+	mfaSvc  service.MFAService
+	userSvc service.UserService
+	jwtHdl  ijwt.Handler
+	l       logger.LoggerV1
+}
+
+func NewLoginAuditHandler(svc service.LoginAuditService,
+	mfaSvc service.MFAService,
+	userSvc service.UserService,
+	jwtHdl ijwt.Handler,
+	l logger.LoggerV1) *LoginAuditHandler {
+	return &LoginAuditHandler{
+		svc:     svc,
+		mfaSvc:  mfaSvc,
+		userSvc: userSvc,
+		jwtHdl:  jwtHdl,
+		l:       l,
+	}
+}
+
+func (h *LoginAuditHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/login")
+	g.GET("/history", h.History)
+	// 还没有登录，需要在登录校验里面忽略
+	g.POST("/challenge", h.VerifyChallenge)
+}
+
+type LoginLogVO struct {
+	Method    string `json:"method"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Ctime     string `json:"ctime"`
+}
+
+func (h *LoginAuditHandler) History(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	logs, err := h.svc.History(ctx, claims.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询登录记录失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	res := make([]LoginLogVO, 0, len(logs))
+	for _, l := range logs {
+		res = append(res, LoginLogVO{
+			Method:    l.Method,
+			Status:    l.Status.String(),
+			Reason:    l.Reason,
+			IP:        l.IP,
+			UserAgent: l.UserAgent,
+			Ctime:     l.Ctime.Format(time.DateTime),
+		})
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+func (h *LoginAuditHandler) VerifyChallenge(ctx *gin.Context) {
+	type Req struct {
+		Token string `json:"token"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	c, err := h.svc.VerifyChallenge(ctx, req.Token, req.Code, ctx.Request.UserAgent())
+	switch {
+	case errors.Is(err, service.ErrLoginChallengeCodeInvalid):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "验证码不对",
+		})
+		return
+	case errors.Is(err, service.ErrLoginChallengeNotFound):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "验证已经过期，请重新登录",
+		})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("可疑登录二次验证失败", logger.Error(err))
+		return
+	}
+	// 等待二次验证的时候可能被封禁了
+	if !checkNotBanned(ctx, h.userSvc, h.svc, c.Uid, c.Method) {
+		return
+	}
+	if !issueLoginToken(ctx, h.mfaSvc, h.jwtHdl, c.Uid, c.Method) {
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "登录成功",
+	})
+}
+
+// newLoginLog account 是用户输入的手机号或者邮箱
+func newLoginLog(c *gin.Context, method, account string) domain.LoginLog {
+	return domain.LoginLog{
+		Account:   account,
+		Method:    method,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Ctime:     time.Now(),
+	}
+}
+
+// recordLoginFailure 记录失败的登录，记录失败不影响返回给用户的结果
+func recordLoginFailure(c *gin.Context, auditSvc service.LoginAuditService, method, account, reason string) {
+	l := newLoginLog(c, method, account)
+	l.Reason = reason
+	if err := auditSvc.RecordFailure(c, l); err != nil {
+		zap.L().Error("记录登录失败", zap.String("method", method), zap.Error(err))
+	}
+}
+
+// finishLogin 各种登录方式验证通过之后都走这里：检查登录是否可疑，正常的登录签发 token
+// 返回 true 表示已经签发了 token，由调用方返回登录成功；返回 false 的时候已经写好了响应
+func finishLogin(c *gin.Context, auditSvc service.LoginAuditService, mfaSvc service.MFAService,
+	jwtHdl ijwt.Handler, u domain.User, method, account string) bool {
+	if rejectBanned(c, auditSvc, u, method, account) {
+		return false
+	}
+	ch, challenged, err := auditSvc.Check(c, u, newLoginLog(c, method, account))
+	switch {
+	case errors.Is(err, service.ErrCodeSendTooMany):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "验证码发送太频繁，请稍后再试",
+		})
+		return false
+	case err != nil:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("检查登录是否可疑失败", zap.Int64("uid", u.Id), zap.Error(err))
+		return false
+	case challenged && ch.Phone != "":
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: CodeLoginChallenge,
+			Msg:  "检测到异常登录，请输入手机收到的验证码",
+			Data: gin.H{
+				"token": ch.Token,
+				"phone": domain.MaskPhone(ch.Phone),
+			},
+		})
+		return false
+	case challenged:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: CodeLoginChallenge,
+			Msg:  "检测到异常登录，请输入邮箱收到的验证码",
+			Data: gin.H{
+				"token": ch.Token,
+				"email": domain.MaskEmail(ch.Email),
+			},
+		})
+		return false
+	}
+	return issueLoginToken(c, mfaSvc, jwtHdl, u.Id, method)
+}
+
+// rejectBanned 被封禁的用户记录失败的登录并且写好响应，返回 true
+func rejectBanned(c *gin.Context, auditSvc service.LoginAuditService, u domain.User, method, account string) bool {
+	if u.Ban.Effective(time.Now()) != domain.UserBanStatusBanned {
+		return false
+	}
+	l := newLoginLog(c, method, account)
+	l.Uid = u.Id
+	l.Reason = "账号已封禁"
+	if err := auditSvc.RecordFailure(c, l); err != nil {
+		zap.L().Error("记录登录失败", zap.String("method", method), zap.Error(err))
+	}
+	c.JSON(http.StatusOK, ginx.Result{
+		Code: CodeLoginBanned,
+		Msg:  "账号已经被封禁",
+		Data: NewBanVO(u.Ban),
+	})
+	return true
+}
+
+// checkNotBanned 二次验证、两步验证通过之后签发 token 之前重新检查封禁
+// 返回 false 的时候已经写好了响应
+func checkNotBanned(c *gin.Context, userSvc service.UserService, auditSvc service.LoginAuditService,
+	uid int64, method string) bool {
+	u, err := userSvc.Profile(c, uid)
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("查询用户失败", zap.Int64("uid", uid), zap.Error(err))
+		return false
+	}
+	return !rejectBanned(c, auditSvc, u, method, "")
+}
+
+// issueLoginToken 开启了两步验证的返回等待两步验证的 token，没有开启的直接签发登录 token
+// 返回值的含义和 finishLogin 一样
+func issueLoginToken(c *gin.Context, mfaSvc service.MFAService, jwtHdl ijwt.Handler, uid int64, method string) bool {
+	enabled, err := mfaSvc.Enabled(c, uid)
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("查询两步验证失败", zap.Int64("uid", uid), zap.Error(err))
+		return false
+	}
+	if enabled {
+		p, err := mfaSvc.StartLogin(c, domain.MFAPending{
+			Uid:       uid,
+			Method:    method,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		if err != nil {
+			c.JSON(http.StatusOK, ginx.Result{
+				Code: 5,
+				Msg:  "系统错误",
+			})
+			zap.L().Error("开始两步验证失败", zap.Int64("uid", uid), zap.Error(err))
+			return false
+		}
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: CodeLoginMFARequired,
+			Msg:  "请输入两步验证码",
+			Data: gin.H{"mfa_token": p.Token},
+		})
+		return false
+	}
+	if err = jwtHdl.SetLoginToken(c, uid, method); err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("设置登录 token 失败", zap.Int64("uid", uid), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// BanVO 封禁或者限制的原因，Until 为空表示永久
+type BanVO struct {
+	Reason string `json:"reason"`
+	Until  string `json:"until,omitempty"`
+}
+
+func NewBanVO(b domain.UserBan) BanVO {
+	vo := BanVO{Reason: b.Reason}
+	if !b.Until.IsZero() {
+		vo.Until = b.Until.Format(time.DateTime)
+	}
+	return vo
+}
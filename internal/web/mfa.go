@@ -0,0 +1,219 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*MFAHandler)(nil)
+
+// MFAHandler 两步验证的开启、关闭，以及登录的第二步
+type MFAHandler struct {
+	svc      service.MFAService
+	auditSvc service.LoginAuditService
+	userSvc  service.UserService
+	jwtHdl   ijwt.Handler
+	l        logger.LoggerV1
+}
+
+func NewMFAHandler(svc service.MFAService,
+	auditSvc service.LoginAuditService,
+	userSvc service.UserService,
+	jwtHdl ijwt.Handler,
+	l logger.LoggerV1) *MFAHandler {
+	return &MFAHandler{
+		svc:      svc,
+		auditSvc: auditSvc,
+		userSvc:  userSvc,
+		jwtHdl:   jwtHdl,
+		l:        l,
+	}
+}
+
+func (h *MFAHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/mfa")
+	g.GET("", h.Status)
+	g.POST("/enroll", h.Enroll)
+	g.POST("/confirm", h.Confirm)
+	g.POST("/disable", h.Disable)
+	// 还没有登录，需要在登录校验里面忽略
+	server.POST("/users/login/mfa", h.VerifyLogin)
+}
+
+func (h *MFAHandler) Status(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	enabled, err := h.svc.Enabled(ctx, claims.Uid)
+	if err != nil {
+		h.handleErr(ctx, "查询两步验证失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: gin.H{"enabled": enabled},
+	})
+}
+
+func (h *MFAHandler) Enroll(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	secret, uri, err := h.svc.Enroll(ctx, claims.Uid)
+	if err != nil {
+		h.handleErr(ctx, "绑定两步验证失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: gin.H{
+			// 不能扫码的时候手动输入密钥
+			"secret": secret,
+			"uri":    uri,
+		},
+	})
+}
+
+func (h *MFAHandler) Confirm(ctx *gin.Context) {
+	type Req struct {
+		Code string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	codes, err := h.svc.Confirm(ctx, claims.Uid, req.Code)
+	if err != nil {
+		h.handleErr(ctx, "确认两步验证失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "两步验证已经开启，请妥善保存恢复码，恢复码只显示这一次",
+		Data: gin.H{
+			"recovery_codes": codes,
+		},
+	})
+}
+
+func (h *MFAHandler) Disable(ctx *gin.Context) {
+	type Req struct {
+		Password string `json:"password"`
+		// Code 验证码或者恢复码
+		Code string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.Disable(ctx, claims.Uid, req.Password, req.Code)
+	if err != nil {
+		h.handleErr(ctx, "关闭两步验证失败", claims.Uid, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *MFAHandler) VerifyLogin(ctx *gin.Context) {
+	type Req struct {
+		Token string `json:"mfa_token"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	p, err := h.svc.VerifyLogin(ctx, req.Token, req.Code, ctx.Request.UserAgent())
+	if err != nil {
+		if p.Uid > 0 {
+			h.record(ctx, p, "两步验证码不对")
+		}
+		h.handleErr(ctx, "两步验证登录失败", p.Uid, err)
+		return
+	}
+	// 等待两步验证的时候可能被封禁了
+	if !checkNotBanned(ctx, h.userSvc, h.auditSvc, p.Uid, p.Method) {
+		return
+	}
+	if err = h.jwtHdl.SetLoginToken(ctx, p.Uid, p.Method); err != nil {
+		h.handleErr(ctx, "设置登录 token 失败", p.Uid, err)
+		return
+	}
+	h.record(ctx, p, "")
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "登录成功",
+	})
+}
+
+// record 记录两步验证的结果，failReason 为空表示成功，记录失败不影响返回给用户的结果
+func (h *MFAHandler) record(ctx *gin.Context, p domain.MFAPending, failReason string) {
+	l := domain.LoginLog{
+		Uid:       p.Uid,
+		Method:    p.Method,
+		IP:        p.IP,
+		UserAgent: p.UserAgent,
+		Ctime:     time.Now(),
+	}
+	var err error
+	if failReason != "" {
+		l.Reason = failReason
+		err = h.auditSvc.RecordFailure(ctx, l)
+	} else {
+		l.Reason = "两步验证通过"
+		err = h.auditSvc.RecordSuccess(ctx, l)
+	}
+	if err != nil {
+		h.l.Error("记录两步验证登录失败", logger.Int64("uid", p.Uid), logger.Error(err))
+	}
+}
+
+func (h *MFAHandler) handleErr(ctx *gin.Context, msg string, uid int64, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFACodeInvalid):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "验证码不对",
+		})
+	case errors.Is(err, service.ErrMFAPendingNotFound):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "验证已经过期，请重新登录",
+		})
+	case errors.Is(err, service.ErrMFAEnabled):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "已经开启了两步验证",
+		})
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "请先绑定两步验证",
+		})
+	case errors.Is(err, service.ErrMFANotEnabled):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "没有开启两步验证",
+		})
+	case errors.Is(err, service.ErrInvalidUserOrPassword):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "密码不对",
+		})
+	case errors.Is(err, service.ErrMFADisableTooMany):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "操作太频繁，请稍后再试",
+		})
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(msg, logger.Int64("uid", uid), logger.Error(err))
+	}
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	svcmocks "xiaoweishu/internal/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMFAHandler_VerifyLogin(t *testing.T) {
+	pending := domain.MFAPending{Token: "abc", Uid: 1, Method: domain.LoginMethodEmail, UserAgent: "Chrome"}
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.Local)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (service.MFAService, service.LoginAuditService, service.UserService)
+
+		wantBody ginx.Result
+	}{
+		{
+			name: "验证码不对记录失败",
+			mock: func(ctrl *gomock.Controller) (service.MFAService, service.LoginAuditService, service.UserService) {
+				mfaSvc := svcmocks.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().VerifyLogin(gomock.Any(), "abc", "123456", gomock.Any()).
+					Return(pending, service.ErrMFACodeInvalid)
+				auditSvc := svcmocks.NewMockLoginAuditService(ctrl)
+				auditSvc.EXPECT().RecordFailure(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, l domain.LoginLog) error {
+						assert.Equal(t, int64(1), l.Uid)
+						assert.Equal(t, "两步验证码不对", l.Reason)
+						return nil
+					})
+				return mfaSvc, auditSvc, svcmocks.NewMockUserService(ctrl)
+			},
+			wantBody: ginx.Result{Code: 4, Msg: "验证码不对"},
+		},
+		{
+			name: "等待两步验证的时候被封禁了",
+			mock: func(ctrl *gomock.Controller) (service.MFAService, service.LoginAuditService, service.UserService) {
+				mfaSvc := svcmocks.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().VerifyLogin(gomock.Any(), "abc", "123456", gomock.Any()).Return(pending, nil)
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(1)).Return(domain.User{Id: 1,
+					Ban: domain.UserBan{Status: domain.UserBanStatusBanned, Reason: "发广告", Until: until}}, nil)
+				auditSvc := svcmocks.NewMockLoginAuditService(ctrl)
+				auditSvc.EXPECT().RecordFailure(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, l domain.LoginLog) error {
+						assert.Equal(t, int64(1), l.Uid)
+						assert.Equal(t, "账号已封禁", l.Reason)
+						return nil
+					})
+				return mfaSvc, auditSvc, userSvc
+			},
+			wantBody: ginx.Result{
+				Code: CodeLoginBanned,
+				Msg:  "账号已经被封禁",
+				Data: map[string]any{"reason": "发广告", "until": "2030-01-02 03:04:05"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			mfaSvc, auditSvc, userSvc := tc.mock(ctrl)
+			h := NewMFAHandler(mfaSvc, auditSvc, userSvc, nil, &logger.NopLogger{})
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/login/mfa",
+				bytes.NewBuffer([]byte(`{"mfa_token":"abc","code":"123456"}`)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "Chrome")
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res ginx.Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	"xiaoweishu/internal/web"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountStateMiddlewareBuilder 限制账号状态不正常的用户访问部分接口
+// 例如还没有验证邮箱或者被限制的用户不能发表文章，必须放在登录校验之后
+type AccountStateMiddlewareBuilder struct {
+	paths map[string]struct{}
+	svc   service.UserService
+	l     logger.LoggerV1
+}
+
+func NewAccountStateMiddlewareBuilder(svc service.UserService, l logger.LoggerV1) *AccountStateMiddlewareBuilder {
+	return &AccountStateMiddlewareBuilder{
+		paths: make(map[string]struct{}),
+		svc:   svc,
+		l:     l,
+	}
+}
+
+// LimitPaths 需要校验账号状态的路径，和 IgnorePaths 一样也可以是带参数的路由
+func (b *AccountStateMiddlewareBuilder) LimitPaths(path string) *AccountStateMiddlewareBuilder {
+	b.paths[path] = struct{}{}
+	return b
+}
+
+func (b *AccountStateMiddlewareBuilder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !b.limited(c) {
+			return
+		}
+		val, ok := c.Get("claims")
+		if !ok {
+			return
+		}
+		uc := val.(*ijwt.UserClaims)
+		u, err := b.svc.Profile(c, uc.Uid)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusOK, ginx.Result{
+				Code: 5,
+				Msg:  "系统错误",
+			})
+			b.l.Error("查询账号状态失败", logger.Int64("uid", uc.Uid), logger.Error(err))
+			return
+		}
+		// 封禁的用户 token 已经撤销了，这里是兜底
+		if u.Ban.Effective(time.Now()) != domain.UserBanStatusNone {
+			c.AbortWithStatusJSON(http.StatusOK, ginx.Result{
+				Code: web.CodeUserRestricted,
+				Msg:  "账号已经被限制，只能浏览",
+				Data: web.NewBanVO(u.Ban),
+			})
+			return
+		}
+		if !u.EmailVerified {
+			c.AbortWithStatusJSON(http.StatusOK, ginx.Result{
+				Code: web.CodeUserEmailUnverified,
+				Msg:  "请先验证邮箱",
+			})
+		}
+	}
+}
+
+func (b *AccountStateMiddlewareBuilder) limited(c *gin.Context) bool {
+	if _, ok := b.paths[c.Request.URL.Path]; ok {
+		return true
+	}
+	_, ok := b.paths[c.FullPath()]
+	return ok
+}
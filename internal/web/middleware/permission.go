@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"xiaoweishu/internal/domain"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionMiddlewareBuilder 根据 token 里面的角色校验权限，必须放在登录校验之后
+type PermissionMiddlewareBuilder struct {
+	perms    map[string]string
+	prefixes []string
+}
+
+func NewPermissionMiddlewareBuilder() *PermissionMiddlewareBuilder {
+	return &PermissionMiddlewareBuilder{
+		perms: make(map[string]string),
+	}
+}
+
+// Require 访问 path 需要 perm 权限，和 IgnorePaths 一样也可以是带参数的路由
+func (b *PermissionMiddlewareBuilder) Require(path, perm string) *PermissionMiddlewareBuilder {
+	b.perms[path] = perm
+	return b
+}
+
+// DenyPrefix 这个前缀下面没有配置权限的路径一律拒绝，新加的接口忘记配置权限也不会泄露出去
+func (b *PermissionMiddlewareBuilder) DenyPrefix(prefix string) *PermissionMiddlewareBuilder {
+	b.prefixes = append(b.prefixes, prefix)
+	return b
+}
+
+func (b *PermissionMiddlewareBuilder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		perm, ok := b.permission(c)
+		if !ok {
+			return
+		}
+		val, ok := c.Get("claims")
+		if !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		uc := val.(*ijwt.UserClaims)
+		if perm == "" || !domain.HasPermission(uc.Roles, perm) {
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+}
+
+// permission 返回 false 表示不需要校验，需要校验但是没有配置权限的返回空字符串
+func (b *PermissionMiddlewareBuilder) permission(c *gin.Context) (string, bool) {
+	if perm, ok := b.perms[c.Request.URL.Path]; ok {
+		return perm, true
+	}
+	if perm, ok := b.perms[c.FullPath()]; ok {
+		return perm, true
+	}
+	for _, prefix := range b.prefixes {
+		if strings.HasPrefix(c.Request.URL.Path, prefix) {
+			return "", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"net/http"
+	"time"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*NotificationHandler)(nil)
+
+// NotificationHandler 站内通知
+type NotificationHandler struct {
+	svc service.NotificationService
+	l   logger.LoggerV1
+}
+
+func NewNotificationHandler(svc service.NotificationService, l logger.LoggerV1) *NotificationHandler {
+	return &NotificationHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *NotificationHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/notifications")
+	g.POST("/list", h.List)
+	g.GET("/unread_count", h.UnreadCount)
+	g.POST("/read", h.MarkRead)
+	g.POST("/read_all", h.MarkAllRead)
+}
+
+type NotificationVO struct {
+	Id      int64  `json:"id"`
+	Biz     string `json:"biz"`
+	Actor   string `json:"actor"`
+	Content string `json:"content"`
+	Count   int64  `json:"count"`
+	// ActorCnt 聚合进来的不同的人数
+	ActorCnt int64 `json:"actor_cnt"`
+	// Text 聚合之后展示给用户的文案
+	Text  string `json:"text"`
+	Read  bool   `json:"read"`
+	Utime string `json:"utime"`
+}
+
+type MarkReadReq struct {
+	Ids []int64 `json:"ids"`
+}
+
+func (h *NotificationHandler) List(ctx *gin.Context) {
+	var req PageReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 100
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	ns, err := h.svc.List(ctx, claims.Uid, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询通知失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	res := make([]NotificationVO, 0, len(ns))
+	for _, n := range ns {
+		res = append(res, NotificationVO{
+			Id:       n.Id,
+			Biz:      n.Biz,
+			Actor:    n.Actor,
+			Content:  n.Content,
+			Count:    n.Count,
+			ActorCnt: n.ActorCnt,
+			Text:     n.Text(),
+			Read:     n.Read,
+			Utime:    n.Utime.Format(time.DateTime),
+		})
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+func (h *NotificationHandler) UnreadCount(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	cnt, err := h.svc.UnreadCount(ctx, claims.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询未读通知数失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: cnt,
+	})
+}
+
+func (h *NotificationHandler) MarkRead(ctx *gin.Context) {
+	var req MarkReadReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.MarkRead(ctx, claims.Uid, req.Ids)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("标记通知已读失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *NotificationHandler) MarkAllRead(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.MarkAllRead(ctx, claims.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("标记全部通知已读失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
@@ -0,0 +1,195 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*ProfileHandler)(nil)
+
+// ProfileHandler 公开的个人主页，以及用户名和隐私设置
+type ProfileHandler struct {
+	svc service.ProfileService
+	l   logger.LoggerV1
+}
+
+func NewProfileHandler(svc service.ProfileService, l logger.LoggerV1) *ProfileHandler {
+	return &ProfileHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *ProfileHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users")
+	// 不需要登录，:id 可以是数字 id 也可以是用户名
+	g.GET("/:id/profile", h.PublicProfile)
+	g.POST("/handle", h.SetHandle)
+	g.POST("/privacy", h.UpdatePrivacy)
+}
+
+// PublicProfileVO 其他用户能看到的个人资料
+type PublicProfileVO struct {
+	Id       int64    `json:"id"`
+	Handle   string   `json:"handle,omitempty"`
+	NickName string   `json:"nickname"`
+	AboutMe  string   `json:"about_me"`
+	Avatar   AvatarVO `json:"avatar"`
+	// Email Phone 按照用户的隐私设置打码或者不返回
+	Email      string `json:"email,omitempty"`
+	Phone      string `json:"phone,omitempty"`
+	ArticleCnt int64  `json:"article_cnt"`
+	ReadCnt    int64  `json:"read_cnt"`
+	SeriesCnt  int64  `json:"series_cnt"`
+	Ctime      string `json:"ctime"`
+}
+
+func (h *ProfileHandler) PublicProfile(ctx *gin.Context) {
+	key := ctx.Param("id")
+	var (
+		p   domain.PublicProfile
+		err error
+	)
+	if id, er := strconv.ParseInt(key, 10, 64); er == nil {
+		p, err = h.svc.PublicProfile(ctx, id)
+	} else {
+		p, err = h.svc.PublicProfileByHandle(ctx, key)
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "用户不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询个人主页失败", logger.String("key", key), logger.Error(err))
+		return
+	}
+	if p.MergedInto > 0 {
+		// 被合并的账号跳转到保留的账号，旧的链接还能用
+		ctx.Redirect(http.StatusMovedPermanently, fmt.Sprintf("/users/%d/profile", p.MergedInto))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: PublicProfileVO{
+			Id:         p.Id,
+			Handle:     p.Handle,
+			NickName:   p.NickName,
+			AboutMe:    p.AboutMe,
+			Avatar:     newAvatarVO(p.Avatar),
+			Email:      p.Email,
+			Phone:      p.Phone,
+			ArticleCnt: p.ArticleCnt,
+			ReadCnt:    p.ReadCnt,
+			SeriesCnt:  p.SeriesCnt,
+			Ctime:      p.Ctime.Format(time.DateTime),
+		},
+	})
+}
+
+func (h *ProfileHandler) SetHandle(ctx *gin.Context) {
+	type Req struct {
+		Handle string `json:"handle"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.SetHandle(ctx, claims.Uid, req.Handle)
+	switch {
+	case errors.Is(err, service.ErrHandleInvalid):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "用户名要以字母开头，只能包含字母、数字和下划线，长度 3 到 20 个字符",
+		})
+	case errors.Is(err, service.ErrHandleReserved):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "这个用户名不能使用",
+		})
+	case errors.Is(err, service.ErrHandleTaken):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "用户名已经被使用",
+		})
+	case errors.Is(err, service.ErrHandleCooldown):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  fmt.Sprintf("%d 天之内只能修改一次用户名", int(service.HandleCooldown.Hours()/24)),
+		})
+	case err != nil:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("设置用户名失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Msg: "OK",
+		})
+	}
+}
+
+func (h *ProfileHandler) UpdatePrivacy(ctx *gin.Context) {
+	type Req struct {
+		// Email Phone 取值 hidden 或者 masked
+		Email string `json:"email"`
+		Phone string `json:"phone"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	emailLevel, ok1 := h.privacyLevel(req.Email)
+	phoneLevel, ok2 := h.privacyLevel(req.Phone)
+	if !ok1 || !ok2 {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.UpdatePrivacy(ctx, claims.Uid, domain.UserPrivacy{
+		Email: emailLevel,
+		Phone: phoneLevel,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("修改隐私设置失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *ProfileHandler) privacyLevel(s string) (domain.PrivacyLevel, bool) {
+	switch s {
+	case domain.PrivacyHidden.String():
+		return domain.PrivacyHidden, true
+	case domain.PrivacyMasked.String():
+		return domain.PrivacyMasked, true
+	default:
+		return domain.PrivacyHidden, false
+	}
+}
@@ -0,0 +1,120 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*PushHandler)(nil)
+
+// pushHeartbeatInterval 心跳间隔，要比常见代理的空闲超时短
+const pushHeartbeatInterval = time.Second * 25
+
+// PushHandler 基于 Server-Sent Events 的实时推送
+type PushHandler struct {
+	svc    service.PushService
+	jwtHdl ijwt.Handler
+	l      logger.LoggerV1
+}
+
+func NewPushHandler(svc service.PushService, jwtHdl ijwt.Handler, l logger.LoggerV1) *PushHandler {
+	return &PushHandler{
+		svc:    svc,
+		jwtHdl: jwtHdl,
+		l:      l,
+	}
+}
+
+func (h *PushHandler) RegisterRoutes(server *gin.Engine) {
+	server.GET("/events", h.Events)
+}
+
+// Events 浏览器的 EventSource 不能设置请求头，所以 token 也可以放在 access_token 参数里
+// 这个路径不走登录中间件，在这里用同样的逻辑校验 token
+func (h *PushHandler) Events(ctx *gin.Context) {
+	tokenStr := ctx.Query("access_token")
+	if segs := strings.SplitN(ctx.GetHeader("Authorization"), " ", 2); len(segs) == 2 {
+		tokenStr = segs[1]
+	}
+	claims, err := h.jwtHdl.ParseAccessToken(ctx, tokenStr)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	events, cancel, err := h.svc.Subscribe(claims.Uid)
+	if errors.Is(err, service.ErrTooManyPushConns) {
+		ctx.AbortWithStatus(http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		h.l.Error("建立实时连接失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	defer cancel()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// 禁止 nginx 缓冲
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(pushHeartbeatInterval)
+	defer heartbeat.Stop()
+	// token 过期之后断开，客户端刷新 token 之后再重连
+	expire := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+	defer expire.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-expire.C:
+			return
+		case <-heartbeat.C:
+			// 全部退出登录或者封禁的推送可能丢失，心跳的时候再检查一次会话
+			if h.jwtHdl.CheckSession(ctx, claims.Uid, claims.Ssid, ijwt.IssuedAt(claims.RegisteredClaims)) != nil {
+				return
+			}
+			if _, err = fmt.Fprint(ctx.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			if err = h.write(ctx, evt); err != nil {
+				return
+			}
+			// 当前会话退出登录了，连接也要断开
+			if evt.Type == domain.PushEventSessionLogout && evt.Data["ssid"] == claims.Ssid {
+				return
+			}
+		}
+	}
+}
+
+func (h *PushHandler) write(ctx *gin.Context, evt domain.PushEvent) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(ctx.Writer, "event: %s\ndata: %s\n\n", evt.Type, data)
+	if err != nil {
+		return err
+	}
+	ctx.Writer.Flush()
+	return nil
+}
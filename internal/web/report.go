@@ -0,0 +1,182 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*ReportHandler)(nil)
+
+// ReportHandler 审核举报的接口需要 domain.PermReportReview 权限，由 PermissionMiddlewareBuilder 按照路径校验
+type ReportHandler struct {
+	svc service.ReportService
+	l   logger.LoggerV1
+}
+
+func NewReportHandler(svc service.ReportService, l logger.LoggerV1) *ReportHandler {
+	return &ReportHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *ReportHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/reports")
+	g.POST("", h.Report)
+	rg := g.Group("/review")
+	rg.POST("/list", h.ListPending)
+	rg.POST("/accept", h.Accept)
+	rg.POST("/reject", h.Reject)
+}
+
+type ReportReq struct {
+	Biz    string `json:"biz"`
+	BizId  int64  `json:"biz_id"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+type ReportVO struct {
+	Id       int64  `json:"id"`
+	Reporter int64  `json:"reporter"`
+	Biz      string `json:"biz"`
+	BizId    int64  `json:"biz_id"`
+	Field    string `json:"field"`
+	Reason   string `json:"reason"`
+	Status   uint8  `json:"status"`
+	Ctime    string `json:"ctime"`
+}
+
+type ReviewReq struct {
+	Id   int64  `json:"id"`
+	Note string `json:"note"`
+}
+
+type PageReq struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+func (h *ReportHandler) Report(ctx *gin.Context) {
+	var req ReportReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("claims").(*ijwt.UserClaims)
+	id, err := h.svc.Report(ctx, domain.Report{
+		Reporter: uc.Uid,
+		Biz:      req.Biz,
+		BizId:    req.BizId,
+		Field:    req.Field,
+		Reason:   req.Reason,
+	})
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Msg:  "OK",
+			Data: id,
+		})
+	case errors.Is(err, service.ErrInvalidReport):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "举报参数错误",
+		})
+	case errors.Is(err, service.ErrReportDuplicate):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "你已经举报过了",
+		})
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("提交举报失败", logger.Error(err))
+	}
+}
+
+func (h *ReportHandler) ListPending(ctx *gin.Context) {
+	var req PageReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 100
+	}
+	rps, err := h.svc.ListPending(ctx, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询待审核举报失败", logger.Error(err))
+		return
+	}
+	res := make([]ReportVO, 0, len(rps))
+	for _, rp := range rps {
+		res = append(res, ReportVO{
+			Id:       rp.Id,
+			Reporter: rp.Reporter,
+			Biz:      rp.Biz,
+			BizId:    rp.BizId,
+			Field:    rp.Field,
+			Reason:   rp.Reason,
+			Status:   rp.Status.ToUint8(),
+			Ctime:    rp.Ctime.Format(time.DateTime),
+		})
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+func (h *ReportHandler) Accept(ctx *gin.Context) {
+	var req ReviewReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	err := h.svc.Accept(ctx, adminOperator(ctx), req.Id, req.Note)
+	h.reviewResult(ctx, req.Id, err)
+}
+
+func (h *ReportHandler) Reject(ctx *gin.Context) {
+	var req ReviewReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	err := h.svc.Reject(ctx, adminOperator(ctx), req.Id, req.Note)
+	h.reviewResult(ctx, req.Id, err)
+}
+
+func (h *ReportHandler) reviewResult(ctx *gin.Context, id int64, err error) {
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrReportNotFound):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "举报不存在",
+		})
+	case errors.Is(err, service.ErrReportStatusConflict):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "举报已经被处理过了",
+		})
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("处理举报失败", logger.Int64("report_id", id), logger.Error(err))
+	}
+}
@@ -0,0 +1,234 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*SeriesHandler)(nil)
+
+// SeriesHandler 专栏管理
+type SeriesHandler struct {
+	svc service.SeriesService
+	l   logger.LoggerV1
+}
+
+func NewSeriesHandler(svc service.SeriesService, l logger.LoggerV1) *SeriesHandler {
+	return &SeriesHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *SeriesHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/series")
+	g.POST("/create", h.Create)
+	g.POST("/rename", h.Rename)
+	g.POST("/reorder", h.Reorder)
+	g.POST("/add", h.AddArticle)
+	g.POST("/remove", h.RemoveArticle)
+	g.POST("/list", h.List)
+	g.GET("/:id", h.Detail)
+}
+
+type SeriesReq struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type SeriesReorderReq struct {
+	Id         int64   `json:"id"`
+	ArticleIds []int64 `json:"article_ids"`
+}
+
+type SeriesArticleReq struct {
+	Id        int64 `json:"id"`
+	ArticleId int64 `json:"article_id"`
+}
+
+type SeriesVO struct {
+	Id         int64   `json:"id"`
+	Title      string  `json:"title"`
+	AuthorId   int64   `json:"author_id"`
+	ArticleIds []int64 `json:"article_ids,omitempty"`
+	Utime      string  `json:"utime"`
+}
+
+func (h *SeriesHandler) Create(ctx *gin.Context) {
+	var req SeriesReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	id, err := h.svc.Create(ctx, domain.Series{
+		Title:  req.Title,
+		Author: domain.Author{Id: claims.Uid},
+	})
+	if err != nil {
+		h.handleErr(ctx, "创建专栏失败", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg:  "OK",
+		Data: id,
+	})
+}
+
+func (h *SeriesHandler) Rename(ctx *gin.Context) {
+	var req SeriesReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.Rename(ctx, domain.Series{
+		Id:     req.Id,
+		Title:  req.Title,
+		Author: domain.Author{Id: claims.Uid},
+	})
+	if err != nil {
+		h.handleErr(ctx, "修改专栏名字失败", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *SeriesHandler) Reorder(ctx *gin.Context) {
+	var req SeriesReorderReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.Reorder(ctx, req.Id, claims.Uid, req.ArticleIds)
+	if err != nil {
+		h.handleErr(ctx, "调整专栏顺序失败", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *SeriesHandler) AddArticle(ctx *gin.Context) {
+	var req SeriesArticleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.AddArticle(ctx, req.Id, claims.Uid, req.ArticleId)
+	if err != nil {
+		h.handleErr(ctx, "专栏添加文章失败", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *SeriesHandler) RemoveArticle(ctx *gin.Context) {
+	var req SeriesArticleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.svc.RemoveArticle(ctx, req.Id, claims.Uid, req.ArticleId)
+	if err != nil {
+		h.handleErr(ctx, "专栏移除文章失败", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+func (h *SeriesHandler) List(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	ss, err := h.svc.ListByAuthor(ctx, claims.Uid)
+	if err != nil {
+		h.handleErr(ctx, "查询专栏列表失败", err)
+		return
+	}
+	res := make([]SeriesVO, 0, len(ss))
+	for _, s := range ss {
+		res = append(res, h.toVO(s))
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+func (h *SeriesHandler) Detail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	s, err := h.svc.FindById(ctx, id)
+	if err != nil {
+		h.handleErr(ctx, "查询专栏失败", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: h.toVO(s),
+	})
+}
+
+func (h *SeriesHandler) toVO(s domain.Series) SeriesVO {
+	return SeriesVO{
+		Id:         s.Id,
+		Title:      s.Title,
+		AuthorId:   s.Author.Id,
+		ArticleIds: s.ArticleIds,
+		Utime:      s.Utime.Format(time.DateTime),
+	}
+}
+
+// handleErr 专栏的接口错误处理都一样，业务错误返回 4，其余的记录日志返回 5
+func (h *SeriesHandler) handleErr(ctx *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrSeriesNotFound):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "专栏不存在",
+		})
+	case errors.Is(err, service.ErrInvalidSeries):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "专栏参数错误",
+		})
+	case errors.Is(err, service.ErrSeriesArticleInvalid):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "只能添加自己已经发表的文章",
+		})
+	case errors.Is(err, service.ErrSeriesArticleDuplicate):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "文章已经在其它专栏中",
+		})
+	case errors.Is(err, service.ErrSeriesVersionConflict):
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "专栏正在被修改，请稍后再试",
+		})
+	default:
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(msg, logger.Error(err))
+	}
+}
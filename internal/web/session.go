@@ -0,0 +1,134 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"xiaoweishu/internal/domain"
+	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+var _ handler = (*SessionHandler)(nil)
+
+// SessionHandler 登录设备管理
+type SessionHandler struct {
+	svc    service.SessionService
+	jwtHdl ijwt.Handler
+	l      logger.LoggerV1
+}
+
+func NewSessionHandler(svc service.SessionService, jwtHdl ijwt.Handler, l logger.LoggerV1) *SessionHandler {
+	return &SessionHandler{
+		svc:    svc,
+		jwtHdl: jwtHdl,
+		l:      l,
+	}
+}
+
+func (h *SessionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/users/sessions")
+	g.GET("", h.List)
+	g.POST("/revoke", h.Revoke)
+	g.POST("/revoke_all", h.RevokeAll)
+}
+
+type SessionVO struct {
+	Ssid        string `json:"ssid"`
+	UserAgent   string `json:"user_agent"`
+	IP          string `json:"ip"`
+	LoginMethod string `json:"login_method"`
+	Ctime       string `json:"ctime"`
+	LastActive  string `json:"last_active"`
+	// Current 是不是发起请求的这个设备
+	Current bool `json:"current"`
+}
+
+func newSessionVO(s domain.Session, current string) SessionVO {
+	return SessionVO{
+		Ssid:        s.Ssid,
+		UserAgent:   s.UserAgent,
+		IP:          s.IP,
+		LoginMethod: s.LoginMethod,
+		Ctime:       s.Ctime.Format(time.DateTime),
+		LastActive:  s.LastActive.Format(time.DateTime),
+		Current:     s.Ssid == current,
+	}
+}
+
+func (h *SessionHandler) List(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	sessions, err := h.svc.List(ctx, claims.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询登录设备失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	res := make([]SessionVO, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, newSessionVO(s, claims.Ssid))
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Data: res,
+	})
+}
+
+func (h *SessionHandler) Revoke(ctx *gin.Context) {
+	type Req struct {
+		Ssid string `json:"ssid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.jwtHdl.RevokeSession(ctx, claims.Uid, req.Ssid)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "设备已经退出登录",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("退出登录设备失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	if req.Ssid == claims.Ssid {
+		ctx.Header("x-jwt-token", "")
+		ctx.Header("x-refresh-token", "")
+	}
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
+
+// RevokeAll 所有设备都退出登录，包括当前设备
+func (h *SessionHandler) RevokeAll(ctx *gin.Context) {
+	claims := ctx.MustGet("claims").(*ijwt.UserClaims)
+	err := h.jwtHdl.RevokeSessions(ctx, claims.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("退出所有设备失败", logger.Int64("uid", claims.Uid), logger.Error(err))
+		return
+	}
+	ctx.Header("x-jwt-token", "")
+	ctx.Header("x-refresh-token", "")
+	ctx.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
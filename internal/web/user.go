@@ -2,17 +2,21 @@ package web
 
 import (
 	"errors"
+	"io"
 	"log"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/pkg/ginx"
 	"xiaoweishu/internal/service"
+	"xiaoweishu/internal/service/email"
 
 	regexp "github.com/dlclark/regexp2"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
 	ijwt "xiaoweishu/internal/web/jwt"
@@ -20,18 +24,40 @@ import (
 
 const biz = "login"
 
+const (
+	// nickNameMaxLen 和 aboutMeMaxLen 与 users 表的字段长度保持一致
+	nickNameMaxLen = 128
+	aboutMeMaxLen  = 4096
+	// avatarMaxBytes 头像文件的大小上限
+	avatarMaxBytes = 5 << 20
+)
+
 var _ handler = (*UserHandler)(nil)
 
 // UserHandler 定义所有和user相关的路由
 type UserHandler struct {
 	svc         service.UserService
 	codeSvc     service.CodeService
+	resetSvc    service.PasswordResetService
+	verifySvc   service.EmailVerifyService
+	auditSvc    service.LoginAuditService
+	guardSvc    service.LoginGuardService
+	captchaSvc  service.CaptchaService
+	mfaSvc      service.MFAService
 	emailExp    *regexp.Regexp
 	passwordExp *regexp.Regexp
 	ijwt.Handler
 }
 
-func NewUserHandler(svc service.UserService, codeSvc service.CodeService, cmd redis.Cmdable) *UserHandler {
+func NewUserHandler(svc service.UserService,
+	codeSvc service.CodeService,
+	resetSvc service.PasswordResetService,
+	verifySvc service.EmailVerifyService,
+	auditSvc service.LoginAuditService,
+	guardSvc service.LoginGuardService,
+	captchaSvc service.CaptchaService,
+	mfaSvc service.MFAService,
+	jwtHdl ijwt.Handler) *UserHandler {
 	const (
 		emailRegexPattern    = `^\w+([-+.]\w+)*@\w+([-.]\w+)*\.\w+([-.]\w+)*$`
 		passwordRegexPattern = `^(?=.*[A-Za-z])(?=.*\d)(?=.*[$@$!%*#?&])[A-Za-z\d$@$!%*#?&]{8,}$`
@@ -40,9 +66,15 @@ func NewUserHandler(svc service.UserService, codeSvc service.CodeService, cmd re
 	return &UserHandler{
 		svc:         svc,
 		codeSvc:     codeSvc,
+		resetSvc:    resetSvc,
+		verifySvc:   verifySvc,
+		auditSvc:    auditSvc,
+		guardSvc:    guardSvc,
+		captchaSvc:  captchaSvc,
+		mfaSvc:      mfaSvc,
 		emailExp:    regexp.MustCompile(emailRegexPattern, regexp.None),
 		passwordExp: regexp.MustCompile(passwordRegexPattern, regexp.None),
-		Handler:     ijwt.NewRedisJwtHandler(cmd),
+		Handler:     jwtHdl,
 	}
 }
 
@@ -57,6 +89,14 @@ func (u *UserHandler) RegisterRoutes(server *gin.Engine) {
 	//ug.POST("/logout", u.Logout)
 	ug.POST("/logout", u.LogoutJWT)
 	ug.GET("/profile", u.ProfileJWT)
+	ug.POST("/avatar", u.UploadAvatar)
+	ug.POST("/password/change", u.ChangePassword)
+	ug.POST("/password/reset/send", u.SendPasswordResetCode)
+	ug.POST("/password/reset", u.ResetPassword)
+	ug.POST("/login/unlock/send", u.SendLoginUnlockCode)
+	ug.POST("/login/unlock", u.UnlockLogin)
+	ug.POST("/email/verify", u.VerifyEmail)
+	ug.POST("/email/resend", u.ResendEmailCode)
 	ug.POST("/sms/login/send", u.SendLoginSMSCode)
 	ug.POST("/sms/login/verify", u.VerifyLoginSMSCode)
 	ug.POST("/refresh_token", u.RefreshToekn)
@@ -65,6 +105,9 @@ func (u *UserHandler) RegisterRoutes(server *gin.Engine) {
 func (u *UserHandler) SendLoginSMSCode(c *gin.Context) {
 	type Req struct {
 		Phone string `json:"phone"`
+		// CaptchaId 和 Captcha 只有返回 CodeCaptchaRequired 之后才需要
+		CaptchaId string `json:"captcha_id"`
+		Captcha   string `json:"captcha"`
 	}
 
 	var req Req
@@ -82,6 +125,9 @@ func (u *UserHandler) SendLoginSMSCode(c *gin.Context) {
 		})
 		return
 	}
+	if !u.checkSMSCaptcha(c, req.Phone, req.CaptchaId, req.Captcha) {
+		return
+	}
 	err := u.codeSvc.Send(c, biz, req.Phone)
 	switch err {
 	case nil:
@@ -107,6 +153,47 @@ func (u *UserHandler) SendLoginSMSCode(c *gin.Context) {
 
 }
 
+// checkSMSCaptcha 同一个手机号或者 IP 发送太多次之后要求图片验证码，返回 false 的时候已经写好了响应
+func (u *UserHandler) checkSMSCaptcha(c *gin.Context, phone, id, answer string) bool {
+	required, err := u.captchaSvc.RequiredForSMS(c, phone, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("检查是否需要图片验证码失败", zap.Error(err))
+		return false
+	}
+	if !required {
+		return true
+	}
+	if id == "" {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: CodeCaptchaRequired,
+			Msg:  "请先输入图片验证码",
+		})
+		return false
+	}
+	ok, err := u.captchaSvc.Verify(c, id, answer)
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("校验图片验证码失败", zap.Error(err))
+		return false
+	}
+	if !ok {
+		// 验证过一次就作废了，前端需要换一张
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: CodeCaptchaRequired,
+			Msg:  "图片验证码不对，请重新输入",
+		})
+		return false
+	}
+	return true
+}
+
 func (u *UserHandler) VerifyLoginSMSCode(c *gin.Context) {
 	type Req struct {
 		Phone string `json:"phone"`
@@ -131,6 +218,7 @@ func (u *UserHandler) VerifyLoginSMSCode(c *gin.Context) {
 		return
 	}
 	if !ok {
+		recordLoginFailure(c, u.auditSvc, domain.LoginMethodPhone, req.Phone, "验证码不对")
 		c.JSON(http.StatusOK, ginx.Result{
 			Code: 4,
 			Msg:  "短信验证失败",
@@ -146,12 +234,7 @@ func (u *UserHandler) VerifyLoginSMSCode(c *gin.Context) {
 		})
 		return
 	}
-	if err := u.SetLoginToken(c, user.Id); err != nil {
-		c.JSON(http.StatusOK, ginx.Result{
-			Code: 5,
-			Msg:  "系统错误",
-			Data: nil,
-		})
+	if !finishLogin(c, u.auditSvc, u.mfaSvc, u.Handler, user, domain.LoginMethodPhone, req.Phone) {
 		return
 	}
 	c.JSON(http.StatusOK, ginx.Result{
@@ -217,6 +300,11 @@ func (u *UserHandler) SignUp(c *gin.Context) {
 		c.String(http.StatusOK, "系统异常")
 		return
 	}
+	// 验证码发送失败不影响注册，登录之后可以重新发送
+	err = u.codeSvc.SendEmail(c, email.BizSignupVerify, req.Email)
+	if err != nil {
+		zap.L().Error("发送邮箱验证码失败", zap.String("email", req.Email), zap.Error(err))
+	}
 
 	c.String(http.StatusOK, "注册成功!")
 	return
@@ -269,8 +357,16 @@ func (u *UserHandler) LogInJWT(c *gin.Context) {
 	if err := c.Bind(&req); err != nil {
 		return
 	}
+	if !u.allowLogin(c, req.Email) {
+		return
+	}
 	user, err := u.svc.Login(c, req.Email, req.Password)
 	if errors.Is(err, service.ErrInvalidUserOrPassword) {
+		// 账号不存在和密码不对一样处理，都计入失败次数
+		if err = u.guardSvc.Fail(c, req.Email, c.ClientIP()); err != nil {
+			zap.L().Error("记录密码登录失败次数失败", zap.Error(err))
+		}
+		recordLoginFailure(c, u.auditSvc, domain.LoginMethodEmail, req.Email, "密码不对")
 		c.String(http.StatusOK, "用户名/密码错误")
 		return
 	}
@@ -278,17 +374,43 @@ func (u *UserHandler) LogInJWT(c *gin.Context) {
 		c.String(http.StatusOK, "系统错误")
 		return
 	}
+	if err = u.guardSvc.Succeed(c, req.Email); err != nil {
+		zap.L().Error("清空密码登录失败次数失败", zap.Int64("uid", user.Id), zap.Error(err))
+	}
+
+	if !finishLogin(c, u.auditSvc, u.mfaSvc, u.Handler, user, domain.LoginMethodEmail, req.Email) {
+		return
+	}
+	c.String(http.StatusOK, "登录成功")
+	return
+}
 
-	if err := u.SetLoginToken(c, user.Id); err != nil {
+// allowLogin 密码输错太多次的时候拒绝登录，返回 false 的时候已经写好了响应
+func (u *UserHandler) allowLogin(c *gin.Context, email string) bool {
+	wait, err := u.guardSvc.Allow(c, email, c.ClientIP())
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrLoginLocked):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: CodeLoginLocked,
+			Msg:  "密码错误次数太多，账号已经暂时锁定，可以通过验证码解锁",
+			Data: gin.H{"retry_after": int64(wait.Seconds()) + 1},
+		})
+	case errors.Is(err, service.ErrLoginTooFrequent):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: CodeLoginTooFrequent,
+			Msg:  "登录太频繁，请稍后再试",
+			Data: gin.H{"retry_after": int64(wait.Seconds()) + 1},
+		})
+	default:
 		c.JSON(http.StatusOK, ginx.Result{
 			Code: 5,
 			Msg:  "系统错误",
-			Data: nil,
 		})
-		return
+		zap.L().Error("检查密码登录失败次数失败", zap.Error(err))
 	}
-	c.String(http.StatusOK, "登录成功")
-	return
+	return false
 }
 
 func (u *UserHandler) LoginSMS(c *gin.Context) {
@@ -316,6 +438,7 @@ func (u *UserHandler) LoginSMS(c *gin.Context) {
 		return
 	}
 	if !ok {
+		recordLoginFailure(c, u.auditSvc, domain.LoginMethodPhone, req.Phone, "验证码不对")
 		c.JSON(http.StatusOK, ginx.Result{
 			Code: http.StatusUnauthorized,
 			Msg:  "手机号/验证码错误",
@@ -333,12 +456,7 @@ func (u *UserHandler) LoginSMS(c *gin.Context) {
 		return
 	}
 
-	if err := u.SetLoginToken(c, user.Id); err != nil {
-		c.JSON(http.StatusOK, ginx.Result{
-			Code: 5,
-			Msg:  "系统错误",
-			Data: nil,
-		})
+	if !finishLogin(c, u.auditSvc, u.mfaSvc, u.Handler, user, domain.LoginMethodPhone, req.Phone) {
 		return
 	}
 
@@ -378,6 +496,7 @@ func (u *UserHandler) LogoutJWT(c *gin.Context) {
 func (u *UserHandler) Edit(c *gin.Context) {
 	type EditReq struct {
 		NickName string `json:"nickname"`
+		// Birthday YYYY-MM-DD，为空表示清空生日
 		Birthday string `json:"birthday"`
 		AboutMe  string `json:"about_me"`
 	}
@@ -386,10 +505,64 @@ func (u *UserHandler) Edit(c *gin.Context) {
 	if err := c.Bind(&req); err != nil {
 		return
 	}
+	req.NickName = strings.TrimSpace(req.NickName)
 	if req.NickName == "" {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "昵称不能为空",
+		})
 		return
 	}
+	if utf8.RuneCountInString(req.NickName) > nickNameMaxLen {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "昵称过长",
+		})
+		return
+	}
+	if utf8.RuneCountInString(req.AboutMe) > aboutMeMaxLen {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "个人简介过长",
+		})
+		return
+	}
+	if req.Birthday != "" {
+		birthday, err := time.ParseInLocation(time.DateOnly, req.Birthday, time.Local)
+		if err != nil {
+			c.JSON(http.StatusOK, ginx.Result{
+				Code: 4,
+				Msg:  "生日格式不对，应该是 YYYY-MM-DD",
+			})
+			return
+		}
+		if birthday.After(time.Now()) {
+			c.JSON(http.StatusOK, ginx.Result{
+				Code: 4,
+				Msg:  "生日不能是未来的日期",
+			})
+			return
+		}
+	}
 
+	uc := c.MustGet("claims").(*ijwt.UserClaims)
+	err := u.svc.UpdateNonSensitiveInfo(c, domain.User{
+		Id:       uc.Uid,
+		NickName: req.NickName,
+		Birthday: req.Birthday,
+		AboutMe:  req.AboutMe,
+	})
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("修改个人资料失败", zap.Int64("uid", uc.Uid), zap.Error(err))
+		return
+	}
+	c.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
 }
 
 func (u *UserHandler) Profile(c *gin.Context) {
@@ -410,13 +583,100 @@ func (u *UserHandler) Profile(c *gin.Context) {
 	})
 }
 
+type AvatarVO struct {
+	Small  string `json:"small"`
+	Medium string `json:"medium"`
+	Large  string `json:"large"`
+}
+
+func newAvatarVO(a domain.Avatar) AvatarVO {
+	return AvatarVO{
+		Small:  a.Small,
+		Medium: a.Medium,
+		Large:  a.Large,
+	}
+}
+
+func (u *UserHandler) UploadAvatar(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, avatarMaxBytes)
+	fh, err := c.FormFile("avatar")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "头像文件不能超过 5MB",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "请选择头像文件",
+		})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("读取头像文件失败", zap.Error(err))
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("读取头像文件失败", zap.Error(err))
+		return
+	}
+
+	uc := c.MustGet("claims").(*ijwt.UserClaims)
+	avatar, err := u.svc.UploadAvatar(c, uc.Uid, data)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, ginx.Result{
+			Msg:  "OK",
+			Data: newAvatarVO(avatar),
+		})
+	case errors.Is(err, service.ErrAvatarInvalidFormat):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "头像只支持 JPEG、PNG 和 WebP 格式",
+		})
+	case errors.Is(err, service.ErrAvatarInvalidSize):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "头像的宽和高要在 96 到 4096 像素之间",
+		})
+	default:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("上传头像失败", zap.Int64("uid", uc.Uid), zap.Error(err))
+	}
+}
+
 func (u *UserHandler) ProfileJWT(c *gin.Context) {
 	type ProfileResp struct {
-		Email    string `json:"email"`
-		Phone    string `json:"phone"`
-		NicName  string `json:"nicName"`
-		Birthday string `json:"birthday"`
-		AboutMe  string `json:"about_me"`
+		Email         string   `json:"email"`
+		EmailVerified bool     `json:"email_verified"`
+		Phone         string   `json:"phone"`
+		NicName       string   `json:"nicName"`
+		Birthday      string   `json:"birthday"`
+		AboutMe       string   `json:"about_me"`
+		Avatar        AvatarVO `json:"avatar"`
+		Handle        string   `json:"handle"`
+		// EmailPrivacy PhonePrivacy 个人主页上的展示方式，取值 hidden 或者 masked
+		EmailPrivacy string `json:"email_privacy"`
+		PhonePrivacy string `json:"phone_privacy"`
+		// DeleteAt 申请了注销的时候是真正注销的时间
+		DeleteAt string `json:"delete_at,omitempty"`
 	}
 	uc := c.MustGet("claims").(*ijwt.UserClaims)
 	res, err := u.svc.Profile(c, uc.Uid)
@@ -424,15 +684,292 @@ func (u *UserHandler) ProfileJWT(c *gin.Context) {
 		c.String(http.StatusOK, "系统错误")
 		return
 	}
-	c.JSON(http.StatusOK, ProfileResp{
-		Email:    res.Email,
-		Phone:    res.Phone,
-		NicName:  res.NickName,
-		Birthday: res.Birthday,
-		AboutMe:  res.AboutMe,
+	resp := ProfileResp{
+		Email:         res.Email,
+		EmailVerified: res.EmailVerified,
+		Phone:         res.Phone,
+		NicName:       res.NickName,
+		Birthday:      res.Birthday,
+		AboutMe:       res.AboutMe,
+		Avatar:        newAvatarVO(res.Avatar),
+		Handle:        res.Handle,
+		EmailPrivacy:  res.Privacy.Email.String(),
+		PhonePrivacy:  res.Privacy.Phone.String(),
+	}
+	if !res.DeleteAt.IsZero() {
+		resp.DeleteAt = res.DeleteAt.Format(time.DateTime)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (u *UserHandler) ChangePassword(c *gin.Context) {
+	type ChangePasswordReq struct {
+		OldPassword     string `json:"oldPassword"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+	var req ChangePasswordReq
+	if err := c.Bind(&req); err != nil {
+		return
+	}
+	if !u.checkNewPassword(c, req.Password, req.ConfirmPassword) {
+		return
+	}
+	uc := c.MustGet("claims").(*ijwt.UserClaims)
+	err := u.svc.ChangePassword(c, uc.Uid, req.OldPassword, req.Password)
+	if errors.Is(err, service.ErrInvalidUserOrPassword) {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "旧密码不对",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("修改密码失败", zap.Int64("uid", uc.Uid), zap.Error(err))
+		return
+	}
+	// 其它设备上的登录全部失效，当前设备换一组新的 token 继续使用
+	err = u.RevokeOtherSessions(c, uc)
+	if err != nil {
+		// 密码已经改好了，只是需要重新登录
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "密码已经修改，请重新登录",
+		})
+		zap.L().Error("修改密码之后撤销登录失败", zap.Int64("uid", uc.Uid), zap.Error(err))
+		return
+	}
+	c.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
 	})
 }
 
+func (u *UserHandler) SendPasswordResetCode(c *gin.Context) {
+	type Req struct {
+		// Account 邮箱或者手机号
+		Account string `json:"account"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		return
+	}
+	if req.Account == "" {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "请输入邮箱或者手机号",
+		})
+		return
+	}
+	err := u.resetSvc.SendCode(c, req.Account, c.ClientIP())
+	switch {
+	case err == nil:
+		// 不管账号是否存在都返回一样的结果
+		c.JSON(http.StatusOK, ginx.Result{
+			Msg: "如果账号存在，验证码已经发送",
+		})
+	case errors.Is(err, service.ErrPasswordResetTooMany):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "操作太频繁，请稍后再试",
+		})
+	default:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("发送找回密码验证码失败", zap.Error(err))
+	}
+}
+
+func (u *UserHandler) ResetPassword(c *gin.Context) {
+	type Req struct {
+		Account         string `json:"account"`
+		Code            string `json:"code"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		return
+	}
+	if !u.checkNewPassword(c, req.Password, req.ConfirmPassword) {
+		return
+	}
+	uid, err := u.resetSvc.Reset(c, req.Account, req.Code, req.Password, c.ClientIP())
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrPasswordResetTooMany):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "操作太频繁，请稍后再试",
+		})
+		return
+	case errors.Is(err, service.ErrPasswordResetInvalid):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "账号或者验证码不对",
+		})
+		return
+	default:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("找回密码失败", zap.Error(err))
+		return
+	}
+	// 忘记密码的时候可能账号已经被别人登录了，所有设备都要重新登录
+	if err = u.RevokeSessions(c, uid); err != nil {
+		zap.L().Error("找回密码之后撤销登录失败", zap.Int64("uid", uid), zap.Error(err))
+	}
+	c.JSON(http.StatusOK, ginx.Result{
+		Msg: "密码已经重置，请重新登录",
+	})
+}
+
+func (u *UserHandler) SendLoginUnlockCode(c *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+		// Channel email 或者 sms，默认发到邮箱
+		Channel string `json:"channel"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		return
+	}
+	err := u.guardSvc.SendUnlockCode(c, req.Email, req.Channel)
+	switch {
+	case err == nil:
+		// 不管账号是否存在都返回一样的结果
+		c.JSON(http.StatusOK, ginx.Result{
+			Msg: "如果账号存在，验证码已经发送",
+		})
+	default:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("发送登录解锁验证码失败", zap.Error(err))
+	}
+}
+
+func (u *UserHandler) UnlockLogin(c *gin.Context) {
+	type Req struct {
+		Email   string `json:"email"`
+		Channel string `json:"channel"`
+		Code    string `json:"code"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		return
+	}
+	err := u.guardSvc.Unlock(c, req.Email, req.Channel, req.Code)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, ginx.Result{
+			Msg: "账号已经解锁，请重新登录",
+		})
+	case errors.Is(err, service.ErrLoginUnlockInvalid):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "账号或者验证码不对",
+		})
+	default:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("解锁登录失败", zap.Error(err))
+	}
+}
+
+func (u *UserHandler) VerifyEmail(c *gin.Context) {
+	type Req struct {
+		Code string `json:"code"`
+	}
+	var req Req
+	if err := c.Bind(&req); err != nil {
+		return
+	}
+	uc := c.MustGet("claims").(*ijwt.UserClaims)
+	err := u.verifySvc.Verify(c, uc.Uid, req.Code)
+	switch {
+	case err == nil, errors.Is(err, service.ErrEmailAlreadyVerified):
+		c.JSON(http.StatusOK, ginx.Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrEmailVerifyInvalid):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "验证码不对或者已经失效，请重新发送",
+		})
+	default:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("验证邮箱失败", zap.Int64("uid", uc.Uid), zap.Error(err))
+	}
+}
+
+func (u *UserHandler) ResendEmailCode(c *gin.Context) {
+	uc := c.MustGet("claims").(*ijwt.UserClaims)
+	err := u.verifySvc.SendCode(c, uc.Uid)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, ginx.Result{
+			Msg: "验证码已经发送",
+		})
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "邮箱已经验证过了",
+		})
+	case errors.Is(err, service.ErrCodeSendTooMany):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "发送太频繁，请稍后再试",
+		})
+	default:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("重新发送邮箱验证码失败", zap.Int64("uid", uc.Uid), zap.Error(err))
+	}
+}
+
+// checkNewPassword 校验新密码，和注册的规则一样，不通过的时候已经写好了响应
+func (u *UserHandler) checkNewPassword(c *gin.Context, password, confirmPassword string) bool {
+	if password != confirmPassword {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "两次输入的密码不一致",
+		})
+		return false
+	}
+	ok, err := u.passwordExp.MatchString(password)
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "密码必须大于8位，且包含特殊字符",
+		})
+		return false
+	}
+	return true
+}
+
 func (u *UserHandler) RefreshToekn(c *gin.Context) {
 	// 只有这个接口, 拿出来的才是 refresh-token, 其余的都是 access-token
 	refresh_token := u.ExtractToken(c)
@@ -447,7 +984,7 @@ func (u *UserHandler) RefreshToekn(c *gin.Context) {
 		return
 	}
 
-	err = u.CheckSession(c, rc.Ssid)
+	err = u.CheckSession(c, rc.Uid, rc.Ssid, ijwt.IssuedAt(rc.RegisteredClaims))
 	if err != nil {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
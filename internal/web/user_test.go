@@ -6,11 +6,16 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/pkg/ginx"
+	"xiaoweishu/internal/pkg/logger"
 	"xiaoweishu/internal/service"
+	"xiaoweishu/internal/service/email"
 	svcmocks "xiaoweishu/internal/service/mocks"
+	ijwt "xiaoweishu/internal/web/jwt"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -32,18 +37,40 @@ import (
 func TestUserHandler_SignUp(t *testing.T) {
 	testCases := []struct {
 		name     string
-		mock     func(ctrl *gomock.Controller) service.UserService
+		mock     func(ctrl *gomock.Controller) (service.UserService, service.CodeService)
 		reqBody  string
 		wantCode int
 		wantBody string
 	}{
 		{
 			name: "注册成功",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				userSvc := svcmocks.NewMockUserService(ctrl)
 				// 注册成功 return nil
 				userSvc.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(nil).Times(1)
-				return userSvc
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().SendEmail(gomock.Any(), email.BizSignupVerify, "1234@qq.com").Return(nil)
+				return userSvc, codeSvc
+			},
+			reqBody: `
+{
+	"email":"1234@qq.com",
+	"password":"hello@world123",
+	"confirmPassword":"hello@world123"
+}
+`,
+			wantCode: http.StatusOK,
+			wantBody: "注册成功!",
+		},
+		{
+			name: "验证码发送失败也注册成功",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(nil)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().SendEmail(gomock.Any(), email.BizSignupVerify, "1234@qq.com").
+					Return(service.ErrCodeSendTooMany)
+				return userSvc, codeSvc
 			},
 			reqBody: `
 {
@@ -57,9 +84,9 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "参数不对, bind 失败",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				userSvc := svcmocks.NewMockUserService(ctrl)
-				return userSvc
+				return userSvc, nil
 			},
 			reqBody: `
 {
@@ -72,9 +99,9 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "邮箱格式有误",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				userSvc := svcmocks.NewMockUserService(ctrl)
-				return userSvc
+				return userSvc, nil
 			},
 			reqBody: `
 {
@@ -88,9 +115,9 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "两次输入的密码不一致",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				userSvc := svcmocks.NewMockUserService(ctrl)
-				return userSvc
+				return userSvc, nil
 			},
 			reqBody: `
 {
@@ -104,9 +131,9 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "密码格式不对",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				userSvc := svcmocks.NewMockUserService(ctrl)
-				return userSvc
+				return userSvc, nil
 			},
 			reqBody: `
 {
@@ -120,11 +147,11 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "邮箱冲突",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				userSvc := svcmocks.NewMockUserService(ctrl)
 				// 注册成功 return nil
 				userSvc.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(service.ErrUserDuplicate).Times(1)
-				return userSvc
+				return userSvc, nil
 			},
 			reqBody: `
 {
@@ -138,11 +165,11 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "系统异常",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				userSvc := svcmocks.NewMockUserService(ctrl)
 				// 注册成功 return nil
 				userSvc.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(errors.New("系统异常")).Times(1)
-				return userSvc
+				return userSvc, nil
 			},
 			reqBody: `
 {
@@ -162,8 +189,8 @@ func TestUserHandler_SignUp(t *testing.T) {
 			defer ctrl.Finish()
 
 			server := gin.Default()
-			// SignUp 没有使用CodeService
-			h := NewUserHandler(tc.mock(ctrl), nil, nil)
+			userSvc, codeSvc := tc.mock(ctrl)
+			h := NewUserHandler(userSvc, codeSvc, nil, nil, nil, nil, nil, nil, nil)
 			h.RegisterRoutes(server)
 
 			req, err := http.NewRequest(http.MethodPost, "/users/signup", bytes.NewBuffer([]byte(tc.reqBody)))
@@ -275,6 +302,61 @@ func TestUserHandler_LoginSMS(t *testing.T) {
 				Msg:  "手机号/验证码错误",
 			},
 		},
+		{
+			name: "账号已经被封禁",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				svc := svcmocks.NewMockUserService(ctrl)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), biz, gomock.Any(), "123456").Return(true, nil).Times(1)
+				svc.EXPECT().FindOrCreate(gomock.Any(), "12345678901").Return(domain.User{
+					Id:    1,
+					Phone: "12345678901",
+					Ban:   domain.UserBan{Status: domain.UserBanStatusBanned, Reason: "发广告"},
+				}, nil).Times(1)
+				return svc, codeSvc
+			},
+			reqBody: `
+{
+	"phone":"12345678901",
+	"code":"123456"
+}
+`,
+			wantCode: http.StatusOK,
+			wantBody: ginx.Result{
+				Code: CodeLoginBanned,
+				Msg:  "账号已经被封禁",
+				Data: map[string]any{"reason": "发广告"},
+			},
+		},
+		{
+			name: "封禁已经到期",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				svc := svcmocks.NewMockUserService(ctrl)
+				codeSvc := svcmocks.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), biz, gomock.Any(), "123456").Return(true, nil).Times(1)
+				svc.EXPECT().FindOrCreate(gomock.Any(), "12345678901").Return(domain.User{
+					Id:    1,
+					Phone: "12345678901",
+					Ban: domain.UserBan{
+						Status: domain.UserBanStatusBanned,
+						Reason: "发广告",
+						Until:  time.Now().Add(-time.Minute),
+					},
+				}, nil).Times(1)
+				return svc, codeSvc
+			},
+			reqBody: `
+{
+	"phone":"12345678901",
+	"code":"123456"
+}
+`,
+			wantCode: http.StatusOK,
+			wantBody: ginx.Result{
+				Code: http.StatusOK,
+				Msg:  "短信验证成功",
+			},
+		},
 		{
 			name: "用户不存在/创建用户失败",
 			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
@@ -305,7 +387,25 @@ func TestUserHandler_LoginSMS(t *testing.T) {
 
 			server := gin.Default()
 			userSvc, codeSvc := tc.mock(ctrl)
-			h := NewUserHandler(userSvc, codeSvc, nil)
+			notifySvc := svcmocks.NewMockNotificationService(ctrl)
+			// 登录成功会发送登录提醒
+			notifySvc.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			// 登录成功会记录会话
+			sessionSvc := svcmocks.NewMockSessionService(ctrl)
+			sessionSvc.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			// 登录记录，这里都当作正常的登录
+			auditSvc := svcmocks.NewMockLoginAuditService(ctrl)
+			auditSvc.EXPECT().RecordFailure(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			auditSvc.EXPECT().Check(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(domain.LoginChallenge{}, false, nil).AnyTimes()
+			// 没有开启两步验证
+			mfaSvc := svcmocks.NewMockMFAService(ctrl)
+			mfaSvc.EXPECT().Enabled(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+			// 签发 token 的时候会查询角色
+			roleSvc := svcmocks.NewMockUserRoleService(ctrl)
+			roleSvc.EXPECT().Roles(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+			h := NewUserHandler(userSvc, codeSvc, nil, nil, auditSvc, nil, nil, mfaSvc,
+				ijwt.NewRedisJwtHandler(nil, notifySvc, nil, sessionSvc, roleSvc, &logger.NopLogger{}))
 			h.RegisterRoutes(server)
 
 			req, err := http.NewRequest(http.MethodPost, "/users/login_sms", bytes.NewBuffer([]byte(tc.reqBody)))
@@ -322,3 +422,122 @@ func TestUserHandler_LoginSMS(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_Edit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.UserService
+		reqBody  string
+		wantCode int
+		wantBody ginx.Result
+	}{
+		{
+			name: "修改成功",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				svc := svcmocks.NewMockUserService(ctrl)
+				svc.EXPECT().UpdateNonSensitiveInfo(gomock.Any(), domain.User{
+					Id:       123,
+					NickName: "小微",
+					Birthday: "2000-01-02",
+					AboutMe:  "你好",
+				}).Return(nil)
+				return svc
+			},
+			reqBody:  `{"nickname":" 小微 ","birthday":"2000-01-02","about_me":"你好"}`,
+			wantCode: http.StatusOK,
+			wantBody: ginx.Result{
+				Msg: "OK",
+			},
+		},
+		{
+			name: "昵称为空",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(ctrl)
+			},
+			reqBody:  `{"nickname":"  ","birthday":"2000-01-02"}`,
+			wantCode: http.StatusOK,
+			wantBody: ginx.Result{
+				Code: 4,
+				Msg:  "昵称不能为空",
+			},
+		},
+		{
+			name: "生日格式不对",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(ctrl)
+			},
+			reqBody:  `{"nickname":"小微","birthday":"2000/01/02"}`,
+			wantCode: http.StatusOK,
+			wantBody: ginx.Result{
+				Code: 4,
+				Msg:  "生日格式不对，应该是 YYYY-MM-DD",
+			},
+		},
+		{
+			name: "生日在未来",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(ctrl)
+			},
+			reqBody:  `{"nickname":"小微","birthday":"` + time.Now().AddDate(0, 0, 2).Format(time.DateOnly) + `"}`,
+			wantCode: http.StatusOK,
+			wantBody: ginx.Result{
+				Code: 4,
+				Msg:  "生日不能是未来的日期",
+			},
+		},
+		{
+			name: "个人简介过长",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return svcmocks.NewMockUserService(ctrl)
+			},
+			reqBody:  `{"nickname":"小微","about_me":"` + strings.Repeat("长", aboutMeMaxLen+1) + `"}`,
+			wantCode: http.StatusOK,
+			wantBody: ginx.Result{
+				Code: 4,
+				Msg:  "个人简介过长",
+			},
+		},
+		{
+			name: "系统错误",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				svc := svcmocks.NewMockUserService(ctrl)
+				svc.EXPECT().UpdateNonSensitiveInfo(gomock.Any(), gomock.Any()).Return(errors.New("mock error"))
+				return svc
+			},
+			reqBody:  `{"nickname":"小微"}`,
+			wantCode: http.StatusOK,
+			wantBody: ginx.Result{
+				Code: 5,
+				Msg:  "系统错误",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{
+					Uid: 123,
+				})
+			})
+			h := NewUserHandler(tc.mock(ctrl), nil, nil, nil, nil, nil, nil, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/users/edit", bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			var res ginx.Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
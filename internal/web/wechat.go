@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/pkg/ginx"
 	"xiaoweishu/internal/service"
 	"xiaoweishu/internal/service/oauth2/wechat"
@@ -13,6 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	uuid "github.com/lithammer/shortuuid/v4"
+	"go.uber.org/zap"
 )
 
 type WechatHandlerConfig struct {
@@ -20,17 +22,32 @@ type WechatHandlerConfig struct {
 }
 
 type Oauth2WechatHandler struct {
-	svc     wechat.Service
-	userSvc service.UserService
+	svc      wechat.Service
+	userSvc  service.UserService
+	bindSvc  service.AccountBindService
+	mergeSvc service.AccountMergeService
+	auditSvc service.LoginAuditService
+	mfaSvc   service.MFAService
 	ijwt.Handler
 	stateKey []byte
 	cfg      WechatHandlerConfig
 }
 
-func NewOauth2WechatHandler(svc wechat.Service, userSvc service.UserService, cfg WechatHandlerConfig, jwtHdl ijwt.Handler) *Oauth2WechatHandler {
+func NewOauth2WechatHandler(svc wechat.Service,
+	userSvc service.UserService,
+	bindSvc service.AccountBindService,
+	mergeSvc service.AccountMergeService,
+	auditSvc service.LoginAuditService,
+	mfaSvc service.MFAService,
+	cfg WechatHandlerConfig,
+	jwtHdl ijwt.Handler) *Oauth2WechatHandler {
 	return &Oauth2WechatHandler{
 		svc:      svc,
 		userSvc:  userSvc,
+		bindSvc:  bindSvc,
+		mergeSvc: mergeSvc,
+		auditSvc: auditSvc,
+		mfaSvc:   mfaSvc,
 		stateKey: []byte("KntbYH88cXJHKDRdFrXrQjh5yZp7c5QQXKh3MXJHwYFnt2v43wGCy2d8XCSpmwPjFy"),
 		cfg:      cfg,
 		Handler:  jwtHdl,
@@ -40,10 +57,29 @@ func NewOauth2WechatHandler(svc wechat.Service, userSvc service.UserService, cfg
 func (h *Oauth2WechatHandler) RegisterRoutes(server *gin.Engine) {
 	ug := server.Group("/oauth2/wechat")
 	ug.GET("/authurl", h.AuthURL)
+	// 已经登录的用户绑定微信，回调还是同一个地址
+	ug.GET("/bindurl", h.BindURL)
+	// 把微信注册的账号合并到当前登录的账号
+	ug.GET("/mergeurl", h.MergeURL)
 	ug.Any("/callback", h.Callback)
 }
 
 func (h *Oauth2WechatHandler) AuthURL(c *gin.Context) {
+	h.authURL(c, 0, false)
+}
+
+func (h *Oauth2WechatHandler) BindURL(c *gin.Context) {
+	uc := c.MustGet("claims").(*ijwt.UserClaims)
+	h.authURL(c, uc.Uid, false)
+}
+
+func (h *Oauth2WechatHandler) MergeURL(c *gin.Context) {
+	uc := c.MustGet("claims").(*ijwt.UserClaims)
+	h.authURL(c, uc.Uid, true)
+}
+
+// authURL uid 不为 0 的时候回调里面绑定或者合并到这个用户，而不是登录
+func (h *Oauth2WechatHandler) authURL(c *gin.Context, uid int64, merge bool) {
 	state := uuid.New()
 	url, err := h.svc.AuthUrl(c, state)
 	if err != nil {
@@ -53,7 +89,7 @@ func (h *Oauth2WechatHandler) AuthURL(c *gin.Context) {
 		})
 		return
 	}
-	if err := h.SetStateCookie(c, state); err != nil {
+	if err := h.SetStateCookie(c, state, uid, merge); err != nil {
 		c.JSON(http.StatusOK, ginx.Result{
 			Code: 5,
 			Msg:  "系统异常",
@@ -65,19 +101,33 @@ func (h *Oauth2WechatHandler) AuthURL(c *gin.Context) {
 
 func (h *Oauth2WechatHandler) Callback(c *gin.Context) {
 	code := c.Query("code")
-	err := h.verifyState(c)
+	sc, err := h.verifyState(c)
 	if err != nil {
 		c.JSON(http.StatusOK, ginx.Result{
 			Code: http.StatusInternalServerError,
 			Msg:  "登陆失败",
 		})
+		return
 	}
 	info, err := h.svc.VerifyCode(c, code)
 	if err != nil {
+		// 只有登录需要记录，绑定和合并的时候用户已经登录了
+		if sc.Uid == 0 {
+			recordLoginFailure(c, h.auditSvc, domain.LoginMethodWechat, "", "微信授权失败")
+		}
 		c.JSON(http.StatusOK, ginx.Result{
 			Code: http.StatusInternalServerError,
 			Msg:  "系统错误",
 		})
+		return
+	}
+	if sc.Uid > 0 && sc.Merge {
+		h.merge(c, sc.Uid, info)
+		return
+	}
+	if sc.Uid > 0 {
+		h.bind(c, sc.Uid, info)
+		return
 	}
 	// 这里需要设置为登录态, 需要 set token, 还需要拿到 uid
 	u, err := h.userSvc.FindOrCreateByWechat(c, info)
@@ -86,30 +136,89 @@ func (h *Oauth2WechatHandler) Callback(c *gin.Context) {
 			Code: http.StatusInternalServerError,
 			Msg:  "系统错误",
 		})
+		return
+	}
+
+	if !finishLogin(c, h.auditSvc, h.mfaSvc, h.Handler, u, domain.LoginMethodWechat, info.OpenID) {
+		return
 	}
 
-	if err := h.SetLoginToken(c, u.Id); err != nil {
+	c.JSON(http.StatusOK, ginx.Result{
+		Code: http.StatusOK,
+		Data: info,
+	})
+}
+
+func (h *Oauth2WechatHandler) bind(c *gin.Context, uid int64, info domain.WechatInfo) {
+	err := h.bindSvc.BindWechat(c, uid, info)
+	if errors.Is(err, service.ErrUserWechatDuplicate) {
 		c.JSON(http.StatusOK, ginx.Result{
-			Code: http.StatusInternalServerError,
+			Code: CodeUserWechatBound,
+			Msg:  "微信已经被其它账号绑定",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
 			Msg:  "系统错误",
 		})
+		zap.L().Error("绑定微信失败", zap.Int64("uid", uid), zap.Error(err))
 		return
 	}
+	c.JSON(http.StatusOK, ginx.Result{
+		Msg: "OK",
+	})
+}
 
+func (h *Oauth2WechatHandler) merge(c *gin.Context, uid int64, info domain.WechatInfo) {
+	log, err := h.mergeSvc.MergeWechat(c, uid, info)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrMergeTargetInvalid):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 4,
+			Msg:  "这个微信没有注册过账号",
+		})
+		return
+	case errors.Is(err, service.ErrUserMerged):
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: CodeUserMerged,
+			Msg:  "账号已经被合并过了",
+		})
+		return
+	default:
+		c.JSON(http.StatusOK, ginx.Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		zap.L().Error("合并微信账号失败", zap.Int64("uid", uid), zap.Error(err))
+		return
+	}
+	// 被合并账号的登录全部失效
+	if err = h.RevokeSessions(c, log.MergedId); err != nil {
+		zap.L().Error("撤销被合并账号的登录失败", zap.Int64("uid", log.MergedId), zap.Error(err))
+	}
 	c.JSON(http.StatusOK, ginx.Result{
-		Code: http.StatusOK,
-		Data: info,
+		Msg:  "OK",
+		Data: newUserMergeLogVO(log),
 	})
 }
 
 type StateClaims struct {
 	jwt.RegisteredClaims
-	state string
+	State string
+	// Uid 绑定或者合并微信的时候是当前登录的用户，登录的时候是 0
+	Uid int64
+	// Merge 合并微信注册的账号，而不是绑定
+	Merge bool
 }
 
-func (h *Oauth2WechatHandler) SetStateCookie(c *gin.Context, state string) error {
+func (h *Oauth2WechatHandler) SetStateCookie(c *gin.Context, state string, uid int64, merge bool) error {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, StateClaims{
-		state: state,
+		State: state,
+		Uid:   uid,
+		Merge: merge,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
 		},
@@ -122,7 +231,7 @@ func (h *Oauth2WechatHandler) SetStateCookie(c *gin.Context, state string) error
 	return nil
 }
 
-func (h *Oauth2WechatHandler) verifyState(c *gin.Context) error {
+func (h *Oauth2WechatHandler) verifyState(c *gin.Context) (StateClaims, error) {
 	state := c.Query("state")
 	ck, err := c.Cookie("jwt-state")
 	if err != nil {
@@ -130,7 +239,7 @@ func (h *Oauth2WechatHandler) verifyState(c *gin.Context) error {
 			Code: http.StatusInternalServerError,
 			Msg:  "系统错误",
 		})
-		return fmt.Errorf("拿不到 state 的 cookie, %w", err)
+		return StateClaims{}, fmt.Errorf("拿不到 state 的 cookie, %w", err)
 	}
 	var sc StateClaims
 	token, err := jwt.ParseWithClaims(ck, &sc, func(token *jwt.Token) (interface{}, error) {
@@ -141,10 +250,10 @@ func (h *Oauth2WechatHandler) verifyState(c *gin.Context) error {
 			Code: http.StatusInternalServerError,
 			Msg:  "系统错误",
 		})
-		return fmt.Errorf("token 已过期, %w", err)
+		return StateClaims{}, fmt.Errorf("token 已过期, %w", err)
 	}
-	if sc.state != state {
-		return errors.New("state 不匹配")
+	if sc.State != state {
+		return StateClaims{}, errors.New("state 不匹配")
 	}
-	return nil
+	return sc, nil
 }
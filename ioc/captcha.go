@@ -0,0 +1,17 @@
+package ioc
+
+import (
+	"time"
+	limiter "xiaoweishu/internal/pkg/ratelimit"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func InitCaptchaService(repo repository.CaptchaRepository, cmd redis.Cmdable) service.CaptchaService {
+	// 同一个手机号每小时发送 3 次以内、同一个 IP 每小时发送 10 次以内不需要图片验证码
+	return service.NewCaptchaService(repo,
+		limiter.NewRedisSlidingWindowLimiter(cmd, time.Hour, 3),
+		limiter.NewRedisSlidingWindowLimiter(cmd, time.Hour, 10))
+}
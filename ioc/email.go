@@ -0,0 +1,50 @@
+package ioc
+
+import (
+	"time"
+	limiter "xiaoweishu/internal/pkg/ratelimit"
+	"xiaoweishu/internal/service/email"
+	"xiaoweishu/internal/service/email/failover"
+	"xiaoweishu/internal/service/email/memory"
+	"xiaoweishu/internal/service/email/ratelimit"
+	"xiaoweishu/internal/service/email/retryable"
+	"xiaoweishu/internal/service/email/smtp"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// InitEmailService 没有配置 SMTP 服务器的时候使用内存实现，邮件只会打印出来
+// 配置了备用的 SMTP 服务器的时候轮流使用，一个失败了就换下一个
+func InitEmailService(cmd redis.Cmdable) email.Service {
+	tpls, err := email.NewBuiltinTemplates()
+	if err != nil {
+		panic(err)
+	}
+	var cfg smtp.Config
+	if err = viper.UnmarshalKey("email.smtp", &cfg); err != nil {
+		panic(err)
+	}
+	if cfg.Host == "" {
+		return memory.NewService(tpls)
+	}
+	var backups []smtp.Config
+	if err = viper.UnmarshalKey("email.failover", &backups); err != nil {
+		panic(err)
+	}
+	svcs := make([]email.Service, 0, len(backups)+1)
+	for _, c := range append([]smtp.Config{cfg}, backups...) {
+		svc, err := smtp.NewService(c, tpls)
+		if err != nil {
+			panic(err)
+		}
+		// 每个服务器单独限流，被限流了 failover 会换下一个
+		svcs = append(svcs, ratelimit.NewRatelimitEmailService(svc,
+			limiter.NewRedisSlidingWindowLimiter(cmd, time.Second, 20), "smtp:"+c.Host))
+	}
+	svc := svcs[0]
+	if len(svcs) > 1 {
+		svc = failover.NewFailoverEmailService(svcs...)
+	}
+	return retryable.NewService(svc, 3)
+}
@@ -0,0 +1,20 @@
+package ioc
+
+import (
+	"strings"
+	"xiaoweishu/internal/service"
+
+	"github.com/spf13/viper"
+)
+
+func NewFeedConfig() service.FeedConfig {
+	cfg := service.FeedConfig{
+		SiteName: "小微书",
+		BaseURL:  "http://localhost:8080",
+	}
+	if err := viper.UnmarshalKey("feed", &cfg); err != nil {
+		panic(err)
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return cfg
+}
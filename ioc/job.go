@@ -0,0 +1,45 @@
+package ioc
+
+import (
+	"time"
+	"xiaoweishu/internal/job"
+	"xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
+	ijwt "xiaoweishu/internal/web/jwt"
+
+	"github.com/spf13/viper"
+)
+
+func InitScheduler(l logger.LoggerV1,
+	purgeJob *job.ArticlePurgeJob,
+	draftFlushJob *job.ArticleDraftFlushJob,
+	unverifiedPurgeJob *job.UnverifiedUserPurgeJob,
+	deletionJob *job.AccountDeletionJob,
+	loginLogPurgeJob *job.LoginLogPurgeJob) *job.Scheduler {
+	return job.NewScheduler(l).
+		Register(purgeJob, time.Hour).
+		Register(draftFlushJob, time.Minute).
+		Register(unverifiedPurgeJob, time.Hour).
+		Register(deletionJob, time.Hour).
+		Register(loginLogPurgeJob, time.Hour)
+}
+
+func InitUnverifiedUserPurgeJob(svc service.EmailVerifyService,
+	jwtHdl ijwt.Handler,
+	l logger.LoggerV1) *job.UnverifiedUserPurgeJob {
+	// 默认保留 7 天
+	retention := time.Hour * 24 * 7
+	if viper.IsSet("user.unverifiedRetention") {
+		retention = viper.GetDuration("user.unverifiedRetention")
+	}
+	return job.NewUnverifiedUserPurgeJob(svc, jwtHdl, retention, l)
+}
+
+func InitLoginLogPurgeJob(svc service.LoginAuditService, l logger.LoggerV1) *job.LoginLogPurgeJob {
+	// 默认保留 180 天，常用设备和地点也只按照这段时间内的登录判断
+	retention := time.Hour * 24 * 180
+	if viper.IsSet("user.loginLogRetention") {
+		retention = viper.GetDuration("user.loginLogRetention")
+	}
+	return job.NewLoginLogPurgeJob(svc, retention, l)
+}
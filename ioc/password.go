@@ -0,0 +1,36 @@
+package ioc
+
+import (
+	"time"
+	limiter "xiaoweishu/internal/pkg/ratelimit"
+	"xiaoweishu/internal/repository"
+	"xiaoweishu/internal/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func InitPasswordResetService(repo repository.UserRepository,
+	codeSvc service.CodeService,
+	cmd redis.Cmdable) service.PasswordResetService {
+	// 同一个账号每小时最多尝试 5 次，同一个 IP 每小时最多 20 次
+	return service.NewPasswordResetService(repo, codeSvc,
+		limiter.NewRedisSlidingWindowLimiter(cmd, time.Hour, 5),
+		limiter.NewRedisSlidingWindowLimiter(cmd, time.Hour, 20))
+}
+
+func InitAccountMergeService(repo repository.UserMergeRepository,
+	userRepo repository.UserRepository,
+	codeSvc service.CodeService,
+	cmd redis.Cmdable) service.AccountMergeService {
+	// 合并账号可以用密码证明，同一个账号每小时最多尝试 10 次
+	return service.NewAccountMergeService(repo, userRepo, codeSvc,
+		limiter.NewRedisSlidingWindowLimiter(cmd, time.Hour, 10))
+}
+
+func InitMFAService(repo repository.UserMFARepository,
+	userRepo repository.UserRepository,
+	cmd redis.Cmdable) service.MFAService {
+	// 关闭两步验证要输入密码和验证码，同一个用户每小时最多尝试 5 次
+	return service.NewMFAService(repo, userRepo,
+		limiter.NewRedisSlidingWindowLimiter(cmd, time.Hour, 5))
+}
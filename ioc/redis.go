@@ -8,7 +8,8 @@ import (
 	"github.com/spf13/viper"
 )
 
-func InitRedis() redis.Cmdable {
+// InitRedis 返回 UniversalClient，实时推送需要用到 pub/sub
+func InitRedis() redis.UniversalClient {
 	type Config struct {
 		Addr string `yaml:"addr"`
 	}
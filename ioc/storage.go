@@ -0,0 +1,33 @@
+package ioc
+
+import (
+	"xiaoweishu/internal/service/storage"
+	"xiaoweishu/internal/service/storage/local"
+
+	"github.com/spf13/viper"
+)
+
+// staticPath 本地存储的文件由 web 服务器在这个路径下提供访问
+const staticPath = "/static"
+
+type StorageConfig struct {
+	// Dir 文件存放的目录
+	Dir string `yaml:"dir"`
+	// BaseURL 文件访问地址的前缀，要和 staticPath 对应
+	BaseURL string `yaml:"baseURL"`
+}
+
+func NewStorageConfig() StorageConfig {
+	cfg := StorageConfig{
+		Dir:     "./data/static",
+		BaseURL: "http://localhost:8080" + staticPath,
+	}
+	if err := viper.UnmarshalKey("storage", &cfg); err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func InitFileStorage(cfg StorageConfig) storage.Service {
+	return local.NewService(cfg.Dir, cfg.BaseURL)
+}
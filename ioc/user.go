@@ -1,17 +1,34 @@
 package ioc
 
 import (
+	"time"
 	"xiaoweishu/internal/pkg/logger"
 	"xiaoweishu/internal/repository"
 	"xiaoweishu/internal/service"
+	"xiaoweishu/internal/service/storage"
 
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
-func InitUserHandler(repo repository.UserRepository) service.UserService {
+func InitUserHandler(repo repository.UserRepository,
+	notifySvc service.NotificationService,
+	store storage.Service) service.UserService {
 	l, err := zap.NewDevelopment()
 	if err != nil {
 		panic(err)
 	}
-	return service.NewUserService(repo, logger.NewZapLogger(l))
+	return service.NewUserService(repo, notifySvc, store, logger.NewZapLogger(l))
+}
+
+func NewAccountDeletionConfig() service.AccountDeletionConfig {
+	// 默认 15 天冷静期，文章保留
+	cfg := service.AccountDeletionConfig{
+		Cooldown: time.Hour * 24 * 15,
+		Articles: service.AccountDeletionKeepArticles,
+	}
+	if err := viper.UnmarshalKey("user.deletion", &cfg); err != nil {
+		panic(err)
+	}
+	return cfg
 }
@@ -4,9 +4,11 @@ import (
 	"context"
 	"strings"
 	"time"
+	"xiaoweishu/internal/domain"
 	"xiaoweishu/internal/pkg/ginx/middlewares/logger"
 	"xiaoweishu/internal/pkg/ginx/middlewares/ratelimit"
 	lg "xiaoweishu/internal/pkg/logger"
+	"xiaoweishu/internal/service"
 	"xiaoweishu/internal/web"
 	ijwt "xiaoweishu/internal/web/jwt"
 	"xiaoweishu/internal/web/middleware"
@@ -18,16 +20,57 @@ import (
 	"github.com/spf13/viper"
 )
 
-func InitWebServer(mdls []gin.HandlerFunc, userHdl *web.UserHandler, oauth2Hdl *web.Oauth2WechatHandler, articleHdl *web.ArticleHandler) *gin.Engine {
+func InitWebServer(mdls []gin.HandlerFunc,
+	storageCfg StorageConfig,
+	userHdl *web.UserHandler,
+	profileHdl *web.ProfileHandler,
+	bindHdl *web.AccountBindHandler,
+	mergeHdl *web.AccountMergeHandler,
+	dataHdl *web.AccountDataHandler,
+	sessionHdl *web.SessionHandler,
+	loginAuditHdl *web.LoginAuditHandler,
+	captchaHdl *web.CaptchaHandler,
+	mfaHdl *web.MFAHandler,
+	oauth2Hdl *web.Oauth2WechatHandler,
+	articleHdl *web.ArticleHandler,
+	archiveHdl *web.ArticleArchiveHandler,
+	draftHdl *web.ArticleDraftHandler,
+	reportHdl *web.ReportHandler,
+	feedHdl *web.FeedHandler,
+	seriesHdl *web.SeriesHandler,
+	notifyHdl *web.NotificationHandler,
+	pushHdl *web.PushHandler,
+	adminHdl *web.AdminHandler) *gin.Engine {
 	server := gin.Default()
 	server.Use(mdls...)
+	server.Static(staticPath, storageCfg.Dir)
 	userHdl.RegisterRoutes(server)
+	profileHdl.RegisterRoutes(server)
+	bindHdl.RegisterRoutes(server)
+	mergeHdl.RegisterRoutes(server)
+	dataHdl.RegisterRoutes(server)
+	sessionHdl.RegisterRoutes(server)
+	loginAuditHdl.RegisterRoutes(server)
+	captchaHdl.RegisterRoutes(server)
+	mfaHdl.RegisterRoutes(server)
 	oauth2Hdl.RegisterRoutes(server)
 	articleHdl.RegisterRoutes(server)
+	archiveHdl.RegisterRoutes(server)
+	draftHdl.RegisterRoutes(server)
+	reportHdl.RegisterRoutes(server)
+	feedHdl.RegisterRoutes(server)
+	seriesHdl.RegisterRoutes(server)
+	notifyHdl.RegisterRoutes(server)
+	pushHdl.RegisterRoutes(server)
+	adminHdl.RegisterRoutes(server)
 	return server
 }
 
-func InitMiddlewares(redisClient redis.Cmdable, jwtHdl ijwt.Handler, l lg.LoggerV1) []gin.HandlerFunc {
+func InitMiddlewares(redisClient redis.Cmdable,
+	jwtHdl ijwt.Handler,
+	userSvc service.UserService,
+	sessionSvc service.SessionService,
+	l lg.LoggerV1) []gin.HandlerFunc {
 	bd := logger.NewBuilder(func(c context.Context, al *logger.AccessLog) {
 		l.Debug("HTTP 请求", lg.Field{Key: "al", Value: al})
 	}).AllowReqBody(true).AllowRespBody()
@@ -39,14 +82,60 @@ func InitMiddlewares(redisClient redis.Cmdable, jwtHdl ijwt.Handler, l lg.Logger
 		corsHdl(),
 		bd.Build(),
 		ratelimit.NewBuilder(NewRateLimiter(redisClient, time.Second, 100)).Build(),
-		middleware.NewLoginJWTMiddlewareBuilder(jwtHdl).
+		middleware.NewLoginJWTMiddlewareBuilder(jwtHdl, sessionSvc).
 			IgnorePaths("/users/login").
 			IgnorePaths("/users/signup").
 			IgnorePaths("/users/refresh_token").
 			IgnorePaths("/users/sms/login/send").
 			IgnorePaths("/oauth2/wechat/authurl").
-			IgnorePaths("oauth2/wechat/callback").
-			IgnorePaths("/users/sms/login/verify").Build(),
+			IgnorePaths("/oauth2/wechat/callback").
+			IgnorePaths("/users/sms/login/verify").
+			IgnorePaths("/users/login/challenge").
+			IgnorePaths("/users/login/mfa").
+			IgnorePaths("/users/login/unlock/send").
+			IgnorePaths("/users/login/unlock").
+			IgnorePaths("/captcha").
+			IgnorePaths("/users/password/reset/send").
+			IgnorePaths("/users/password/reset").
+			IgnorePaths("/users/:id/profile").
+			IgnorePaths("/users/:id/feed.xml").
+			IgnorePaths("/users/:id/atom.xml").
+			IgnorePaths("/feed.xml").
+			IgnorePaths("/atom.xml").
+			IgnorePaths("/events").
+			IgnorePaths(staticPath + "/*filepath").
+			// 订阅里面的文章链接，没有登录的读者也要能打开
+			OptionalPaths("/articles/pub/:id").Build(),
+		// 没有验证邮箱或者被限制的用户只能浏览，不能发表、修改内容，不能举报和修改资料
+		middleware.NewAccountStateMiddlewareBuilder(userSvc, l).
+			LimitPaths("/articles/edit").
+			LimitPaths("/articles/publish").
+			LimitPaths("/articles/autosave").
+			LimitPaths("/articles/import").
+			LimitPaths("/articles/delete").
+			LimitPaths("/articles/restore").
+			LimitPaths("/series/create").
+			LimitPaths("/series/rename").
+			LimitPaths("/series/reorder").
+			LimitPaths("/series/add").
+			LimitPaths("/series/remove").
+			LimitPaths("/reports").
+			LimitPaths("/users/edit").
+			LimitPaths("/users/avatar").
+			LimitPaths("/users/handle").Build(),
+		// 管理后台按照 token 里面的角色校验权限
+		middleware.NewPermissionMiddlewareBuilder().
+			DenyPrefix("/admin/").
+			Require("/admin/users/search", domain.PermUserRead).
+			Require("/admin/users/:id/ban", domain.PermUserBan).
+			Require("/admin/users/:id/unban", domain.PermUserBan).
+			Require("/admin/articles/:id", domain.PermArticleRead).
+			Require("/admin/articles/:id/hide", domain.PermArticleHide).
+			Require("/admin/audit_logs", domain.PermAuditLogRead).
+			DenyPrefix("/reports/review/").
+			Require("/reports/review/list", domain.PermReportReview).
+			Require("/reports/review/accept", domain.PermReportReview).
+			Require("/reports/review/reject", domain.PermReportReview).Build(),
 	}
 }
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 
@@ -16,8 +17,16 @@ import (
 func main() {
 	initViperV1()
 	initLogger()
-	server := InitWebServer()
+	app := InitApp()
+	app.scheduler.Start()
+	defer app.scheduler.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = app.pushSvc.Run(ctx)
+	}()
 
+	server := app.server
 	server.GET("/hello", func(c *gin.Context) {
 		c.String(http.StatusOK, "hello world")
 	})
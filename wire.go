@@ -3,6 +3,7 @@
 package main
 
 import (
+	"xiaoweishu/internal/job"
 	"xiaoweishu/internal/repository"
 	"xiaoweishu/internal/repository/cache"
 	"xiaoweishu/internal/repository/dao"
@@ -11,41 +12,133 @@ import (
 	ijwt "xiaoweishu/internal/web/jwt"
 	"xiaoweishu/ioc"
 
-	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 )
 
-func InitWebServer() *gin.Engine {
+func InitApp() *App {
 	wire.Build(
 		// DB
 		ioc.InitDB,
 		// Cache
 		ioc.InitRedis,
+		wire.Bind(new(redis.Cmdable), new(redis.UniversalClient)),
 		//Logger
 		ioc.InitLogger,
 		// DAO
 		dao.NewUserDao,
+		dao.NewGormUserMergeDao,
+		dao.NewGormUserDeletionDao,
+		dao.NewGormArticleDao,
+		dao.NewGormReportDao,
+		dao.NewGormSeriesDao,
+		dao.NewGormNotificationDao,
+		dao.NewGormLoginLogDao,
+		dao.NewGormUserMFADao,
+		dao.NewGormUserRoleDao,
+		dao.NewGormAdminAuditLogDao,
 		cache.NewUserCache,
 		cache.NewCodeCache,
+		cache.NewFeedCache,
+		cache.NewNotificationCache,
+		cache.NewPushCache,
+		cache.NewSessionCache,
+		cache.NewLoginChallengeCache,
+		cache.NewLoginGuardCache,
+		cache.NewCaptchaCache,
+		cache.NewMFAPendingCache,
+		cache.NewArticleDraftCache,
 		// Repository
 		repository.NewUserRepository,
+		repository.NewUserMergeRepository,
+		repository.NewUserDeletionRepository,
 		repository.NewCodeRepository,
+		repository.NewArticleRepository,
+		repository.NewReportRepository,
+		repository.NewFeedRepository,
+		repository.NewSeriesRepository,
+		repository.NewNotificationRepository,
+		repository.NewPushRepository,
+		repository.NewSessionRepository,
+		repository.NewLoginLogRepository,
+		repository.NewLoginGuardRepository,
+		repository.NewCaptchaRepository,
+		repository.NewUserMFARepository,
+		repository.NewArticleDraftRepository,
+		repository.NewUserRoleRepository,
+		repository.NewAdminAuditLogRepository,
 		// Service
 		service.NewUserService,
 		service.NewCodeService,
+		ioc.InitPasswordResetService,
+		service.NewEmailVerifyService,
+		service.NewAccountBindService,
+		ioc.InitAccountMergeService,
+		ioc.NewAccountDeletionConfig,
+		service.NewAccountDataService,
+		service.NewArticleService,
+		service.NewArticleArchiveService,
+		service.NewArticleDraftService,
+		service.NewReportService,
+		ioc.NewFeedConfig,
+		service.NewFeedService,
+		service.NewSeriesService,
+		service.NewNotificationService,
+		service.NewPushService,
+		service.NewSessionService,
+		service.NewLoginAuditService,
+		service.NewLoginGuardService,
+		ioc.InitCaptchaService,
+		ioc.InitMFAService,
+		service.NewUserRoleService,
+		service.NewAdminService,
+		service.NewProfileService,
 		ioc.InitSmsService,
+		ioc.InitEmailService,
+		ioc.NewStorageConfig,
+		ioc.InitFileStorage,
 		ioc.InitOauth2WechatService,
 		// Handler
 		ijwt.NewRedisJwtHandler,
+		wire.Bind(new(ijwt.Notifier), new(service.NotificationService)),
+		wire.Bind(new(ijwt.Pusher), new(service.PushService)),
+		wire.Bind(new(ijwt.SessionStore), new(service.SessionService)),
+		wire.Bind(new(ijwt.RoleProvider), new(service.UserRoleService)),
 		web.NewUserHandler,
+		web.NewProfileHandler,
+		web.NewAccountBindHandler,
+		web.NewAccountMergeHandler,
+		web.NewAccountDataHandler,
+		web.NewSessionHandler,
+		web.NewLoginAuditHandler,
+		web.NewCaptchaHandler,
+		web.NewMFAHandler,
 		web.NewArticleHandler,
+		web.NewArticleArchiveHandler,
+		web.NewArticleDraftHandler,
 		ioc.NewWechatHandlerConfig,
 		web.NewOauth2WechatHandler,
+		web.NewReportHandler,
+		web.NewFeedHandler,
+		web.NewSeriesHandler,
+		web.NewNotificationHandler,
+		web.NewPushHandler,
+		web.NewAdminHandler,
 
 		// middlewares
 		ioc.InitMiddlewares,
 
 		ioc.InitWebServer,
+
+		// jobs
+		job.NewArticlePurgeJob,
+		job.NewArticleDraftFlushJob,
+		ioc.InitUnverifiedUserPurgeJob,
+		ioc.InitLoginLogPurgeJob,
+		job.NewAccountDeletionJob,
+		ioc.InitScheduler,
+
+		wire.Struct(new(App), "*"),
 	)
-	return new(gin.Engine)
+	return new(App)
 }
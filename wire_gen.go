@@ -7,7 +7,7 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
+	"xiaoweishu/internal/job"
 	"xiaoweishu/internal/repository"
 	"xiaoweishu/internal/repository/cache"
 	"xiaoweishu/internal/repository/dao"
@@ -23,24 +23,113 @@ import (
 
 // Injectors from wire.go:
 
-func InitWebServer() *gin.Engine {
-	cmdable := ioc.InitRedis()
-	handler := jwt.NewRedisJwtHandler(cmdable)
+func InitApp() *App {
+	universalClient := ioc.InitRedis()
 	loggerV1 := ioc.InitLogger()
-	v := ioc.InitMiddlewares(cmdable, handler, loggerV1)
-	db := ioc.InitDB()
+	db := ioc.InitDB(loggerV1)
+	notificationDao := dao.NewGormNotificationDao(db)
+	notificationCache := cache.NewNotificationCache(universalClient)
+	notificationRepository := repository.NewNotificationRepository(notificationDao, notificationCache)
+	notificationService := service.NewNotificationService(notificationRepository)
+	pushCache := cache.NewPushCache(universalClient)
+	pushRepository := repository.NewPushRepository(pushCache)
+	pushService := service.NewPushService(pushRepository, loggerV1)
+	sessionCache := cache.NewSessionCache(universalClient)
+	sessionRepository := repository.NewSessionRepository(sessionCache)
+	sessionService := service.NewSessionService(sessionRepository)
+	userRoleDao := dao.NewGormUserRoleDao(db)
+	userRoleRepository := repository.NewUserRoleRepository(userRoleDao)
+	userRoleService := service.NewUserRoleService(userRoleRepository)
+	handler := jwt.NewRedisJwtHandler(universalClient, notificationService, pushService, sessionService, userRoleService, loggerV1)
 	userDao := dao.NewUserDao(db)
-	userCache := cache.NewUserCache(cmdable)
+	userCache := cache.NewUserCache(universalClient)
 	userRepository := repository.NewUserRepository(userDao, userCache)
-	userService := service.NewUserService(userRepository, loggerV1)
-	codeCache := cache.NewCodeCache(cmdable)
+	storageConfig := ioc.NewStorageConfig()
+	storageService := ioc.InitFileStorage(storageConfig)
+	userService := service.NewUserService(userRepository, notificationService, storageService, loggerV1)
+	v := ioc.InitMiddlewares(universalClient, handler, userService, sessionService, loggerV1)
+	codeCache := cache.NewCodeCache(universalClient)
 	codeRepository := repository.NewCodeRepository(codeCache)
-	smsService := ioc.InitSmsService(cmdable)
-	codeService := service.NewCodeService(codeRepository, smsService)
-	userHandler := web.NewUserHandler(userService, codeService, cmdable)
+	smsService := ioc.InitSmsService(universalClient)
+	emailService := ioc.InitEmailService(universalClient)
+	codeService := service.NewCodeService(codeRepository, smsService, emailService)
+	passwordResetService := ioc.InitPasswordResetService(userRepository, codeService, universalClient)
+	emailVerifyService := service.NewEmailVerifyService(userRepository, codeService)
+	loginLogDao := dao.NewGormLoginLogDao(db)
+	loginChallengeCache := cache.NewLoginChallengeCache(universalClient)
+	loginLogRepository := repository.NewLoginLogRepository(loginLogDao, loginChallengeCache)
+	userMFADao := dao.NewGormUserMFADao(db)
+	mfaPendingCache := cache.NewMFAPendingCache(universalClient)
+	userMFARepository := repository.NewUserMFARepository(userMFADao, mfaPendingCache)
+	loginAuditService := service.NewLoginAuditService(loginLogRepository, userRepository, userMFARepository, codeService, notificationService, loggerV1)
+	loginGuardCache := cache.NewLoginGuardCache(universalClient)
+	loginGuardRepository := repository.NewLoginGuardRepository(loginGuardCache)
+	loginGuardService := service.NewLoginGuardService(loginGuardRepository, userRepository, codeService, loggerV1)
+	captchaCache := cache.NewCaptchaCache(universalClient)
+	captchaRepository := repository.NewCaptchaRepository(captchaCache)
+	captchaService := ioc.InitCaptchaService(captchaRepository, universalClient)
+	mfaService := ioc.InitMFAService(userMFARepository, userRepository, universalClient)
+	userHandler := web.NewUserHandler(userService, codeService, passwordResetService, emailVerifyService, loginAuditService, loginGuardService, captchaService, mfaService, handler)
+	articleDao := dao.NewGormArticleDao(db)
+	articleRepository := repository.NewArticleRepository(articleDao)
+	seriesDao := dao.NewGormSeriesDao(db)
+	seriesRepository := repository.NewSeriesRepository(seriesDao)
+	profileService := service.NewProfileService(userService, userRepository, articleRepository, seriesRepository)
+	profileHandler := web.NewProfileHandler(profileService, loggerV1)
+	accountBindService := service.NewAccountBindService(userRepository, codeService)
+	accountBindHandler := web.NewAccountBindHandler(accountBindService, loggerV1)
+	userMergeDao := dao.NewGormUserMergeDao(db)
+	userMergeRepository := repository.NewUserMergeRepository(userMergeDao, userCache, notificationCache)
+	accountMergeService := ioc.InitAccountMergeService(userMergeRepository, userRepository, codeService, universalClient)
+	accountMergeHandler := web.NewAccountMergeHandler(accountMergeService, handler, loggerV1)
+	userDeletionDao := dao.NewGormUserDeletionDao(db)
+	userDeletionRepository := repository.NewUserDeletionRepository(userDeletionDao, userCache, notificationCache)
+	reportDao := dao.NewGormReportDao(db)
+	reportRepository := repository.NewReportRepository(reportDao)
+	accountDeletionConfig := ioc.NewAccountDeletionConfig()
+	accountDataService := service.NewAccountDataService(userService, userDeletionRepository, articleRepository, seriesRepository, notificationRepository, reportRepository, userMergeRepository, loginLogRepository, storageService, accountDeletionConfig, loggerV1)
+	accountDataHandler := web.NewAccountDataHandler(accountDataService, loggerV1)
+	sessionHandler := web.NewSessionHandler(sessionService, handler, loggerV1)
+	loginAuditHandler := web.NewLoginAuditHandler(loginAuditService, mfaService, userService, handler, loggerV1)
+	captchaHandler := web.NewCaptchaHandler(captchaService, loggerV1)
+	mfaHandler := web.NewMFAHandler(mfaService, loginAuditService, userService, handler, loggerV1)
 	wechatService := ioc.InitOauth2WechatService(loggerV1)
 	wechatHandlerConfig := ioc.NewWechatHandlerConfig()
-	oauth2WechatHandler := web.NewOauth2WechatHandler(wechatService, userService, wechatHandlerConfig, handler)
-	engine := ioc.InitWebServer(v, userHandler, oauth2WechatHandler)
-	return engine
+	oauth2WechatHandler := web.NewOauth2WechatHandler(wechatService, userService, accountBindService, accountMergeService, loginAuditService, mfaService, wechatHandlerConfig, handler)
+	articleService := service.NewArticleService(articleRepository)
+	seriesService := service.NewSeriesService(seriesRepository, articleRepository)
+	articleHandler := web.NewArticleHandler(articleService, seriesService, userService, loggerV1)
+	articleArchiveService := service.NewArticleArchiveService(articleService, articleRepository)
+	articleArchiveHandler := web.NewArticleArchiveHandler(articleArchiveService, loggerV1)
+	articleDraftCache := cache.NewArticleDraftCache(universalClient)
+	articleDraftRepository := repository.NewArticleDraftRepository(articleDraftCache)
+	articleDraftService := service.NewArticleDraftService(articleDraftRepository, articleRepository, articleService, loggerV1)
+	articleDraftHandler := web.NewArticleDraftHandler(articleDraftService, loggerV1)
+	adminAuditLogDao := dao.NewGormAdminAuditLogDao(db)
+	adminAuditLogRepository := repository.NewAdminAuditLogRepository(adminAuditLogDao)
+	reportService := service.NewReportService(reportRepository, articleRepository, userRepository, adminAuditLogRepository, loggerV1)
+	reportHandler := web.NewReportHandler(reportService, loggerV1)
+	feedCache := cache.NewFeedCache(universalClient)
+	feedRepository := repository.NewFeedRepository(feedCache)
+	feedConfig := ioc.NewFeedConfig()
+	feedService := service.NewFeedService(feedRepository, articleRepository, userRepository, feedConfig, loggerV1)
+	feedHandler := web.NewFeedHandler(feedService, loggerV1)
+	seriesHandler := web.NewSeriesHandler(seriesService, loggerV1)
+	notificationHandler := web.NewNotificationHandler(notificationService, loggerV1)
+	pushHandler := web.NewPushHandler(pushService, handler, loggerV1)
+	adminService := service.NewAdminService(userRepository, articleRepository, adminAuditLogRepository)
+	adminHandler := web.NewAdminHandler(adminService, handler, loggerV1)
+	engine := ioc.InitWebServer(v, storageConfig, userHandler, profileHandler, accountBindHandler, accountMergeHandler, accountDataHandler, sessionHandler, loginAuditHandler, captchaHandler, mfaHandler, oauth2WechatHandler, articleHandler, articleArchiveHandler, articleDraftHandler, reportHandler, feedHandler, seriesHandler, notificationHandler, pushHandler, adminHandler)
+	articlePurgeJob := job.NewArticlePurgeJob(articleService, loggerV1)
+	articleDraftFlushJob := job.NewArticleDraftFlushJob(articleDraftService, loggerV1)
+	unverifiedUserPurgeJob := ioc.InitUnverifiedUserPurgeJob(emailVerifyService, handler, loggerV1)
+	accountDeletionJob := job.NewAccountDeletionJob(accountDataService, handler, loggerV1)
+	loginLogPurgeJob := ioc.InitLoginLogPurgeJob(loginAuditService, loggerV1)
+	scheduler := ioc.InitScheduler(loggerV1, articlePurgeJob, articleDraftFlushJob, unverifiedUserPurgeJob, accountDeletionJob, loginLogPurgeJob)
+	app := &App{
+		server:    engine,
+		scheduler: scheduler,
+		pushSvc:   pushService,
+	}
+	return app
 }